@@ -3,17 +3,32 @@ package main
 import (
 	configs "g37-lanchonete/configs"
 	"g37-lanchonete/internal/api"
+	"g37-lanchonete/internal/controllers"
 	"g37-lanchonete/internal/controllers/_api"
 	"g37-lanchonete/internal/core/usecases"
+	"g37-lanchonete/internal/core/usecases/dto"
 	authorizerDriver "g37-lanchonete/internal/infra/drivers/auth"
+	eventsDriver "g37-lanchonete/internal/infra/drivers/events"
 	httpDriver "g37-lanchonete/internal/infra/drivers/http"
+	loyaltyDriver "g37-lanchonete/internal/infra/drivers/loyalty"
 	paymentDriver "g37-lanchonete/internal/infra/drivers/payment"
+	qrcodeDriver "g37-lanchonete/internal/infra/drivers/qrcode"
 	sqlDriver "g37-lanchonete/internal/infra/drivers/sql"
 	"g37-lanchonete/internal/infra/gateways"
 
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	// the runtime image has no zoneinfo, the business hours timezone is loaded from the embedded database
+	_ "time/tzdata"
+
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
+	log "github.com/sirupsen/logrus"
 )
 
 func main() {
@@ -23,39 +38,136 @@ func main() {
 		panic(err)
 	}
 
+	err = dto.SetOrderStatuses(appConfig.OrderStatuses)
+	if err != nil {
+		panic(err)
+	}
+
+	paymentMethods, err := dto.ParsePaymentMethods(appConfig.PaymentMethods)
+	if err != nil {
+		panic(err)
+	}
+
+	businessHours, err := usecases.NewBusinessHours(appConfig.BusinessHours, appConfig.BusinessHoursTimezone)
+	if err != nil {
+		panic(err)
+	}
+
+	log.SetFormatter(&log.TextFormatter{FullTimestamp: true})
+	logger := api.NewServiceLogger("g37-lanches")
+
 	httpClient := httpDriver.NewHttpClient()
 	postgresSQLClient := createPostgresSQLClient(appConfig)
+	api.LogLifecycle(logger, api.LifecycleDatabaseConnected, log.Fields{"host": appConfig.DatabaseHost, "database": appConfig.DatabaseName})
+
 	err = performMigrations(postgresSQLClient)
 	if err != nil {
 		panic(err)
 	}
+	api.LogLifecycle(logger, api.LifecycleMigrationsApplied, nil)
 
 	authorizerClient := httpDriver.NewHttpClient()
 	authorizer := authorizerDriver.NewAuthorizer(authorizerClient, appConfig.AuthorizerURL)
 
 	paymentBroker := paymentDriver.NewMercadoPagoBroker(httpClient, appConfig.PaymentBrokerURL)
 
+	// without a loyalty service the orders asking for store credit are rejected
+	var storeCreditProvider loyaltyDriver.StoreCreditProvider
+	if appConfig.LoyaltyURL != "" {
+		storeCreditProvider = loyaltyDriver.NewStoreCreditProvider(httpClient, appConfig.LoyaltyURL)
+	}
+
+	eventPublisher := createEventPublisher(appConfig)
+
+	qrCodeRenderer := qrcodeDriver.NewRenderer(appConfig.QRCodeSize)
+
 	customerRepositoryGateway := gateways.NewCustomerRepositoryGateway(postgresSQLClient)
-	productRepositoryGateway := gateways.NewProductRepositoryGateway(postgresSQLClient)
-	orderRepositoryGateway := gateways.NewOrderRepositoryGateway(postgresSQLClient)
+	productRepositoryGateway := gateways.NewCoalescingProductRepositoryGateway(gateways.NewProductRepositoryGateway(postgresSQLClient, appConfig.CategoryMatchMode))
+	orderRepositoryGateway := gateways.NewOrderRepositoryGateway(postgresSQLClient, appConfig.OrdersDefaultSort, appConfig.OrderStations)
+	productPriceHistoryRepositoryGateway := gateways.NewProductPriceHistoryRepositoryGateway(postgresSQLClient)
+	productVariantRepositoryGateway := gateways.NewProductVariantRepositoryGateway(postgresSQLClient)
+	couponRepositoryGateway := gateways.NewCouponRepositoryGateway(postgresSQLClient)
+
+	clock := usecases.NewSystemClock()
+	taxCalculator := usecases.NewTaxCalculator(appConfig.TaxRate, appConfig.TaxCategoryRates, appConfig.OrderMaxLineTotal)
+	categoryPolicy := usecases.NewCategoryPolicy(appConfig.UncategorizedProductMode, appConfig.DefaultProductCategory)
+	exchangeRateProvider := usecases.NewStaticExchangeRateProvider(appConfig.BaseCurrency, appConfig.DisplayCurrencyRates)
+	noteRedactor := usecases.NewNoteRedactor(appConfig.NotesRedactionEnabled, appConfig.NotesRedactionWords)
+	orderNumberGenerator := usecases.NewOrderNumberGenerator(appConfig.OrderNumberMode)
 
 	customerUsecase := usecases.NewCustomerUsecase(customerRepositoryGateway)
-	productUsecase := usecases.NewProductUsecase(productRepositoryGateway)
-	paymentUsecase := usecases.NewPaymentUsecase(appConfig.NotificationURL, appConfig.SponsorId, paymentBroker)
-	authorizerUsecase := usecases.NewAuthorizerUsecase(authorizer)
-	orderUsecase := usecases.NewOrderUsecase(authorizerUsecase, paymentUsecase, productUsecase, orderRepositoryGateway)
+	productUsecase := usecases.NewProductUsecase(productRepositoryGateway, productPriceHistoryRepositoryGateway, productVariantRepositoryGateway, taxCalculator, categoryPolicy)
+	paymentUsecase := usecases.NewPaymentUsecase(appConfig.NotificationURL, appConfig.SponsorId, paymentBroker, paymentMethods, appConfig.PaymentExpiration, clock)
+	authorizerUsecase := usecases.NewAuthorizerUsecase(authorizer, appConfig.AuthorizerCacheTTL, appConfig.AuthorizerNegativeCacheTTL, clock)
+	orderUsecase := usecases.NewOrderUsecase(authorizerUsecase, paymentUsecase, productUsecase, orderRepositoryGateway, couponRepositoryGateway, taxCalculator, noteRedactor, eventPublisher, qrCodeRenderer, orderNumberGenerator, businessHours, storeCreditProvider, exchangeRateProvider, appConfig.OrderReopenGracePeriod, clock)
+	orderUsecase = usecases.NewDedupOrderUsecase(orderUsecase, appConfig.OrderCreationDedupWindow, clock)
+	couponUsecase := usecases.NewCouponUsecase(couponRepositoryGateway, orderUsecase, clock)
+	orderRetentionUsecase := usecases.NewOrderRetentionUsecase(orderRepositoryGateway, appConfig.OrderRetentionPeriod, clock)
+
+	// parsed at startup so a broken template override fails fast, the notifier will use it once it lands
+	_, err = usecases.NewNotificationUsecase(appConfig.NotificationTemplatesDir, appConfig.EstimatedPreparationTime, appConfig.ETARoundingIncrement, clock)
+	if err != nil {
+		panic(err)
+	}
 
 	customerController := _api.NewCustomerController(customerUsecase)
-	productController := _api.NewProductController(productUsecase)
-	orderController := _api.NewOrderController(orderUsecase)
+	productController := controllers.NewProductController(productUsecase, dto.PageLimits{Default: appConfig.ProductsDefaultPageSize, Max: appConfig.ProductsMaxPageSize, WarnClamped: appConfig.WarnClampedPageSize})
+	paymentController := controllers.NewPaymentController(paymentUsecase)
+	couponController := controllers.NewCouponController(couponUsecase)
+	orderController := controllers.NewOrderController(orderUsecase, dto.PageLimits{Default: appConfig.OrdersDefaultPageSize, Max: appConfig.OrdersMaxPageSize, WarnClamped: appConfig.WarnClampedPageSize})
 
 	apiParams := api.ApiParams{
-		CustomerController: customerController,
-		ProductController:  productController,
-		OrderController:    orderController,
+		CustomerController:         customerController,
+		ProductController:          productController,
+		OrderController:            orderController,
+		PaymentController:          paymentController,
+		CouponController:           couponController,
+		StrictJSONBinding:          appConfig.StrictJSONBinding,
+		MaxJSONArrayElements:       appConfig.MaxJSONArrayElements,
+		JSONNaming:                 appConfig.JSONNaming,
+		BasePaths:                  appConfig.BasePaths,
+		TrailingSlash:              appConfig.TrailingSlash,
+		OrderCreationMaxConcurrent: appConfig.OrderCreationMaxConcurrent,
+		OrderCreationQueueTimeout:  appConfig.OrderCreationQueueTimeout,
+		ReadRateLimit:              appConfig.ReadRateLimit,
+		ReadRateLimitWindow:        appConfig.ReadRateLimitWindow,
+		AuthRouteRoles:             appConfig.AuthRouteRoles,
+		AuthTokenRoles:             appConfig.AuthTokenRoles,
+		AuthTokenSubjects:          appConfig.AuthTokenSubjects,
+	}
+	router := api.NewApi(apiParams)
+
+	listener, err := net.Listen("tcp", ":8080")
+	if err != nil {
+		panic(err)
+	}
+
+	if appConfig.OrderRetentionPeriod > 0 {
+		go runOrderRetentionJob(orderRetentionUsecase, appConfig.OrderRetentionInterval)
+	}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+
+	err = api.Serve(router, listener, stop, appConfig.ShutdownTimeout, logger)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// runOrderRetentionJob anonymizes the expired orders on startup and then on every interval, for as long as the service runs.
+func runOrderRetentionJob(orderRetentionUsecase usecases.OrderRetentionUsecase, interval time.Duration) {
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		// failures are logged by the usecase, the next run tries again
+		_, _ = orderRetentionUsecase.AnonymizeExpiredOrders()
+		<-ticker.C
 	}
-	api := api.NewApi(apiParams)
-	api.Run(":8080")
 }
 
 func createPostgresSQLClient(appConfig configs.AppConfig) sqlDriver.SQLClient {
@@ -64,9 +176,20 @@ func createPostgresSQLClient(appConfig configs.AppConfig) sqlDriver.SQLClient {
 		panic("failed to connect database")
 	}
 
-	err = db.Ping()
+	err = sqlDriver.WaitForDatabase(db, appConfig.DatabaseConnectMaxAttempts, appConfig.DatabaseConnectInterval, sqlDriver.NewSystemSleeper())
 	if err != nil {
-		panic("failed to ping database")
+		panic(err)
+	}
+
+	db = sqlDriver.NewDeadlockRetrySQLClient(db, appConfig.DeadlockRetryMaxAttempts, appConfig.DeadlockRetryBackoff, sqlDriver.NewSystemSleeper())
+
+	if appConfig.RepositoryDelay > 0 {
+		if appConfig.Environment == "prod" || appConfig.Environment == "production" {
+			log.Warnf("ignoring repository delay [%s] on environment [%s]", appConfig.RepositoryDelay, appConfig.Environment)
+			return db
+		}
+		log.Warnf("adding a synthetic delay of [%s] to every database call", appConfig.RepositoryDelay)
+		return sqlDriver.NewDelayedSQLClient(db, appConfig.RepositoryDelay, sqlDriver.NewSystemSleeper())
 	}
 
 	return db
@@ -92,3 +215,25 @@ func performMigrations(client sqlDriver.SQLClient) error {
 
 	return nil
 }
+
+func createEventPublisher(appConfig configs.AppConfig) eventsDriver.Publisher {
+	sleeper := sqlDriver.NewSystemSleeper()
+	deadLetter := eventsDriver.NewLogDeadLetter()
+
+	publishers := []eventsDriver.Publisher{
+		eventsDriver.NewRetryingPublisher(eventsDriver.NewLogPublisher(), deadLetter, sleeper,
+			appConfig.EventPublishMaxAttempts, appConfig.EventPublishBackoff),
+	}
+
+	webhookClient := &http.Client{Timeout: appConfig.WebhookTimeout}
+	for _, partner := range appConfig.WebhookPartners {
+		webhook := eventsDriver.Webhook{Partner: partner.Name, URL: partner.URL, Secret: partner.Secret}
+		// each partner retries on its own, so a failing partner does not deliver twice to the others, and on its own
+		// worker, so an unreachable partner does not hold the order being updated
+		retrying := eventsDriver.NewRetryingPublisher(eventsDriver.NewWebhookPublisher(webhookClient, webhook), deadLetter, sleeper,
+			appConfig.WebhookMaxAttempts, appConfig.WebhookBackoff)
+		publishers = append(publishers, eventsDriver.NewAsyncPublisher(partner.Name, retrying, deadLetter, appConfig.WebhookQueueSize))
+	}
+
+	return eventsDriver.NewMultiPublisher(publishers...)
+}
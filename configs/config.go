@@ -2,6 +2,8 @@ package configs
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
@@ -11,9 +13,53 @@ type Config struct {
 	viper *viper.Viper
 }
 
+type routeAuthRequirement struct {
+	Method string   `mapstructure:"method"`
+	Path   string   `mapstructure:"path"`
+	Roles  []string `mapstructure:"roles"`
+}
+
+type apiToken struct {
+	Token   string `mapstructure:"token"`
+	Role    string `mapstructure:"role"`
+	Subject string `mapstructure:"subject"`
+}
+
+type paymentMethod struct {
+	Name    string `mapstructure:"name"`
+	Enabled bool   `mapstructure:"enabled"`
+}
+
+type WebhookPartner struct {
+	Name   string `mapstructure:"name"`
+	URL    string `mapstructure:"url"`
+	Secret string `mapstructure:"secret"`
+}
+
 type AppConfig struct {
 	Environment string
 
+	StrictJSONBinding    bool
+	MaxJSONArrayElements int
+	JSONNaming           string
+	BasePaths            []string
+	TrailingSlash        string
+	ShutdownTimeout      time.Duration
+	ReadRateLimit        int
+	ReadRateLimitWindow  time.Duration
+
+	ProductsDefaultPageSize int
+	ProductsMaxPageSize     int
+	OrdersDefaultPageSize   int
+	OrdersMaxPageSize       int
+	WarnClampedPageSize     bool
+
+	// AuthRouteRoles maps "METHOD /path", relative to the base paths, to the roles allowed to call it. Routes not listed are public.
+	AuthRouteRoles map[string][]string
+	AuthTokenRoles map[string]string
+	// AuthTokenSubjects maps the tokens to who they belong to, defaulting to their role.
+	AuthTokenSubjects map[string]string
+
 	DatabaseHost     string
 	DatabasePort     string
 	DatabaseName     string
@@ -21,7 +67,19 @@ type AppConfig struct {
 	DatabasePassword string
 	DatabaseSSLMode  string
 
-	AuthorizerURL string
+	DatabaseConnectMaxAttempts int
+	DatabaseConnectInterval    time.Duration
+
+	DeadlockRetryMaxAttempts int
+	DeadlockRetryBackoff     time.Duration
+
+	RepositoryDelay time.Duration
+
+	AuthorizerURL              string
+	AuthorizerCacheTTL         time.Duration
+	AuthorizerNegativeCacheTTL time.Duration
+
+	LoyaltyURL string
 
 	SQSRegion   string
 	SQSEndpoint string
@@ -29,6 +87,62 @@ type AppConfig struct {
 	PaymentBrokerURL string
 	NotificationURL  string
 	SponsorId        string
+	QRCodeSize       int
+	// PaymentMethods lists only the enabled methods, in the configured order.
+	PaymentMethods []string
+	// PaymentExpiration is how long the payment qrcodes can be paid, 0 keeps them from expiring.
+	PaymentExpiration time.Duration
+
+	TaxRate float64
+	// TaxCategoryRates maps the lowercase tax categories of the products to their rates.
+	TaxCategoryRates map[string]float64
+
+	BaseCurrency string
+	// DisplayCurrencyRates maps the currencies the order totals can be shown in to the rate from the base currency.
+	DisplayCurrencyRates map[string]float64
+
+	EventPublishMaxAttempts int
+	EventPublishBackoff     time.Duration
+
+	WebhookPartners    []WebhookPartner
+	WebhookTimeout     time.Duration
+	WebhookMaxAttempts int
+	WebhookBackoff     time.Duration
+	// WebhookQueueSize caps the events waiting to be delivered to each partner, the ones beyond it go to the dead letter.
+	WebhookQueueSize int
+
+	OrderStatuses   []string
+	OrderNumberMode string
+
+	OrderRetentionPeriod   time.Duration
+	OrderRetentionInterval time.Duration
+
+	// BusinessHours maps the lowercase weekday names to their "HH:MM-HH:MM" opening hours.
+	BusinessHours         map[string]string
+	BusinessHoursTimezone string
+
+	OrderReopenGracePeriod time.Duration
+	OrderMaxLineTotal      float64
+	// OrdersDefaultSort is the sort of GET /v1/orders when no sort is asked: "newest", "oldest" or "priority".
+	OrdersDefaultSort string
+	// OrderStations maps each preparation station to the product categories prepared at it, filtering the queue.
+	OrderStations map[string][]string
+
+	OrderCreationMaxConcurrent int
+	OrderCreationQueueTimeout  time.Duration
+	// OrderCreationDedupWindow answers the same order submitted again by the customer within it with the first one.
+	OrderCreationDedupWindow time.Duration
+
+	UncategorizedProductMode string
+	DefaultProductCategory   string
+	CategoryMatchMode        string
+
+	NotesRedactionEnabled bool
+	NotesRedactionWords   []string
+
+	NotificationTemplatesDir string
+	EstimatedPreparationTime time.Duration
+	ETARoundingIncrement     time.Duration
 }
 
 func NewConfig() *Config {
@@ -85,18 +199,126 @@ func (c *Config) extractConfigVars() (AppConfig, error) {
 
 	appConfig.Environment = c.viper.GetString("ENVIRONMENT")
 
+	appConfig.StrictJSONBinding = c.viper.GetBool("api.strictJsonBinding")
+	appConfig.MaxJSONArrayElements = c.viper.GetInt("api.maxJsonArrayElements")
+	appConfig.JSONNaming = c.viper.GetString("api.jsonNaming")
+	appConfig.BasePaths = c.viper.GetStringSlice("api.basePaths")
+	appConfig.TrailingSlash = c.viper.GetString("api.trailingSlash")
+	appConfig.ShutdownTimeout = c.viper.GetDuration("api.shutdownTimeout")
+	appConfig.ReadRateLimit = c.viper.GetInt("api.readRateLimit.requests")
+	appConfig.ReadRateLimitWindow = c.viper.GetDuration("api.readRateLimit.window")
+
+	appConfig.ProductsDefaultPageSize = c.viper.GetInt("pagination.products.defaultLimit")
+	appConfig.ProductsMaxPageSize = c.viper.GetInt("pagination.products.maxLimit")
+	appConfig.OrdersDefaultPageSize = c.viper.GetInt("pagination.orders.defaultLimit")
+	appConfig.OrdersMaxPageSize = c.viper.GetInt("pagination.orders.maxLimit")
+	appConfig.WarnClampedPageSize = c.viper.GetBool("pagination.warnClampedLimit")
+
+	var routes []routeAuthRequirement
+	if err := c.viper.UnmarshalKey("auth.routes", &routes); err != nil {
+		return AppConfig{}, fmt.Errorf("failed to read auth routes, error: %v", err)
+	}
+	appConfig.AuthRouteRoles = make(map[string][]string, len(routes))
+	for _, route := range routes {
+		key := strings.ToUpper(route.Method) + " " + route.Path
+		appConfig.AuthRouteRoles[key] = route.Roles
+	}
+
+	var tokens []apiToken
+	if err := c.viper.UnmarshalKey("auth.tokens", &tokens); err != nil {
+		return AppConfig{}, fmt.Errorf("failed to read auth tokens, error: %v", err)
+	}
+	appConfig.AuthTokenRoles = make(map[string]string, len(tokens))
+	appConfig.AuthTokenSubjects = make(map[string]string, len(tokens))
+	for _, token := range tokens {
+		appConfig.AuthTokenRoles[token.Token] = token.Role
+		appConfig.AuthTokenSubjects[token.Token] = token.Subject
+		if token.Subject == "" {
+			appConfig.AuthTokenSubjects[token.Token] = token.Role
+		}
+	}
+
 	appConfig.DatabaseHost = c.viper.GetString("POSTGRES_HOST")
 	appConfig.DatabasePort = c.viper.GetString("POSTGRES_PORT")
 	appConfig.DatabaseName = c.viper.GetString("POSTGRES_DB")
 	appConfig.DatabaseSSLMode = c.viper.GetString("POSTGRES_SSLMODE")
 	appConfig.DatabaseUser = c.viper.GetString("POSTGRES_USER")
 	appConfig.DatabasePassword = c.viper.GetString("POSTGRES_PASSWORD")
+	appConfig.DatabaseConnectMaxAttempts = c.viper.GetInt("database.connectRetry.maxAttempts")
+	appConfig.DatabaseConnectInterval = c.viper.GetDuration("database.connectRetry.interval")
+	appConfig.DeadlockRetryMaxAttempts = c.viper.GetInt("database.deadlockRetry.maxAttempts")
+	appConfig.DeadlockRetryBackoff = c.viper.GetDuration("database.deadlockRetry.backoff")
+	appConfig.RepositoryDelay = c.viper.GetDuration("chaos.repositoryDelay")
 
 	appConfig.AuthorizerURL = c.viper.GetString("AUTHORIZER_URL")
+	appConfig.AuthorizerCacheTTL = c.viper.GetDuration("authorizer.cache.ttl")
+	appConfig.AuthorizerNegativeCacheTTL = c.viper.GetDuration("authorizer.cache.negativeTtl")
+	appConfig.LoyaltyURL = c.viper.GetString("LOYALTY_URL")
 
 	appConfig.PaymentBrokerURL = c.viper.GetString("paymentBroker.url")
 	appConfig.NotificationURL = c.viper.GetString("paymentBroker.notificationUrl")
 	appConfig.SponsorId = c.viper.GetString("paymentBroker.sponsorId")
+	appConfig.QRCodeSize = c.viper.GetInt("paymentBroker.qrCodeSize")
+
+	appConfig.PaymentExpiration = c.viper.GetDuration("payments.expiration")
+
+	var methods []paymentMethod
+	if err := c.viper.UnmarshalKey("payments.methods", &methods); err != nil {
+		return AppConfig{}, fmt.Errorf("failed to read payment methods, error: %v", err)
+	}
+	for _, method := range methods {
+		if method.Enabled {
+			appConfig.PaymentMethods = append(appConfig.PaymentMethods, strings.ToUpper(method.Name))
+		}
+	}
+
+	appConfig.TaxRate = c.viper.GetFloat64("tax.rate")
+	if err := c.viper.UnmarshalKey("tax.categories", &appConfig.TaxCategoryRates); err != nil {
+		return AppConfig{}, fmt.Errorf("failed to read tax categories, error: %v", err)
+	}
+
+	appConfig.BaseCurrency = c.viper.GetString("currency.base")
+	if err := c.viper.UnmarshalKey("currency.displayRates", &appConfig.DisplayCurrencyRates); err != nil {
+		return AppConfig{}, fmt.Errorf("failed to read display currency rates, error: %v", err)
+	}
+
+	appConfig.EventPublishMaxAttempts = c.viper.GetInt("events.publish.maxAttempts")
+	appConfig.EventPublishBackoff = c.viper.GetDuration("events.publish.backoff")
+
+	if err := c.viper.UnmarshalKey("webhooks.partners", &appConfig.WebhookPartners); err != nil {
+		return AppConfig{}, fmt.Errorf("failed to read webhook partners, error: %v", err)
+	}
+	appConfig.WebhookTimeout = c.viper.GetDuration("webhooks.timeout")
+	appConfig.WebhookMaxAttempts = c.viper.GetInt("webhooks.maxAttempts")
+	appConfig.WebhookBackoff = c.viper.GetDuration("webhooks.backoff")
+	appConfig.WebhookQueueSize = c.viper.GetInt("webhooks.queueSize")
+
+	appConfig.OrderStatuses = c.viper.GetStringSlice("orders.statuses")
+	appConfig.OrderNumberMode = c.viper.GetString("orders.number.mode")
+	appConfig.OrderRetentionPeriod = c.viper.GetDuration("orders.retention.period")
+	appConfig.OrderRetentionInterval = c.viper.GetDuration("orders.retention.interval")
+	appConfig.BusinessHours = c.viper.GetStringMapString("orders.businessHours.days")
+	appConfig.BusinessHoursTimezone = c.viper.GetString("orders.businessHours.timezone")
+	appConfig.OrderReopenGracePeriod = c.viper.GetDuration("orders.reopen.gracePeriod")
+	appConfig.OrderMaxLineTotal = c.viper.GetFloat64("orders.maxLineTotal")
+	appConfig.OrdersDefaultSort = c.viper.GetString("orders.defaultSort")
+	if err := c.viper.UnmarshalKey("orders.stations", &appConfig.OrderStations); err != nil {
+		return AppConfig{}, fmt.Errorf("failed to read order stations, error: %v", err)
+	}
+	appConfig.OrderCreationMaxConcurrent = c.viper.GetInt("orders.creation.maxConcurrent")
+	appConfig.OrderCreationQueueTimeout = c.viper.GetDuration("orders.creation.queueTimeout")
+	appConfig.OrderCreationDedupWindow = c.viper.GetDuration("orders.creation.dedupWindow")
+
+	appConfig.UncategorizedProductMode = c.viper.GetString("products.uncategorized.mode")
+	appConfig.DefaultProductCategory = c.viper.GetString("products.uncategorized.defaultCategory")
+	appConfig.CategoryMatchMode = c.viper.GetString("products.categoryMatch.mode")
+
+	appConfig.NotesRedactionEnabled = c.viper.GetBool("notes.redaction.enabled")
+	appConfig.NotesRedactionWords = c.viper.GetStringSlice("notes.redaction.words")
+
+	appConfig.NotificationTemplatesDir = c.viper.GetString("notifications.templatesDir")
+	appConfig.EstimatedPreparationTime = c.viper.GetDuration("notifications.estimatedPreparationTime")
+	appConfig.ETARoundingIncrement = c.viper.GetDuration("notifications.etaRoundingIncrement")
 
 	return appConfig, nil
-}
\ No newline at end of file
+}
@@ -1,35 +1,157 @@
 package api
 
 import (
+	"g37-lanchonete/internal/controllers"
 	"g37-lanchonete/internal/controllers/_api"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 type ApiParams struct {
 	CustomerController _api.CustomeController
-	ProductController  _api.ProductController
-	OrderController    _api.OrderController
+	ProductController  controllers.ProductController
+	OrderController    controllers.OrderController
+	PaymentController  controllers.PaymentController
+	CouponController   controllers.CouponController
+
+	StrictJSONBinding    bool
+	MaxJSONArrayElements int
+	JSONNaming           string
+	AuthRouteRoles       map[string][]string
+	AuthTokenRoles       map[string]string
+	AuthTokenSubjects    map[string]string
+	// TrailingSlash is how "/v1/products/" is handled: redirected to "/v1/products" (the default), not found when
+	// strict, or served as "/v1/products" when tolerant.
+	TrailingSlash string
+	// BasePaths are the prefixes the routes are registered under, e.g. "/v1" and "/v2" side by side. Defaults to DefaultBasePath.
+	BasePaths []string
+
+	// OrderCreationMaxConcurrent caps the orders being created at the same time, 0 disables the limit.
+	OrderCreationMaxConcurrent int
+	OrderCreationQueueTimeout  time.Duration
+	// ReadRateLimit caps the GET requests of each client within ReadRateLimitWindow, 0 disables the limit.
+	ReadRateLimit       int
+	ReadRateLimitWindow time.Duration
 }
 
+// DefaultBasePath is the prefix of the routes when no base path is configured.
+const DefaultBasePath = "/v1"
+
 func NewApi(params ApiParams) *gin.Engine {
-	router := gin.Default()
-	v1 := router.Group("/v1")
-	{
-		v1.GET("/customers", params.CustomerController.GetCustomers)
-		v1.POST("/customers", params.CustomerController.SaveCustomer)
+	router := gin.New()
+	router.Use(gin.Logger())
+	router.Use(controllers.CorrelationIDMiddleware())
+	// ahead of the recovery, so the error answered for a panic is renamed too
+	if params.JSONNaming == controllers.JSONNamingSnakeCase {
+		router.Use(controllers.SnakeCaseJSONMiddleware())
+	}
+	router.Use(controllers.RecoveryMiddleware())
+	basePaths := []string{DefaultBasePath}
+	if len(params.BasePaths) > 0 {
+		basePaths = make([]string, len(params.BasePaths))
+		for i, basePath := range params.BasePaths {
+			basePaths[i] = normalizeBasePath(basePath)
+		}
+	}
+
+	router.Use(controllers.AuthMiddleware(params.AuthRouteRoles, params.AuthTokenRoles, basePaths))
+	router.Use(controllers.AuthSubjectMiddleware(params.AuthTokenSubjects))
+	// after the auth subject, which tells the clients apart
+	if params.ReadRateLimit > 0 {
+		router.Use(controllers.ReadRateLimitMiddleware(params.ReadRateLimit, params.ReadRateLimitWindow))
+	}
+	if params.StrictJSONBinding {
+		router.Use(controllers.StrictJSONBindingMiddleware())
+	}
+	if params.MaxJSONArrayElements > 0 {
+		router.Use(controllers.JSONArrayLimitMiddleware(params.MaxJSONArrayElements))
+	}
 
-		v1.GET("/products", params.ProductController.GetProducts)
-		v1.POST("/products", params.ProductController.CreateProducts)
-		v1.PUT("/products/:id", params.ProductController.UpdateProduct)
-		v1.DELETE("/products/:id", params.ProductController.DeleteProduct)
+	createOrderHandlers := []gin.HandlerFunc{params.OrderController.CreateOrder}
+	if params.OrderCreationMaxConcurrent > 0 {
+		createOrderHandlers = append([]gin.HandlerFunc{
+			controllers.ConcurrencyLimitMiddleware(params.OrderCreationMaxConcurrent, params.OrderCreationQueueTimeout),
+		}, createOrderHandlers...)
+	}
+
+	for _, basePath := range basePaths {
+		registerRoutes(router.Group(basePath), params, createOrderHandlers)
+	}
 
-		v1.GET("/orders", params.OrderController.GetAllOrders)
-		v1.POST("/orders", params.OrderController.CreateOrder)
-		v1.GET("/orders/:id/status", params.OrderController.GetOrderStatus)
-		v1.PUT("/orders/:id/status", params.OrderController.UpdateOrderStatus)
-		v1.PUT("/orders/:id/payment", params.OrderController.HandleOrderPayment)
+	switch params.TrailingSlash {
+	case controllers.TrailingSlashStrict:
+		router.RedirectTrailingSlash = false
+	case controllers.TrailingSlashTolerant:
+		router.RedirectTrailingSlash = false
+		router.NoRoute(controllers.TrailingSlashTolerantHandler(router))
+	default:
+		router.RedirectTrailingSlash = true
 	}
 
+	router.HandleMethodNotAllowed = true
+	router.NoMethod(controllers.MethodNotAllowedHandler(router.Routes()))
+
 	return router
 }
+
+// normalizeBasePath makes "api/v1/" and "/api/v1" the same prefix, "/" registering the routes at the root.
+func normalizeBasePath(basePath string) string {
+	trimmed := strings.Trim(strings.TrimSpace(basePath), "/")
+	if trimmed == "" {
+		return "/"
+	}
+	return "/" + trimmed
+}
+
+func registerRoutes(group *gin.RouterGroup, params ApiParams, createOrderHandlers []gin.HandlerFunc) {
+	group.GET("/customers", params.CustomerController.GetCustomers)
+	group.POST("/customers", params.CustomerController.SaveCustomer)
+
+	group.GET("/menu", params.ProductController.GetMenu)
+
+	group.GET("/products", params.ProductController.GetProducts)
+	group.POST("/products", params.ProductController.CreateProducts)
+	group.PUT("/products/:id", params.ProductController.UpdateProduct)
+	group.POST("/products/:id/duplicate", params.ProductController.DuplicateProduct)
+	group.DELETE("/products", params.ProductController.BulkDeleteProducts)
+	group.PATCH("/products/availability", params.ProductController.SetCategoryAvailability)
+	group.GET("/products/sku/:skuId", params.ProductController.GetProductBySKU)
+	group.GET("/products/low-stock", params.ProductController.GetLowStockProducts)
+	group.GET("/products/audit", params.ProductController.GetProductsAudit)
+	group.DELETE("/products/:id", params.ProductController.DeleteProduct)
+	group.GET("/products/:id/popularity", params.ProductController.GetProductPopularity)
+	group.GET("/products/:id/price-history", params.ProductController.GetProductPriceHistory)
+	group.GET("/products/:id/ratings", params.ProductController.GetProductRatings)
+	group.GET("/products/:id/variants", params.ProductController.GetProductVariants)
+	group.POST("/products/:id/variants", params.ProductController.CreateProductVariant)
+
+	group.GET("/orders", params.OrderController.GetAllOrders)
+	group.POST("/orders", createOrderHandlers...)
+	group.POST("/orders/batch", params.OrderController.BatchCreateOrders)
+	group.POST("/orders/validate", params.OrderController.ValidateOrder)
+	group.GET("/orders/queue", params.OrderController.GetOrderQueue)
+	group.GET("/orders/status", params.OrderController.GetOrderStatuses)
+	group.GET("/orders/completed", params.OrderController.GetCompletedOrders)
+	group.GET("/orders/throughput", params.OrderController.GetOrderThroughput)
+	group.GET("/orders/number/:number", params.OrderController.GetOrderByNumber)
+	group.DELETE("/orders/customer/:cpf/unpaid", params.OrderController.CancelUnpaidOrders)
+	group.GET("/orders/:id/status", params.OrderController.GetOrderStatus)
+	group.GET("/orders/:id/qrcode.png", params.OrderController.GetOrderQRCode)
+	group.GET("/orders/:id/payment/qr", params.OrderController.GetOrderPaymentQRCode)
+	group.PUT("/orders/:id/status", params.OrderController.UpdateOrderStatus)
+	group.POST("/orders/:id/reopen", params.OrderController.ReopenOrder)
+	group.POST("/orders/:id/recalculate", params.OrderController.RecalculateOrder)
+	group.PUT("/orders/:id/payment", params.OrderController.HandleOrderPayment)
+	group.POST("/orders/:id/items", params.OrderController.AddOrderItem)
+	group.DELETE("/orders/:id/items/:itemId", params.OrderController.RemoveOrderItem)
+	group.PUT("/orders/:id/items/:itemId/status", params.OrderController.UpdateOrderItemStatus)
+	group.POST("/orders/:id/feedback", params.OrderController.SendOrderFeedback)
+
+	group.GET("/payments/methods", params.PaymentController.GetPaymentMethods)
+	group.POST("/payments/webhook/replay", params.OrderController.ReplayPaymentWebhook)
+
+	group.GET("/coupons/usage", params.CouponController.GetCouponUsage)
+	group.POST("/coupons/:code/check", params.CouponController.CheckCoupon)
+}
@@ -0,0 +1,220 @@
+package api
+
+import (
+	"g37-lanchonete/internal/controllers"
+	"g37-lanchonete/internal/core/usecases"
+	"g37-lanchonete/internal/core/usecases/dto"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewApi_MethodNotAllowed(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := NewApi(ApiParams{})
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		statusCode int
+		allow      string
+		respBody   string
+	}{
+		{
+			name:       "should return 405 listing the methods supported by the products collection",
+			method:     http.MethodPatch,
+			path:       "/v1/products",
+			statusCode: http.StatusMethodNotAllowed,
+			allow:      "DELETE, GET, POST",
+			respBody:   `{"message":"method not allowed","error":"method [PATCH] is not supported by [/v1/products]","requestId":"correlation-123"}`,
+		},
+		{
+			name:       "should match the path parameters of the route",
+			method:     http.MethodGet,
+			path:       "/v1/products/10",
+			statusCode: http.StatusMethodNotAllowed,
+			allow:      "DELETE, PUT",
+			respBody:   `{"message":"method not allowed","error":"method [GET] is not supported by [/v1/products/10]","requestId":"correlation-123"}`,
+		},
+		{
+			name:       "should keep returning 404 for unknown paths",
+			method:     http.MethodPatch,
+			path:       "/v1/unknown",
+			statusCode: http.StatusNotFound,
+			respBody:   "404 page not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("X-Correlation-ID", "correlation-123")
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.statusCode, rr.Code)
+			assert.Equal(t, tt.allow, rr.Header().Get("Allow"))
+			assert.Equal(t, tt.respBody, rr.Body.String())
+		})
+	}
+}
+
+func TestNewApi_BasePaths(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		basePaths  []string
+		path       string
+		statusCode int
+	}{
+		{
+			name:       "should register the routes under /v1 when no base path is configured",
+			path:       "/v1/products",
+			statusCode: http.StatusMethodNotAllowed,
+		},
+		{
+			name:       "should register the routes under a custom base path",
+			basePaths:  []string{"api/v2/"},
+			path:       "/api/v2/products",
+			statusCode: http.StatusMethodNotAllowed,
+		},
+		{
+			name:       "should not register the routes under /v1 when another base path is configured",
+			basePaths:  []string{"/api/v2"},
+			path:       "/v1/products",
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name:       "should register the routes under every base path side by side",
+			basePaths:  []string{"/v1", "/v2"},
+			path:       "/v2/products",
+			statusCode: http.StatusMethodNotAllowed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := NewApi(ApiParams{BasePaths: tt.basePaths})
+
+			// PATCH reaches no handler, so a registered route answers 405 and an unknown one 404
+			req, _ := http.NewRequest(http.MethodPatch, tt.path, nil)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.statusCode, rr.Code)
+		})
+	}
+
+	router := NewApi(ApiParams{BasePaths: []string{"/api/v2"}})
+	paths := []string{}
+	for _, route := range router.Routes() {
+		paths = append(paths, route.Method+" "+route.Path)
+	}
+	assert.Contains(t, paths, "GET /api/v2/orders/queue")
+	assert.Contains(t, paths, "POST /api/v2/coupons/:code/check")
+}
+
+func TestNewApi_AuthRoutesUnderEveryBasePath(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		routeRoles map[string][]string
+		path       string
+	}{
+		{
+			name:       "should protect the route under the first base path",
+			routeRoles: map[string][]string{"GET /products/audit": {"ADMIN"}},
+			path:       "/v1/products/audit",
+		},
+		{
+			name:       "should protect the route under a second base path",
+			routeRoles: map[string][]string{"GET /products/audit": {"ADMIN"}},
+			path:       "/v2/products/audit",
+		},
+		{
+			name:       "should protect the route under a second base path when configured with the first one",
+			routeRoles: map[string][]string{"GET /v1/products/audit": {"ADMIN"}},
+			path:       "/v2/products/audit",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := NewApi(ApiParams{
+				BasePaths:      []string{"/v1", "/v2"},
+				AuthRouteRoles: tt.routeRoles,
+				AuthTokenRoles: map[string]string{"customer-token": "CUSTOMER"},
+			})
+
+			req, _ := http.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set("Authorization", "Bearer customer-token")
+			req.Header.Set("X-Correlation-ID", "correlation-123")
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusForbidden, rr.Code)
+			assert.Equal(t, `{"message":"access denied","error":"role [CUSTOMER] is not allowed to access this route","requestId":"correlation-123"}`, rr.Body.String())
+		})
+	}
+}
+
+func TestNewApi_TrailingSlash(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	paymentController := controllers.NewPaymentController(usecases.NewPaymentUsecase("", "", nil, []dto.PaymentMethod{dto.PaymentMethodMercadoPagoQRCode}, 0, nil))
+
+	tests := []struct {
+		name          string
+		trailingSlash string
+		method        string
+		statusCode    int
+		location      string
+		respBody      string
+	}{
+		{
+			name:       "should redirect to the path without the slash by default",
+			method:     http.MethodGet,
+			statusCode: http.StatusMovedPermanently,
+			location:   "/v1/payments/methods",
+		},
+		{
+			name:          "should not find the path with the slash when strict",
+			trailingSlash: controllers.TrailingSlashStrict,
+			method:        http.MethodGet,
+			statusCode:    http.StatusNotFound,
+			respBody:      "404 page not found",
+		},
+		{
+			name:          "should serve the path without the slash when tolerant",
+			trailingSlash: controllers.TrailingSlashTolerant,
+			method:        http.MethodGet,
+			statusCode:    http.StatusOK,
+			respBody:      `{"methods":["MERCADO_PAGO_QRCODE"]}`,
+		},
+		{
+			name:          "should answer the unsupported methods of the path without the slash when tolerant",
+			trailingSlash: controllers.TrailingSlashTolerant,
+			method:        http.MethodPost,
+			statusCode:    http.StatusMethodNotAllowed,
+			respBody:      `{"message":"method not allowed","error":"method [POST] is not supported by [/v1/payments/methods]","requestId":"correlation-123"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := NewApi(ApiParams{PaymentController: paymentController, TrailingSlash: tt.trailingSlash})
+
+			req, _ := http.NewRequest(tt.method, "/v1/payments/methods/", nil)
+			req.Header.Set("X-Correlation-ID", "correlation-123")
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.statusCode, rr.Code)
+			assert.Equal(t, tt.location, rr.Header().Get("Location"))
+			if tt.respBody != "" {
+				assert.Equal(t, tt.respBody, rr.Body.String())
+			}
+		})
+	}
+}
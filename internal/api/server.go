@@ -0,0 +1,66 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type LifecycleEvent string
+
+const (
+	LifecycleDatabaseConnected LifecycleEvent = "database connected"
+	LifecycleMigrationsApplied LifecycleEvent = "migrations applied"
+	LifecycleListening         LifecycleEvent = "listening"
+	LifecycleShutdownInitiated LifecycleEvent = "shutdown initiated"
+	LifecycleDrainComplete     LifecycleEvent = "drain complete"
+)
+
+// NewServiceLogger returns the logger used for the service lifecycle, which is not tied to any request.
+func NewServiceLogger(service string) *log.Entry {
+	return log.WithField("service", service)
+}
+
+func LogLifecycle(logger *log.Entry, event LifecycleEvent, fields log.Fields) {
+	logger.WithFields(fields).WithField("lifecycle", string(event)).Info(string(event))
+}
+
+// Serve handles the requests accepted by the listener until a signal arrives on stop, then stops accepting new
+// connections and waits up to drainTimeout for the in-flight requests to finish.
+func Serve(handler http.Handler, listener net.Listener, stop <-chan os.Signal, drainTimeout time.Duration, logger *log.Entry) error {
+	server := &http.Server{Handler: handler}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(listener)
+	}()
+	LogLifecycle(logger, LifecycleListening, log.Fields{"address": listener.Addr().String()})
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("failed to serve requests, error %w", err)
+	case sig := <-stop:
+		LogLifecycle(logger, LifecycleShutdownInitiated, log.Fields{"signal": sig.String(), "drainTimeout": drainTimeout.String()})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+	defer cancel()
+
+	err := server.Shutdown(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to drain in-flight requests, error %w", err)
+	}
+
+	if err := <-serveErr; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve requests, error %w", err)
+	}
+	LogLifecycle(logger, LifecycleDrainComplete, nil)
+
+	return nil
+}
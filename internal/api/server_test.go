@@ -0,0 +1,60 @@
+package api
+
+import (
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServe_Lifecycle(t *testing.T) {
+	logger, hook := test.NewNullLogger()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	requestStarted := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(requestStarted)
+		time.Sleep(50 * time.Millisecond)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	stop := make(chan os.Signal, 1)
+	served := make(chan error, 1)
+	go func() {
+		served <- Serve(handler, listener, stop, time.Second, logger.WithField("service", "g37-lanches"))
+	}()
+
+	// the request in flight when the shutdown starts must still be answered
+	responded := make(chan int, 1)
+	go func() {
+		resp, err := http.Get("http://" + listener.Addr().String())
+		if err != nil {
+			responded <- 0
+			return
+		}
+		resp.Body.Close()
+		responded <- resp.StatusCode
+	}()
+	<-requestStarted
+	stop <- syscall.SIGTERM
+
+	assert.NoError(t, <-served)
+	assert.Equal(t, http.StatusNoContent, <-responded)
+
+	var events []string
+	for _, entry := range hook.AllEntries() {
+		events = append(events, entry.Data["lifecycle"].(string))
+		assert.Equal(t, "g37-lanches", entry.Data["service"])
+		assert.Equal(t, log.InfoLevel, entry.Level)
+	}
+	assert.Equal(t, []string{"listening", "shutdown initiated", "drain complete"}, events)
+	assert.Equal(t, listener.Addr().String(), hook.AllEntries()[0].Data["address"])
+	assert.Equal(t, "terminated", hook.AllEntries()[1].Data["signal"])
+}
@@ -50,7 +50,7 @@ func (c OrderController) GetAllOrders(ctx *gin.Context) {
 		application.HandleBadRequestResponse(ctx, "invalid query parameters", err)
 	}
 
-	page, err := c.orderUsecase.GetAllOrders(pageParams)
+	page, err := c.orderUsecase.GetAllOrders(pageParams, dto.OrderFilters{})
 	if err != nil {
 		application.HandleInternalServerResponse(ctx, "failed to get all orders", err)
 		return
@@ -108,7 +108,7 @@ func (c OrderController) UpdateOrderStatus(ctx *gin.Context) {
 		return
 	}
 
-	err = c.orderUsecase.UpdateOrderStatus(orderId, string(orderStatus.Status))
+	err = c.orderUsecase.UpdateOrderStatus(orderId, string(orderStatus.Status), "")
 	if err != nil {
 		application.HandleInternalServerResponse(ctx, "failed to update order status", err)
 		return
@@ -143,7 +143,7 @@ func (c OrderController) HandleOrderPayment(ctx *gin.Context) {
 		return
 	}
 
-	err = c.orderUsecase.CreateOrderPayment(orderId)
+	err = c.orderUsecase.ConfirmOrderPayment(orderId)
 	if err != nil {
 		application.HandleInternalServerResponse(ctx, "failed to handle payment", err)
 		return
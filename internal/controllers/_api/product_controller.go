@@ -50,7 +50,7 @@ func (c ProductController) CreateProducts(ctx *gin.Context) {
 		return
 	}
 
-	err = c.productUsecase.CreateProduct(product)
+	err = c.productUsecase.CreateProduct(product, "")
 	if err != nil {
 		application.HandleInternalServerResponse(ctx, "failed to create product", err)
 		return
@@ -79,7 +79,7 @@ func (c ProductController) UpdateProduct(ctx *gin.Context) {
 		return
 	}
 
-	err = c.productUsecase.UpdateProduct(id, product)
+	err = c.productUsecase.UpdateProduct(id, product, "")
 	if err != nil {
 		if errors.Is(err, sql.ErrNotFound) {
 			application.HandleNotFoundResponse(ctx, "product not found", err)
@@ -113,7 +113,7 @@ func (c ProductController) DeleteProduct(ctx *gin.Context) {
 }
 
 func (c ProductController) getAllProducts(ctx *gin.Context, pageParameters dto.PageParams) {
-	products, err := c.productUsecase.GetAllProducts(pageParameters)
+	products, err := c.productUsecase.GetAllProducts(pageParameters, false)
 	if err != nil {
 		application.HandleInternalServerResponse(ctx, "failed to get all products", err)
 		return
@@ -122,7 +122,7 @@ func (c ProductController) getAllProducts(ctx *gin.Context, pageParameters dto.P
 }
 
 func (c ProductController) getProductsByCategory(ctx *gin.Context, pageParameters dto.PageParams, category string) {
-	products, err := c.productUsecase.GetProductsByCategory(pageParameters, category)
+	products, err := c.productUsecase.GetProductsByCategory(pageParameters, category, false)
 	if err != nil {
 		application.HandleInternalServerResponse(ctx, "failed to get products by category", err)
 		return
@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/g73-techchallenge-order/internal/core/usecases"
+	"github.com/g73-techchallenge-order/internal/core/usecases/dto"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CouponController struct {
+	couponUsecase usecases.CouponUsecase
+}
+
+func NewCouponController(couponUsecase usecases.CouponUsecase) CouponController {
+	return CouponController{
+		couponUsecase: couponUsecase,
+	}
+}
+
+func (c CouponController) CheckCoupon(ctx *gin.Context) {
+	var check dto.CouponCheckDTO
+	err := bindJSON(ctx, &check)
+	if err != nil {
+		handleBadRequestResponse(ctx, "failed to bind coupon check payload", err)
+		return
+	}
+
+	valid, err := check.Validate()
+	if !valid {
+		handleBadRequestResponse(ctx, "invalid coupon check payload", dto.LocalizeValidationError(err, getLocale(ctx)))
+		return
+	}
+
+	eligibility, err := c.couponUsecase.CheckCoupon(ctx.Param("code"), check)
+	if err != nil {
+		respondError(ctx, "coupon check", "failed to check coupon", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, eligibility)
+}
+
+func (c CouponController) GetCouponUsage(ctx *gin.Context) {
+	from, to, err := getTimeRangeParams(ctx)
+	if err != nil {
+		handleBadRequestResponse(ctx, "invalid query parameters", err)
+		return
+	}
+
+	report, err := c.couponUsecase.GetCouponUsage(from, to)
+	if err != nil {
+		respondError(ctx, "coupon", "failed to get coupon usage", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
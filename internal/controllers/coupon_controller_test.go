@@ -0,0 +1,213 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/g73-techchallenge-order/internal/core/usecases/dto"
+	mock_usecases "github.com/g73-techchallenge-order/internal/core/usecases/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCouponController_CheckCoupon(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	couponUseCase := mock_usecases.NewMockCouponUsecase(ctrl)
+	couponController := NewCouponController(couponUseCase)
+
+	gin.SetMode(gin.TestMode)
+	c, e := gin.CreateTestContext(httptest.NewRecorder())
+	e.POST("/v1/coupons/:code/check", couponController.CheckCoupon)
+
+	type args struct {
+		code    string
+		reqBody string
+	}
+	type want struct {
+		statusCode int
+		respBody   string
+	}
+	type couponUseCaseCall struct {
+		times       int
+		eligibility dto.CouponEligibilityDTO
+		err         error
+	}
+	tests := []struct {
+		name string
+		args
+		want
+		couponUseCaseCall
+	}{
+		{
+			name: "should return bad request when the cart is empty",
+			args: args{
+				code:    "APP10",
+				reqBody: `{"items":[]}`,
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"invalid coupon check payload","error":"items must not be empty"}`,
+			},
+		},
+		{
+			name: "should return the discount of an eligible cart",
+			args: args{
+				code:    "APP10",
+				reqBody: `{"items":[{"productId":1,"quantity":2,"type":"UNIT"}]}`,
+			},
+			want: want{
+				statusCode: 200,
+				respBody:   `{"code":"APP10","eligible":true,"total":20,"discount":2}`,
+			},
+			couponUseCaseCall: couponUseCaseCall{
+				times:       1,
+				eligibility: dto.CouponEligibilityDTO{Code: "APP10", Eligible: true, Total: 20, Discount: 2},
+			},
+		},
+		{
+			name: "should return the reason a cart is not eligible",
+			args: args{
+				code:    "APP10",
+				reqBody: `{"items":[{"productId":1,"quantity":1,"type":"UNIT"}]}`,
+			},
+			want: want{
+				statusCode: 200,
+				respBody:   `{"code":"APP10","eligible":false,"total":10,"discount":0,"reason":"MIN_TOTAL_NOT_MET"}`,
+			},
+			couponUseCaseCall: couponUseCaseCall{
+				times:       1,
+				eligibility: dto.CouponEligibilityDTO{Code: "APP10", Total: 10, Reason: dto.CouponReasonMinTotalNotMet},
+			},
+		},
+		{
+			name: "should return bad request when a product of the cart does not exist",
+			args: args{
+				code:    "APP10",
+				reqBody: `{"items":[{"productId":99,"quantity":1,"type":"UNIT"}]}`,
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"invalid coupon check payload","error":"produto [99] não encontrado"}`,
+			},
+			couponUseCaseCall: couponUseCaseCall{
+				times: 1,
+				err:   dto.OrderItemProductNotFoundError{ProductID: 99},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		couponUseCase.
+			EXPECT().
+			CheckCoupon(gomock.Eq(tt.args.code), gomock.Any()).
+			Times(tt.couponUseCaseCall.times).
+			Return(tt.couponUseCaseCall.eligibility, tt.couponUseCaseCall.err)
+
+		c.Request, _ = http.NewRequest(http.MethodPost, "/v1/coupons/"+tt.args.code+"/check", strings.NewReader(tt.args.reqBody))
+		c.Request.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		e.ServeHTTP(rr, c.Request)
+
+		assert.Equal(t, tt.want.statusCode, rr.Code)
+		assert.Equal(t, tt.want.respBody, rr.Body.String())
+	}
+}
+
+func TestCouponController_GetCouponUsage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	couponUseCase := mock_usecases.NewMockCouponUsecase(ctrl)
+	couponController := NewCouponController(couponUseCase)
+
+	gin.SetMode(gin.TestMode)
+	c, e := gin.CreateTestContext(httptest.NewRecorder())
+	e.GET("/v1/coupons/usage", couponController.GetCouponUsage)
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	type args struct {
+		query string
+	}
+	type want struct {
+		statusCode int
+		respBody   string
+	}
+	type couponUseCaseCall struct {
+		times  int
+		report dto.CouponUsageReportDTO
+		err    error
+	}
+	tests := []struct {
+		name string
+		args
+		want
+		couponUseCaseCall
+	}{
+		{
+			name: "should return bad request when the range is reversed",
+			args: args{
+				query: "?from=2024-02-01T00:00:00Z&to=2024-01-01T00:00:00Z",
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"invalid query parameters","error":"from [2024-02-01T00:00:00Z] must be before to [2024-01-01T00:00:00Z]"}`,
+			},
+		},
+		{
+			name: "should return the usage of each coupon in the range",
+			args: args{
+				query: "?from=2024-01-01T00:00:00Z&to=2024-02-01T00:00:00Z",
+			},
+			want: want{
+				statusCode: 200,
+				respBody: `{"from":"2024-01-01T00:00:00Z","to":"2024-02-01T00:00:00Z","coupons":[` +
+					`{"code":"APP10","redemptions":12,"discountTotal":540.5},{"code":"FREE50","redemptions":3,"discountTotal":97.2}]}`,
+			},
+			couponUseCaseCall: couponUseCaseCall{
+				times: 1,
+				report: dto.CouponUsageReportDTO{From: from, To: to, Coupons: []dto.CouponUsageDTO{
+					{Code: "APP10", Redemptions: 12, DiscountTotal: 540.5},
+					{Code: "FREE50", Redemptions: 3, DiscountTotal: 97.2},
+				}},
+			},
+		},
+		{
+			name: "should return internal server error when the usage can not be read",
+			args: args{
+				query: "?from=2024-01-01T00:00:00Z&to=2024-02-01T00:00:00Z",
+			},
+			want: want{
+				statusCode: 500,
+				respBody:   `{"message":"failed to get coupon usage","error":"connection refused"}`,
+			},
+			couponUseCaseCall: couponUseCaseCall{
+				times: 1,
+				err:   errors.New("connection refused"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		couponUseCase.
+			EXPECT().
+			GetCouponUsage(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(gotFrom, gotTo time.Time) (dto.CouponUsageReportDTO, error) {
+				assert.True(t, from.Equal(gotFrom))
+				assert.True(t, to.Equal(gotTo))
+				return tt.couponUseCaseCall.report, tt.couponUseCaseCall.err
+			}).
+			Times(tt.couponUseCaseCall.times)
+
+		c.Request, _ = http.NewRequest(http.MethodGet, "/v1/coupons/usage"+tt.args.query, nil)
+		rr := httptest.NewRecorder()
+		e.ServeHTTP(rr, c.Request)
+
+		assert.Equal(t, tt.want.statusCode, rr.Code)
+		assert.Equal(t, tt.want.respBody, rr.Body.String())
+	}
+}
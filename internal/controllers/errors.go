@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/g73-techchallenge-order/internal/core/usecases/dto"
+	"github.com/g73-techchallenge-order/internal/infra/drivers/authorizer"
+	"github.com/g73-techchallenge-order/internal/infra/drivers/sql"
+	"github.com/gin-gonic/gin"
+)
+
+// statusClientClosedRequest is the nginx convention for requests the client gave up on before the response.
+const statusClientClosedRequest = 499
+
+// errorMapping is the response given to the usecase errors matching target.
+type errorMapping struct {
+	target  error
+	status  int
+	message string
+}
+
+// errorMappings lists the usecase errors answered with a fixed response, new error types are wired by adding them here.
+var errorMappings = []errorMapping{
+	{target: authorizer.ErrUnauthorized, status: http.StatusForbidden, message: "customer cpf invalid"},
+	{target: dto.ErrOrderQRCodeNotFound, status: http.StatusNotFound, message: "order qrcode not found"},
+	{target: dto.ErrOrderPaymentNotPending, status: http.StatusConflict, message: "order qrcode is no longer available"},
+	{target: dto.ErrOrderItemNotFound, status: http.StatusNotFound, message: "order item not found"},
+	{target: dto.ErrOrderNotEditable, status: http.StatusConflict, message: "order can no longer be changed"},
+	{target: dto.ErrOrderLastItem, status: http.StatusBadRequest, message: "order must keep at least one item"},
+	{target: dto.ErrUnknownStation, status: http.StatusBadRequest, message: "invalid query parameters"},
+	{target: dto.ErrOrderNotDone, status: http.StatusConflict, message: "order feedback is not allowed"},
+	{target: dto.ErrOrderFeedbackAlreadySent, status: http.StatusConflict, message: "order feedback is not allowed"},
+	{target: dto.ErrProductInOrder, status: http.StatusConflict, message: "product can not be deleted"},
+	{target: dto.ErrProductSKUTaken, status: http.StatusConflict, message: "product sku already in use"},
+	{target: dto.ErrStoreClosed, status: http.StatusConflict, message: "store is closed"},
+	{target: dto.ErrOrderNotReopenable, status: http.StatusConflict, message: "order can not be reopened"},
+	{target: dto.ErrStoreCreditUnavailable, status: http.StatusConflict, message: "store credit can not be applied"},
+	{target: dto.ErrStoreCreditExceeded, status: http.StatusConflict, message: "store credit can not be applied"},
+	{target: dto.ErrUnsupportedCurrency, status: http.StatusBadRequest, message: "invalid display currency"},
+	{target: dto.ErrOrderStatusConflict, status: http.StatusConflict, message: "order status changed meanwhile"},
+	{target: dto.ErrOrderNotInProgress, status: http.StatusConflict, message: "order items can not be updated"},
+	{target: context.DeadlineExceeded, status: http.StatusGatewayTimeout, message: "request timed out"},
+}
+
+// respondError answers an error returned by a usecase. Payload errors only found by the usecase are answered as
+// "invalid <resource> payload" and sql.ErrNotFound as "<resource> not found", the errors in errorMappings with their
+// own response, and anything else as an internal server error with message. Canceled requests get no body, since
+// the client already disconnected.
+func respondError(c *gin.Context, resource string, message string, err error) {
+	if errors.Is(err, context.Canceled) {
+		c.AbortWithStatus(statusClientClosedRequest)
+		return
+	}
+
+	if isPayloadError(err) {
+		handleBadRequestResponse(c, fmt.Sprintf("invalid %s payload", resource), dto.LocalizeValidationError(err, getLocale(c)))
+		return
+	}
+
+	if errors.Is(err, sql.ErrNotFound) {
+		handleNotFoundResponse(c, resource+" not found", err)
+		return
+	}
+
+	for _, mapping := range errorMappings {
+		if errors.Is(err, mapping.target) {
+			c.JSON(mapping.status, ErrorResponse{
+				Message:   mapping.message,
+				Err:       err.Error(),
+				RequestID: getCorrelationID(c),
+			})
+			return
+		}
+	}
+
+	handleInternalServerResponse(c, message, err)
+}
+
+func isPayloadError(err error) bool {
+	var productNotFoundErr dto.OrderItemProductNotFoundError
+	var quantityLimitErr dto.ProductQuantityLimitError
+	var lineTotalLimitErr dto.LineTotalLimitError
+	return errors.Is(err, dto.ErrProductCategoryRequired) || errors.Is(err, dto.ErrUnknownTaxCategory) || errors.As(err, &productNotFoundErr) ||
+		errors.As(err, &quantityLimitErr) || errors.As(err, &lineTotalLimitErr) || isProductVariantError(err)
+}
+
+func isProductVariantError(err error) bool {
+	var variantRequiredErr dto.ProductVariantRequiredError
+	var invalidVariantErr dto.InvalidProductVariantError
+	return errors.As(err, &variantRequiredErr) || errors.As(err, &invalidVariantErr)
+}
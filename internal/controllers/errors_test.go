@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/g73-techchallenge-order/internal/core/usecases/dto"
+	"github.com/g73-techchallenge-order/internal/infra/drivers/authorizer"
+	"github.com/g73-techchallenge-order/internal/infra/drivers/sql"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRespondError(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantStatus  int
+		wantMessage string
+	}{
+		{
+			name:        "should answer a missing resource as not found",
+			err:         fmt.Errorf("failed to find order, error %w", sql.ErrNotFound),
+			wantStatus:  http.StatusNotFound,
+			wantMessage: "order not found",
+		},
+		{
+			name:        "should answer an invalid customer as forbidden",
+			err:         authorizer.ErrUnauthorized,
+			wantStatus:  http.StatusForbidden,
+			wantMessage: "customer cpf invalid",
+		},
+		{
+			name:        "should answer a missing order item product as an invalid payload",
+			err:         dto.OrderItemProductNotFoundError{ProductID: 1},
+			wantStatus:  http.StatusBadRequest,
+			wantMessage: "invalid order payload",
+		},
+		{
+			name:        "should answer a missing product variant as an invalid payload",
+			err:         dto.ProductVariantRequiredError{ProductID: 1},
+			wantStatus:  http.StatusBadRequest,
+			wantMessage: "invalid order payload",
+		},
+		{
+			name:        "should answer an unknown product variant as an invalid payload",
+			err:         dto.InvalidProductVariantError{ProductID: 1, VariantID: 2},
+			wantStatus:  http.StatusBadRequest,
+			wantMessage: "invalid order payload",
+		},
+		{
+			name:        "should answer a quantity out of the product limits as an invalid payload",
+			err:         dto.ProductQuantityLimitError{ProductID: 1, Quantity: 11, MaxQty: 10},
+			wantStatus:  http.StatusBadRequest,
+			wantMessage: "invalid order payload",
+		},
+		{
+			name:        "should answer an item over the maximum line total as an invalid payload",
+			err:         dto.LineTotalLimitError{ProductID: 1, Quantity: 1000000, MaxLineTotal: 100000},
+			wantStatus:  http.StatusBadRequest,
+			wantMessage: "invalid order payload",
+		},
+		{
+			name:        "should answer a missing product category as an invalid payload",
+			err:         dto.ErrProductCategoryRequired,
+			wantStatus:  http.StatusBadRequest,
+			wantMessage: "invalid order payload",
+		},
+		{
+			name:        "should answer an order without qrcode as not found",
+			err:         dto.ErrOrderQRCodeNotFound,
+			wantStatus:  http.StatusNotFound,
+			wantMessage: "order qrcode not found",
+		},
+		{
+			name:        "should answer an order with a finished payment as conflict",
+			err:         dto.ErrOrderPaymentNotPending,
+			wantStatus:  http.StatusConflict,
+			wantMessage: "order qrcode is no longer available",
+		},
+		{
+			name:        "should answer a missing order item as not found",
+			err:         dto.ErrOrderItemNotFound,
+			wantStatus:  http.StatusNotFound,
+			wantMessage: "order item not found",
+		},
+		{
+			name:        "should answer an order no longer editable as conflict",
+			err:         dto.ErrOrderNotEditable,
+			wantStatus:  http.StatusConflict,
+			wantMessage: "order can no longer be changed",
+		},
+		{
+			name:        "should answer the removal of the last order item as bad request",
+			err:         dto.ErrOrderLastItem,
+			wantStatus:  http.StatusBadRequest,
+			wantMessage: "order must keep at least one item",
+		},
+		{
+			name:        "should answer the feedback of an order not done as conflict",
+			err:         dto.ErrOrderNotDone,
+			wantStatus:  http.StatusConflict,
+			wantMessage: "order feedback is not allowed",
+		},
+		{
+			name:        "should answer a repeated order feedback as conflict",
+			err:         dto.ErrOrderFeedbackAlreadySent,
+			wantStatus:  http.StatusConflict,
+			wantMessage: "order feedback is not allowed",
+		},
+		{
+			name:        "should answer the deletion of a product in an order as conflict",
+			err:         dto.ErrProductInOrder,
+			wantStatus:  http.StatusConflict,
+			wantMessage: "product can not be deleted",
+		},
+		{
+			name:        "should answer an order outside the business hours as conflict",
+			err:         fmt.Errorf("%w, orders are accepted again at 2024-01-01T18:00:00Z", dto.ErrStoreClosed),
+			wantStatus:  http.StatusConflict,
+			wantMessage: "store is closed",
+		},
+		{
+			name:        "should answer a request past its deadline as gateway timeout",
+			err:         fmt.Errorf("failed to find order, error %w", context.DeadlineExceeded),
+			wantStatus:  http.StatusGatewayTimeout,
+			wantMessage: "request timed out",
+		},
+		{
+			name:        "should answer unknown errors as internal server error",
+			err:         errors.New("connection refused"),
+			wantStatus:  http.StatusInternalServerError,
+			wantMessage: "failed to handle order",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			rr := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rr)
+			c.Request, _ = http.NewRequest(http.MethodGet, "/v1/orders", nil)
+
+			respondError(c, "order", "failed to handle order", tt.err)
+
+			var response ErrorResponse
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			assert.Equal(t, tt.wantMessage, response.Message)
+		})
+	}
+}
+
+func TestRespondError_ClientCanceled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rr := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rr)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/v1/orders", nil)
+
+	respondError(c, "order", "failed to handle order", fmt.Errorf("failed to find order, error %w", context.Canceled))
+
+	assert.Equal(t, statusClientClosedRequest, rr.Code)
+	assert.Empty(t, rr.Body.String())
+	assert.True(t, c.IsAborted())
+}
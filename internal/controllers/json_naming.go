@@ -0,0 +1,149 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	JSONNamingCamelCase = "camelCase"
+	JSONNamingSnakeCase = "snake_case"
+)
+
+// jsonNamingWriter holds the response body, so its keys can be renamed once the handler is done writing it.
+type jsonNamingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *jsonNamingWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *jsonNamingWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+// SnakeCaseJSONMiddleware renames the keys of the JSON responses from the camelCase of the json tags to snake_case,
+// keeping their order. Other responses are sent as written.
+func SnakeCaseJSONMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		writer := &jsonNamingWriter{ResponseWriter: ctx.Writer}
+		ctx.Writer = writer
+		// deferred, so the body written by the recovery of a panic further down the chain is sent too
+		defer func() {
+			ctx.Writer = writer.ResponseWriter
+			body := writer.body.Bytes()
+			if len(body) == 0 {
+				return
+			}
+
+			if strings.HasPrefix(writer.Header().Get("Content-Type"), gin.MIMEJSON) {
+				renamed, err := renameJSONKeys(body, snakeCase)
+				if err != nil {
+					log.Errorf("failed to rename the keys of the response of [%s %s], error: %v", ctx.Request.Method, ctx.Request.URL.Path, err)
+				} else {
+					body = renamed
+				}
+			}
+
+			if _, err := writer.ResponseWriter.Write(body); err != nil {
+				log.Errorf("failed to write the response of [%s %s], error: %v", ctx.Request.Method, ctx.Request.URL.Path, err)
+			}
+		}()
+
+		ctx.Next()
+	}
+}
+
+// jsonContainer tracks the elements written to an object or array, an object alternating between keys and values.
+type jsonContainer struct {
+	object   bool
+	elements int
+}
+
+// renameJSONKeys rewrites the JSON document token by token, so the keys keep their order and the values are untouched.
+func renameJSONKeys(data []byte, rename func(string) string) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var out bytes.Buffer
+	var containers []*jsonContainer
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if delim, ok := token.(json.Delim); ok && (delim == '}' || delim == ']') {
+			out.WriteRune(rune(delim))
+			containers = containers[:len(containers)-1]
+			if len(containers) > 0 {
+				containers[len(containers)-1].elements++
+			}
+			continue
+		}
+
+		isKey := false
+		if len(containers) > 0 {
+			parent := containers[len(containers)-1]
+			isKey = parent.object && parent.elements%2 == 0
+			switch {
+			case parent.object && !isKey:
+				out.WriteByte(':')
+			case parent.elements > 0:
+				out.WriteByte(',')
+			}
+		}
+
+		if delim, ok := token.(json.Delim); ok {
+			out.WriteRune(rune(delim))
+			containers = append(containers, &jsonContainer{object: delim == '{'})
+			continue
+		}
+
+		if key, ok := token.(string); ok && isKey {
+			token = rename(key)
+		}
+		encoded, err := json.Marshal(token)
+		if err != nil {
+			return nil, err
+		}
+		out.Write(encoded)
+
+		if len(containers) > 0 {
+			containers[len(containers)-1].elements++
+		}
+	}
+
+	return out.Bytes(), nil
+}
+
+// snakeCase splits the words at each uppercase letter, keeping acronyms together, e.g. "qrCodeURL" is "qr_code_url".
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var builder strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			startsWord := i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1])))
+			if startsWord && runes[i-1] != '_' {
+				builder.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		builder.WriteRune(r)
+	}
+
+	return builder.String()
+}
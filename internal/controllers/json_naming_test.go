@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/g73-techchallenge-order/internal/core/usecases/dto"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnakeCaseJSONMiddleware_OrderCreationResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		naming   string
+		wantBody string
+	}{
+		{
+			name:     "should keep the camelCase keys by default",
+			naming:   JSONNamingCamelCase,
+			wantBody: `{"qrCode":"mercadopago123456","orderId":7,"orderNumber":"042"}`,
+		},
+		{
+			name:     "should rename the keys to snake_case keeping their order",
+			naming:   JSONNamingSnakeCase,
+			wantBody: `{"qr_code":"mercadopago123456","order_id":7,"order_number":"042"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			_, e := gin.CreateTestContext(httptest.NewRecorder())
+			if tt.naming == JSONNamingSnakeCase {
+				e.Use(SnakeCaseJSONMiddleware())
+			}
+			e.POST("/v1/orders", func(ctx *gin.Context) {
+				ctx.JSON(http.StatusCreated, dto.OrderCreationResponse{QRCode: "mercadopago123456", OrderID: 7, Number: "042"})
+			})
+
+			req, _ := http.NewRequest(http.MethodPost, "/v1/orders", nil)
+			rr := httptest.NewRecorder()
+			e.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusCreated, rr.Code)
+			assert.Equal(t, tt.wantBody, rr.Body.String())
+		})
+	}
+}
+
+func TestSnakeCaseJSONMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	_, e := gin.CreateTestContext(httptest.NewRecorder())
+	e.Use(SnakeCaseJSONMiddleware(), RecoveryMiddleware())
+	e.GET("/v1/orders/status", func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, dto.OrderStatusesDTO{Statuses: map[int]dto.OrderStatus{7: dto.OrderStatusReady}, NotFound: []int{8}})
+	})
+	e.GET("/v1/orders/7/qrcode.png", func(ctx *gin.Context) {
+		ctx.Data(http.StatusOK, "image/png", []byte("notJsonAtAll"))
+	})
+	e.GET("/v1/panic", func(ctx *gin.Context) {
+		panic("something went wrong")
+	})
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "should rename nested keys without touching map keys and values",
+			path:       "/v1/orders/status",
+			wantStatus: http.StatusOK,
+			wantBody:   `{"statuses":{"7":"READY"},"not_found":[8]}`,
+		},
+		{
+			name:       "should send the responses other than JSON as written",
+			path:       "/v1/orders/7/qrcode.png",
+			wantStatus: http.StatusOK,
+			wantBody:   "notJsonAtAll",
+		},
+		{
+			name:       "should send the error answered for a panic",
+			path:       "/v1/panic",
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `{"message":"unexpected error","error":"internal server error"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, tt.path, nil)
+			rr := httptest.NewRecorder()
+			e.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			assert.Equal(t, tt.wantBody, rr.Body.String())
+		})
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	assert.Equal(t, "qr_code", snakeCase("qrCode"))
+	assert.Equal(t, "customer_cpf", snakeCase("customerCpf"))
+	assert.Equal(t, "qr_code_url", snakeCase("qrCodeURL"))
+	assert.Equal(t, "http_status", snakeCase("HTTPStatus"))
+	assert.Equal(t, "id", snakeCase("id"))
+	assert.Equal(t, "already_snake", snakeCase("already_snake"))
+}
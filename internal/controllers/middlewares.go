@@ -0,0 +1,309 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	correlationIDHeader     = "X-Correlation-ID"
+	resultTruncatedHeader   = "X-Result-Truncated"
+	strictJSONBindingKey    = "strictJSONBinding"
+	maxJSONArrayElementsKey = "maxJSONArrayElements"
+	authSubjectKey          = "authSubject"
+	correlationIDKey        = "correlationId"
+)
+
+// maxCorrelationIDLength keeps the clients from flooding the logs with their ids, longer ids are replaced.
+const maxCorrelationIDLength = 128
+
+// CorrelationIDMiddleware keeps the X-Correlation-ID sent by the client, or makes up one, so the logs and the error
+// responses of the request can be tied together. The id is sent back on the same header.
+func CorrelationIDMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		correlationID := strings.TrimSpace(ctx.GetHeader(correlationIDHeader))
+		if correlationID == "" || len(correlationID) > maxCorrelationIDLength {
+			correlationID = newCorrelationID()
+		}
+
+		ctx.Set(correlationIDKey, correlationID)
+		ctx.Header(correlationIDHeader, correlationID)
+		ctx.Next()
+	}
+}
+
+func newCorrelationID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		log.Errorf("failed to generate correlation id, error: %v", err)
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+
+	return hex.EncodeToString(id)
+}
+
+func RecoveryMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.WithField("correlationId", getCorrelationID(ctx)).
+					Errorf("recovered from panic on [%s %s], error: %v\n%s", ctx.Request.Method, ctx.Request.URL.Path, r, debug.Stack())
+				handleInternalServerResponse(ctx, "unexpected error", errors.New("internal server error"))
+				ctx.Abort()
+			}
+		}()
+
+		ctx.Next()
+	}
+}
+
+func StrictJSONBindingMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Set(strictJSONBindingKey, true)
+		ctx.Next()
+	}
+}
+
+const (
+	TrailingSlashRedirect = "redirect"
+	TrailingSlashStrict   = "strict"
+	TrailingSlashTolerant = "tolerant"
+)
+
+// TrailingSlashTolerantHandler serves a path ending with slashes as the same path without them, instead of the
+// redirect, when set as the NoRoute handler of the router with RedirectTrailingSlash disabled.
+func TrailingSlashTolerantHandler(router *gin.Engine) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		path := ctx.Request.URL.Path
+		if len(path) > 1 && strings.HasSuffix(path, "/") {
+			ctx.Request.URL.Path = strings.TrimRight(path, "/")
+			router.HandleContext(ctx)
+		}
+	}
+}
+
+// MethodNotAllowedHandler answers the requests to a known path with an unsupported method, listing on the Allow
+// header the methods registered for that path. It needs the router with HandleMethodNotAllowed enabled.
+func MethodNotAllowedHandler(routes gin.RoutesInfo) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		allowed := map[string]bool{}
+		for _, route := range routes {
+			if matchRoutePath(route.Path, ctx.Request.URL.Path) {
+				allowed[route.Method] = true
+			}
+		}
+
+		methods := make([]string, 0, len(allowed))
+		for method := range allowed {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+
+		ctx.Header("Allow", strings.Join(methods, ", "))
+		handleMethodNotAllowedResponse(ctx, "method not allowed", fmt.Errorf("method [%s] is not supported by [%s]", ctx.Request.Method, ctx.Request.URL.Path))
+		ctx.Abort()
+	}
+}
+
+// matchRoutePath checks a path against a route template, where :param matches one segment and *param the rest of the path.
+func matchRoutePath(template, path string) bool {
+	templateSegments := strings.Split(strings.Trim(template, "/"), "/")
+	pathSegments := strings.Split(strings.Trim(path, "/"), "/")
+
+	for i, segment := range templateSegments {
+		if strings.HasPrefix(segment, "*") {
+			return true
+		}
+		if i >= len(pathSegments) {
+			return false
+		}
+		if strings.HasPrefix(segment, ":") {
+			if pathSegments[i] == "" {
+				return false
+			}
+			continue
+		}
+		if segment != pathSegments[i] {
+			return false
+		}
+	}
+
+	return len(templateSegments) == len(pathSegments)
+}
+
+// JSONArrayLimitMiddleware makes the JSON binding reject payloads with arrays longer than maxElements,
+// before their elements are allocated.
+func JSONArrayLimitMiddleware(maxElements int) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Set(maxJSONArrayElementsKey, maxElements)
+		ctx.Next()
+	}
+}
+
+var errConcurrencyLimitReached = errors.New("too many requests in progress, try again later")
+
+// ConcurrencyLimitMiddleware caps the requests handled at the same time by the routes using it. The requests over
+// limit wait up to queueTimeout for a slot to free up, and are answered with 503 when none does.
+func ConcurrencyLimitMiddleware(limit int, queueTimeout time.Duration) gin.HandlerFunc {
+	slots := make(chan struct{}, limit)
+	return func(ctx *gin.Context) {
+		select {
+		case slots <- struct{}{}:
+		default:
+			timer := time.NewTimer(queueTimeout)
+			defer timer.Stop()
+			select {
+			case slots <- struct{}{}:
+			case <-timer.C:
+				handleServiceUnavailableResponse(ctx, "service is busy", errConcurrencyLimitReached)
+				ctx.Abort()
+				return
+			case <-ctx.Request.Context().Done():
+				ctx.Abort()
+				return
+			}
+		}
+		defer func() { <-slots }()
+
+		ctx.Next()
+	}
+}
+
+var errReadRateLimitReached = errors.New("too many read requests, try again later")
+
+// readRateWindow counts the requests made by a client since start.
+type readRateWindow struct {
+	start    time.Time
+	requests int
+}
+
+// ReadRateLimitMiddleware caps the GET requests each client makes within window, answering the ones over limit with
+// 429 and the seconds left until the window resets on Retry-After. Clients are told apart by their auth subject,
+// falling back to their IP, as the read routes carry no CPF. Other methods are left to their own limits.
+func ReadRateLimitMiddleware(limit int, window time.Duration) gin.HandlerFunc {
+	var mu sync.Mutex
+	windows := map[string]*readRateWindow{}
+	lastSweep := time.Now()
+	return func(ctx *gin.Context) {
+		if ctx.Request.Method != http.MethodGet && ctx.Request.Method != http.MethodHead {
+			ctx.Next()
+			return
+		}
+
+		client := getAuthSubject(ctx)
+		if client == "" {
+			client = ctx.ClientIP()
+		}
+
+		now := time.Now()
+		mu.Lock()
+		// the expired windows are dropped once per window, so the clients gone quiet do not pile up
+		if now.Sub(lastSweep) >= window {
+			for key, expired := range windows {
+				if now.Sub(expired.start) >= window {
+					delete(windows, key)
+				}
+			}
+			lastSweep = now
+		}
+		current, ok := windows[client]
+		if !ok || now.Sub(current.start) >= window {
+			current = &readRateWindow{start: now}
+			windows[client] = current
+		}
+		current.requests++
+		overLimit := current.requests > limit
+		retryAfter := current.start.Add(window).Sub(now)
+		mu.Unlock()
+
+		if overLimit {
+			ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			handleTooManyRequestsResponse(ctx, "too many requests", errReadRateLimitReached)
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// AuthMiddleware enforces the roles required by each route, keyed by "METHOD /path" using the route template relative
+// to the base paths (e.g. "DELETE /products/:id"), so a route is protected under every base path it is registered
+// under. Keys still prefixed by one of the base paths (e.g. "DELETE /v1/products/:id") are read the same way.
+// Callers authenticate with "Authorization: Bearer <token>", and the token is resolved to a role through tokenRoles.
+// Routes missing from routeRoles stay public.
+func AuthMiddleware(routeRoles map[string][]string, tokenRoles map[string]string, basePaths []string) gin.HandlerFunc {
+	relativeRouteRoles := make(map[string][]string, len(routeRoles))
+	for route, roles := range routeRoles {
+		method, path, _ := strings.Cut(route, " ")
+		relativeRouteRoles[method+" "+trimBasePath(path, basePaths)] = roles
+	}
+
+	return func(ctx *gin.Context) {
+		requiredRoles, ok := relativeRouteRoles[ctx.Request.Method+" "+trimBasePath(ctx.FullPath(), basePaths)]
+		if !ok {
+			ctx.Next()
+			return
+		}
+
+		token, found := strings.CutPrefix(ctx.GetHeader("Authorization"), "Bearer ")
+		role, valid := tokenRoles[strings.TrimSpace(token)]
+		if !found || !valid {
+			handleUnauthenticatedResponse(ctx, "authentication required", errors.New("missing or invalid bearer token"))
+			ctx.Abort()
+			return
+		}
+
+		for _, requiredRole := range requiredRoles {
+			if strings.EqualFold(role, requiredRole) {
+				ctx.Next()
+				return
+			}
+		}
+
+		handleUnauthorizedResponse(ctx, "access denied", fmt.Errorf("role [%s] is not allowed to access this route", role))
+		ctx.Abort()
+	}
+}
+
+// trimBasePath returns the path relative to the longest of the base paths it is under, or the path itself when it is
+// under none of them.
+func trimBasePath(path string, basePaths []string) string {
+	relative := path
+	for _, basePath := range basePaths {
+		if basePath == "/" {
+			continue
+		}
+		rest, found := strings.CutPrefix(path, basePath)
+		if found && strings.HasPrefix(rest, "/") && len(rest) < len(relative) {
+			relative = rest
+		}
+	}
+	return relative
+}
+
+// AuthSubjectMiddleware resolves the bearer token of any request, public routes included, to the subject it belongs
+// to, so the changes can be credited to it. Requests without a known token have no subject.
+func AuthSubjectMiddleware(tokenSubjects map[string]string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		token, found := strings.CutPrefix(ctx.GetHeader("Authorization"), "Bearer ")
+		if subject, valid := tokenSubjects[strings.TrimSpace(token)]; found && valid {
+			ctx.Set(authSubjectKey, subject)
+		}
+
+		ctx.Next()
+	}
+}
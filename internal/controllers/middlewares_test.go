@@ -0,0 +1,416 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/g73-techchallenge-order/internal/infra/drivers/sql"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecoveryMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, e := gin.CreateTestContext(httptest.NewRecorder())
+	e.Use(RecoveryMiddleware())
+	e.GET("/v1/panic", func(ctx *gin.Context) {
+		panic("something went wrong")
+	})
+	e.GET("/v1/ok", func(ctx *gin.Context) {
+		ctx.Status(http.StatusNoContent)
+	})
+
+	type args struct {
+		path string
+	}
+	type want struct {
+		statusCode  int
+		contentType string
+		respBody    string
+	}
+	tests := []struct {
+		name string
+		args
+		want
+	}{
+		{
+			name: "should return a structured internal server error when the handler panics",
+			args: args{
+				path: "/v1/panic",
+			},
+			want: want{
+				statusCode:  500,
+				contentType: "application/json; charset=utf-8",
+				respBody:    `{"message":"unexpected error","error":"internal server error"}`,
+			},
+		},
+		{
+			name: "should not change the response when the handler does not panic",
+			args: args{
+				path: "/v1/ok",
+			},
+			want: want{
+				statusCode: 204,
+				respBody:   "",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		c.Request, _ = http.NewRequest(http.MethodGet, tt.args.path, nil)
+		c.Request.Header.Set(correlationIDHeader, "correlation-123")
+		rr := httptest.NewRecorder()
+		e.ServeHTTP(rr, c.Request)
+
+		assert.Equal(t, tt.want.statusCode, rr.Code)
+		assert.Equal(t, tt.want.contentType, rr.Header().Get("Content-Type"))
+		assert.Equal(t, tt.want.respBody, rr.Body.String())
+	}
+}
+
+func TestCorrelationIDMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	_, e := gin.CreateTestContext(httptest.NewRecorder())
+	e.Use(CorrelationIDMiddleware())
+	e.GET("/v1/products/:id", func(ctx *gin.Context) {
+		respondError(ctx, "product", "failed to get product", sql.ErrNotFound)
+	})
+
+	tests := []struct {
+		name          string
+		correlationID string
+		wantID        string
+	}{
+		{
+			name:          "should echo the correlation id sent by the client in the error body",
+			correlationID: "correlation-123",
+			wantID:        "correlation-123",
+		},
+		{
+			name: "should make up a correlation id when the client sends none",
+		},
+		{
+			name:          "should replace a correlation id longer than allowed",
+			correlationID: strings.Repeat("x", maxCorrelationIDLength+1),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "/v1/products/7", nil)
+			if tt.correlationID != "" {
+				req.Header.Set(correlationIDHeader, tt.correlationID)
+			}
+			rr := httptest.NewRecorder()
+			e.ServeHTTP(rr, req)
+
+			correlationID := rr.Header().Get(correlationIDHeader)
+			if tt.wantID != "" {
+				assert.Equal(t, tt.wantID, correlationID)
+			} else {
+				assert.Regexp(t, "^[0-9a-f]{32}$", correlationID)
+			}
+			assert.Equal(t, http.StatusNotFound, rr.Code)
+			assert.Equal(t, `{"message":"product not found","error":"entity not found","requestId":"`+correlationID+`"}`, rr.Body.String())
+		})
+	}
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, e := gin.CreateTestContext(httptest.NewRecorder())
+	routeRoles := map[string][]string{
+		"DELETE /v1/products/:id": {"ADMIN"},
+	}
+	tokenRoles := map[string]string{
+		"admin-token":    "ADMIN",
+		"customer-token": "CUSTOMER",
+	}
+	e.Use(AuthMiddleware(routeRoles, tokenRoles, []string{"/v1"}))
+	e.DELETE("/v1/products/:id", func(ctx *gin.Context) {
+		ctx.Status(http.StatusNoContent)
+	})
+	e.GET("/v1/products", func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+
+	type args struct {
+		method        string
+		path          string
+		authorization string
+	}
+	type want struct {
+		statusCode int
+		respBody   string
+	}
+	tests := []struct {
+		name string
+		args
+		want
+	}{
+		{
+			name: "should allow an admin token on an admin-only route",
+			args: args{
+				method:        http.MethodDelete,
+				path:          "/v1/products/1",
+				authorization: "Bearer admin-token",
+			},
+			want: want{
+				statusCode: 204,
+				respBody:   "",
+			},
+		},
+		{
+			name: "should reject a non-admin token on an admin-only route",
+			args: args{
+				method:        http.MethodDelete,
+				path:          "/v1/products/1",
+				authorization: "Bearer customer-token",
+			},
+			want: want{
+				statusCode: 403,
+				respBody:   `{"message":"access denied","error":"role [CUSTOMER] is not allowed to access this route"}`,
+			},
+		},
+		{
+			name: "should reject a request without token on an admin-only route",
+			args: args{
+				method: http.MethodDelete,
+				path:   "/v1/products/1",
+			},
+			want: want{
+				statusCode: 401,
+				respBody:   `{"message":"authentication required","error":"missing or invalid bearer token"}`,
+			},
+		},
+		{
+			name: "should reject an unknown token on an admin-only route",
+			args: args{
+				method:        http.MethodDelete,
+				path:          "/v1/products/1",
+				authorization: "Bearer unknown-token",
+			},
+			want: want{
+				statusCode: 401,
+				respBody:   `{"message":"authentication required","error":"missing or invalid bearer token"}`,
+			},
+		},
+		{
+			name: "should keep public routes unauthenticated",
+			args: args{
+				method: http.MethodGet,
+				path:   "/v1/products",
+			},
+			want: want{
+				statusCode: 200,
+				respBody:   "",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		c.Request, _ = http.NewRequest(tt.args.method, tt.args.path, nil)
+		if tt.args.authorization != "" {
+			c.Request.Header.Set("Authorization", tt.args.authorization)
+		}
+		rr := httptest.NewRecorder()
+		e.ServeHTTP(rr, c.Request)
+
+		assert.Equal(t, tt.want.statusCode, rr.Code)
+		assert.Equal(t, tt.want.respBody, rr.Body.String())
+	}
+}
+
+func TestAuthSubjectMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, e := gin.CreateTestContext(httptest.NewRecorder())
+	e.Use(AuthSubjectMiddleware(map[string]string{"admin-token": "maria"}))
+	e.GET("/v1/products", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, getAuthSubject(ctx))
+	})
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantSubject   string
+	}{
+		{
+			name:          "should resolve the subject of a known token",
+			authorization: "Bearer admin-token",
+			wantSubject:   "maria",
+		},
+		{
+			name:          "should leave the subject empty for an unknown token",
+			authorization: "Bearer unknown-token",
+		},
+		{
+			name: "should leave the subject empty without token",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c.Request, _ = http.NewRequest(http.MethodGet, "/v1/products", nil)
+			if tt.authorization != "" {
+				c.Request.Header.Set("Authorization", tt.authorization)
+			}
+			rr := httptest.NewRecorder()
+			e.ServeHTTP(rr, c.Request)
+
+			assert.Equal(t, tt.wantSubject, rr.Body.String())
+		})
+	}
+}
+
+func TestConcurrencyLimitMiddleware_CapsConcurrentRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	_, e := gin.CreateTestContext(httptest.NewRecorder())
+
+	var inFlight, maxInFlight int32
+	e.POST("/v1/orders", ConcurrencyLimitMiddleware(2, 5*time.Second), func(ctx *gin.Context) {
+		current := atomic.AddInt32(&inFlight, 1)
+		for {
+			observed := atomic.LoadInt32(&maxInFlight)
+			if current <= observed || atomic.CompareAndSwapInt32(&maxInFlight, observed, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		ctx.Status(http.StatusCreated)
+	})
+
+	const requests = 10
+	codes := make([]int, requests)
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			req, _ := http.NewRequest(http.MethodPost, "/v1/orders", nil)
+			rr := httptest.NewRecorder()
+			e.ServeHTTP(rr, req)
+			codes[i] = rr.Code
+		}(i)
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, atomic.LoadInt32(&maxInFlight), int32(2))
+	for _, code := range codes {
+		assert.Equal(t, http.StatusCreated, code)
+	}
+}
+
+func TestConcurrencyLimitMiddleware_OverLimit(t *testing.T) {
+	tests := []struct {
+		name         string
+		queueTimeout time.Duration
+		wantCode     int
+		wantBody     string
+	}{
+		{
+			name:         "should answer 503 when no slot frees up within the queue timeout",
+			queueTimeout: 0,
+			wantCode:     http.StatusServiceUnavailable,
+			wantBody:     `{"message":"service is busy","error":"too many requests in progress, try again later"}`,
+		},
+		{
+			name:         "should handle the queued request once a slot frees up",
+			queueTimeout: 5 * time.Second,
+			wantCode:     http.StatusCreated,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			_, e := gin.CreateTestContext(httptest.NewRecorder())
+
+			started := make(chan struct{}, 1)
+			release := make(chan struct{})
+			e.POST("/v1/orders", ConcurrencyLimitMiddleware(1, tt.queueTimeout), func(ctx *gin.Context) {
+				started <- struct{}{}
+				<-release
+				ctx.Status(http.StatusCreated)
+			})
+
+			first := httptest.NewRecorder()
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				req, _ := http.NewRequest(http.MethodPost, "/v1/orders", nil)
+				e.ServeHTTP(first, req)
+			}()
+			<-started
+
+			second := httptest.NewRecorder()
+			secondDone := make(chan struct{})
+			go func() {
+				defer close(secondDone)
+				req, _ := http.NewRequest(http.MethodPost, "/v1/orders", nil)
+				e.ServeHTTP(second, req)
+			}()
+
+			if tt.wantCode == http.StatusServiceUnavailable {
+				<-secondDone
+				close(release)
+			} else {
+				close(release)
+				<-secondDone
+			}
+			<-done
+
+			assert.Equal(t, http.StatusCreated, first.Code)
+			assert.Equal(t, tt.wantCode, second.Code)
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, second.Body.String())
+			}
+		})
+	}
+}
+
+func TestReadRateLimitMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	_, e := gin.CreateTestContext(httptest.NewRecorder())
+	e.Use(AuthSubjectMiddleware(map[string]string{"secret": "kitchen"}), ReadRateLimitMiddleware(2, time.Minute))
+	e.GET("/v1/orders/:id/status", func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+	e.PUT("/v1/orders/:id/status", func(ctx *gin.Context) {
+		ctx.Status(http.StatusNoContent)
+	})
+
+	request := func(method string, remoteAddr string, token string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, "/v1/orders/7/status", nil)
+		req.RemoteAddr = remoteAddr
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		e.ServeHTTP(rr, req)
+		return rr
+	}
+
+	assert.Equal(t, http.StatusOK, request(http.MethodGet, "10.0.0.1:5000", "").Code)
+	assert.Equal(t, http.StatusOK, request(http.MethodGet, "10.0.0.1:5001", "").Code)
+
+	t.Run("should answer 429 with Retry-After once the client exhausts the read limit", func(t *testing.T) {
+		rr := request(http.MethodGet, "10.0.0.1:5002", "")
+
+		assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+		assert.Equal(t, "60", rr.Header().Get("Retry-After"))
+		assert.Equal(t, `{"message":"too many requests","error":"too many read requests, try again later"}`, rr.Body.String())
+	})
+
+	t.Run("should not count the writes against the read limit", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, request(http.MethodPut, "10.0.0.1:5003", "").Code)
+	})
+
+	t.Run("should keep a separate limit for each client", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, request(http.MethodGet, "10.0.0.2:5000", "").Code)
+		// authenticated callers are told apart by their subject, even behind an exhausted IP
+		assert.Equal(t, http.StatusOK, request(http.MethodGet, "10.0.0.1:5004", "secret").Code)
+	})
+}
@@ -2,29 +2,32 @@ package controllers
 
 import (
 	"errors"
+	"fmt"
 
 	"net/http"
-	"strconv"
+	"strings"
+	"time"
 
 	"github.com/g73-techchallenge-order/internal/core/usecases"
 	"github.com/g73-techchallenge-order/internal/core/usecases/dto"
-	"github.com/g73-techchallenge-order/internal/infra/drivers/authorizer"
 	"github.com/gin-gonic/gin"
 )
 
 type OrderController struct {
 	orderUsecase usecases.OrderUsecase
+	pageLimits   dto.PageLimits
 }
 
-func NewOrderController(orderUsecase usecases.OrderUsecase) OrderController {
+func NewOrderController(orderUsecase usecases.OrderUsecase, pageLimits dto.PageLimits) OrderController {
 	return OrderController{
 		orderUsecase: orderUsecase,
+		pageLimits:   pageLimits,
 	}
 }
 
 func (c OrderController) CreateOrder(ctx *gin.Context) {
 	var order dto.OrderDTO
-	err := ctx.ShouldBindJSON(&order)
+	err := bindJSON(ctx, &order)
 	if err != nil {
 		handleBadRequestResponse(ctx, "failed to bind order payload", err)
 		return
@@ -32,37 +35,163 @@ func (c OrderController) CreateOrder(ctx *gin.Context) {
 
 	valid, err := order.ValidateOrder()
 	if !valid {
-		handleBadRequestResponse(ctx, "invalid order payload", err)
+		handleBadRequestResponse(ctx, "invalid order payload", dto.LocalizeValidationError(err, getLocale(ctx)))
 		return
 	}
 
 	createResponse, err := c.orderUsecase.CreateOrder(order)
 	if err != nil {
-		if errors.Is(err, authorizer.ErrUnauthorized) {
-			handleUnauthorizedResponse(ctx, "customer cpf invalid", err)
+		respondError(ctx, "order", "failed to create order", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.OrderCreationResponse{QRCode: createResponse.QRCode, ExpiresAt: createResponse.ExpiresAt, OrderID: createResponse.OrderID, Number: createResponse.Number})
+}
+
+// BatchCreateOrders creates the orders of a catering client at once, reporting each order on its own.
+func (c OrderController) BatchCreateOrders(ctx *gin.Context) {
+	var batch dto.BatchOrdersDTO
+	err := bindJSON(ctx, &batch)
+	if err != nil {
+		handleBadRequestResponse(ctx, "failed to bind batch orders payload", err)
+		return
+	}
+
+	valid, err := batch.Validate()
+	if !valid {
+		handleBadRequestResponse(ctx, "invalid batch orders payload", err)
+		return
+	}
+
+	results := c.orderUsecase.BatchCreateOrders(batch.Orders)
+	ctx.JSON(http.StatusOK, dto.BatchOrdersResponseDTO{Results: results})
+}
+
+func (c OrderController) ValidateOrder(ctx *gin.Context) {
+	var order dto.OrderDTO
+	err := bindJSON(ctx, &order)
+	if err != nil {
+		handleBadRequestResponse(ctx, "failed to bind order payload", err)
+		return
+	}
+
+	fieldErrors, err := c.orderUsecase.ValidateOrder(order)
+	if err != nil {
+		respondError(ctx, "order", "failed to validate order", err)
+		return
+	}
+
+	if len(fieldErrors) > 0 {
+		handleValidationErrorsResponse(ctx, "invalid order payload", dto.LocalizeFieldErrors(fieldErrors, getLocale(ctx)))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (c OrderController) GetAllOrders(ctx *gin.Context) {
+	pageParams, fieldErrors := getPageParams(ctx, c.pageLimits)
+	filters, filterErrors := getOrderFilters(ctx)
+	fieldErrors = append(fieldErrors, filterErrors...)
+	if len(fieldErrors) > 0 {
+		handleQueryParamErrorsResponse(ctx, fieldErrors)
+		return
+	}
+
+	page, err := c.orderUsecase.GetAllOrders(pageParams, filters)
+	if err != nil {
+		respondError(ctx, "order", "failed to get all orders", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+// GetOrderQueue lists the orders like GetAllOrders, always in the priority sort and with only the fields shown on the
+// kitchen queue. A station lists only the items prepared at it.
+func (c OrderController) GetOrderQueue(ctx *gin.Context) {
+	pageParams, fieldErrors := getPageParams(ctx, c.pageLimits)
+	filters, filterErrors := getOrderFilters(ctx)
+	fieldErrors = append(fieldErrors, filterErrors...)
+	if len(fieldErrors) > 0 {
+		handleQueryParamErrorsResponse(ctx, fieldErrors)
+		return
+	}
+	filters.Station = ctx.Query("station")
+
+	page, err := c.orderUsecase.GetOrderQueue(pageParams, filters)
+	if err != nil {
+		respondError(ctx, "order", "failed to get the order queue", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+func (c OrderController) GetCompletedOrders(ctx *gin.Context) {
+	var since time.Time
+	if sinceQueryParam := ctx.Query("since"); sinceQueryParam != "" {
+		parsedSince, err := time.Parse(time.RFC3339, sinceQueryParam)
+		if err != nil {
+			handleBadRequestResponse(ctx, "[since] query parameter is invalid", err)
 			return
 		}
-		handleInternalServerResponse(ctx, "failed to create order", err)
+		since = parsedSince
+	}
+
+	orders, err := c.orderUsecase.GetCompletedOrders(since)
+	if err != nil {
+		respondError(ctx, "order", "failed to get completed orders", err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, dto.OrderCreationResponse{QRCode: createResponse.QRCode, OrderID: createResponse.OrderID})
+	ctx.JSON(http.StatusOK, orders)
 }
 
-func (c OrderController) GetAllOrders(ctx *gin.Context) {
-	pageParams, err := getPageParams(ctx)
+// GetOrderThroughput reports the average and percentile time the orders stay in each status before moving on.
+func (c OrderController) GetOrderThroughput(ctx *gin.Context) {
+	from, to, err := getTimeRangeParams(ctx)
 	if err != nil {
 		handleBadRequestResponse(ctx, "invalid query parameters", err)
 		return
 	}
 
-	page, err := c.orderUsecase.GetAllOrders(pageParams)
+	throughput, err := c.orderUsecase.GetOrderThroughput(from, to)
 	if err != nil {
-		handleInternalServerResponse(ctx, "failed to get all orders", err)
+		respondError(ctx, "order", "failed to get order throughput", err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, page)
+	ctx.JSON(http.StatusOK, throughput)
+}
+
+// GetOrderByNumber finds the order by the number printed on its receipt.
+func (c OrderController) GetOrderByNumber(ctx *gin.Context) {
+	number := strings.TrimSpace(ctx.Param("number"))
+	if number == "" {
+		handleBadRequestResponse(ctx, "[number] path parameter is required", errors.New("number is missing"))
+		return
+	}
+
+	order, err := c.orderUsecase.GetOrderByNumber(number)
+	if err != nil {
+		respondError(ctx, "order", "failed to get order by number", err)
+		return
+	}
+
+	displayCurrency := strings.TrimSpace(ctx.Query("displayCurrency"))
+	if displayCurrency == "" {
+		ctx.JSON(http.StatusOK, order)
+		return
+	}
+
+	displayTotal, err := c.orderUsecase.ConvertOrderTotal(order, displayCurrency)
+	if err != nil {
+		respondError(ctx, "order", "failed to convert order total", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.OrderDetailDTO{Order: order, DisplayTotal: &displayTotal})
 }
 
 func (c OrderController) GetOrderStatus(ctx *gin.Context) {
@@ -72,20 +201,239 @@ func (c OrderController) GetOrderStatus(ctx *gin.Context) {
 		return
 	}
 
-	orderID, err := strconv.Atoi(id)
+	orderID, err := parseIdParam(id)
 	if err != nil {
 		handleBadRequestResponse(ctx, "[id] path parameter is invalid", err)
 		return
 	}
 
+	includeAllowedNext, err := getBoolQueryParam(ctx, "includeAllowedNext")
+	if err != nil {
+		handleBadRequestResponse(ctx, "[includeAllowedNext] query parameter is invalid", err)
+		return
+	}
+
 	response, err := c.orderUsecase.GetOrderStatus(orderID)
 	if err != nil {
-		handleInternalServerResponse(ctx, "failed to get order status", err)
+		respondError(ctx, "order", "failed to get order status", err)
+		return
+	}
+
+	if !response.UpdatedAt.IsZero() {
+		ctx.Header("Last-Modified", response.UpdatedAt.UTC().Format(http.TimeFormat))
+	}
+	if notModifiedSince(ctx, response.UpdatedAt) {
+		ctx.Status(http.StatusNotModified)
+		return
+	}
+
+	if includeAllowedNext {
+		ctx.JSON(http.StatusOK, dto.OrderStatusWithNextDTO{
+			OrderStatusDTO: response,
+			AllowedNext:    response.Status.AllowedNext(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (c OrderController) GetOrderStatuses(ctx *gin.Context) {
+	orderIDs, err := getIdsQueryParam(ctx, "ids")
+	if err != nil {
+		handleBadRequestResponse(ctx, "[ids] query parameter is invalid", err)
+		return
+	}
+
+	if len(orderIDs) > dto.MaxOrderStatusesIds {
+		handleBadRequestResponse(ctx, "[ids] query parameter is invalid", fmt.Errorf("at most %d ids are allowed per request", dto.MaxOrderStatusesIds))
+		return
+	}
+
+	response, err := c.orderUsecase.GetOrderStatuses(orderIDs)
+	if err != nil {
+		respondError(ctx, "order", "failed to get order statuses", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (c OrderController) GetOrderQRCode(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		handleBadRequestResponse(ctx, "[id] path parameter is required", errors.New("id is missing"))
+		return
+	}
+
+	orderId, err := parseIdParam(id)
+	if err != nil {
+		handleBadRequestResponse(ctx, "[id] path parameter is invalid", err)
+		return
+	}
+
+	png, err := c.orderUsecase.GetOrderQRCodePNG(orderId)
+	if err != nil {
+		respondError(ctx, "order qrcode", "failed to get order qrcode", err)
+		return
+	}
+
+	ctx.Data(http.StatusOK, "image/png", png)
+}
+
+// GetOrderPaymentQRCode answers only the payment qrcode of the order, for the clients rendering it themselves.
+func (c OrderController) GetOrderPaymentQRCode(ctx *gin.Context) {
+	orderId, err := parseIdParam(ctx.Param("id"))
+	if err != nil {
+		handleBadRequestResponse(ctx, "[id] path parameter is invalid", err)
+		return
+	}
+
+	qrCode, err := c.orderUsecase.GetOrderPaymentQRCode(orderId)
+	if err != nil {
+		respondError(ctx, "order", "failed to get order payment qrcode", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, qrCode)
+}
+
+func (c OrderController) AddOrderItem(ctx *gin.Context) {
+	orderId, err := parseIdParam(ctx.Param("id"))
+	if err != nil {
+		handleBadRequestResponse(ctx, "[id] path parameter is invalid", err)
+		return
+	}
+
+	var item dto.OrderItemDTO
+	err = bindJSON(ctx, &item)
+	if err != nil {
+		handleBadRequestResponse(ctx, "failed to bind order item payload", err)
+		return
+	}
+
+	valid, err := item.Validate()
+	if !valid {
+		handleBadRequestResponse(ctx, "invalid order item payload", dto.LocalizeValidationError(err, getLocale(ctx)))
+		return
+	}
+
+	response, err := c.orderUsecase.AddOrderItem(orderId, item)
+	if err != nil {
+		if isPayloadError(err) {
+			handleBadRequestResponse(ctx, "invalid order item payload", dto.LocalizeValidationError(err, getLocale(ctx)))
+			return
+		}
+		respondError(ctx, "order", "failed to add order item", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (c OrderController) RemoveOrderItem(ctx *gin.Context) {
+	orderId, err := parseIdParam(ctx.Param("id"))
+	if err != nil {
+		handleBadRequestResponse(ctx, "[id] path parameter is invalid", err)
+		return
+	}
+
+	itemId, err := parseIdParam(ctx.Param("itemId"))
+	if err != nil {
+		handleBadRequestResponse(ctx, "[itemId] path parameter is invalid", err)
+		return
+	}
+
+	response, err := c.orderUsecase.RemoveOrderItem(orderId, itemId)
+	if err != nil {
+		respondError(ctx, "order", "failed to remove order item", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// RecalculateOrder prices a CREATED order again from the current products, answering its new totals.
+func (c OrderController) RecalculateOrder(ctx *gin.Context) {
+	orderId, err := parseIdParam(ctx.Param("id"))
+	if err != nil {
+		handleBadRequestResponse(ctx, "[id] path parameter is invalid", err)
+		return
+	}
+
+	response, err := c.orderUsecase.RecalculateOrder(orderId)
+	if err != nil {
+		respondError(ctx, "order", "failed to recalculate order", err)
 		return
 	}
 
 	ctx.JSON(http.StatusOK, response)
+}
+
+// UpdateOrderItemStatus marks an item of an order in progress as READY or back to PENDING, the order moves to READY
+// once all of its items are ready.
+func (c OrderController) UpdateOrderItemStatus(ctx *gin.Context) {
+	orderId, err := parseIdParam(ctx.Param("id"))
+	if err != nil {
+		handleBadRequestResponse(ctx, "[id] path parameter is invalid", err)
+		return
+	}
+
+	itemId, err := parseIdParam(ctx.Param("itemId"))
+	if err != nil {
+		handleBadRequestResponse(ctx, "[itemId] path parameter is invalid", err)
+		return
+	}
+
+	var itemStatus dto.OrderItemStatusDTO
+	err = bindJSON(ctx, &itemStatus)
+	if err != nil {
+		handleBadRequestResponse(ctx, "failed to bind order item status payload", err)
+		return
+	}
+
+	valid, err := itemStatus.Validate()
+	if !valid {
+		handleBadRequestResponse(ctx, "invalid order item status payload", dto.LocalizeValidationError(err, getLocale(ctx)))
+		return
+	}
+
+	response, err := c.orderUsecase.UpdateOrderItemStatus(orderId, itemId, itemStatus.Status)
+	if err != nil {
+		respondError(ctx, "order", "failed to update order item status", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (c OrderController) SendOrderFeedback(ctx *gin.Context) {
+	orderId, err := parseIdParam(ctx.Param("id"))
+	if err != nil {
+		handleBadRequestResponse(ctx, "[id] path parameter is invalid", err)
+		return
+	}
+
+	var feedback dto.OrderFeedbackDTO
+	err = bindJSON(ctx, &feedback)
+	if err != nil {
+		handleBadRequestResponse(ctx, "failed to bind order feedback payload", err)
+		return
+	}
+
+	valid, err := feedback.Validate()
+	if !valid {
+		handleBadRequestResponse(ctx, "invalid order feedback payload", dto.LocalizeValidationError(err, getLocale(ctx)))
+		return
+	}
+
+	response, err := c.orderUsecase.SendOrderFeedback(orderId, feedback)
+	if err != nil {
+		respondError(ctx, "order", "failed to send order feedback", err)
+		return
+	}
 
+	ctx.JSON(http.StatusCreated, response)
 }
 
 func (c OrderController) UpdateOrderStatus(ctx *gin.Context) {
@@ -95,30 +443,129 @@ func (c OrderController) UpdateOrderStatus(ctx *gin.Context) {
 		return
 	}
 
-	orderId, err := strconv.Atoi(id)
+	orderId, err := parseIdParam(id)
 	if err != nil {
 		handleBadRequestResponse(ctx, "[id] path parameter is invalid", err)
 		return
 	}
 
-	var orderStatus dto.OrderStatusDTO
-	err = ctx.ShouldBindJSON(&orderStatus)
+	var orderStatus dto.OrderStatusUpdateDTO
+	err = bindJSON(ctx, &orderStatus)
 	if err != nil {
 		handleBadRequestResponse(ctx, "failed to bind order status payload", err)
 		return
 	}
 
+	orderStatus = orderStatus.Normalize()
 	valid, err := orderStatus.Validate()
 	if !valid {
-		handleBadRequestResponse(ctx, "invalid order status payload", err)
+		handleBadRequestResponse(ctx, "invalid order status payload", dto.LocalizeValidationError(err, getLocale(ctx)))
+		return
+	}
+
+	err = c.orderUsecase.UpdateOrderStatus(orderId, string(orderStatus.Status), string(orderStatus.ExpectedStatus))
+	if err != nil {
+		respondError(ctx, "order", "failed to update order status", err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// ReopenOrder reverts an order marked DONE by mistake back to READY, within the configured grace period.
+func (c OrderController) ReopenOrder(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		handleBadRequestResponse(ctx, "[id] path parameter is required", errors.New("id is missing"))
 		return
 	}
 
-	err = c.orderUsecase.UpdateOrderStatus(orderId, string(orderStatus.Status))
+	orderId, err := parseIdParam(id)
 	if err != nil {
-		handleInternalServerResponse(ctx, "failed to update order status", err)
+		handleBadRequestResponse(ctx, "[id] path parameter is invalid", err)
+		return
+	}
+
+	err = c.orderUsecase.ReopenOrder(orderId)
+	if err != nil {
+		respondError(ctx, "order", "failed to reopen order", err)
 		return
 	}
 
 	ctx.Status(http.StatusNoContent)
 }
+
+// CancelUnpaidOrders cancels the orders of the customer still waiting for their payment, answering how many were cancelled.
+func (c OrderController) CancelUnpaidOrders(ctx *gin.Context) {
+	cpf := strings.TrimSpace(ctx.Param("cpf"))
+	if err := dto.ValidateCPF(cpf); err != nil {
+		handleBadRequestResponse(ctx, "[cpf] path parameter is invalid", err)
+		return
+	}
+
+	response, err := c.orderUsecase.CancelUnpaidOrders(cpf)
+	if err != nil {
+		respondError(ctx, "order", "failed to cancel unpaid orders", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (c OrderController) HandleOrderPayment(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		handleBadRequestResponse(ctx, "[id] path parameter is required", errors.New("id is missing"))
+		return
+	}
+
+	orderId, err := parseIdParam(id)
+	if err != nil {
+		handleBadRequestResponse(ctx, "[id] path parameter is invalid", err)
+		return
+	}
+
+	var paymentNotification dto.PaymentNotificationDTO
+	err = bindJSON(ctx, &paymentNotification)
+	if err != nil {
+		handleBadRequestResponse(ctx, "failed to bind payment notification payload", err)
+		return
+	}
+
+	valid, err := paymentNotification.ValidatePaymentNotification()
+	if !valid {
+		handleBadRequestResponse(ctx, "invalid payment notification payload", err)
+		return
+	}
+
+	err = c.orderUsecase.ConfirmOrderPayment(orderId)
+	if err != nil {
+		respondError(ctx, "order", "failed to handle payment", err)
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+// ReplayPaymentWebhook lets the support run a missed or failed payment webhook again. Replaying a notification of an
+// order already paid changes nothing.
+func (c OrderController) ReplayPaymentWebhook(ctx *gin.Context) {
+	var paymentReplay dto.PaymentReplayDTO
+	if err := bindJSON(ctx, &paymentReplay); err != nil {
+		handleBadRequestResponse(ctx, "failed to bind payment replay payload", err)
+		return
+	}
+
+	if valid, err := paymentReplay.Validate(); !valid {
+		handleBadRequestResponse(ctx, "invalid payment replay payload", err)
+		return
+	}
+
+	response, err := c.orderUsecase.ReplayOrderPayment(paymentReplay.OrderID)
+	if err != nil {
+		respondError(ctx, "order", "failed to replay payment", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
@@ -1,19 +1,25 @@
 package controllers
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/g73-techchallenge-order/internal/core/entities"
+	"github.com/g73-techchallenge-order/internal/core/usecases"
 	"github.com/g73-techchallenge-order/internal/core/usecases/dto"
 	mock_usecases "github.com/g73-techchallenge-order/internal/core/usecases/mocks"
 	"github.com/g73-techchallenge-order/internal/infra/drivers/authorizer"
+	"github.com/g73-techchallenge-order/internal/infra/drivers/events"
+	"github.com/g73-techchallenge-order/internal/infra/drivers/sql"
+	mock_gateways "github.com/g73-techchallenge-order/internal/infra/gateways/mocks"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
@@ -27,14 +33,15 @@ var orderResponseValid, _ = os.ReadFile("./testdata/order_response_valid.json")
 func TestOrderController_CreateOrder(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	orderUseCase := mock_usecases.NewMockOrderUsecase(ctrl)
-	orderController := NewOrderController(orderUseCase)
+	orderController := NewOrderController(orderUseCase, dto.PageLimits{})
 
 	gin.SetMode(gin.TestMode)
 	c, e := gin.CreateTestContext(httptest.NewRecorder())
 	e.POST("/v1/orders", orderController.CreateOrder)
 
 	type args struct {
-		reqBody string
+		reqBody        string
+		acceptLanguage string
 	}
 	type want struct {
 		statusCode int
@@ -66,6 +73,17 @@ func TestOrderController_CreateOrder(t *testing.T) {
 			args: args{
 				reqBody: string(orderRequestMissingStatus),
 			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"invalid order payload","error":"Status inválido"}`,
+			},
+		},
+		{
+			name: "should return bad request in english when status is missing and the accept language is en",
+			args: args{
+				reqBody:        string(orderRequestMissingStatus),
+				acceptLanguage: "en-US,en;q=0.9",
+			},
 			want: want{
 				statusCode: 400,
 				respBody:   `{"message":"invalid order payload","error":"Status is invalid"}`,
@@ -78,7 +96,50 @@ func TestOrderController_CreateOrder(t *testing.T) {
 			},
 			want: want{
 				statusCode: 400,
-				respBody:   `{"message":"invalid order payload","error":"invalid CPF [11122233344]"}`,
+				respBody:   `{"message":"invalid order payload","error":"CPF inválido [11122233344], dígitos verificadores não conferem"}`,
+			},
+		},
+		{
+			name: "should return bad request in english when cpf is wrong and the accept language is en",
+			args: args{
+				reqBody:        string(orderRequestWrongCpf),
+				acceptLanguage: "en",
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"invalid order payload","error":"invalid CPF [11122233344], check digits do not match"}`,
+			},
+		},
+		{
+			name: "should return bad request in portuguese when cpf is wrong and the accept language is pt-BR",
+			args: args{
+				reqBody:        string(orderRequestWrongCpf),
+				acceptLanguage: "pt-BR",
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"invalid order payload","error":"CPF inválido [11122233344], dígitos verificadores não conferem"}`,
+			},
+		},
+		{
+			name: "should return bad request when a delivery order has no address",
+			args: args{
+				reqBody: `{"items":[{"productId":1,"quantity":1,"type":"UNIT"}],"customerCpf":"00551146010","status":"CREATED","fulfillmentType":"DELIVERY"}`,
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"invalid order payload","error":"endereço de entrega é obrigatório para pedidos DELIVERY"}`,
+			},
+		},
+		{
+			name: "should return bad request when the fulfillment type is invalid",
+			args: args{
+				reqBody:        `{"items":[{"productId":1,"quantity":1,"type":"UNIT"}],"customerCpf":"00551146010","status":"CREATED","fulfillmentType":"DRIVE_THRU"}`,
+				acceptLanguage: "en",
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"invalid order payload","error":"Fulfillment type is invalid"}`,
 			},
 		},
 		{
@@ -129,108 +190,39 @@ func TestOrderController_CreateOrder(t *testing.T) {
 				err: nil,
 			},
 		},
-	}
-
-	for _, tt := range tests {
-		orderUseCase.
-			EXPECT().
-			CreateOrder(gomock.Any()).
-			Times(tt.orderUseCaseCall.times).
-			Return(tt.orderUseCaseCall.orderResponse, tt.orderUseCaseCall.err)
-
-		c.Request, _ = http.NewRequest(http.MethodPost, "/v1/orders", strings.NewReader(tt.args.reqBody))
-		c.Request.Header.Set("Content-Type", "application/json")
-		rr := httptest.NewRecorder()
-		e.ServeHTTP(rr, c.Request)
-
-		assert.Equal(t, tt.want.statusCode, rr.Code)
-		assert.Equal(t, tt.want.respBody, rr.Body.String())
-	}
-}
-
-func TestOrderController_GetAllOrders(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	orderUseCase := mock_usecases.NewMockOrderUsecase(ctrl)
-	orderController := NewOrderController(orderUseCase)
-
-	gin.SetMode(gin.TestMode)
-	c, e := gin.CreateTestContext(httptest.NewRecorder())
-	e.GET("/v1/orders", orderController.GetAllOrders)
-
-	type args struct {
-		limit  string
-		offset string
-	}
-	type want struct {
-		statusCode int
-		respBody   string
-	}
-	type orderUseCaseCall struct {
-		times int
-		page  dto.Page[entities.Order]
-		err   error
-	}
-	tests := []struct {
-		name string
-		args
-		want
-		orderUseCaseCall
-	}{
-		{
-			name: "should return bad request when limit is not a number",
-			args: args{
-				limit:  "123abc",
-				offset: "",
-			},
-			want: want{
-				statusCode: 400,
-				respBody:   `{"message":"invalid query parameters","error":"strconv.Atoi: parsing \"123abc\": invalid syntax"}`,
-			},
-		},
-		{
-			name: "should return bad request when offset is not a number",
-			args: args{
-				limit:  "1",
-				offset: "123abc",
-			},
-			want: want{
-				statusCode: 400,
-				respBody:   `{"message":"invalid query parameters","error":"strconv.Atoi: parsing \"123abc\": invalid syntax"}`,
-			},
-		},
 		{
-			name: "should not get order when the user case returns error",
+			name: "should return the order number on creation",
 			args: args{
-				limit:  "1",
-				offset: "2",
+				reqBody: string(orderRequestValid),
 			},
 			want: want{
-				statusCode: 500,
-				respBody:   `{"message":"failed to get all orders","error":"internal server error"}`,
+				statusCode: 200,
+				respBody:   `{"qrCode":"mercadopago123456","orderId":98765,"orderNumber":"042"}`,
 			},
 			orderUseCaseCall: orderUseCaseCall{
 				times: 1,
-				page:  dto.Page[entities.Order]{},
-				err:   errors.New("internal server error"),
+				orderResponse: dto.OrderCreationResponse{
+					QRCode:  "mercadopago123456",
+					OrderID: 98765,
+					Number:  "042",
+				},
 			},
 		},
 		{
-			name: "should get all orders succesfully",
+			name: "should omit the qrcode of an order with nothing to pay",
 			args: args{
-				limit:  "1",
-				offset: "2",
+				reqBody: string(orderRequestValid),
 			},
 			want: want{
 				statusCode: 200,
-				respBody:   string(orderResponseValid),
+				respBody:   `{"orderId":98765,"orderNumber":"042"}`,
 			},
 			orderUseCaseCall: orderUseCaseCall{
 				times: 1,
-				page: dto.Page[entities.Order]{
-					Result: []entities.Order{createOrder()},
-					Next:   new(int),
+				orderResponse: dto.OrderCreationResponse{
+					OrderID: 98765,
+					Number:  "042",
 				},
-				err: nil,
 			},
 		},
 	}
@@ -238,12 +230,15 @@ func TestOrderController_GetAllOrders(t *testing.T) {
 	for _, tt := range tests {
 		orderUseCase.
 			EXPECT().
-			GetAllOrders(gomock.Any()).
+			CreateOrder(gomock.Any()).
 			Times(tt.orderUseCaseCall.times).
-			Return(tt.orderUseCaseCall.page, tt.orderUseCaseCall.err)
+			Return(tt.orderUseCaseCall.orderResponse, tt.orderUseCaseCall.err)
 
-		c.Request, _ = http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/orders?limit=%s&offset=%s", tt.args.limit, tt.args.offset), nil)
+		c.Request, _ = http.NewRequest(http.MethodPost, "/v1/orders", strings.NewReader(tt.args.reqBody))
 		c.Request.Header.Set("Content-Type", "application/json")
+		if tt.args.acceptLanguage != "" {
+			c.Request.Header.Set("Accept-Language", tt.args.acceptLanguage)
+		}
 		rr := httptest.NewRecorder()
 		e.ServeHTTP(rr, c.Request)
 
@@ -252,26 +247,70 @@ func TestOrderController_GetAllOrders(t *testing.T) {
 	}
 }
 
-func TestOrderController_GetOrderStatus(t *testing.T) {
+func TestOrderController_CreateOrder_JSONArrayLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	orderUseCase := mock_usecases.NewMockOrderUsecase(ctrl)
+	orderController := NewOrderController(orderUseCase, dto.PageLimits{})
+
+	gin.SetMode(gin.TestMode)
+	_, e := gin.CreateTestContext(httptest.NewRecorder())
+	e.POST("/v1/orders", JSONArrayLimitMiddleware(3), orderController.CreateOrder)
+
+	item := `{"productId":1,"quantity":1,"type":"UNIT"}`
+	overLimitItems := strings.TrimSuffix(strings.Repeat(item+",", 4), ",")
+
+	t.Run("should reject an item array over the limit before binding it", func(t *testing.T) {
+		orderUseCase.EXPECT().CreateOrder(gomock.Any()).Times(0)
+
+		reqBody := fmt.Sprintf(`{"items":[%s],"customerCpf":"00551146010","status":"CREATED"}`, overLimitItems)
+		req, _ := http.NewRequest(http.MethodPost, "/v1/orders", strings.NewReader(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		e.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, `{"message":"failed to bind order payload","error":"json arrays are limited to 3 elements"}`, rr.Body.String())
+	})
+
+	t.Run("should bind an item array within the limit", func(t *testing.T) {
+		orderUseCase.
+			EXPECT().
+			CreateOrder(gomock.Any()).
+			DoAndReturn(func(order dto.OrderDTO) (dto.OrderCreationResponse, error) {
+				assert.Len(t, order.Items, 2)
+				return dto.OrderCreationResponse{QRCode: "mercadopago123456", OrderID: 98765}, nil
+			}).
+			Times(1)
+
+		req, _ := http.NewRequest(http.MethodPost, "/v1/orders", strings.NewReader(string(orderRequestValid)))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		e.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+}
+
+func TestOrderController_ValidateOrder(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	orderUseCase := mock_usecases.NewMockOrderUsecase(ctrl)
-	orderController := NewOrderController(orderUseCase)
+	orderController := NewOrderController(orderUseCase, dto.PageLimits{})
 
 	gin.SetMode(gin.TestMode)
 	c, e := gin.CreateTestContext(httptest.NewRecorder())
-	e.GET("/v1/orders/:id/status", orderController.GetOrderStatus)
+	e.POST("/v1/orders/validate", orderController.ValidateOrder)
 
 	type args struct {
-		id string
+		reqBody        string
+		acceptLanguage string
 	}
 	type want struct {
 		statusCode int
 		respBody   string
 	}
 	type orderUseCaseCall struct {
-		orderId     int
 		times       int
-		orderStatus dto.OrderStatusDTO
+		fieldErrors []dto.FieldError
 		err         error
 	}
 	tests := []struct {
@@ -281,55 +320,79 @@ func TestOrderController_GetOrderStatus(t *testing.T) {
 		orderUseCaseCall
 	}{
 		{
-			name: "should return bad request when id is missing",
-			args: args{},
+			name: "should return bad request when req body is not a json",
+			args: args{
+				reqBody: "<invalidJson>",
+			},
 			want: want{
 				statusCode: 400,
-				respBody:   `{"message":"[id] path parameter is required","error":"id is missing"}`,
+				respBody:   `{"message":"failed to bind order payload","error":"invalid character '\u003c' looking for beginning of value"}`,
 			},
 		},
 		{
-			name: "should return bad request when id is not a number",
+			name: "should return no content when the order is valid",
 			args: args{
-				id: "abc",
+				reqBody: string(orderRequestValid),
 			},
 			want: want{
-				statusCode: 400,
-				respBody:   `{"message":"[id] path parameter is invalid","error":"strconv.Atoi: parsing \"abc\": invalid syntax"}`,
+				statusCode: 204,
+			},
+			orderUseCaseCall: orderUseCaseCall{
+				times:       1,
+				fieldErrors: []dto.FieldError{},
 			},
 		},
 		{
-			name: "should not create order when the user case returns error",
+			name: "should return all the errors of an invalid order",
 			args: args{
-				id: "123",
+				reqBody:        string(orderRequestWrongCpf),
+				acceptLanguage: "en",
 			},
 			want: want{
-				statusCode: 500,
-				respBody:   `{"message":"failed to get order status","error":"internal server error"}`,
+				statusCode: 400,
+				respBody: `{"message":"invalid order payload","errors":[` +
+					`{"field":"customerCpf","message":"invalid CPF [11122233344], check digits do not match"},` +
+					`{"field":"items[1].productId","message":"product [2] not found"}]}`,
 			},
 			orderUseCaseCall: orderUseCaseCall{
-				orderId:     123,
-				orderStatus: dto.OrderStatusDTO{},
-				times:       1,
-				err:         errors.New("internal server error"),
+				times: 1,
+				fieldErrors: []dto.FieldError{
+					{Field: "customerCpf", Err: dto.InvalidCPFError{CPF: "11122233344", Reason: dto.InvalidCPFCheckDigits}},
+					{Field: "items[1].productId", Err: dto.OrderItemProductNotFoundError{ProductID: 2}},
+				},
 			},
 		},
 		{
-			name: "should get order status succesfully",
+			name: "should return all the errors of an invalid order in portuguese",
 			args: args{
-				id: "123",
+				reqBody: string(orderRequestWrongCpf),
 			},
 			want: want{
-				statusCode: 200,
-				respBody:   `{"status":"CREATED"}`,
+				statusCode: 400,
+				respBody: `{"message":"invalid order payload","errors":[` +
+					`{"field":"customerCpf","message":"CPF inválido [11122233344], dígitos verificadores não conferem"},` +
+					`{"field":"items[1].productId","message":"produto [2] não encontrado"}]}`,
 			},
 			orderUseCaseCall: orderUseCaseCall{
-				orderId: 123,
-				orderStatus: dto.OrderStatusDTO{
-					Status: "CREATED",
+				times: 1,
+				fieldErrors: []dto.FieldError{
+					{Field: "customerCpf", Err: dto.InvalidCPFError{CPF: "11122233344", Reason: dto.InvalidCPFCheckDigits}},
+					{Field: "items[1].productId", Err: dto.OrderItemProductNotFoundError{ProductID: 2}},
 				},
+			},
+		},
+		{
+			name: "should return internal server error when the validation fails",
+			args: args{
+				reqBody: string(orderRequestValid),
+			},
+			want: want{
+				statusCode: 500,
+				respBody:   `{"message":"failed to validate order","error":"internal server error"}`,
+			},
+			orderUseCaseCall: orderUseCaseCall{
 				times: 1,
-				err:   nil,
+				err:   errors.New("internal server error"),
 			},
 		},
 	}
@@ -337,12 +400,15 @@ func TestOrderController_GetOrderStatus(t *testing.T) {
 	for _, tt := range tests {
 		orderUseCase.
 			EXPECT().
-			GetOrderStatus(gomock.Eq(tt.orderUseCaseCall.orderId)).
+			ValidateOrder(gomock.Any()).
 			Times(tt.orderUseCaseCall.times).
-			Return(tt.orderUseCaseCall.orderStatus, tt.orderUseCaseCall.err)
+			Return(tt.orderUseCaseCall.fieldErrors, tt.orderUseCaseCall.err)
 
-		c.Request, _ = http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/orders/%s/status", tt.args.id), nil)
+		c.Request, _ = http.NewRequest(http.MethodPost, "/v1/orders/validate", strings.NewReader(tt.args.reqBody))
 		c.Request.Header.Set("Content-Type", "application/json")
+		if tt.args.acceptLanguage != "" {
+			c.Request.Header.Set("Accept-Language", tt.args.acceptLanguage)
+		}
 		rr := httptest.NewRecorder()
 		e.ServeHTTP(rr, c.Request)
 
@@ -351,28 +417,30 @@ func TestOrderController_GetOrderStatus(t *testing.T) {
 	}
 }
 
-func TestOrderController_UpdateOrderStatus(t *testing.T) {
+func TestOrderController_GetAllOrders(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	orderUseCase := mock_usecases.NewMockOrderUsecase(ctrl)
-	orderController := NewOrderController(orderUseCase)
+	orderController := NewOrderController(orderUseCase, dto.PageLimits{})
 
 	gin.SetMode(gin.TestMode)
 	c, e := gin.CreateTestContext(httptest.NewRecorder())
-	e.PUT("/v1/orders/:id/status", orderController.UpdateOrderStatus)
+	e.GET("/v1/orders", orderController.GetAllOrders)
 
 	type args struct {
-		id      string
-		reqBody string
+		limit           string
+		offset          string
+		fulfillmentType string
+		status          string
 	}
 	type want struct {
 		statusCode int
 		respBody   string
 	}
 	type orderUseCaseCall struct {
-		orderId     int
-		orderStatus string
-		times       int
-		err         error
+		times    int
+		statuses []string
+		page     dto.Page[entities.Order]
+		err      error
 	}
 	tests := []struct {
 		name string
@@ -381,79 +449,1825 @@ func TestOrderController_UpdateOrderStatus(t *testing.T) {
 		orderUseCaseCall
 	}{
 		{
-			name: "should return bad request when id is missing",
+			name: "should return bad request when limit is not a number",
 			args: args{
-				reqBody: `{"status":"CREATED"}`,
+				limit:  "123abc",
+				offset: "",
 			},
 			want: want{
 				statusCode: 400,
-				respBody:   `{"message":"[id] path parameter is required","error":"id is missing"}`,
+				respBody:   `{"message":"invalid query parameters","errors":[{"field":"limit","message":"strconv.Atoi: parsing \"123abc\": invalid syntax"}]}`,
 			},
 		},
 		{
-			name: "should return bad request when id is not a number",
+			name: "should return bad request when offset is not a number",
 			args: args{
-				id:      "abc",
-				reqBody: `{"status":"CREATED"}`,
+				limit:  "1",
+				offset: "123abc",
 			},
 			want: want{
 				statusCode: 400,
-				respBody:   `{"message":"[id] path parameter is invalid","error":"strconv.Atoi: parsing \"abc\": invalid syntax"}`,
+				respBody:   `{"message":"invalid query parameters","errors":[{"field":"offset","message":"strconv.Atoi: parsing \"123abc\": invalid syntax"}]}`,
 			},
 		},
 		{
-			name: "should return bad request when req body is not a json",
+			name: "should return every invalid query parameter at once",
 			args: args{
-				id:      "123",
-				reqBody: "<invalidJson>",
+				limit:           "ten",
+				offset:          "-x",
+				fulfillmentType: "DRIVE_THRU",
+				status:          "COOKING",
 			},
 			want: want{
 				statusCode: 400,
-				respBody:   `{"message":"failed to bind order status payload","error":"invalid character '\u003c' looking for beginning of value"}`,
+				respBody:   `{"message":"invalid query parameters","errors":[{"field":"limit","message":"strconv.Atoi: parsing \"ten\": invalid syntax"},{"field":"offset","message":"strconv.Atoi: parsing \"-x\": invalid syntax"},{"field":"fulfillmentType","message":"fulfillmentType [DRIVE_THRU] is invalid"},{"field":"status","message":"status [COOKING] is invalid"}]}`,
 			},
 		},
-
 		{
-			name: "should return bad request when state is wrong in the request",
+			name: "should return bad request when fulfillment type is invalid",
 			args: args{
-				id:      "123",
-				reqBody: `{"status":"WRONG_STATE"}`,
+				limit:           "1",
+				offset:          "2",
+				fulfillmentType: "DRIVE_THRU",
 			},
 			want: want{
 				statusCode: 400,
-				respBody:   `{"message":"invalid order status payload","error":"status: WRONG_STATE does not validate as in(CREATED|PAID|RECEIVED|IN_PROGRESS|READY|DONE)"}`,
+				respBody:   `{"message":"invalid query parameters","errors":[{"field":"fulfillmentType","message":"fulfillmentType [DRIVE_THRU] is invalid"}]}`,
 			},
 		},
 		{
-			name: "should not create order when the user case returns error",
+			name: "should get orders filtered by fulfillment type",
 			args: args{
-				id:      "123",
-				reqBody: `{"status":"CREATED"}`,
+				limit:           "1",
+				offset:          "2",
+				fulfillmentType: "DELIVERY",
 			},
 			want: want{
-				statusCode: 500,
-				respBody:   `{"message":"failed to update order status","error":"internal server error"}`,
+				statusCode: 200,
+				respBody:   `{"results":[]}`,
 			},
 			orderUseCaseCall: orderUseCaseCall{
-				orderId:     123,
-				orderStatus: "CREATED",
-				times:       1,
-				err:         errors.New("internal server error"),
+				times: 1,
+				page: dto.Page[entities.Order]{
+					Result: []entities.Order{},
+				},
+				err: nil,
 			},
 		},
 		{
-			name: "should update order status succesfully",
+			name: "should return bad request when one of the statuses is invalid",
 			args: args{
-				id:      "123",
-				reqBody: string(orderRequestValid),
+				limit:  "1",
+				offset: "2",
+				status: "PAID,COOKING",
 			},
 			want: want{
-				statusCode: 204,
-				respBody:   "",
+				statusCode: 400,
+				respBody:   `{"message":"invalid query parameters","errors":[{"field":"status","message":"status [COOKING] is invalid"}]}`,
 			},
-			orderUseCaseCall: orderUseCaseCall{
-				orderId:     123,
-				orderStatus: "CREATED",
+		},
+		{
+			name: "should get orders filtered by several statuses",
+			args: args{
+				limit:  "1",
+				offset: "2",
+				status: "RECEIVED,IN_PROGRESS&status=READY",
+			},
+			want: want{
+				statusCode: 200,
+				respBody:   `{"results":[]}`,
+			},
+			orderUseCaseCall: orderUseCaseCall{
+				times:    1,
+				statuses: []string{"RECEIVED", "IN_PROGRESS", "READY"},
+				page: dto.Page[entities.Order]{
+					Result: []entities.Order{},
+				},
+				err: nil,
+			},
+		},
+		{
+			name: "should not get order when the user case returns error",
+			args: args{
+				limit:  "1",
+				offset: "2",
+			},
+			want: want{
+				statusCode: 500,
+				respBody:   `{"message":"failed to get all orders","error":"internal server error"}`,
+			},
+			orderUseCaseCall: orderUseCaseCall{
+				times: 1,
+				page:  dto.Page[entities.Order]{},
+				err:   errors.New("internal server error"),
+			},
+		},
+		{
+			name: "should get all orders succesfully",
+			args: args{
+				limit:  "1",
+				offset: "2",
+			},
+			want: want{
+				statusCode: 200,
+				respBody:   string(orderResponseValid),
+			},
+			orderUseCaseCall: orderUseCaseCall{
+				times: 1,
+				page: dto.Page[entities.Order]{
+					Result: []entities.Order{createOrder()},
+					Next:   new(int),
+				},
+				err: nil,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		orderUseCase.
+			EXPECT().
+			GetAllOrders(gomock.Any(), gomock.Eq(dto.OrderFilters{FulfillmentType: tt.args.fulfillmentType, Statuses: tt.orderUseCaseCall.statuses})).
+			Times(tt.orderUseCaseCall.times).
+			Return(tt.orderUseCaseCall.page, tt.orderUseCaseCall.err)
+
+		c.Request, _ = http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/orders?limit=%s&offset=%s&fulfillmentType=%s&status=%s", tt.args.limit, tt.args.offset, tt.args.fulfillmentType, tt.args.status), nil)
+		c.Request.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		e.ServeHTTP(rr, c.Request)
+
+		assert.Equal(t, tt.want.statusCode, rr.Code)
+		assert.Equal(t, tt.want.respBody, rr.Body.String())
+	}
+}
+
+func TestOrderController_GetAllOrders_PageLimits(t *testing.T) {
+	tests := []struct {
+		name      string
+		limit     string
+		wantLimit int
+	}{
+		{
+			name:      "should use the orders default page size when limit is omitted",
+			limit:     "",
+			wantLimit: 20,
+		},
+		{
+			name:      "should cap the limit to the orders max page size",
+			limit:     "500",
+			wantLimit: 200,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			orderUseCase := mock_usecases.NewMockOrderUsecase(ctrl)
+			orderController := NewOrderController(orderUseCase, dto.PageLimits{Default: 20, Max: 200})
+
+			gin.SetMode(gin.TestMode)
+			_, e := gin.CreateTestContext(httptest.NewRecorder())
+			e.GET("/v1/orders", orderController.GetAllOrders)
+
+			var pageParams dto.PageParams
+			orderUseCase.
+				EXPECT().
+				GetAllOrders(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(params dto.PageParams, filters dto.OrderFilters) (dto.Page[entities.Order], error) {
+					pageParams = params
+					return dto.Page[entities.Order]{}, nil
+				}).
+				Times(1)
+
+			req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/orders?limit=%s", tt.limit), nil)
+			rr := httptest.NewRecorder()
+			e.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, tt.wantLimit, pageParams.GetLimit())
+		})
+	}
+}
+
+func TestOrderController_GetAllOrders_ResultTruncatedHeader(t *testing.T) {
+	tests := []struct {
+		name       string
+		limits     dto.PageLimits
+		limit      string
+		wantHeader string
+	}{
+		{
+			name:       "should warn the limit was clamped to the orders max page size",
+			limits:     dto.PageLimits{Default: 20, Max: 200, WarnClamped: true},
+			limit:      "500",
+			wantHeader: "true",
+		},
+		{
+			name:   "should not warn when the limit is within the max page size",
+			limits: dto.PageLimits{Default: 20, Max: 200, WarnClamped: true},
+			limit:  "200",
+		},
+		{
+			name:   "should not warn when the warning is disabled",
+			limits: dto.PageLimits{Default: 20, Max: 200},
+			limit:  "500",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			orderUseCase := mock_usecases.NewMockOrderUsecase(ctrl)
+			orderController := NewOrderController(orderUseCase, tt.limits)
+
+			gin.SetMode(gin.TestMode)
+			_, e := gin.CreateTestContext(httptest.NewRecorder())
+			e.GET("/v1/orders", orderController.GetAllOrders)
+
+			orderUseCase.EXPECT().GetAllOrders(gomock.Any(), gomock.Any()).Return(dto.Page[entities.Order]{}, nil).Times(1)
+
+			req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/orders?limit=%s", tt.limit), nil)
+			rr := httptest.NewRecorder()
+			e.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, tt.wantHeader, rr.Header().Get("X-Result-Truncated"))
+		})
+	}
+}
+
+func TestOrderController_GetAllOrders_Sort(t *testing.T) {
+	tests := []struct {
+		name       string
+		sort       string
+		wantStatus int
+		wantSort   dto.OrderSort
+		wantBody   string
+	}{
+		{
+			name:       "should leave the sort to the default when none is asked",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "should pass the sort asked to override the default",
+			sort:       "oldest",
+			wantStatus: http.StatusOK,
+			wantSort:   dto.OrderSortOldest,
+		},
+		{
+			name:       "should return bad request when the sort is unknown",
+			sort:       "cheapest",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"message":"invalid query parameters","errors":[{"field":"sort","message":"sort [cheapest] is invalid"}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			orderUseCase := mock_usecases.NewMockOrderUsecase(ctrl)
+			orderController := NewOrderController(orderUseCase, dto.PageLimits{})
+
+			gin.SetMode(gin.TestMode)
+			_, e := gin.CreateTestContext(httptest.NewRecorder())
+			e.GET("/v1/orders", orderController.GetAllOrders)
+
+			times := 0
+			if tt.wantStatus == http.StatusOK {
+				times = 1
+			}
+			orderUseCase.EXPECT().
+				GetAllOrders(gomock.Any(), gomock.Eq(dto.OrderFilters{Sort: tt.wantSort})).
+				Return(dto.Page[entities.Order]{}, nil).
+				Times(times)
+
+			req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/orders?sort=%s", tt.sort), nil)
+			rr := httptest.NewRecorder()
+			e.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestOrderController_GetOrderByNumber(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	orderUseCase := mock_usecases.NewMockOrderUsecase(ctrl)
+	orderController := NewOrderController(orderUseCase, dto.PageLimits{})
+
+	gin.SetMode(gin.TestMode)
+	c, e := gin.CreateTestContext(httptest.NewRecorder())
+	e.GET("/v1/orders/number/:number", orderController.GetOrderByNumber)
+
+	order := createOrder()
+	order.Number = "042"
+	orderResponse, _ := json.Marshal(order)
+
+	tests := []struct {
+		name         string
+		number       string
+		order        entities.Order
+		err          error
+		wantStatus   int
+		wantRespBody string
+	}{
+		{
+			name:         "should return the order with the number",
+			number:       "042",
+			order:        order,
+			wantStatus:   200,
+			wantRespBody: string(orderResponse),
+		},
+		{
+			name:         "should return not found when no order has the number",
+			number:       "999",
+			err:          sql.ErrNotFound,
+			wantStatus:   404,
+			wantRespBody: `{"message":"order not found","error":"entity not found"}`,
+		},
+		{
+			name:         "should return internal server error when the order can not be found",
+			number:       "043",
+			err:          errors.New("connection refused"),
+			wantStatus:   500,
+			wantRespBody: `{"message":"failed to get order by number","error":"connection refused"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orderUseCase.EXPECT().GetOrderByNumber(tt.number).Return(tt.order, tt.err).Times(1)
+
+			c.Request, _ = http.NewRequest(http.MethodGet, "/v1/orders/number/"+tt.number, nil)
+			rr := httptest.NewRecorder()
+			e.ServeHTTP(rr, c.Request)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			assert.Equal(t, tt.wantRespBody, rr.Body.String())
+		})
+	}
+}
+
+func TestOrderController_GetOrderByNumber_DisplayCurrency(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	orderUseCase := mock_usecases.NewMockOrderUsecase(ctrl)
+	orderController := NewOrderController(orderUseCase, dto.PageLimits{})
+
+	gin.SetMode(gin.TestMode)
+	c, e := gin.CreateTestContext(httptest.NewRecorder())
+	e.GET("/v1/orders/number/:number", orderController.GetOrderByNumber)
+
+	order := createOrder()
+	order.Number = "042"
+	displayTotal := dto.DisplayTotalDTO{BaseCurrency: "BRL", BaseAmount: 45.9, Currency: "USD", Rate: 0.18, Amount: 8.26}
+	orderResponse, _ := json.Marshal(dto.OrderDetailDTO{Order: order, DisplayTotal: &displayTotal})
+
+	tests := []struct {
+		name         string
+		currency     string
+		displayTotal dto.DisplayTotalDTO
+		err          error
+		wantStatus   int
+		wantRespBody string
+	}{
+		{
+			name:         "should return the order with the total converted to the display currency",
+			currency:     "USD",
+			displayTotal: displayTotal,
+			wantStatus:   200,
+			wantRespBody: string(orderResponse),
+		},
+		{
+			name:         "should return bad request when the display currency is not supported",
+			currency:     "XYZ",
+			err:          fmt.Errorf("%w [XYZ]", dto.ErrUnsupportedCurrency),
+			wantStatus:   400,
+			wantRespBody: `{"message":"invalid display currency","error":"currency is not supported [XYZ]"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orderUseCase.EXPECT().GetOrderByNumber("042").Return(order, nil).Times(1)
+			orderUseCase.EXPECT().ConvertOrderTotal(order, tt.currency).Return(tt.displayTotal, tt.err).Times(1)
+
+			c.Request, _ = http.NewRequest(http.MethodGet, "/v1/orders/number/042?displayCurrency="+tt.currency, nil)
+			rr := httptest.NewRecorder()
+			e.ServeHTTP(rr, c.Request)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			assert.Equal(t, tt.wantRespBody, rr.Body.String())
+		})
+	}
+}
+
+func TestOrderController_ReplayPaymentWebhook(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	orderUseCase := mock_usecases.NewMockOrderUsecase(ctrl)
+	orderController := NewOrderController(orderUseCase, dto.PageLimits{})
+
+	gin.SetMode(gin.TestMode)
+	c, e := gin.CreateTestContext(httptest.NewRecorder())
+	e.POST("/v1/payments/webhook/replay", orderController.ReplayPaymentWebhook)
+
+	tests := []struct {
+		name         string
+		body         string
+		replayCalls  int
+		response     dto.PaymentReplayResponseDTO
+		wantStatus   int
+		wantRespBody string
+	}{
+		{
+			name:         "should confirm the order of the replayed payload",
+			body:         `{"orderId":7,"notification":{"type":"payment","data":{"id":"123456"}}}`,
+			replayCalls:  1,
+			response:     dto.PaymentReplayResponseDTO{OrderID: 7, Confirmed: true, Status: dto.OrderStatusPaid},
+			wantStatus:   200,
+			wantRespBody: `{"orderId":7,"confirmed":true,"status":"PAID"}`,
+		},
+		{
+			name:         "should answer a no-op replay of an order already paid",
+			body:         `{"orderId":8,"notification":{"type":"payment","data":{"id":"123456"}}}`,
+			replayCalls:  1,
+			response:     dto.PaymentReplayResponseDTO{OrderID: 8, Confirmed: false, Status: dto.OrderStatusReady},
+			wantStatus:   200,
+			wantRespBody: `{"orderId":8,"confirmed":false,"status":"READY"}`,
+		},
+		{
+			name:         "should return bad request when the notification is not a payment",
+			body:         `{"orderId":7,"notification":{"type":"refund","data":{"id":"123456"}}}`,
+			wantStatus:   400,
+			wantRespBody: `{"message":"invalid payment replay payload","error":"type: refund does not validate as in(payment)"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orderUseCase.EXPECT().ReplayOrderPayment(tt.response.OrderID).Return(tt.response, nil).Times(tt.replayCalls)
+
+			c.Request, _ = http.NewRequest(http.MethodPost, "/v1/payments/webhook/replay", strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+			e.ServeHTTP(rr, c.Request)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			assert.Equal(t, tt.wantRespBody, rr.Body.String())
+		})
+	}
+}
+
+func TestOrderController_GetOrderStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	orderUseCase := mock_usecases.NewMockOrderUsecase(ctrl)
+	orderController := NewOrderController(orderUseCase, dto.PageLimits{})
+
+	gin.SetMode(gin.TestMode)
+	c, e := gin.CreateTestContext(httptest.NewRecorder())
+	e.GET("/v1/orders/:id/status", orderController.GetOrderStatus)
+
+	type args struct {
+		id    string
+		query string
+	}
+	type want struct {
+		statusCode int
+		respBody   string
+	}
+	type orderUseCaseCall struct {
+		orderId     int
+		times       int
+		orderStatus dto.OrderStatusDTO
+		err         error
+	}
+	tests := []struct {
+		name string
+		args
+		want
+		orderUseCaseCall
+	}{
+		{
+			name: "should return bad request when id is missing",
+			args: args{},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"[id] path parameter is required","error":"id is missing"}`,
+			},
+		},
+		{
+			name: "should return bad request when id is not a number",
+			args: args{
+				id: "abc",
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"[id] path parameter is invalid","error":"strconv.Atoi: parsing \"abc\": invalid syntax"}`,
+			},
+		},
+		{
+			name: "should not create order when the user case returns error",
+			args: args{
+				id: "123",
+			},
+			want: want{
+				statusCode: 500,
+				respBody:   `{"message":"failed to get order status","error":"internal server error"}`,
+			},
+			orderUseCaseCall: orderUseCaseCall{
+				orderId:     123,
+				orderStatus: dto.OrderStatusDTO{},
+				times:       1,
+				err:         errors.New("internal server error"),
+			},
+		},
+		{
+			name: "should get order status succesfully",
+			args: args{
+				id: "123",
+			},
+			want: want{
+				statusCode: 200,
+				respBody:   `{"status":"CREATED"}`,
+			},
+			orderUseCaseCall: orderUseCaseCall{
+				orderId: 123,
+				orderStatus: dto.OrderStatusDTO{
+					Status: "CREATED",
+				},
+				times: 1,
+				err:   nil,
+			},
+		},
+		{
+			name: "should return bad request when includeAllowedNext is not a boolean",
+			args: args{
+				id:    "123",
+				query: "?includeAllowedNext=maybe",
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"[includeAllowedNext] query parameter is invalid","error":"strconv.ParseBool: parsing \"maybe\": invalid syntax"}`,
+			},
+		},
+		{
+			name: "should return the allowed next statuses of a CREATED order",
+			args: args{
+				id:    "123",
+				query: "?includeAllowedNext=true",
+			},
+			want: want{
+				statusCode: 200,
+				respBody:   `{"status":"CREATED","allowedNext":["PAID"]}`,
+			},
+			orderUseCaseCall: orderUseCaseCall{
+				orderId: 123,
+				orderStatus: dto.OrderStatusDTO{
+					Status: "CREATED",
+				},
+				times: 1,
+			},
+		},
+		{
+			name: "should return the allowed next statuses of a READY order",
+			args: args{
+				id:    "123",
+				query: "?includeAllowedNext=true",
+			},
+			want: want{
+				statusCode: 200,
+				respBody:   `{"status":"READY","allowedNext":["DONE"]}`,
+			},
+			orderUseCaseCall: orderUseCaseCall{
+				orderId: 123,
+				orderStatus: dto.OrderStatusDTO{
+					Status: "READY",
+				},
+				times: 1,
+			},
+		},
+		{
+			name: "should return no allowed next statuses for a DONE order",
+			args: args{
+				id:    "123",
+				query: "?includeAllowedNext=true",
+			},
+			want: want{
+				statusCode: 200,
+				respBody:   `{"status":"DONE","allowedNext":[]}`,
+			},
+			orderUseCaseCall: orderUseCaseCall{
+				orderId: 123,
+				orderStatus: dto.OrderStatusDTO{
+					Status: "DONE",
+				},
+				times: 1,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		orderUseCase.
+			EXPECT().
+			GetOrderStatus(gomock.Eq(tt.orderUseCaseCall.orderId)).
+			Times(tt.orderUseCaseCall.times).
+			Return(tt.orderUseCaseCall.orderStatus, tt.orderUseCaseCall.err)
+
+		c.Request, _ = http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/orders/%s/status%s", tt.args.id, tt.args.query), nil)
+		c.Request.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		e.ServeHTTP(rr, c.Request)
+
+		assert.Equal(t, tt.want.statusCode, rr.Code)
+		assert.Equal(t, tt.want.respBody, rr.Body.String())
+	}
+}
+
+func TestOrderController_GetOrderStatus_ConditionalRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	orderUseCase := mock_usecases.NewMockOrderUsecase(ctrl)
+	orderController := NewOrderController(orderUseCase, dto.PageLimits{})
+
+	gin.SetMode(gin.TestMode)
+	_, e := gin.CreateTestContext(httptest.NewRecorder())
+	e.GET("/v1/orders/:id/status", orderController.GetOrderStatus)
+
+	updatedAt := time.Date(2024, 1, 1, 12, 30, 15, 500000000, time.FixedZone("BRT", -3*60*60))
+	orderUseCase.EXPECT().
+		GetOrderStatus(123).
+		Return(dto.OrderStatusDTO{Status: dto.OrderStatusInProgress, UpdatedAt: updatedAt}, nil).
+		Times(3)
+
+	req, _ := http.NewRequest(http.MethodGet, "/v1/orders/123/status", nil)
+	rr := httptest.NewRecorder()
+	e.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `{"status":"IN_PROGRESS"}`, rr.Body.String())
+	lastModified := rr.Header().Get("Last-Modified")
+	assert.Equal(t, "Mon, 01 Jan 2024 15:30:15 GMT", lastModified)
+
+	req, _ = http.NewRequest(http.MethodGet, "/v1/orders/123/status", nil)
+	req.Header.Set("If-Modified-Since", lastModified)
+	rr = httptest.NewRecorder()
+	e.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNotModified, rr.Code)
+	assert.Empty(t, rr.Body.String())
+	assert.Equal(t, lastModified, rr.Header().Get("Last-Modified"))
+
+	req, _ = http.NewRequest(http.MethodGet, "/v1/orders/123/status", nil)
+	req.Header.Set("If-Modified-Since", "Mon, 01 Jan 2024 15:30:14 GMT")
+	rr = httptest.NewRecorder()
+	e.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `{"status":"IN_PROGRESS"}`, rr.Body.String())
+}
+
+func TestOrderController_GetCompletedOrders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	orderUseCase := mock_usecases.NewMockOrderUsecase(ctrl)
+	orderController := NewOrderController(orderUseCase, dto.PageLimits{})
+
+	gin.SetMode(gin.TestMode)
+	c, e := gin.CreateTestContext(httptest.NewRecorder())
+	e.GET("/v1/orders/completed", orderController.GetCompletedOrders)
+	e.GET("/v1/orders/:id/status", orderController.GetOrderStatus)
+
+	since := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	type args struct {
+		query string
+	}
+	type want struct {
+		statusCode int
+		respBody   string
+	}
+	type orderUseCaseCall struct {
+		since  time.Time
+		times  int
+		orders []dto.CompletedOrderDTO
+		err    error
+	}
+	tests := []struct {
+		name string
+		args
+		want
+		orderUseCaseCall
+	}{
+		{
+			name: "should return bad request when since is not a RFC3339 time",
+			args: args{query: "?since=yesterday"},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"[since] query parameter is invalid","error":"parsing time \"yesterday\" as \"2006-01-02T15:04:05Z07:00\": cannot parse \"yesterday\" as \"2006\""}`,
+			},
+		},
+		{
+			name: "should return internal server error when the usecase fails",
+			args: args{query: "?since=2024-01-01T12:00:00Z"},
+			want: want{
+				statusCode: 500,
+				respBody:   `{"message":"failed to get completed orders","error":"internal server error"}`,
+			},
+			orderUseCaseCall: orderUseCaseCall{
+				since: since,
+				times: 1,
+				err:   errors.New("internal server error"),
+			},
+		},
+		{
+			name: "should list the orders completed since the given time, the most recent first",
+			args: args{query: "?since=2024-01-01T12:00:00Z"},
+			want: want{
+				statusCode: 200,
+				respBody:   `[{"orderId":8,"orderNumber":"043","completedAt":"2024-01-01T12:20:00Z"},{"orderId":7,"orderNumber":"042","completedAt":"2024-01-01T12:05:00Z"}]`,
+			},
+			orderUseCaseCall: orderUseCaseCall{
+				since: since,
+				times: 1,
+				orders: []dto.CompletedOrderDTO{
+					{OrderID: 8, Number: "043", CompletedAt: since.Add(20 * time.Minute)},
+					{OrderID: 7, Number: "042", CompletedAt: since.Add(5 * time.Minute)},
+				},
+			},
+		},
+		{
+			name: "should leave the default window to the usecase when since is omitted",
+			want: want{
+				statusCode: 200,
+				respBody:   `[]`,
+			},
+			orderUseCaseCall: orderUseCaseCall{
+				times:  1,
+				orders: []dto.CompletedOrderDTO{},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		orderUseCase.
+			EXPECT().
+			GetCompletedOrders(gomock.Eq(tt.orderUseCaseCall.since)).
+			Times(tt.orderUseCaseCall.times).
+			Return(tt.orderUseCaseCall.orders, tt.orderUseCaseCall.err)
+
+		c.Request, _ = http.NewRequest(http.MethodGet, "/v1/orders/completed"+tt.args.query, nil)
+		rr := httptest.NewRecorder()
+		e.ServeHTTP(rr, c.Request)
+
+		assert.Equal(t, tt.want.statusCode, rr.Code)
+		assert.Equal(t, tt.want.respBody, rr.Body.String())
+	}
+}
+
+func TestOrderController_GetOrderStatuses(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	orderUseCase := mock_usecases.NewMockOrderUsecase(ctrl)
+	orderController := NewOrderController(orderUseCase, dto.PageLimits{})
+
+	gin.SetMode(gin.TestMode)
+	c, e := gin.CreateTestContext(httptest.NewRecorder())
+	e.GET("/v1/orders/status", orderController.GetOrderStatuses)
+	e.GET("/v1/orders/:id/status", orderController.GetOrderStatus)
+
+	type args struct {
+		query string
+	}
+	type want struct {
+		statusCode int
+		respBody   string
+	}
+	type orderUseCaseCall struct {
+		orderIds      []int
+		times         int
+		orderStatuses dto.OrderStatusesDTO
+		err           error
+	}
+	tests := []struct {
+		name string
+		args
+		want
+		orderUseCaseCall
+	}{
+		{
+			name: "should return bad request when ids are missing",
+			args: args{},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"[ids] query parameter is invalid","error":"[ids] query parameter is required"}`,
+			},
+		},
+		{
+			name: "should return bad request when an id is not a number",
+			args: args{query: "?ids=1,abc"},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"[ids] query parameter is invalid","error":"id [abc] is invalid"}`,
+			},
+		},
+		{
+			name: "should return internal server error when the use case fails",
+			args: args{query: "?ids=1"},
+			want: want{
+				statusCode: 500,
+				respBody:   `{"message":"failed to get order statuses","error":"internal server error"}`,
+			},
+			orderUseCaseCall: orderUseCaseCall{
+				orderIds: []int{1},
+				times:    1,
+				err:      errors.New("internal server error"),
+			},
+		},
+		{
+			name: "should return the status of every order when all are found",
+			args: args{query: "?ids=1,2,3"},
+			want: want{
+				statusCode: 200,
+				respBody:   `{"statuses":{"1":"CREATED","2":"IN_PROGRESS","3":"READY"},"notFound":[]}`,
+			},
+			orderUseCaseCall: orderUseCaseCall{
+				orderIds: []int{1, 2, 3},
+				times:    1,
+				orderStatuses: dto.OrderStatusesDTO{
+					Statuses: map[int]dto.OrderStatus{1: dto.OrderStatusCreated, 2: dto.OrderStatusInProgress, 3: dto.OrderStatusReady},
+					NotFound: []int{},
+				},
+			},
+		},
+		{
+			name: "should report unknown orders separately when only some are found",
+			args: args{query: "?ids=1,%2099&ids=2"},
+			want: want{
+				statusCode: 200,
+				respBody:   `{"statuses":{"1":"PAID","2":"DONE"},"notFound":[99]}`,
+			},
+			orderUseCaseCall: orderUseCaseCall{
+				orderIds: []int{1, 99, 2},
+				times:    1,
+				orderStatuses: dto.OrderStatusesDTO{
+					Statuses: map[int]dto.OrderStatus{1: dto.OrderStatusPaid, 2: dto.OrderStatusDone},
+					NotFound: []int{99},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		orderUseCase.
+			EXPECT().
+			GetOrderStatuses(gomock.Eq(tt.orderUseCaseCall.orderIds)).
+			Times(tt.orderUseCaseCall.times).
+			Return(tt.orderUseCaseCall.orderStatuses, tt.orderUseCaseCall.err)
+
+		c.Request, _ = http.NewRequest(http.MethodGet, "/v1/orders/status"+tt.args.query, nil)
+		rr := httptest.NewRecorder()
+		e.ServeHTTP(rr, c.Request)
+
+		assert.Equal(t, tt.want.statusCode, rr.Code)
+		assert.Equal(t, tt.want.respBody, rr.Body.String())
+	}
+}
+
+func TestOrderController_NonPositiveIds(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	// no usecase call is expected, the ids are rejected by the handlers
+	orderUseCase := mock_usecases.NewMockOrderUsecase(ctrl)
+	orderController := NewOrderController(orderUseCase, dto.PageLimits{})
+
+	gin.SetMode(gin.TestMode)
+	_, e := gin.CreateTestContext(httptest.NewRecorder())
+	e.GET("/v1/orders/status", orderController.GetOrderStatuses)
+	e.GET("/v1/orders/:id/status", orderController.GetOrderStatus)
+	e.GET("/v1/orders/:id/qrcode.png", orderController.GetOrderQRCode)
+	e.DELETE("/v1/orders/:id/items/:itemId", orderController.RemoveOrderItem)
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		respBody string
+	}{
+		{
+			name:     "should reject a zero order id",
+			method:   http.MethodGet,
+			path:     "/v1/orders/0/status",
+			respBody: `{"message":"[id] path parameter is invalid","error":"id must be greater than zero"}`,
+		},
+		{
+			name:     "should reject a negative order id",
+			method:   http.MethodGet,
+			path:     "/v1/orders/-5/qrcode.png",
+			respBody: `{"message":"[id] path parameter is invalid","error":"id must be greater than zero"}`,
+		},
+		{
+			name:     "should reject a negative item id",
+			method:   http.MethodDelete,
+			path:     "/v1/orders/7/items/-5",
+			respBody: `{"message":"[itemId] path parameter is invalid","error":"id must be greater than zero"}`,
+		},
+		{
+			name:     "should reject a zero id on the bulk status query",
+			method:   http.MethodGet,
+			path:     "/v1/orders/status?ids=1,0",
+			respBody: `{"message":"[ids] query parameter is invalid","error":"id [0] is invalid"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, tt.path, nil)
+			rr := httptest.NewRecorder()
+			e.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Equal(t, tt.respBody, rr.Body.String())
+		})
+	}
+}
+
+func TestOrderController_GetOrderQRCode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	orderUseCase := mock_usecases.NewMockOrderUsecase(ctrl)
+	orderController := NewOrderController(orderUseCase, dto.PageLimits{})
+
+	gin.SetMode(gin.TestMode)
+	c, e := gin.CreateTestContext(httptest.NewRecorder())
+	e.GET("/v1/orders/:id/qrcode.png", orderController.GetOrderQRCode)
+
+	png := []byte("\x89PNG\r\n\x1a\n")
+
+	type args struct {
+		id string
+	}
+	type want struct {
+		statusCode  int
+		contentType string
+		respBody    string
+	}
+	type orderUseCaseCall struct {
+		orderId int
+		times   int
+		png     []byte
+		err     error
+	}
+	tests := []struct {
+		name string
+		args
+		want
+		orderUseCaseCall
+	}{
+		{
+			name: "should return bad request when id is not a number",
+			args: args{
+				id: "abc",
+			},
+			want: want{
+				statusCode:  400,
+				contentType: "application/json; charset=utf-8",
+				respBody:    `{"message":"[id] path parameter is invalid","error":"strconv.Atoi: parsing \"abc\": invalid syntax"}`,
+			},
+		},
+		{
+			name: "should return the qrcode as a png image",
+			args: args{
+				id: "123",
+			},
+			want: want{
+				statusCode:  200,
+				contentType: "image/png",
+				respBody:    string(png),
+			},
+			orderUseCaseCall: orderUseCaseCall{
+				orderId: 123,
+				times:   1,
+				png:     png,
+			},
+		},
+		{
+			name: "should return not found when the order does not exist",
+			args: args{
+				id: "123",
+			},
+			want: want{
+				statusCode:  404,
+				contentType: "application/json; charset=utf-8",
+				respBody:    `{"message":"order qrcode not found","error":"entity not found"}`,
+			},
+			orderUseCaseCall: orderUseCaseCall{
+				orderId: 123,
+				times:   1,
+				err:     sql.ErrNotFound,
+			},
+		},
+		{
+			name: "should return not found when the order has no qrcode",
+			args: args{
+				id: "123",
+			},
+			want: want{
+				statusCode:  404,
+				contentType: "application/json; charset=utf-8",
+				respBody:    `{"message":"order qrcode not found","error":"order has no payment qrcode"}`,
+			},
+			orderUseCaseCall: orderUseCaseCall{
+				orderId: 123,
+				times:   1,
+				err:     dto.ErrOrderQRCodeNotFound,
+			},
+		},
+		{
+			name: "should return conflict when the order is already paid",
+			args: args{
+				id: "123",
+			},
+			want: want{
+				statusCode:  409,
+				contentType: "application/json; charset=utf-8",
+				respBody:    `{"message":"order qrcode is no longer available","error":"order payment is not pending"}`,
+			},
+			orderUseCaseCall: orderUseCaseCall{
+				orderId: 123,
+				times:   1,
+				err:     dto.ErrOrderPaymentNotPending,
+			},
+		},
+		{
+			name: "should return internal server error when the use case fails",
+			args: args{
+				id: "123",
+			},
+			want: want{
+				statusCode:  500,
+				contentType: "application/json; charset=utf-8",
+				respBody:    `{"message":"failed to get order qrcode","error":"internal server error"}`,
+			},
+			orderUseCaseCall: orderUseCaseCall{
+				orderId: 123,
+				times:   1,
+				err:     errors.New("internal server error"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		orderUseCase.
+			EXPECT().
+			GetOrderQRCodePNG(gomock.Eq(tt.orderUseCaseCall.orderId)).
+			Times(tt.orderUseCaseCall.times).
+			Return(tt.orderUseCaseCall.png, tt.orderUseCaseCall.err)
+
+		c.Request, _ = http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/orders/%s/qrcode.png", tt.args.id), nil)
+		rr := httptest.NewRecorder()
+		e.ServeHTTP(rr, c.Request)
+
+		assert.Equal(t, tt.want.statusCode, rr.Code)
+		assert.Equal(t, tt.want.contentType, rr.Header().Get("Content-Type"))
+		assert.Equal(t, tt.want.respBody, rr.Body.String())
+	}
+}
+
+func TestOrderController_GetOrderPaymentQRCode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	orderUseCase := mock_usecases.NewMockOrderUsecase(ctrl)
+	orderController := NewOrderController(orderUseCase, dto.PageLimits{})
+
+	gin.SetMode(gin.TestMode)
+	c, e := gin.CreateTestContext(httptest.NewRecorder())
+	e.GET("/v1/orders/:id/payment/qr", orderController.GetOrderPaymentQRCode)
+
+	expiresAt := time.Date(2024, 1, 1, 12, 15, 0, 0, time.UTC)
+
+	type want struct {
+		statusCode int
+		respBody   string
+	}
+	type orderUseCaseCall struct {
+		times  int
+		qrCode dto.PaymentQRCode
+		err    error
+	}
+	tests := []struct {
+		name string
+		id   string
+		want
+		orderUseCaseCall
+	}{
+		{
+			name: "should return the qrcode with its expiration for an order waiting for payment",
+			id:   "123",
+			want: want{
+				statusCode: 200,
+				respBody:   `{"qrcode":"mercadopago123456","expiresAt":"2024-01-01T12:15:00Z"}`,
+			},
+			orderUseCaseCall: orderUseCaseCall{
+				times:  1,
+				qrCode: dto.PaymentQRCode{QRCode: "mercadopago123456", ExpiresAt: &expiresAt},
+			},
+		},
+		{
+			name: "should return conflict when the order is already paid",
+			id:   "123",
+			want: want{
+				statusCode: 409,
+				respBody:   `{"message":"order qrcode is no longer available","error":"order payment is not pending"}`,
+			},
+			orderUseCaseCall: orderUseCaseCall{
+				times: 1,
+				err:   dto.ErrOrderPaymentNotPending,
+			},
+		},
+		{
+			name: "should return not found when the order does not exist",
+			id:   "123",
+			want: want{
+				statusCode: 404,
+				respBody:   `{"message":"order not found","error":"entity not found"}`,
+			},
+			orderUseCaseCall: orderUseCaseCall{
+				times: 1,
+				err:   sql.ErrNotFound,
+			},
+		},
+		{
+			name: "should return bad request when id is not a number",
+			id:   "abc",
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"[id] path parameter is invalid","error":"strconv.Atoi: parsing \"abc\": invalid syntax"}`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orderUseCase.
+				EXPECT().
+				GetOrderPaymentQRCode(gomock.Eq(123)).
+				Times(tt.orderUseCaseCall.times).
+				Return(tt.orderUseCaseCall.qrCode, tt.orderUseCaseCall.err)
+
+			c.Request, _ = http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/orders/%s/payment/qr", tt.id), nil)
+			rr := httptest.NewRecorder()
+			e.ServeHTTP(rr, c.Request)
+
+			assert.Equal(t, tt.want.statusCode, rr.Code)
+			assert.Equal(t, tt.want.respBody, rr.Body.String())
+		})
+	}
+}
+
+func TestOrderController_AddOrderItem(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	orderUseCase := mock_usecases.NewMockOrderUsecase(ctrl)
+	orderController := NewOrderController(orderUseCase, dto.PageLimits{})
+
+	gin.SetMode(gin.TestMode)
+	c, e := gin.CreateTestContext(httptest.NewRecorder())
+	e.POST("/v1/orders/:id/items", orderController.AddOrderItem)
+
+	type args struct {
+		id      string
+		reqBody string
+	}
+	type want struct {
+		statusCode int
+		respBody   string
+	}
+	type orderUseCaseCall struct {
+		orderId  int
+		item     dto.OrderItemDTO
+		times    int
+		response dto.OrderItemsUpdateResponse
+		err      error
+	}
+	tests := []struct {
+		name string
+		args
+		want
+		orderUseCaseCall
+	}{
+		{
+			name: "should return bad request when the item is invalid",
+			args: args{id: "7", reqBody: `{"productId":2,"quantity":1,"type":"BOX"}`},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"invalid order item payload","error":"type: BOX não é válido para in(UNIT|COMBO|CUSTOM_COMBO)"}`,
+			},
+		},
+		{
+			name: "should add the order item succesfully",
+			args: args{id: "7", reqBody: `{"productId":2,"quantity":1,"type":"UNIT"}`},
+			want: want{
+				statusCode: 200,
+				respBody:   `{"orderId":7,"items":[{"id":3,"product":{"id":2,"name":"Refrigerante","skuId":"","description":"","category":"","price":5,"createdAt":null,"updatedAt":null},"quantity":1,"type":"UNIT"}],"subtotalAmount":5,"taxAmount":0,"totalAmount":5,"qrCode":"mercadopago654321"}`,
+			},
+			orderUseCaseCall: orderUseCaseCall{
+				orderId: 7,
+				item:    dto.OrderItemDTO{ProductId: 2, Quantity: 1, Type: dto.OrderItemTypeUnit},
+				times:   1,
+				response: dto.OrderItemsUpdateResponse{
+					OrderID:        7,
+					Items:          []entities.OrderItem{{ID: 3, Product: entities.Product{ID: 2, Name: "Refrigerante", Price: 5}, Quantity: 1, Type: "UNIT"}},
+					SubtotalAmount: 5,
+					TotalAmount:    5,
+					QRCode:         "mercadopago654321",
+				},
+			},
+		},
+		{
+			name: "should return conflict when the order is no longer editable",
+			args: args{id: "7", reqBody: `{"productId":2,"quantity":1,"type":"UNIT"}`},
+			want: want{
+				statusCode: 409,
+				respBody:   `{"message":"order can no longer be changed","error":"order is no longer editable"}`,
+			},
+			orderUseCaseCall: orderUseCaseCall{
+				orderId: 7,
+				item:    dto.OrderItemDTO{ProductId: 2, Quantity: 1, Type: dto.OrderItemTypeUnit},
+				times:   1,
+				err:     dto.ErrOrderNotEditable,
+			},
+		},
+		{
+			name: "should return bad request when the product does not exist",
+			args: args{id: "7", reqBody: `{"productId":2,"quantity":1,"type":"UNIT"}`},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"invalid order item payload","error":"produto [2] não encontrado"}`,
+			},
+			orderUseCaseCall: orderUseCaseCall{
+				orderId: 7,
+				item:    dto.OrderItemDTO{ProductId: 2, Quantity: 1, Type: dto.OrderItemTypeUnit},
+				times:   1,
+				err:     dto.OrderItemProductNotFoundError{ProductID: 2},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		orderUseCase.
+			EXPECT().
+			AddOrderItem(tt.orderUseCaseCall.orderId, tt.orderUseCaseCall.item).
+			Times(tt.orderUseCaseCall.times).
+			Return(tt.orderUseCaseCall.response, tt.orderUseCaseCall.err)
+
+		c.Request, _ = http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/orders/%s/items", tt.args.id), strings.NewReader(tt.args.reqBody))
+		c.Request.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		e.ServeHTTP(rr, c.Request)
+
+		assert.Equal(t, tt.want.statusCode, rr.Code)
+		assert.Equal(t, tt.want.respBody, rr.Body.String())
+	}
+}
+
+func TestOrderController_RemoveOrderItem(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	orderUseCase := mock_usecases.NewMockOrderUsecase(ctrl)
+	orderController := NewOrderController(orderUseCase, dto.PageLimits{})
+
+	gin.SetMode(gin.TestMode)
+	c, e := gin.CreateTestContext(httptest.NewRecorder())
+	e.DELETE("/v1/orders/:id/items/:itemId", orderController.RemoveOrderItem)
+
+	type args struct {
+		id     string
+		itemId string
+	}
+	type want struct {
+		statusCode int
+		respBody   string
+	}
+	type orderUseCaseCall struct {
+		orderId  int
+		itemId   int
+		times    int
+		response dto.OrderItemsUpdateResponse
+		err      error
+	}
+	tests := []struct {
+		name string
+		args
+		want
+		orderUseCaseCall
+	}{
+		{
+			name: "should return bad request when item id is not a number",
+			args: args{id: "7", itemId: "abc"},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"[itemId] path parameter is invalid","error":"strconv.Atoi: parsing \"abc\": invalid syntax"}`,
+			},
+		},
+		{
+			name: "should remove the order item succesfully",
+			args: args{id: "7", itemId: "2"},
+			want: want{
+				statusCode: 200,
+				respBody:   `{"orderId":7,"items":[{"id":1,"product":{"id":1,"name":"X-Burguer","skuId":"","description":"","category":"","price":10,"createdAt":null,"updatedAt":null},"quantity":2,"type":"UNIT"}],"subtotalAmount":20,"taxAmount":0,"totalAmount":20,"qrCode":"mercadopago654321"}`,
+			},
+			orderUseCaseCall: orderUseCaseCall{
+				orderId: 7,
+				itemId:  2,
+				times:   1,
+				response: dto.OrderItemsUpdateResponse{
+					OrderID:        7,
+					Items:          []entities.OrderItem{{ID: 1, Product: entities.Product{ID: 1, Name: "X-Burguer", Price: 10}, Quantity: 2, Type: "UNIT"}},
+					SubtotalAmount: 20,
+					TotalAmount:    20,
+					QRCode:         "mercadopago654321",
+				},
+			},
+		},
+		{
+			name: "should return conflict when the order is no longer editable",
+			args: args{id: "7", itemId: "2"},
+			want: want{
+				statusCode: 409,
+				respBody:   `{"message":"order can no longer be changed","error":"order is no longer editable"}`,
+			},
+			orderUseCaseCall: orderUseCaseCall{orderId: 7, itemId: 2, times: 1, err: dto.ErrOrderNotEditable},
+		},
+		{
+			name: "should return bad request when removing the last item",
+			args: args{id: "7", itemId: "1"},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"order must keep at least one item","error":"order must keep at least one item"}`,
+			},
+			orderUseCaseCall: orderUseCaseCall{orderId: 7, itemId: 1, times: 1, err: dto.ErrOrderLastItem},
+		},
+		{
+			name: "should return not found when the item is not part of the order",
+			args: args{id: "7", itemId: "9"},
+			want: want{
+				statusCode: 404,
+				respBody:   `{"message":"order item not found","error":"order item not found"}`,
+			},
+			orderUseCaseCall: orderUseCaseCall{orderId: 7, itemId: 9, times: 1, err: dto.ErrOrderItemNotFound},
+		},
+	}
+
+	for _, tt := range tests {
+		orderUseCase.
+			EXPECT().
+			RemoveOrderItem(tt.orderUseCaseCall.orderId, tt.orderUseCaseCall.itemId).
+			Times(tt.orderUseCaseCall.times).
+			Return(tt.orderUseCaseCall.response, tt.orderUseCaseCall.err)
+
+		c.Request, _ = http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/orders/%s/items/%s", tt.args.id, tt.args.itemId), nil)
+		rr := httptest.NewRecorder()
+		e.ServeHTTP(rr, c.Request)
+
+		assert.Equal(t, tt.want.statusCode, rr.Code)
+		assert.Equal(t, tt.want.respBody, rr.Body.String())
+	}
+}
+
+func TestOrderController_RecalculateOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	orderUseCase := mock_usecases.NewMockOrderUsecase(ctrl)
+	orderController := NewOrderController(orderUseCase, dto.PageLimits{})
+
+	gin.SetMode(gin.TestMode)
+	c, e := gin.CreateTestContext(httptest.NewRecorder())
+	e.POST("/v1/orders/:id/recalculate", orderController.RecalculateOrder)
+
+	type want struct {
+		statusCode int
+		respBody   string
+	}
+	type orderUseCaseCall struct {
+		orderId  int
+		times    int
+		response dto.OrderItemsUpdateResponse
+		err      error
+	}
+	tests := []struct {
+		name string
+		id   string
+		want
+		orderUseCaseCall
+	}{
+		{
+			name: "should return bad request when id is not a number",
+			id:   "abc",
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"[id] path parameter is invalid","error":"strconv.Atoi: parsing \"abc\": invalid syntax"}`,
+			},
+		},
+		{
+			name: "should answer the recalculated totals of the order",
+			id:   "7",
+			want: want{
+				statusCode: 200,
+				respBody:   `{"orderId":7,"items":[{"id":1,"product":{"id":1,"name":"X-Burguer","skuId":"","description":"","category":"","price":10,"createdAt":null,"updatedAt":null},"quantity":2,"type":"UNIT"}],"subtotalAmount":20,"taxAmount":0,"totalAmount":20,"qrCode":"mercadopago654321"}`,
+			},
+			orderUseCaseCall: orderUseCaseCall{
+				orderId: 7,
+				times:   1,
+				response: dto.OrderItemsUpdateResponse{
+					OrderID:        7,
+					Items:          []entities.OrderItem{{ID: 1, Product: entities.Product{ID: 1, Name: "X-Burguer", Price: 10}, Quantity: 2, Type: "UNIT"}},
+					SubtotalAmount: 20,
+					TotalAmount:    20,
+					QRCode:         "mercadopago654321",
+				},
+			},
+		},
+		{
+			name: "should return conflict when the order is no longer editable",
+			id:   "7",
+			want: want{
+				statusCode: 409,
+				respBody:   `{"message":"order can no longer be changed","error":"order is no longer editable"}`,
+			},
+			orderUseCaseCall: orderUseCaseCall{orderId: 7, times: 1, err: dto.ErrOrderNotEditable},
+		},
+		{
+			name: "should return not found when the order does not exist",
+			id:   "8",
+			want: want{
+				statusCode: 404,
+				respBody:   `{"message":"order not found","error":"entity not found"}`,
+			},
+			orderUseCaseCall: orderUseCaseCall{orderId: 8, times: 1, err: sql.ErrNotFound},
+		},
+	}
+
+	for _, tt := range tests {
+		orderUseCase.
+			EXPECT().
+			RecalculateOrder(tt.orderUseCaseCall.orderId).
+			Times(tt.orderUseCaseCall.times).
+			Return(tt.orderUseCaseCall.response, tt.orderUseCaseCall.err)
+
+		c.Request, _ = http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/orders/%s/recalculate", tt.id), nil)
+		rr := httptest.NewRecorder()
+		e.ServeHTTP(rr, c.Request)
+
+		assert.Equal(t, tt.want.statusCode, rr.Code)
+		assert.Equal(t, tt.want.respBody, rr.Body.String())
+	}
+}
+
+func TestOrderController_SendOrderFeedback(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	orderUseCase := mock_usecases.NewMockOrderUsecase(ctrl)
+	orderController := NewOrderController(orderUseCase, dto.PageLimits{})
+
+	gin.SetMode(gin.TestMode)
+	c, e := gin.CreateTestContext(httptest.NewRecorder())
+	e.POST("/v1/orders/:id/feedback", orderController.SendOrderFeedback)
+
+	type args struct {
+		id      string
+		reqBody string
+	}
+	type want struct {
+		statusCode int
+		respBody   string
+	}
+	type orderUseCaseCall struct {
+		orderId  int
+		feedback dto.OrderFeedbackDTO
+		times    int
+		response entities.OrderFeedback
+		err      error
+	}
+	tests := []struct {
+		name string
+		args
+		want
+		orderUseCaseCall
+	}{
+		{
+			name: "should save a valid rating",
+			args: args{id: "7", reqBody: `{"rating":5,"comment":"Lanche muito bom"}`},
+			want: want{
+				statusCode: 201,
+				respBody:   `{"id":1,"orderId":7,"rating":5,"comment":"Lanche muito bom","createdAt":null}`,
+			},
+			orderUseCaseCall: orderUseCaseCall{
+				orderId:  7,
+				feedback: dto.OrderFeedbackDTO{Rating: 5, Comment: "Lanche muito bom"},
+				times:    1,
+				response: entities.OrderFeedback{ID: 1, OrderID: 7, Rating: 5, Comment: "Lanche muito bom"},
+			},
+		},
+		{
+			name: "should return bad request when the rating is out of range",
+			args: args{id: "7", reqBody: `{"rating":6}`},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"invalid order feedback payload","error":"nota deve estar entre 1 e 5"}`,
+			},
+		},
+		{
+			name: "should return conflict when the order is not done",
+			args: args{id: "7", reqBody: `{"rating":4}`},
+			want: want{
+				statusCode: 409,
+				respBody:   `{"message":"order feedback is not allowed","error":"order is not done yet"}`,
+			},
+			orderUseCaseCall: orderUseCaseCall{
+				orderId:  7,
+				feedback: dto.OrderFeedbackDTO{Rating: 4},
+				times:    1,
+				err:      dto.ErrOrderNotDone,
+			},
+		},
+		{
+			name: "should return not found when the order does not exist",
+			args: args{id: "7", reqBody: `{"rating":4}`},
+			want: want{
+				statusCode: 404,
+				respBody:   `{"message":"order not found","error":"entity not found"}`,
+			},
+			orderUseCaseCall: orderUseCaseCall{
+				orderId:  7,
+				feedback: dto.OrderFeedbackDTO{Rating: 4},
+				times:    1,
+				err:      sql.ErrNotFound,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		orderUseCase.
+			EXPECT().
+			SendOrderFeedback(tt.orderUseCaseCall.orderId, tt.orderUseCaseCall.feedback).
+			Times(tt.orderUseCaseCall.times).
+			Return(tt.orderUseCaseCall.response, tt.orderUseCaseCall.err)
+
+		c.Request, _ = http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/orders/%s/feedback", tt.args.id), strings.NewReader(tt.args.reqBody))
+		c.Request.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		e.ServeHTTP(rr, c.Request)
+
+		assert.Equal(t, tt.want.statusCode, rr.Code)
+		assert.Equal(t, tt.want.respBody, rr.Body.String())
+	}
+}
+
+func TestOrderController_UpdateOrderItemStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	orderUseCase := mock_usecases.NewMockOrderUsecase(ctrl)
+	orderController := NewOrderController(orderUseCase, dto.PageLimits{})
+
+	gin.SetMode(gin.TestMode)
+	c, e := gin.CreateTestContext(httptest.NewRecorder())
+	e.PUT("/v1/orders/:id/items/:itemId/status", orderController.UpdateOrderItemStatus)
+
+	tests := []struct {
+		name         string
+		body         string
+		useCaseCalls int
+		response     dto.OrderItemStatusResponseDTO
+		err          error
+		wantStatus   int
+		wantRespBody string
+	}{
+		{
+			name:         "should keep the order in progress when one item is ready",
+			body:         `{"status":"READY"}`,
+			useCaseCalls: 1,
+			response:     dto.OrderItemStatusResponseDTO{OrderID: 1, ItemID: 10, ItemStatus: dto.OrderItemStatusReady, OrderStatus: dto.OrderStatusInProgress},
+			wantStatus:   200,
+			wantRespBody: `{"orderId":1,"itemId":10,"itemStatus":"READY","orderStatus":"IN_PROGRESS"}`,
+		},
+		{
+			name:         "should return the order ready when all items are ready",
+			body:         `{"status":"READY"}`,
+			useCaseCalls: 1,
+			response:     dto.OrderItemStatusResponseDTO{OrderID: 1, ItemID: 10, ItemStatus: dto.OrderItemStatusReady, OrderStatus: dto.OrderStatusReady},
+			wantStatus:   200,
+			wantRespBody: `{"orderId":1,"itemId":10,"itemStatus":"READY","orderStatus":"READY"}`,
+		},
+		{
+			name:         "should return conflict when the order is not in progress",
+			body:         `{"status":"READY"}`,
+			useCaseCalls: 1,
+			err:          fmt.Errorf("%w, order is [RECEIVED]", dto.ErrOrderNotInProgress),
+			wantStatus:   409,
+			wantRespBody: `{"message":"order items can not be updated","error":"order is not in progress, order is [RECEIVED]"}`,
+		},
+		{
+			name:         "should return bad request when the item status is invalid",
+			body:         `{"status":"DONE"}`,
+			wantStatus:   400,
+			wantRespBody: `{"message":"invalid order item status payload","error":"status: DONE não é válido para in(PENDING|READY)"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orderUseCase.EXPECT().UpdateOrderItemStatus(1, 10, dto.OrderItemStatusReady).Return(tt.response, tt.err).Times(tt.useCaseCalls)
+
+			c.Request, _ = http.NewRequest(http.MethodPut, "/v1/orders/1/items/10/status", strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+			e.ServeHTTP(rr, c.Request)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			assert.Equal(t, tt.wantRespBody, rr.Body.String())
+		})
+	}
+}
+
+func TestOrderController_UpdateOrderStatus_ConcurrentTerminals(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	orderRepositoryGateway := mock_gateways.NewMockOrderRepositoryGateway(ctrl)
+	orderUsecase := usecases.NewOrderUsecase(nil, nil, nil, orderRepositoryGateway, nil, usecases.NewTaxCalculator(0, nil, 0), usecases.NewNoteRedactor(false, nil),
+		events.NewLogPublisher(), nil, nil, nil, nil, nil, 0, usecases.NewSystemClock())
+	orderController := NewOrderController(orderUsecase, dto.PageLimits{})
+
+	gin.SetMode(gin.TestMode)
+	_, e := gin.CreateTestContext(httptest.NewRecorder())
+	e.PUT("/v1/orders/:id/status", orderController.UpdateOrderStatus)
+
+	// emulates UPDATE ... WHERE status = $3, only the first terminal finds the order still IN_PROGRESS
+	var mu sync.Mutex
+	status := "IN_PROGRESS"
+	orderRepositoryGateway.EXPECT().
+		UpdateOrderStatus(1, gomock.Any(), "IN_PROGRESS").
+		DoAndReturn(func(orderId int, orderStatus string, expectedStatus string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if status != expectedStatus {
+				return sql.ErrNotFound
+			}
+			status = orderStatus
+			return nil
+		}).
+		Times(2)
+	orderRepositoryGateway.EXPECT().
+		GetOrderStatus(1).
+		DoAndReturn(func(orderId int) (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			return status, nil
+		}).
+		Times(1)
+
+	var wg sync.WaitGroup
+	statusCodes := make([]int, 2)
+	for i, orderStatus := range []string{"READY", "READY"} {
+		wg.Add(1)
+		go func(i int, orderStatus string) {
+			defer wg.Done()
+			body := fmt.Sprintf(`{"status":"%s","expectedStatus":"IN_PROGRESS"}`, orderStatus)
+			req, _ := http.NewRequest(http.MethodPut, "/v1/orders/1/status", strings.NewReader(body))
+			rr := httptest.NewRecorder()
+			e.ServeHTTP(rr, req)
+			statusCodes[i] = rr.Code
+		}(i, orderStatus)
+	}
+	wg.Wait()
+
+	assert.ElementsMatch(t, []int{http.StatusNoContent, http.StatusConflict}, statusCodes)
+}
+
+func TestOrderController_UpdateOrderStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	orderUseCase := mock_usecases.NewMockOrderUsecase(ctrl)
+	orderController := NewOrderController(orderUseCase, dto.PageLimits{})
+
+	gin.SetMode(gin.TestMode)
+	c, e := gin.CreateTestContext(httptest.NewRecorder())
+	e.PUT("/v1/orders/:id/status", orderController.UpdateOrderStatus)
+
+	type args struct {
+		id      string
+		reqBody string
+	}
+	type want struct {
+		statusCode int
+		respBody   string
+	}
+	type orderUseCaseCall struct {
+		orderId     int
+		orderStatus string
+		times       int
+		err         error
+	}
+	tests := []struct {
+		name string
+		args
+		want
+		orderUseCaseCall
+	}{
+		{
+			name: "should return bad request when id is missing",
+			args: args{
+				reqBody: `{"status":"CREATED"}`,
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"[id] path parameter is required","error":"id is missing"}`,
+			},
+		},
+		{
+			name: "should return bad request when id is not a number",
+			args: args{
+				id:      "abc",
+				reqBody: `{"status":"CREATED"}`,
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"[id] path parameter is invalid","error":"strconv.Atoi: parsing \"abc\": invalid syntax"}`,
+			},
+		},
+		{
+			name: "should return bad request when req body is not a json",
+			args: args{
+				id:      "123",
+				reqBody: "<invalidJson>",
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"failed to bind order status payload","error":"invalid character '\u003c' looking for beginning of value"}`,
+			},
+		},
+
+		{
+			name: "should return bad request when state is wrong in the request",
+			args: args{
+				id:      "123",
+				reqBody: `{"status":"WRONG_STATE"}`,
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"invalid order status payload","error":"status: WRONG_STATE não é válido para in(CREATED|PAID|RECEIVED|IN_PROGRESS|READY|DONE)"}`,
+			},
+		},
+		{
+			name: "should update order status sent in lowercase",
+			args: args{
+				id:      "123",
+				reqBody: `{"status":"paid"}`,
+			},
+			want: want{
+				statusCode: 204,
+				respBody:   "",
+			},
+			orderUseCaseCall: orderUseCaseCall{
+				orderId:     123,
+				orderStatus: "PAID",
+				times:       1,
+			},
+		},
+		{
+			name: "should update order status padded with whitespace",
+			args: args{
+				id:      "123",
+				reqBody: `{"status":" Paid\t"}`,
+			},
+			want: want{
+				statusCode: 204,
+				respBody:   "",
+			},
+			orderUseCaseCall: orderUseCaseCall{
+				orderId:     123,
+				orderStatus: "PAID",
+				times:       1,
+			},
+		},
+		{
+			name: "should return bad request when the normalized state is still wrong",
+			args: args{
+				id:      "123",
+				reqBody: `{"status":" payed "}`,
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"invalid order status payload","error":"status: PAYED não é válido para in(CREATED|PAID|RECEIVED|IN_PROGRESS|READY|DONE)"}`,
+			},
+		},
+		{
+			name: "should not create order when the user case returns error",
+			args: args{
+				id:      "123",
+				reqBody: `{"status":"CREATED"}`,
+			},
+			want: want{
+				statusCode: 500,
+				respBody:   `{"message":"failed to update order status","error":"internal server error"}`,
+			},
+			orderUseCaseCall: orderUseCaseCall{
+				orderId:     123,
+				orderStatus: "CREATED",
+				times:       1,
+				err:         errors.New("internal server error"),
+			},
+		},
+		{
+			name: "should update order status succesfully",
+			args: args{
+				id:      "123",
+				reqBody: string(orderRequestValid),
+			},
+			want: want{
+				statusCode: 204,
+				respBody:   "",
+			},
+			orderUseCaseCall: orderUseCaseCall{
+				orderId:     123,
+				orderStatus: "CREATED",
 				times:       1,
 				err:         nil,
 			},
@@ -463,7 +2277,7 @@ func TestOrderController_UpdateOrderStatus(t *testing.T) {
 	for _, tt := range tests {
 		orderUseCase.
 			EXPECT().
-			UpdateOrderStatus(gomock.Eq(tt.orderUseCaseCall.orderId), gomock.Eq(tt.orderUseCaseCall.orderStatus)).
+			UpdateOrderStatus(gomock.Eq(tt.orderUseCaseCall.orderId), gomock.Eq(tt.orderUseCaseCall.orderStatus), gomock.Eq("")).
 			Times(tt.orderUseCaseCall.times).
 			Return(tt.orderUseCaseCall.err)
 
@@ -477,6 +2291,186 @@ func TestOrderController_UpdateOrderStatus(t *testing.T) {
 	}
 }
 
+func TestOrderController_ReopenOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	orderUseCase := mock_usecases.NewMockOrderUsecase(ctrl)
+	orderController := NewOrderController(orderUseCase, dto.PageLimits{})
+
+	gin.SetMode(gin.TestMode)
+	c, e := gin.CreateTestContext(httptest.NewRecorder())
+	e.POST("/v1/orders/:id/reopen", orderController.ReopenOrder)
+
+	tests := []struct {
+		name         string
+		id           int
+		err          error
+		wantStatus   int
+		wantRespBody string
+	}{
+		{
+			name:       "should reopen the order",
+			id:         123,
+			wantStatus: 204,
+		},
+		{
+			name:         "should return conflict when the grace period has expired",
+			id:           124,
+			err:          fmt.Errorf("%w, the grace period of 10m0s since its completion has expired", dto.ErrOrderNotReopenable),
+			wantStatus:   409,
+			wantRespBody: `{"message":"order can not be reopened","error":"order can not be reopened, the grace period of 10m0s since its completion has expired"}`,
+		},
+		{
+			name:         "should return not found when the order does not exist",
+			id:           125,
+			err:          sql.ErrNotFound,
+			wantStatus:   404,
+			wantRespBody: `{"message":"order not found","error":"entity not found"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orderUseCase.EXPECT().ReopenOrder(tt.id).Return(tt.err).Times(1)
+
+			c.Request, _ = http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/orders/%d/reopen", tt.id), nil)
+			rr := httptest.NewRecorder()
+			e.ServeHTTP(rr, c.Request)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			assert.Equal(t, tt.wantRespBody, rr.Body.String())
+		})
+	}
+}
+
+func TestOrderController_GetOrderThroughput(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	orderUseCase := mock_usecases.NewMockOrderUsecase(ctrl)
+	orderController := NewOrderController(orderUseCase, dto.PageLimits{})
+
+	gin.SetMode(gin.TestMode)
+	c, e := gin.CreateTestContext(httptest.NewRecorder())
+	e.GET("/v1/orders/throughput", orderController.GetOrderThroughput)
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	// a day of history where the kitchen took 5 to 20 minutes per order, and the orders waited about 2 minutes for it
+	throughput := dto.OrderThroughputDTO{From: from, To: to, Transitions: []dto.StatusTransitionStatsDTO{
+		{From: dto.OrderStatusInProgress, To: dto.OrderStatusReady, Count: 40, AverageSeconds: 540, P50Seconds: 480, P90Seconds: 960, P95Seconds: 1140},
+		{From: dto.OrderStatusReceived, To: dto.OrderStatusInProgress, Count: 42, AverageSeconds: 125.5, P50Seconds: 110, P90Seconds: 230, P95Seconds: 290},
+	}}
+
+	tests := []struct {
+		name         string
+		query        string
+		usecaseCalls int
+		err          error
+		wantStatus   int
+		wantRespBody string
+	}{
+		{
+			name:         "should return the durations of each status transition in the range",
+			query:        "?from=2024-01-01T00:00:00Z&to=2024-01-02T00:00:00Z",
+			usecaseCalls: 1,
+			wantStatus:   200,
+			wantRespBody: `{"from":"2024-01-01T00:00:00Z","to":"2024-01-02T00:00:00Z","transitions":[` +
+				`{"from":"IN_PROGRESS","to":"READY","count":40,"averageSeconds":540,"p50Seconds":480,"p90Seconds":960,"p95Seconds":1140},` +
+				`{"from":"RECEIVED","to":"IN_PROGRESS","count":42,"averageSeconds":125.5,"p50Seconds":110,"p90Seconds":230,"p95Seconds":290}]}`,
+		},
+		{
+			name:         "should return bad request when the range is reversed",
+			query:        "?from=2024-01-02T00:00:00Z&to=2024-01-01T00:00:00Z",
+			wantStatus:   400,
+			wantRespBody: `{"message":"invalid query parameters","error":"from [2024-01-02T00:00:00Z] must be before to [2024-01-01T00:00:00Z]"}`,
+		},
+		{
+			name:         "should return internal server error when the throughput can not be read",
+			query:        "?from=2024-01-01T00:00:00Z&to=2024-01-02T00:00:00Z",
+			usecaseCalls: 1,
+			err:          errors.New("connection refused"),
+			wantStatus:   500,
+			wantRespBody: `{"message":"failed to get order throughput","error":"connection refused"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := throughput
+			if tt.err != nil {
+				response = dto.OrderThroughputDTO{}
+			}
+			orderUseCase.EXPECT().GetOrderThroughput(from, to).Return(response, tt.err).Times(tt.usecaseCalls)
+
+			c.Request, _ = http.NewRequest(http.MethodGet, "/v1/orders/throughput"+tt.query, nil)
+			rr := httptest.NewRecorder()
+			e.ServeHTTP(rr, c.Request)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			assert.Equal(t, tt.wantRespBody, rr.Body.String())
+		})
+	}
+}
+
+func TestOrderController_CancelUnpaidOrders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	orderUseCase := mock_usecases.NewMockOrderUsecase(ctrl)
+	orderController := NewOrderController(orderUseCase, dto.PageLimits{})
+
+	gin.SetMode(gin.TestMode)
+	c, e := gin.CreateTestContext(httptest.NewRecorder())
+	e.DELETE("/v1/orders/customer/:cpf/unpaid", orderController.CancelUnpaidOrders)
+
+	tests := []struct {
+		name         string
+		cpf          string
+		response     dto.UnpaidOrdersCancellationDTO
+		err          error
+		usecaseCalls int
+		wantStatus   int
+		wantRespBody string
+	}{
+		{
+			name:         "should answer how many unpaid orders were cancelled",
+			cpf:          "00551146010",
+			response:     dto.UnpaidOrdersCancellationDTO{CustomerCPF: "00551146010", Cancelled: 2},
+			usecaseCalls: 1,
+			wantStatus:   200,
+			wantRespBody: `{"customerCpf":"00551146010","cancelled":2}`,
+		},
+		{
+			name:         "should answer zero when the customer has no unpaid order",
+			cpf:          "00551146010",
+			response:     dto.UnpaidOrdersCancellationDTO{CustomerCPF: "00551146010", Cancelled: 0},
+			usecaseCalls: 1,
+			wantStatus:   200,
+			wantRespBody: `{"customerCpf":"00551146010","cancelled":0}`,
+		},
+		{
+			name:         "should return bad request when the cpf is invalid",
+			cpf:          "11122233344",
+			wantStatus:   400,
+			wantRespBody: `{"message":"[cpf] path parameter is invalid","error":"invalid CPF [11122233344], check digits do not match"}`,
+		},
+		{
+			name:         "should return internal server error when the orders can not be cancelled",
+			cpf:          "00551146010",
+			err:          errors.New("connection refused"),
+			usecaseCalls: 1,
+			wantStatus:   500,
+			wantRespBody: `{"message":"failed to cancel unpaid orders","error":"connection refused"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orderUseCase.EXPECT().CancelUnpaidOrders(tt.cpf).Return(tt.response, tt.err).Times(tt.usecaseCalls)
+
+			c.Request, _ = http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/orders/customer/%s/unpaid", tt.cpf), nil)
+			rr := httptest.NewRecorder()
+			e.ServeHTTP(rr, c.Request)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			assert.Equal(t, tt.wantRespBody, rr.Body.String())
+		})
+	}
+}
+
 func createOrder() entities.Order {
 	return entities.Order{
 		ID: 123,
@@ -492,15 +2486,100 @@ func createOrder() entities.Order {
 					Description: "Batata canoa",
 					Category:    "Acompanhamento",
 					Price:       9.99,
-					CreatedAt:   time.Time{},
-					UpdatedAt:   time.Time{},
+					CreatedAt:   entities.Timestamp{},
+					UpdatedAt:   entities.Timestamp{},
+				},
+			},
+		},
+		Coupon:          "APP10",
+		TotalAmount:     9.99,
+		Status:          "PAID",
+		FulfillmentType: "DINE_IN",
+		CreatedAt:       entities.Timestamp{},
+		CustomerCPF:     "111222333444",
+	}
+}
+
+func TestOrderController_BatchCreateOrders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	orderUseCase := mock_usecases.NewMockOrderUsecase(ctrl)
+	orderController := NewOrderController(orderUseCase, dto.PageLimits{})
+
+	gin.SetMode(gin.TestMode)
+	c, e := gin.CreateTestContext(httptest.NewRecorder())
+	e.POST("/v1/orders/batch", orderController.BatchCreateOrders)
+
+	type args struct {
+		reqBody string
+	}
+	type want struct {
+		statusCode int
+		respBody   string
+	}
+	type orderUseCaseCall struct {
+		orders  int
+		times   int
+		results []dto.BatchOrderResultDTO
+	}
+	tests := []struct {
+		name string
+		args
+		want
+		orderUseCaseCall
+	}{
+		{
+			name: "should return bad request when req body is not a json",
+			args: args{
+				reqBody: "<invalidJson>",
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"failed to bind batch orders payload","error":"invalid character '\u003c' looking for beginning of value"}`,
+			},
+		},
+		{
+			name: "should return bad request when orders are empty",
+			args: args{
+				reqBody: `{"orders":[]}`,
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"invalid batch orders payload","error":"orders must not be empty"}`,
+			},
+		},
+		{
+			name: "should return the result of each order of a mixed batch",
+			args: args{
+				reqBody: `{"orders":[{"items":[{"productId":1,"quantity":2,"type":"UNIT"}],"customerCpf":"00551146010","status":"CREATED"},{"items":[],"customerCpf":"00551146010"}]}`,
+			},
+			want: want{
+				statusCode: 200,
+				respBody:   `{"results":[{"index":0,"status":"CREATED","orderId":98765,"orderNumber":"042","qrCode":"mercadopago123456"},{"index":1,"status":"INVALID","error":"Status is invalid"}]}`,
+			},
+			orderUseCaseCall: orderUseCaseCall{
+				orders: 2,
+				times:  1,
+				results: []dto.BatchOrderResultDTO{
+					{Index: 0, Status: dto.BatchOrderStatusCreated, OrderID: 98765, Number: "042", QRCode: "mercadopago123456"},
+					{Index: 1, Status: dto.BatchOrderStatusInvalid, Error: "Status is invalid"},
 				},
 			},
 		},
-		Coupon:      "APP10",
-		TotalAmount: 9.99,
-		Status:      "PAID",
-		CreatedAt:   time.Time{},
-		CustomerCPF: "111222333444",
+	}
+
+	for _, tt := range tests {
+		orderUseCase.
+			EXPECT().
+			BatchCreateOrders(gomock.Len(tt.orderUseCaseCall.orders)).
+			Times(tt.orderUseCaseCall.times).
+			Return(tt.orderUseCaseCall.results)
+
+		c.Request, _ = http.NewRequest(http.MethodPost, "/v1/orders/batch", strings.NewReader(tt.args.reqBody))
+		c.Request.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		e.ServeHTTP(rr, c.Request)
+
+		assert.Equal(t, tt.want.statusCode, rr.Code)
+		assert.Equal(t, tt.want.respBody, rr.Body.String())
 	}
 }
@@ -0,0 +1,23 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/g73-techchallenge-order/internal/core/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PaymentController struct {
+	paymentUsecase usecases.PaymentUsecase
+}
+
+func NewPaymentController(paymentUsecase usecases.PaymentUsecase) PaymentController {
+	return PaymentController{
+		paymentUsecase: paymentUsecase,
+	}
+}
+
+func (c PaymentController) GetPaymentMethods(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, c.paymentUsecase.GetPaymentMethods())
+}
@@ -0,0 +1,36 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/g73-techchallenge-order/internal/core/usecases/dto"
+	mock_usecases "github.com/g73-techchallenge-order/internal/core/usecases/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestPaymentController_GetPaymentMethods(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	paymentUseCase := mock_usecases.NewMockPaymentUsecase(ctrl)
+	paymentController := NewPaymentController(paymentUseCase)
+
+	gin.SetMode(gin.TestMode)
+	c, e := gin.CreateTestContext(httptest.NewRecorder())
+	e.GET("/v1/payments/methods", paymentController.GetPaymentMethods)
+
+	paymentUseCase.
+		EXPECT().
+		GetPaymentMethods().
+		Times(1).
+		Return(dto.PaymentMethodsDTO{Methods: []dto.PaymentMethod{dto.PaymentMethodMercadoPagoQRCode}})
+
+	c.Request, _ = http.NewRequest(http.MethodGet, "/v1/payments/methods", nil)
+	rr := httptest.NewRecorder()
+	e.ServeHTTP(rr, c.Request)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `{"methods":["MERCADO_PAGO_QRCODE"]}`, rr.Body.String())
+}
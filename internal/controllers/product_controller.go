@@ -3,6 +3,8 @@ package controllers
 import (
 	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/g73-techchallenge-order/internal/core/usecases"
 	"github.com/g73-techchallenge-order/internal/core/usecases/dto"
@@ -13,33 +15,40 @@ import (
 
 type ProductController struct {
 	productUsecase usecases.ProductUsecase
+	pageLimits     dto.PageLimits
 }
 
-func NewProductController(productUsecase usecases.ProductUsecase) ProductController {
+func NewProductController(productUsecase usecases.ProductUsecase, pageLimits dto.PageLimits) ProductController {
 	return ProductController{
 		productUsecase: productUsecase,
+		pageLimits:     pageLimits,
 	}
 }
 
 func (c ProductController) GetProducts(ctx *gin.Context) {
 	category := ctx.Query("category")
-	pageParams, err := getPageParams(ctx)
+	pageParams, fieldErrors := getPageParams(ctx, c.pageLimits)
+	expandVariants, err := hasExpandQueryParam(ctx, "variants")
 	if err != nil {
-		handleBadRequestResponse(ctx, "invalid query parameters", err)
+		fieldErrors = append(fieldErrors, dto.FieldError{Field: "expand", Err: err})
+	}
+
+	if len(fieldErrors) > 0 {
+		handleQueryParamErrorsResponse(ctx, fieldErrors)
 		return
 	}
 
 	if category != "" {
-		c.getProductsByCategory(ctx, pageParams, category)
+		c.getProductsByCategory(ctx, pageParams, category, expandVariants)
 		return
 	}
 
-	c.getAllProducts(ctx, pageParams)
+	c.getAllProducts(ctx, pageParams, expandVariants)
 }
 
 func (c ProductController) CreateProducts(ctx *gin.Context) {
 	var product dto.ProductDTO
-	err := ctx.ShouldBindJSON(&product)
+	err := bindJSON(ctx, &product)
 	if err != nil {
 		handleBadRequestResponse(ctx, "failed to bind product payload", err)
 		return
@@ -47,19 +56,51 @@ func (c ProductController) CreateProducts(ctx *gin.Context) {
 
 	valid, err := product.ValidateProduct()
 	if !valid {
-		handleBadRequestResponse(ctx, "invalid product payload", err)
+		handleBadRequestResponse(ctx, "invalid product payload", dto.LocalizeValidationError(err, getLocale(ctx)))
 		return
 	}
 
-	err = c.productUsecase.CreateProduct(product)
+	err = c.productUsecase.CreateProduct(product, getAuthSubject(ctx))
 	if err != nil {
-		handleInternalServerResponse(ctx, "failed to create product", err)
+		respondError(ctx, "product", "failed to create product", err)
 		return
 	}
 
 	ctx.Status(http.StatusOK)
 }
 
+// DuplicateProduct copies the product, the sku of the copy is optional on the payload.
+func (c ProductController) DuplicateProduct(ctx *gin.Context) {
+	id, err := parseIdParam(ctx.Param("id"))
+	if err != nil {
+		handleBadRequestResponse(ctx, "id path param is invalid", err)
+		return
+	}
+
+	var duplicate dto.ProductDuplicateDTO
+	if ctx.Request.ContentLength != 0 {
+		err = bindJSON(ctx, &duplicate)
+		if err != nil {
+			handleBadRequestResponse(ctx, "failed to bind product duplicate payload", err)
+			return
+		}
+	}
+
+	valid, err := duplicate.Validate()
+	if !valid {
+		handleBadRequestResponse(ctx, "invalid product duplicate payload", dto.LocalizeValidationError(err, getLocale(ctx)))
+		return
+	}
+
+	product, err := c.productUsecase.DuplicateProduct(id, duplicate, getAuthSubject(ctx))
+	if err != nil {
+		respondError(ctx, "product", "failed to duplicate product", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, product)
+}
+
 func (c ProductController) UpdateProduct(ctx *gin.Context) {
 	id := ctx.Param("id")
 	if id == "" {
@@ -67,26 +108,32 @@ func (c ProductController) UpdateProduct(ctx *gin.Context) {
 		return
 	}
 
+	if _, err := parseIdParam(id); err != nil {
+		handleBadRequestResponse(ctx, "id path param is invalid", err)
+		return
+	}
+
 	var product dto.ProductDTO
-	err := ctx.ShouldBindJSON(&product)
+	err := bindJSON(ctx, &product)
 	if err != nil {
 		handleBadRequestResponse(ctx, "failed to bind product payload", err)
 		return
 	}
 
+	if product.IsEmpty() {
+		handleBadRequestResponse(ctx, "empty product payload", dto.LocalizeValidationError(dto.ErrEmptyProductPayload, getLocale(ctx)))
+		return
+	}
+
 	valid, err := product.ValidateProduct()
 	if !valid {
-		handleBadRequestResponse(ctx, "invalid product payload", err)
+		handleBadRequestResponse(ctx, "invalid product payload", dto.LocalizeValidationError(err, getLocale(ctx)))
 		return
 	}
 
-	err = c.productUsecase.UpdateProduct(id, product)
+	err = c.productUsecase.UpdateProduct(id, product, getAuthSubject(ctx))
 	if err != nil {
-		if errors.Is(err, sql.ErrNotFound) {
-			handleNotFoundResponse(ctx, "product not found", err)
-			return
-		}
-		handleInternalServerResponse(ctx, "failed to update product", err)
+		respondError(ctx, "product", "failed to update product", err)
 		return
 	}
 
@@ -100,33 +147,286 @@ func (c ProductController) DeleteProduct(ctx *gin.Context) {
 		return
 	}
 
-	err := c.productUsecase.DeleteProduct(id)
+	if _, err := parseIdParam(id); err != nil {
+		handleBadRequestResponse(ctx, "id path param is invalid", err)
+		return
+	}
+
+	// idempotent clients only care that the product is gone, whether or not this call removed it
+	idempotent, err := getBoolQueryParam(ctx, "idempotent")
 	if err != nil {
-		if errors.Is(err, sql.ErrNotFound) {
-			handleNotFoundResponse(ctx, "product not found", err)
-			return
-		}
-		handleInternalServerResponse(ctx, "failed to delete product", err)
+		handleBadRequestResponse(ctx, "[idempotent] query parameter is invalid", err)
+		return
+	}
+
+	err = c.productUsecase.DeleteProduct(id)
+	if err != nil && !(idempotent && errors.Is(err, sql.ErrNotFound)) {
+		respondError(ctx, "product", "failed to delete product", err)
 		return
 	}
 
 	ctx.Status(http.StatusNoContent)
 }
 
-func (c ProductController) getAllProducts(ctx *gin.Context, pageParameters dto.PageParams) {
-	products, err := c.productUsecase.GetAllProducts(pageParameters)
+func (c ProductController) BulkDeleteProducts(ctx *gin.Context) {
+	var bulkDelete dto.BulkDeleteProductsDTO
+	err := bindJSON(ctx, &bulkDelete)
+	if err != nil {
+		handleBadRequestResponse(ctx, "failed to bind bulk delete payload", err)
+		return
+	}
+
+	valid, err := bulkDelete.Validate()
+	if !valid {
+		handleBadRequestResponse(ctx, "invalid bulk delete payload", err)
+		return
+	}
+
+	results := c.productUsecase.BulkDeleteProducts(bulkDelete.IDs)
+	ctx.JSON(http.StatusOK, dto.BulkDeleteResponseDTO{Results: results})
+}
+
+func (c ProductController) SetCategoryAvailability(ctx *gin.Context) {
+	var availability dto.CategoryAvailabilityDTO
+	err := bindJSON(ctx, &availability)
+	if err != nil {
+		handleBadRequestResponse(ctx, "failed to bind category availability payload", err)
+		return
+	}
+
+	valid, err := availability.Validate()
+	if !valid {
+		handleBadRequestResponse(ctx, "invalid category availability payload", err)
+		return
+	}
+
+	response, err := c.productUsecase.SetCategoryAvailability(availability)
+	if err != nil {
+		respondError(ctx, "product", "failed to set category availability", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (c ProductController) getAllProducts(ctx *gin.Context, pageParameters dto.PageParams, expandVariants bool) {
+	products, err := c.productUsecase.GetAllProducts(pageParameters, expandVariants)
 	if err != nil {
-		handleInternalServerResponse(ctx, "failed to get all products", err)
+		respondError(ctx, "product", "failed to get all products", err)
 		return
 	}
 	ctx.JSON(http.StatusOK, products)
 }
 
-func (c ProductController) getProductsByCategory(ctx *gin.Context, pageParameters dto.PageParams, category string) {
-	products, err := c.productUsecase.GetProductsByCategory(pageParameters, category)
+func (c ProductController) getProductsByCategory(ctx *gin.Context, pageParameters dto.PageParams, category string, expandVariants bool) {
+	products, err := c.productUsecase.GetProductsByCategory(pageParameters, category, expandVariants)
+	if err != nil {
+		respondError(ctx, "product", "failed to get products by category", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, products)
+}
+
+func (c ProductController) GetProductPopularity(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		handleBadRequestResponse(ctx, "id path param is required", errors.New("id path parameter is missing"))
+		return
+	}
+
+	productId, err := parseIdParam(id)
+	if err != nil {
+		handleBadRequestResponse(ctx, "id path param is invalid", err)
+		return
+	}
+
+	from, to, err := getTimeRangeParams(ctx)
+	if err != nil {
+		handleBadRequestResponse(ctx, "invalid query parameters", err)
+		return
+	}
+
+	popularity, err := c.productUsecase.GetProductPopularity(productId, from, to)
+	if err != nil {
+		respondError(ctx, "product", "failed to get product popularity", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, popularity)
+}
+
+// GetProductsAudit lists the products whose latest change was made by the actor within the from and to range.
+func (c ProductController) GetProductsAudit(ctx *gin.Context) {
+	actor := strings.TrimSpace(ctx.Query("actor"))
+	if actor == "" {
+		handleBadRequestResponse(ctx, "[actor] query parameter is required", errors.New("actor is missing"))
+		return
+	}
+
+	from, to, err := getTimeRangeParams(ctx)
+	if err != nil {
+		handleBadRequestResponse(ctx, "invalid query parameters", err)
+		return
+	}
+
+	products, err := c.productUsecase.GetProductsChangedBy(actor, from, to)
 	if err != nil {
-		handleInternalServerResponse(ctx, "failed to get products by category", err)
+		respondError(ctx, "product", "failed to get products changed by actor", err)
 		return
 	}
+
 	ctx.JSON(http.StatusOK, products)
 }
+
+// GetProductBySKU finds the product by the sku used by the inventory integrations.
+func (c ProductController) GetProductBySKU(ctx *gin.Context) {
+	skuId := strings.TrimSpace(ctx.Param("skuId"))
+	if skuId == "" {
+		handleBadRequestResponse(ctx, "[skuId] path parameter is required", errors.New("skuId is missing"))
+		return
+	}
+
+	product, err := c.productUsecase.GetProductBySKU(skuId)
+	if err != nil {
+		respondError(ctx, "product", "failed to get product by sku", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, product)
+}
+
+func (c ProductController) GetLowStockProducts(ctx *gin.Context) {
+	threshold := 0
+	if thresholdQueryParam := ctx.Query("threshold"); thresholdQueryParam != "" {
+		var err error
+		threshold, err = strconv.Atoi(thresholdQueryParam)
+		if err != nil {
+			handleBadRequestResponse(ctx, "[threshold] query parameter is invalid", err)
+			return
+		}
+
+		if threshold < 0 {
+			handleBadRequestResponse(ctx, "[threshold] query parameter is invalid", errors.New("threshold must not be negative"))
+			return
+		}
+	}
+
+	products, err := c.productUsecase.GetLowStockProducts(threshold)
+	if err != nil {
+		respondError(ctx, "product", "failed to get low stock products", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, products)
+}
+
+func (c ProductController) GetMenu(ctx *gin.Context) {
+	menu, err := c.productUsecase.GetMenu()
+	if err != nil {
+		respondError(ctx, "menu", "failed to get menu", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, menu)
+}
+
+func (c ProductController) GetProductRatings(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		handleBadRequestResponse(ctx, "id path param is required", errors.New("id path parameter is missing"))
+		return
+	}
+
+	productId, err := parseIdParam(id)
+	if err != nil {
+		handleBadRequestResponse(ctx, "id path param is invalid", err)
+		return
+	}
+
+	ratings, err := c.productUsecase.GetProductRatings(productId)
+	if err != nil {
+		respondError(ctx, "product", "failed to get product ratings", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, ratings)
+}
+
+func (c ProductController) GetProductPriceHistory(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		handleBadRequestResponse(ctx, "id path param is required", errors.New("id path parameter is missing"))
+		return
+	}
+
+	productId, err := parseIdParam(id)
+	if err != nil {
+		handleBadRequestResponse(ctx, "id path param is invalid", err)
+		return
+	}
+
+	history, err := c.productUsecase.GetProductPriceHistory(productId)
+	if err != nil {
+		respondError(ctx, "product", "failed to get product price history", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, history)
+}
+
+func (c ProductController) CreateProductVariant(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		handleBadRequestResponse(ctx, "id path param is required", errors.New("id path parameter is missing"))
+		return
+	}
+
+	productId, err := parseIdParam(id)
+	if err != nil {
+		handleBadRequestResponse(ctx, "id path param is invalid", err)
+		return
+	}
+
+	var variant dto.ProductVariantDTO
+	err = bindJSON(ctx, &variant)
+	if err != nil {
+		handleBadRequestResponse(ctx, "failed to bind product variant payload", err)
+		return
+	}
+
+	valid, err := variant.ValidateProductVariant()
+	if !valid {
+		handleBadRequestResponse(ctx, "invalid product variant payload", dto.LocalizeValidationError(err, getLocale(ctx)))
+		return
+	}
+
+	err = c.productUsecase.CreateProductVariant(productId, variant)
+	if err != nil {
+		respondError(ctx, "product", "failed to create product variant", err)
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+func (c ProductController) GetProductVariants(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		handleBadRequestResponse(ctx, "id path param is required", errors.New("id path parameter is missing"))
+		return
+	}
+
+	productId, err := parseIdParam(id)
+	if err != nil {
+		handleBadRequestResponse(ctx, "id path param is invalid", err)
+		return
+	}
+
+	variants, err := c.productUsecase.GetProductVariants(productId)
+	if err != nil {
+		respondError(ctx, "product", "failed to get product variants", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, variants)
+}
@@ -26,7 +26,7 @@ var productResponseValid, _ = os.ReadFile("./testdata/product_response_valid.jso
 func TestProductController_GetProducts(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	productUseCase := mock_usecases.NewMockProductUsecase(ctrl)
-	productController := NewProductController(productUseCase)
+	productController := NewProductController(productUseCase, dto.PageLimits{})
 
 	gin.SetMode(gin.TestMode)
 	c, e := gin.CreateTestContext(httptest.NewRecorder())
@@ -36,16 +36,18 @@ func TestProductController_GetProducts(t *testing.T) {
 		category string
 		limit    string
 		offset   string
+		expand   string
 	}
 	type want struct {
 		statusCode int
 		respBody   string
 	}
 	type productsUseCaseCall struct {
-		category string
-		times    int
-		page     dto.Page[entities.Product]
-		err      error
+		category       string
+		expandVariants bool
+		times          int
+		page           dto.Page[entities.Product]
+		err            error
 	}
 	tests := []struct {
 		name string
@@ -61,7 +63,7 @@ func TestProductController_GetProducts(t *testing.T) {
 			},
 			want: want{
 				statusCode: 400,
-				respBody:   `{"message":"invalid query parameters","error":"strconv.Atoi: parsing \"123abc\": invalid syntax"}`,
+				respBody:   `{"message":"invalid query parameters","errors":[{"field":"limit","message":"strconv.Atoi: parsing \"123abc\": invalid syntax"}]}`,
 			},
 		},
 		{
@@ -72,7 +74,19 @@ func TestProductController_GetProducts(t *testing.T) {
 			},
 			want: want{
 				statusCode: 400,
-				respBody:   `{"message":"invalid query parameters","error":"strconv.Atoi: parsing \"123abc\": invalid syntax"}`,
+				respBody:   `{"message":"invalid query parameters","errors":[{"field":"offset","message":"strconv.Atoi: parsing \"123abc\": invalid syntax"}]}`,
+			},
+		},
+		{
+			name: "should return every invalid query parameter at once",
+			args: args{
+				limit:  "ten",
+				offset: "123abc",
+				expand: "reviews",
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"invalid query parameters","errors":[{"field":"limit","message":"strconv.Atoi: parsing \"ten\": invalid syntax"},{"field":"offset","message":"strconv.Atoi: parsing \"123abc\": invalid syntax"},{"field":"expand","message":"unknown expansion [reviews]"}]}`,
 			},
 		},
 		{
@@ -134,8 +148,8 @@ func TestProductController_GetProducts(t *testing.T) {
 							Description: "Description of product 1",
 							Category:    "Acompanhamento",
 							Price:       9.99,
-							CreatedAt:   time.Time{},
-							UpdatedAt:   time.Time{},
+							CreatedAt:   entities.Timestamp{},
+							UpdatedAt:   entities.Timestamp{},
 						},
 					},
 					Next: new(int),
@@ -164,8 +178,8 @@ func TestProductController_GetProducts(t *testing.T) {
 							Description: "Description of product 1",
 							Category:    "Acompanhamento",
 							Price:       9.99,
-							CreatedAt:   time.Time{},
-							UpdatedAt:   time.Time{},
+							CreatedAt:   entities.Timestamp{},
+							UpdatedAt:   entities.Timestamp{},
 						},
 					},
 					Next: new(int),
@@ -173,24 +187,250 @@ func TestProductController_GetProducts(t *testing.T) {
 				err: nil,
 			},
 		},
+		{
+			name: "should return bad request when the expansion is unknown",
+			args: args{
+				expand: "reviews",
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"invalid query parameters","errors":[{"field":"expand","message":"unknown expansion [reviews]"}]}`,
+			},
+		},
+		{
+			name: "should get all products with their variants",
+			args: args{
+				expand: "variants",
+			},
+			want: want{
+				statusCode: 200,
+				respBody: `{"results":[{"id":1,"name":"Refrigerante","skuId":"","description":"","category":"Bebida","price":8,` +
+					`"variants":[{"id":11,"productId":1,"name":"Grande","skuId":"1-G","price":12,"createdAt":null,"updatedAt":null}],` +
+					`"createdAt":null,"updatedAt":null}]}`,
+			},
+			productsUseCaseCall: productsUseCaseCall{
+				expandVariants: true,
+				times:          1,
+				page: dto.Page[entities.Product]{
+					Result: []entities.Product{
+						{
+							ID:       1,
+							Name:     "Refrigerante",
+							Category: "Bebida",
+							Price:    8,
+							Variants: []entities.ProductVariant{
+								{ID: 11, ProductID: 1, Name: "Grande", SkuId: "1-G", Price: 12},
+							},
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
 		if tt.args.category != "" {
 			productUseCase.
 				EXPECT().
-				GetProductsByCategory(gomock.Any(), gomock.Eq(tt.productsUseCaseCall.category)).
+				GetProductsByCategory(gomock.Any(), gomock.Eq(tt.productsUseCaseCall.category), tt.productsUseCaseCall.expandVariants).
 				Times(tt.productsUseCaseCall.times).
 				Return(tt.productsUseCaseCall.page, tt.productsUseCaseCall.err)
 		} else {
 			productUseCase.
 				EXPECT().
-				GetAllProducts(gomock.Any()).
+				GetAllProducts(gomock.Any(), tt.productsUseCaseCall.expandVariants).
 				Times(tt.productsUseCaseCall.times).
 				Return(tt.productsUseCaseCall.page, tt.productsUseCaseCall.err)
 		}
 
-		c.Request, _ = http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/products?limit=%s&offset=%s&category=%s", tt.args.limit, tt.args.offset, tt.args.category), nil)
+		c.Request, _ = http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/products?limit=%s&offset=%s&category=%s&expand=%s", tt.args.limit, tt.args.offset, tt.args.category, tt.args.expand), nil)
+		c.Request.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		e.ServeHTTP(rr, c.Request)
+
+		assert.Equal(t, tt.want.statusCode, rr.Code)
+		assert.Equal(t, tt.want.respBody, rr.Body.String())
+	}
+}
+
+func TestProductController_GetProducts_PageLimits(t *testing.T) {
+	tests := []struct {
+		name      string
+		limit     string
+		wantLimit int
+	}{
+		{
+			name:      "should use the products default page size when limit is omitted",
+			limit:     "",
+			wantLimit: 50,
+		},
+		{
+			name:      "should cap the limit to the products max page size",
+			limit:     "80",
+			wantLimit: 60,
+		},
+		{
+			name:      "should keep a limit within the products max page size",
+			limit:     "10",
+			wantLimit: 10,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			productUseCase := mock_usecases.NewMockProductUsecase(ctrl)
+			productController := NewProductController(productUseCase, dto.PageLimits{Default: 50, Max: 60})
+
+			gin.SetMode(gin.TestMode)
+			_, e := gin.CreateTestContext(httptest.NewRecorder())
+			e.GET("/v1/products", productController.GetProducts)
+
+			var pageParams dto.PageParams
+			productUseCase.
+				EXPECT().
+				GetAllProducts(gomock.Any(), false).
+				DoAndReturn(func(params dto.PageParams, expandVariants bool) (dto.Page[entities.Product], error) {
+					pageParams = params
+					return dto.Page[entities.Product]{}, nil
+				}).
+				Times(1)
+
+			req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/products?limit=%s", tt.limit), nil)
+			rr := httptest.NewRecorder()
+			e.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, tt.wantLimit, pageParams.GetLimit())
+		})
+	}
+}
+
+func TestProductController_NonPositiveIds(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	// no usecase call is expected, the ids are rejected by the handlers
+	productUseCase := mock_usecases.NewMockProductUsecase(ctrl)
+	productController := NewProductController(productUseCase, dto.PageLimits{})
+
+	gin.SetMode(gin.TestMode)
+	_, e := gin.CreateTestContext(httptest.NewRecorder())
+	e.DELETE("/v1/products/:id", productController.DeleteProduct)
+	e.GET("/v1/products/:id/variants", productController.GetProductVariants)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+	}{
+		{
+			name:   "should reject a zero product id",
+			method: http.MethodDelete,
+			path:   "/v1/products/0",
+		},
+		{
+			name:   "should reject a negative product id",
+			method: http.MethodGet,
+			path:   "/v1/products/-5/variants",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, tt.path, nil)
+			rr := httptest.NewRecorder()
+			e.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Equal(t, `{"message":"id path param is invalid","error":"id must be greater than zero"}`, rr.Body.String())
+		})
+	}
+}
+
+func TestProductController_CreateProductVariant(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	productUseCase := mock_usecases.NewMockProductUsecase(ctrl)
+	productController := NewProductController(productUseCase, dto.PageLimits{})
+
+	gin.SetMode(gin.TestMode)
+	c, e := gin.CreateTestContext(httptest.NewRecorder())
+	e.POST("/v1/products/:id/variants", productController.CreateProductVariant)
+
+	type args struct {
+		id      string
+		reqBody string
+	}
+	type want struct {
+		statusCode int
+		respBody   string
+	}
+	type productUseCaseCall struct {
+		times int
+		err   error
+	}
+	tests := []struct {
+		name string
+		args
+		want
+		productUseCaseCall
+	}{
+		{
+			name: "should return bad request when id is not a number",
+			args: args{
+				id:      "abc",
+				reqBody: `{"name":"Grande","price":12}`,
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"id path param is invalid","error":"strconv.Atoi: parsing \"abc\": invalid syntax"}`,
+			},
+		},
+		{
+			name: "should return bad request when the variant has no name",
+			args: args{
+				id:      "1",
+				reqBody: `{"price":12}`,
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"invalid product variant payload","error":"Nome da variação é obrigatório"}`,
+			},
+		},
+		{
+			name: "should return not found when the product does not exist",
+			args: args{
+				id:      "1",
+				reqBody: `{"name":"Grande","price":12}`,
+			},
+			want: want{
+				statusCode: 404,
+				respBody:   `{"message":"product not found","error":"entity not found"}`,
+			},
+			productUseCaseCall: productUseCaseCall{
+				times: 1,
+				err:   sql.ErrNotFound,
+			},
+		},
+		{
+			name: "should create the variant succesfully",
+			args: args{
+				id:      "1",
+				reqBody: `{"name":"Grande","skuId":"1-G","price":12}`,
+			},
+			want: want{
+				statusCode: 200,
+			},
+			productUseCaseCall: productUseCaseCall{
+				times: 1,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		productUseCase.
+			EXPECT().
+			CreateProductVariant(gomock.Any(), gomock.Any()).
+			Times(tt.productUseCaseCall.times).
+			Return(tt.productUseCaseCall.err)
+
+		c.Request, _ = http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/products/%s/variants", tt.args.id), strings.NewReader(tt.args.reqBody))
 		c.Request.Header.Set("Content-Type", "application/json")
 		rr := httptest.NewRecorder()
 		e.ServeHTTP(rr, c.Request)
@@ -200,17 +440,112 @@ func TestProductController_GetProducts(t *testing.T) {
 	}
 }
 
+func TestProductController_DuplicateProduct(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	productUseCase := mock_usecases.NewMockProductUsecase(ctrl)
+	productController := NewProductController(productUseCase, dto.PageLimits{})
+
+	gin.SetMode(gin.TestMode)
+	c, e := gin.CreateTestContext(httptest.NewRecorder())
+	e.POST("/v1/products/:id/duplicate", productController.DuplicateProduct)
+
+	type want struct {
+		statusCode int
+		respBody   string
+	}
+	type productUseCaseCall struct {
+		duplicateDTO dto.ProductDuplicateDTO
+		times        int
+		product      entities.Product
+		err          error
+	}
+	tests := []struct {
+		name    string
+		id      string
+		reqBody string
+		want
+		productUseCaseCall
+	}{
+		{
+			name: "should duplicate the product without a payload",
+			id:   "222",
+			want: want{
+				statusCode: 201,
+				respBody:   `{"id":223,"name":"Batata Frita","skuId":"333-COPY","description":"Batata canoa","category":"Acompanhamento","price":9.99,"unavailable":true,"createdAt":null,"updatedAt":null}`,
+			},
+			productUseCaseCall: productUseCaseCall{
+				times:   1,
+				product: entities.Product{ID: 223, Name: "Batata Frita", SkuId: "333-COPY", Description: "Batata canoa", Category: "Acompanhamento", Price: 9.99, Unavailable: true},
+			},
+		},
+		{
+			name:    "should return conflict when the sku is already in use",
+			id:      "222",
+			reqBody: `{"skuId":"444"}`,
+			want: want{
+				statusCode: 409,
+				respBody:   `{"message":"product sku already in use","error":"sku is already used by another product, sku [444]"}`,
+			},
+			productUseCaseCall: productUseCaseCall{
+				duplicateDTO: dto.ProductDuplicateDTO{SkuId: "444"},
+				times:        1,
+				err:          fmt.Errorf("%w, sku [444]", dto.ErrProductSKUTaken),
+			},
+		},
+		{
+			name: "should return not found when the product does not exist",
+			id:   "999",
+			want: want{
+				statusCode: 404,
+				respBody:   `{"message":"product not found","error":"entity not found"}`,
+			},
+			productUseCaseCall: productUseCaseCall{
+				times: 1,
+				err:   sql.ErrNotFound,
+			},
+		},
+		{
+			name:    "should return bad request when the sku is too long",
+			id:      "222",
+			reqBody: `{"skuId":"` + strings.Repeat("9", 51) + `"}`,
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"invalid product duplicate payload","error":"Sku deve ter menos de 50 caracteres"}`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			productUseCase.
+				EXPECT().
+				DuplicateProduct(gomock.Any(), gomock.Eq(tt.productUseCaseCall.duplicateDTO), gomock.Eq("")).
+				Times(tt.productUseCaseCall.times).
+				Return(tt.productUseCaseCall.product, tt.productUseCaseCall.err)
+
+			c.Request, _ = http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/products/%s/duplicate", tt.id), strings.NewReader(tt.reqBody))
+			c.Request.Header.Set("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+			e.ServeHTTP(rr, c.Request)
+
+			assert.Equal(t, tt.want.statusCode, rr.Code)
+			assert.Equal(t, tt.want.respBody, rr.Body.String())
+		})
+	}
+}
+
 func TestProductController_CreateProduct(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	productUseCase := mock_usecases.NewMockProductUsecase(ctrl)
-	productController := NewProductController(productUseCase)
+	productController := NewProductController(productUseCase, dto.PageLimits{})
 
 	gin.SetMode(gin.TestMode)
 	c, e := gin.CreateTestContext(httptest.NewRecorder())
 	e.POST("/v1/products", productController.CreateProducts)
 
 	type args struct {
-		reqBody string
+		reqBody        string
+		acceptLanguage string
 	}
 	type want struct {
 		statusCode int
@@ -241,11 +576,43 @@ func TestProductController_CreateProduct(t *testing.T) {
 			args: args{
 				reqBody: string(productRequestMissingPrice),
 			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"invalid product payload","error":"price: valor obrigatório"}`,
+			},
+		},
+		{
+			name: "should return bad request in english when price is missing and the accept language is en",
+			args: args{
+				reqBody:        string(productRequestMissingPrice),
+				acceptLanguage: "en-US",
+			},
 			want: want{
 				statusCode: 400,
 				respBody:   `{"message":"invalid product payload","error":"price: non zero value required"}`,
 			},
 		},
+		{
+			name: "should return bad request in portuguese when price is missing and the accept language is pt-BR",
+			args: args{
+				reqBody:        string(productRequestMissingPrice),
+				acceptLanguage: "pt-BR,pt;q=0.9",
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"invalid product payload","error":"price: valor obrigatório"}`,
+			},
+		},
+		{
+			name: "should return bad request when the product has too many categories",
+			args: args{
+				reqBody: `{"name":"Combo","price":30,"categories":["a","b","c","d","e","f","g","h","i","j","k"]}`,
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"invalid product payload","error":"produto pode ter no máximo 10 categorias"}`,
+			},
+		},
 		{
 			name: "should not create product when the user case returns error",
 			args: args{
@@ -279,12 +646,15 @@ func TestProductController_CreateProduct(t *testing.T) {
 	for _, tt := range tests {
 		productUseCase.
 			EXPECT().
-			CreateProduct(gomock.Any()).
+			CreateProduct(gomock.Any(), gomock.Any()).
 			Times(tt.productUseCaseCall.times).
 			Return(tt.productUseCaseCall.err)
 
 		c.Request, _ = http.NewRequest(http.MethodPost, "/v1/products", strings.NewReader(tt.args.reqBody))
 		c.Request.Header.Set("Content-Type", "application/json")
+		if tt.args.acceptLanguage != "" {
+			c.Request.Header.Set("Accept-Language", tt.args.acceptLanguage)
+		}
 		rr := httptest.NewRecorder()
 		e.ServeHTTP(rr, c.Request)
 
@@ -296,7 +666,7 @@ func TestProductController_CreateProduct(t *testing.T) {
 func TestProductController_UpdateProduct(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	productUseCase := mock_usecases.NewMockProductUsecase(ctrl)
-	productController := NewProductController(productUseCase)
+	productController := NewProductController(productUseCase, dto.PageLimits{})
 
 	gin.SetMode(gin.TestMode)
 	c, e := gin.CreateTestContext(httptest.NewRecorder())
@@ -344,6 +714,28 @@ func TestProductController_UpdateProduct(t *testing.T) {
 				respBody:   `{"message":"failed to bind product payload","error":"invalid character '\u003c' looking for beginning of value"}`,
 			},
 		},
+		{
+			name: "should return bad request with a dedicated message when product payload is an empty object",
+			args: args{
+				id:      "222",
+				reqBody: `{}`,
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"empty product payload","error":"nenhum campo do produto foi informado"}`,
+			},
+		},
+		{
+			name: "should return bad request with a dedicated message when product payload only has empty categories",
+			args: args{
+				id:      "222",
+				reqBody: `{"categories":[]}`,
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"empty product payload","error":"nenhum campo do produto foi informado"}`,
+			},
+		},
 		{
 			name: "should return bad request when product payload is missing price",
 			args: args{
@@ -352,7 +744,7 @@ func TestProductController_UpdateProduct(t *testing.T) {
 			},
 			want: want{
 				statusCode: 400,
-				respBody:   `{"message":"invalid product payload","error":"price: non zero value required"}`,
+				respBody:   `{"message":"invalid product payload","error":"price: valor obrigatório"}`,
 			},
 		},
 		{
@@ -408,7 +800,7 @@ func TestProductController_UpdateProduct(t *testing.T) {
 	for _, tt := range tests {
 		productUseCase.
 			EXPECT().
-			UpdateProduct(gomock.Eq(tt.productUseCaseCall.productId), gomock.Any()).
+			UpdateProduct(gomock.Eq(tt.productUseCaseCall.productId), gomock.Any(), gomock.Any()).
 			Times(tt.productUseCaseCall.times).
 			Return(tt.productUseCaseCall.err)
 
@@ -429,7 +821,7 @@ func TestProductController_UpdateProduct(t *testing.T) {
 func TestProductController_DeleteProduct(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	productUseCase := mock_usecases.NewMockProductUsecase(ctrl)
-	productController := NewProductController(productUseCase)
+	productController := NewProductController(productUseCase, dto.PageLimits{})
 
 	gin.SetMode(gin.TestMode)
 	c, e := gin.CreateTestContext(httptest.NewRecorder())
@@ -437,7 +829,8 @@ func TestProductController_DeleteProduct(t *testing.T) {
 	e.DELETE("/v1/products/:id", productController.DeleteProduct)
 
 	type args struct {
-		id string
+		id    string
+		query string
 	}
 	type want struct {
 		statusCode int
@@ -496,34 +889,282 @@ func TestProductController_DeleteProduct(t *testing.T) {
 			},
 		},
 		{
-			name: "should delete product succesfully",
+			name: "should return not found when the product is missing in strict mode",
 			args: args{
-				id: "222",
+				id:    "222",
+				query: "?idempotent=false",
 			},
 			want: want{
-				statusCode: 204,
-				respBody:   "",
+				statusCode: 404,
+				respBody:   `{"message":"product not found","error":"entity not found"}`,
 			},
 			productUseCaseCall: productUseCaseCall{
 				productId: "222",
 				times:     1,
-				err:       nil,
+				err:       sql.ErrNotFound,
 			},
 		},
-	}
-
-	for _, tt := range tests {
-		productUseCase.
-			EXPECT().
-			DeleteProduct(gomock.Eq(tt.productUseCaseCall.productId)).
-			Times(tt.productUseCaseCall.times).
+		{
+			name: "should return no content when the product is missing in idempotent mode",
+			args: args{
+				id:    "222",
+				query: "?idempotent=true",
+			},
+			want: want{
+				statusCode: 204,
+				respBody:   "",
+			},
+			productUseCaseCall: productUseCaseCall{
+				productId: "222",
+				times:     1,
+				err:       sql.ErrNotFound,
+			},
+		},
+		{
+			name: "should keep reporting other errors in idempotent mode",
+			args: args{
+				id:    "222",
+				query: "?idempotent=true",
+			},
+			want: want{
+				statusCode: 409,
+				respBody:   `{"message":"product can not be deleted","error":"product is part of an order"}`,
+			},
+			productUseCaseCall: productUseCaseCall{
+				productId: "222",
+				times:     1,
+				err:       dto.ErrProductInOrder,
+			},
+		},
+		{
+			name: "should return bad request when the idempotent flag is invalid",
+			args: args{
+				id:    "222",
+				query: "?idempotent=maybe",
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"[idempotent] query parameter is invalid","error":"strconv.ParseBool: parsing \"maybe\": invalid syntax"}`,
+			},
+		},
+		{
+			name: "should not delete product when it is part of an order",
+			args: args{
+				id: "222",
+			},
+			want: want{
+				statusCode: 409,
+				respBody:   `{"message":"product can not be deleted","error":"product is part of an order"}`,
+			},
+			productUseCaseCall: productUseCaseCall{
+				productId: "222",
+				times:     1,
+				err:       dto.ErrProductInOrder,
+			},
+		},
+		{
+			name: "should delete product succesfully",
+			args: args{
+				id: "222",
+			},
+			want: want{
+				statusCode: 204,
+				respBody:   "",
+			},
+			productUseCaseCall: productUseCaseCall{
+				productId: "222",
+				times:     1,
+				err:       nil,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		productUseCase.
+			EXPECT().
+			DeleteProduct(gomock.Eq(tt.productUseCaseCall.productId)).
+			Times(tt.productUseCaseCall.times).
 			Return(tt.productUseCaseCall.err)
 
 		pathParam := ""
 		if tt.args.id != "" {
 			pathParam = "/" + tt.args.id
 		}
-		c.Request, _ = http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/products%s", pathParam), nil)
+		c.Request, _ = http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/products%s%s", pathParam, tt.args.query), nil)
+		c.Request.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		e.ServeHTTP(rr, c.Request)
+
+		assert.Equal(t, tt.want.statusCode, rr.Code)
+		assert.Equal(t, tt.want.respBody, rr.Body.String())
+	}
+}
+
+func TestProductController_BulkDeleteProducts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	productUseCase := mock_usecases.NewMockProductUsecase(ctrl)
+	productController := NewProductController(productUseCase, dto.PageLimits{})
+
+	gin.SetMode(gin.TestMode)
+	c, e := gin.CreateTestContext(httptest.NewRecorder())
+	e.DELETE("/v1/products", productController.BulkDeleteProducts)
+
+	type args struct {
+		reqBody string
+	}
+	type want struct {
+		statusCode int
+		respBody   string
+	}
+	type productUseCaseCall struct {
+		ids     []int
+		times   int
+		results []dto.BulkDeleteResultDTO
+	}
+	tests := []struct {
+		name string
+		args
+		want
+		productUseCaseCall
+	}{
+		{
+			name: "should return bad request when req body is not a json",
+			args: args{
+				reqBody: "<invalidJson>",
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"failed to bind bulk delete payload","error":"invalid character '\u003c' looking for beginning of value"}`,
+			},
+		},
+		{
+			name: "should return bad request when ids are empty",
+			args: args{
+				reqBody: `{"ids":[]}`,
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"invalid bulk delete payload","error":"ids must not be empty"}`,
+			},
+		},
+		{
+			name: "should return the result of each product of a mixed batch",
+			args: args{
+				reqBody: `{"ids":[1,2]}`,
+			},
+			want: want{
+				statusCode: 200,
+				respBody:   `{"results":[{"id":1,"status":"DELETED"},{"id":2,"status":"BLOCKED","error":"product is part of an order"}]}`,
+			},
+			productUseCaseCall: productUseCaseCall{
+				ids:   []int{1, 2},
+				times: 1,
+				results: []dto.BulkDeleteResultDTO{
+					{ID: 1, Status: dto.BulkDeleteStatusDeleted},
+					{ID: 2, Status: dto.BulkDeleteStatusBlocked, Error: "product is part of an order"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		productUseCase.
+			EXPECT().
+			BulkDeleteProducts(gomock.Eq(tt.productUseCaseCall.ids)).
+			Times(tt.productUseCaseCall.times).
+			Return(tt.productUseCaseCall.results)
+
+		c.Request, _ = http.NewRequest(http.MethodDelete, "/v1/products", strings.NewReader(tt.args.reqBody))
+		c.Request.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		e.ServeHTTP(rr, c.Request)
+
+		assert.Equal(t, tt.want.statusCode, rr.Code)
+		assert.Equal(t, tt.want.respBody, rr.Body.String())
+	}
+}
+
+func TestProductController_SetCategoryAvailability(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	productUseCase := mock_usecases.NewMockProductUsecase(ctrl)
+	productController := NewProductController(productUseCase, dto.PageLimits{})
+
+	gin.SetMode(gin.TestMode)
+	c, e := gin.CreateTestContext(httptest.NewRecorder())
+	e.PATCH("/v1/products/availability", productController.SetCategoryAvailability)
+
+	type args struct {
+		reqBody string
+	}
+	type want struct {
+		statusCode int
+		respBody   string
+	}
+	type productUseCaseCall struct {
+		times    int
+		category string
+		response dto.CategoryAvailabilityResponseDTO
+		err      error
+	}
+	tests := []struct {
+		name string
+		args
+		want
+		productUseCaseCall
+	}{
+		{
+			name: "should return bad request when the availability is missing",
+			args: args{
+				reqBody: `{"category":"Acompanhamento"}`,
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"invalid category availability payload","error":"available is required"}`,
+			},
+		},
+		{
+			name: "should return not found when the category has no products",
+			args: args{
+				reqBody: `{"category":"Sobremesa","available":false}`,
+			},
+			want: want{
+				statusCode: 404,
+				respBody:   `{"message":"product not found","error":"entity not found"}`,
+			},
+			productUseCaseCall: productUseCaseCall{
+				times:    1,
+				category: "Sobremesa",
+				err:      sql.ErrNotFound,
+			},
+		},
+		{
+			name: "should return the products updated in the category",
+			args: args{
+				reqBody: `{"category":"Acompanhamento","available":false}`,
+			},
+			want: want{
+				statusCode: 200,
+				respBody:   `{"category":"Acompanhamento","available":false,"productIds":[3,5]}`,
+			},
+			productUseCaseCall: productUseCaseCall{
+				times:    1,
+				category: "Acompanhamento",
+				response: dto.CategoryAvailabilityResponseDTO{Category: "Acompanhamento", ProductIDs: []int{3, 5}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		productUseCase.
+			EXPECT().
+			SetCategoryAvailability(gomock.Any()).
+			DoAndReturn(func(availability dto.CategoryAvailabilityDTO) (dto.CategoryAvailabilityResponseDTO, error) {
+				assert.Equal(t, tt.productUseCaseCall.category, availability.Category)
+				return tt.productUseCaseCall.response, tt.productUseCaseCall.err
+			}).
+			Times(tt.productUseCaseCall.times)
+
+		c.Request, _ = http.NewRequest(http.MethodPatch, "/v1/products/availability", strings.NewReader(tt.args.reqBody))
 		c.Request.Header.Set("Content-Type", "application/json")
 		rr := httptest.NewRecorder()
 		e.ServeHTTP(rr, c.Request)
@@ -532,3 +1173,728 @@ func TestProductController_DeleteProduct(t *testing.T) {
 		assert.Equal(t, tt.want.respBody, rr.Body.String())
 	}
 }
+
+func TestProductController_GetProductPopularity(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	productUseCase := mock_usecases.NewMockProductUsecase(ctrl)
+	productController := NewProductController(productUseCase, dto.PageLimits{})
+
+	gin.SetMode(gin.TestMode)
+	c, e := gin.CreateTestContext(httptest.NewRecorder())
+	e.GET("/v1/products/:id/popularity", productController.GetProductPopularity)
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	type args struct {
+		id   string
+		from string
+		to   string
+	}
+	type want struct {
+		statusCode int
+		respBody   string
+	}
+	type productUseCaseCall struct {
+		productId  int
+		times      int
+		popularity dto.ProductPopularityDTO
+		err        error
+	}
+	tests := []struct {
+		name string
+		args
+		want
+		productUseCaseCall
+	}{
+		{
+			name: "should return bad request when id is not a number",
+			args: args{
+				id: "abc",
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"id path param is invalid","error":"strconv.Atoi: parsing \"abc\": invalid syntax"}`,
+			},
+		},
+		{
+			name: "should return bad request when from is not a valid date",
+			args: args{
+				id:   "222",
+				from: "yesterday",
+				to:   "2024-02-01T00:00:00Z",
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"invalid query parameters","error":"parsing time \"yesterday\" as \"2006-01-02T15:04:05Z07:00\": cannot parse \"yesterday\" as \"2006\""}`,
+			},
+		},
+		{
+			name: "should return bad request when from is after to",
+			args: args{
+				id:   "222",
+				from: "2024-02-01T00:00:00Z",
+				to:   "2024-01-01T00:00:00Z",
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"invalid query parameters","error":"from [2024-02-01T00:00:00Z] must be before to [2024-01-01T00:00:00Z]"}`,
+			},
+		},
+		{
+			name: "should not get product popularity when the use case returns error",
+			args: args{
+				id:   "222",
+				from: "2024-01-01T00:00:00Z",
+				to:   "2024-02-01T00:00:00Z",
+			},
+			want: want{
+				statusCode: 500,
+				respBody:   `{"message":"failed to get product popularity","error":"internal server error"}`,
+			},
+			productUseCaseCall: productUseCaseCall{
+				productId: 222,
+				times:     1,
+				err:       errors.New("internal server error"),
+			},
+		},
+		{
+			name: "should return not found when the product does not exist",
+			args: args{
+				id:   "222",
+				from: "2024-01-01T00:00:00Z",
+				to:   "2024-02-01T00:00:00Z",
+			},
+			want: want{
+				statusCode: 404,
+				respBody:   `{"message":"product not found","error":"entity not found"}`,
+			},
+			productUseCaseCall: productUseCaseCall{
+				productId: 222,
+				times:     1,
+				err:       sql.ErrNotFound,
+			},
+		},
+		{
+			name: "should get product popularity succesfully",
+			args: args{
+				id:   "222",
+				from: "2024-01-01T00:00:00Z",
+				to:   "2024-02-01T00:00:00Z",
+			},
+			want: want{
+				statusCode: 200,
+				respBody:   `{"productId":222,"ordersCount":12,"unitsSold":30,"from":"2024-01-01T00:00:00Z","to":"2024-02-01T00:00:00Z"}`,
+			},
+			productUseCaseCall: productUseCaseCall{
+				productId: 222,
+				times:     1,
+				popularity: dto.ProductPopularityDTO{
+					ProductID:   222,
+					OrdersCount: 12,
+					UnitsSold:   30,
+					From:        from,
+					To:          to,
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		productUseCase.
+			EXPECT().
+			GetProductPopularity(gomock.Eq(tt.productUseCaseCall.productId), gomock.Eq(from), gomock.Eq(to)).
+			Times(tt.productUseCaseCall.times).
+			Return(tt.productUseCaseCall.popularity, tt.productUseCaseCall.err)
+
+		c.Request, _ = http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/products/%s/popularity?from=%s&to=%s", tt.args.id, tt.args.from, tt.args.to), nil)
+		c.Request.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		e.ServeHTTP(rr, c.Request)
+
+		assert.Equal(t, tt.want.statusCode, rr.Code)
+		assert.Equal(t, tt.want.respBody, rr.Body.String())
+	}
+}
+
+func TestProductController_GetProductRatings(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	productUseCase := mock_usecases.NewMockProductUsecase(ctrl)
+	productController := NewProductController(productUseCase, dto.PageLimits{})
+
+	gin.SetMode(gin.TestMode)
+	c, e := gin.CreateTestContext(httptest.NewRecorder())
+	e.GET("/v1/products/:id/ratings", productController.GetProductRatings)
+
+	type args struct {
+		id string
+	}
+	type want struct {
+		statusCode int
+		respBody   string
+	}
+	type productUseCaseCall struct {
+		productId int
+		times     int
+		ratings   dto.ProductRatingsDTO
+		err       error
+	}
+	tests := []struct {
+		name string
+		args
+		want
+		productUseCaseCall
+	}{
+		{
+			name: "should return bad request when id is not a number",
+			args: args{
+				id: "abc",
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"id path param is invalid","error":"strconv.Atoi: parsing \"abc\": invalid syntax"}`,
+			},
+		},
+		{
+			name: "should get product ratings succesfully",
+			args: args{
+				id: "222",
+			},
+			want: want{
+				statusCode: 200,
+				respBody:   `{"productId":222,"ratingsCount":8,"averageRating":4.25}`,
+			},
+			productUseCaseCall: productUseCaseCall{
+				productId: 222,
+				times:     1,
+				ratings:   dto.ProductRatingsDTO{ProductID: 222, RatingsCount: 8, AverageRating: 4.25},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		productUseCase.
+			EXPECT().
+			GetProductRatings(gomock.Eq(tt.productUseCaseCall.productId)).
+			Times(tt.productUseCaseCall.times).
+			Return(tt.productUseCaseCall.ratings, tt.productUseCaseCall.err)
+
+		c.Request, _ = http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/products/%s/ratings", tt.args.id), nil)
+		rr := httptest.NewRecorder()
+		e.ServeHTTP(rr, c.Request)
+
+		assert.Equal(t, tt.want.statusCode, rr.Code)
+		assert.Equal(t, tt.want.respBody, rr.Body.String())
+	}
+}
+
+func TestProductController_GetProductPriceHistory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	productUseCase := mock_usecases.NewMockProductUsecase(ctrl)
+	productController := NewProductController(productUseCase, dto.PageLimits{})
+
+	gin.SetMode(gin.TestMode)
+	c, e := gin.CreateTestContext(httptest.NewRecorder())
+	e.GET("/v1/products/:id/price-history", productController.GetProductPriceHistory)
+
+	type args struct {
+		id string
+	}
+	type want struct {
+		statusCode int
+		respBody   string
+	}
+	type productUseCaseCall struct {
+		productId int
+		times     int
+		history   []entities.ProductPriceChange
+		err       error
+	}
+	tests := []struct {
+		name string
+		args
+		want
+		productUseCaseCall
+	}{
+		{
+			name: "should return bad request when id is not a number",
+			args: args{
+				id: "abc",
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"id path param is invalid","error":"strconv.Atoi: parsing \"abc\": invalid syntax"}`,
+			},
+		},
+		{
+			name: "should not get product price history when the use case returns error",
+			args: args{
+				id: "222",
+			},
+			want: want{
+				statusCode: 500,
+				respBody:   `{"message":"failed to get product price history","error":"internal server error"}`,
+			},
+			productUseCaseCall: productUseCaseCall{
+				productId: 222,
+				times:     1,
+				err:       errors.New("internal server error"),
+			},
+		},
+		{
+			name: "should get product price history succesfully",
+			args: args{
+				id: "222",
+			},
+			want: want{
+				statusCode: 200,
+				respBody:   `[{"productId":222,"price":9.99,"changedAt":"2024-01-01T10:00:00Z"},{"productId":222,"price":11.5,"changedAt":"2024-02-01T10:00:00Z"}]`,
+			},
+			productUseCaseCall: productUseCaseCall{
+				productId: 222,
+				times:     1,
+				history: []entities.ProductPriceChange{
+					{ProductID: 222, Price: 9.99, ChangedAt: entities.NewTimestamp(time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC))},
+					{ProductID: 222, Price: 11.5, ChangedAt: entities.NewTimestamp(time.Date(2024, 2, 1, 10, 0, 0, 0, time.UTC))},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		productUseCase.
+			EXPECT().
+			GetProductPriceHistory(gomock.Eq(tt.productUseCaseCall.productId)).
+			Times(tt.productUseCaseCall.times).
+			Return(tt.productUseCaseCall.history, tt.productUseCaseCall.err)
+
+		c.Request, _ = http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/products/%s/price-history", tt.args.id), nil)
+		rr := httptest.NewRecorder()
+		e.ServeHTTP(rr, c.Request)
+
+		assert.Equal(t, tt.want.statusCode, rr.Code)
+		assert.Equal(t, tt.want.respBody, rr.Body.String())
+	}
+}
+
+func TestProductController_CreateProduct_StrictJSONBinding(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	productUseCase := mock_usecases.NewMockProductUsecase(ctrl)
+	productController := NewProductController(productUseCase, dto.PageLimits{})
+
+	gin.SetMode(gin.TestMode)
+	c, e := gin.CreateTestContext(httptest.NewRecorder())
+	e.POST("/v1/products", StrictJSONBindingMiddleware(), productController.CreateProducts)
+	e.POST("/v1/lenient/products", productController.CreateProducts)
+
+	type args struct {
+		path    string
+		reqBody string
+	}
+	type want struct {
+		statusCode int
+		respBody   string
+	}
+	type productUseCaseCall struct {
+		times int
+	}
+	tests := []struct {
+		name string
+		args
+		want
+		productUseCaseCall
+	}{
+		{
+			name: "should reject unknown fields in strict mode",
+			args: args{
+				path:    "/v1/products",
+				reqBody: `{"name":"Product 1","skuId":"33333","category":"Acompanhamento","price":9.99,"pirce":9.99}`,
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"failed to bind product payload","error":"unknown field [pirce]"}`,
+			},
+		},
+		{
+			name: "should accept a payload without unknown fields in strict mode",
+			args: args{
+				path:    "/v1/products",
+				reqBody: string(productRequestValid),
+			},
+			want: want{
+				statusCode: 200,
+				respBody:   "",
+			},
+			productUseCaseCall: productUseCaseCall{
+				times: 1,
+			},
+		},
+		{
+			name: "should ignore unknown fields when strict mode is not enabled",
+			args: args{
+				path:    "/v1/lenient/products",
+				reqBody: `{"name":"Product 1","skuId":"33333","category":"Acompanhamento","price":9.99,"pirce":9.99}`,
+			},
+			want: want{
+				statusCode: 200,
+				respBody:   "",
+			},
+			productUseCaseCall: productUseCaseCall{
+				times: 1,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		productUseCase.
+			EXPECT().
+			CreateProduct(gomock.Any(), gomock.Any()).
+			Times(tt.productUseCaseCall.times).
+			Return(nil)
+
+		c.Request, _ = http.NewRequest(http.MethodPost, tt.args.path, strings.NewReader(tt.args.reqBody))
+		c.Request.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		e.ServeHTTP(rr, c.Request)
+
+		assert.Equal(t, tt.want.statusCode, rr.Code)
+		assert.Equal(t, tt.want.respBody, rr.Body.String())
+	}
+}
+
+func TestProductController_GetProductBySKU(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	productUseCase := mock_usecases.NewMockProductUsecase(ctrl)
+	productController := NewProductController(productUseCase, dto.PageLimits{})
+
+	gin.SetMode(gin.TestMode)
+	c, e := gin.CreateTestContext(httptest.NewRecorder())
+	e.GET("/v1/products/sku/:skuId", productController.GetProductBySKU)
+
+	type args struct {
+		skuId string
+	}
+	type want struct {
+		statusCode int
+		respBody   string
+	}
+	type productUseCaseCall struct {
+		skuId   string
+		times   int
+		product entities.Product
+		err     error
+	}
+	tests := []struct {
+		name string
+		args
+		want
+		productUseCaseCall
+	}{
+		{
+			name: "should get the product with the sku",
+			args: args{
+				skuId: "333",
+			},
+			want: want{
+				statusCode: 200,
+				respBody:   `{"id":222,"name":"Batata Frita","skuId":"333","description":"Batata canoa","category":"Acompanhamento","price":9.99,"createdAt":null,"updatedAt":null}`,
+			},
+			productUseCaseCall: productUseCaseCall{
+				skuId:   "333",
+				times:   1,
+				product: entities.Product{ID: 222, Name: "Batata Frita", SkuId: "333", Description: "Batata canoa", Category: "Acompanhamento", Price: 9.99},
+			},
+		},
+		{
+			name: "should return not found when no product has the sku",
+			args: args{
+				skuId: "999",
+			},
+			want: want{
+				statusCode: 404,
+				respBody:   `{"message":"product not found","error":"entity not found"}`,
+			},
+			productUseCaseCall: productUseCaseCall{
+				skuId: "999",
+				times: 1,
+				err:   sql.ErrNotFound,
+			},
+		},
+		{
+			name: "should return bad request when the sku is blank",
+			args: args{
+				skuId: "%20",
+			},
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"[skuId] path parameter is required","error":"skuId is missing"}`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		productUseCase.
+			EXPECT().
+			GetProductBySKU(gomock.Eq(tt.productUseCaseCall.skuId)).
+			Times(tt.productUseCaseCall.times).
+			Return(tt.productUseCaseCall.product, tt.productUseCaseCall.err)
+
+		c.Request, _ = http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/products/sku/%s", tt.args.skuId), nil)
+		rr := httptest.NewRecorder()
+		e.ServeHTTP(rr, c.Request)
+
+		assert.Equal(t, tt.want.statusCode, rr.Code)
+		assert.Equal(t, tt.want.respBody, rr.Body.String())
+	}
+}
+
+func TestProductController_GetLowStockProducts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	productUseCase := mock_usecases.NewMockProductUsecase(ctrl)
+	productController := NewProductController(productUseCase, dto.PageLimits{})
+
+	gin.SetMode(gin.TestMode)
+	c, e := gin.CreateTestContext(httptest.NewRecorder())
+	e.GET("/v1/products/low-stock", productController.GetLowStockProducts)
+
+	type want struct {
+		statusCode int
+		respBody   string
+	}
+	type productUseCaseCall struct {
+		threshold int
+		times     int
+		products  []entities.Product
+		err       error
+	}
+	tests := []struct {
+		name  string
+		query string
+		want
+		productUseCaseCall
+	}{
+		{
+			name:  "should list the products at or below the threshold",
+			query: "?threshold=5",
+			want: want{
+				statusCode: 200,
+				respBody:   `[{"id":222,"name":"Batata Frita","skuId":"333","description":"Batata canoa","category":"Acompanhamento","price":9.99,"unavailable":true,"createdAt":null,"updatedAt":null}]`,
+			},
+			productUseCaseCall: productUseCaseCall{
+				threshold: 5,
+				times:     1,
+				products:  []entities.Product{{ID: 222, Name: "Batata Frita", SkuId: "333", Description: "Batata canoa", Category: "Acompanhamento", Price: 9.99, Unavailable: true}},
+			},
+		},
+		{
+			name: "should use a zero threshold when none is given",
+			want: want{
+				statusCode: 200,
+				respBody:   `[]`,
+			},
+			productUseCaseCall: productUseCaseCall{
+				threshold: 0,
+				times:     1,
+				products:  []entities.Product{},
+			},
+		},
+		{
+			name:  "should return bad request when the threshold is negative",
+			query: "?threshold=-1",
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"[threshold] query parameter is invalid","error":"threshold must not be negative"}`,
+			},
+		},
+		{
+			name:  "should return bad request when the threshold is not a number",
+			query: "?threshold=few",
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"[threshold] query parameter is invalid","error":"strconv.Atoi: parsing \"few\": invalid syntax"}`,
+			},
+		},
+		{
+			name:  "should return internal server error when the products can not be listed",
+			query: "?threshold=3",
+			want: want{
+				statusCode: 500,
+				respBody:   `{"message":"failed to get low stock products","error":"connection refused"}`,
+			},
+			productUseCaseCall: productUseCaseCall{
+				threshold: 3,
+				times:     1,
+				err:       errors.New("connection refused"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			productUseCase.
+				EXPECT().
+				GetLowStockProducts(gomock.Eq(tt.productUseCaseCall.threshold)).
+				Times(tt.productUseCaseCall.times).
+				Return(tt.productUseCaseCall.products, tt.productUseCaseCall.err)
+
+			c.Request, _ = http.NewRequest(http.MethodGet, "/v1/products/low-stock"+tt.query, nil)
+			rr := httptest.NewRecorder()
+			e.ServeHTTP(rr, c.Request)
+
+			assert.Equal(t, tt.want.statusCode, rr.Code)
+			assert.Equal(t, tt.want.respBody, rr.Body.String())
+		})
+	}
+}
+
+func TestProductController_GetProductsAudit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	productUseCase := mock_usecases.NewMockProductUsecase(ctrl)
+	productController := NewProductController(productUseCase, dto.PageLimits{})
+
+	gin.SetMode(gin.TestMode)
+	c, e := gin.CreateTestContext(httptest.NewRecorder())
+	e.GET("/v1/products/audit", productController.GetProductsAudit)
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	updatedAt := entities.NewTimestamp(time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC))
+
+	type want struct {
+		statusCode int
+		respBody   string
+	}
+	type productUseCaseCall struct {
+		actor    string
+		times    int
+		products []entities.Product
+		err      error
+	}
+	tests := []struct {
+		name  string
+		query string
+		want
+		productUseCaseCall
+	}{
+		{
+			name:  "should list the products last changed by the actor within the range",
+			query: "?actor=maria&from=2024-01-01T00:00:00Z&to=2024-02-01T00:00:00Z",
+			want: want{
+				statusCode: 200,
+				respBody:   `[{"id":3,"name":"X-Bacon","skuId":"XB","description":"","category":"Lanche","price":20,"createdAt":null,"updatedAt":"2024-01-15T10:00:00Z","createdBy":"joao","updatedBy":"maria"},{"id":1,"name":"Batata Frita","skuId":"333","description":"","category":"Acompanhamento","price":9.99,"createdAt":null,"updatedAt":"2024-01-15T10:00:00Z","createdBy":"maria","updatedBy":"maria"}]`,
+			},
+			productUseCaseCall: productUseCaseCall{
+				actor: "maria",
+				times: 1,
+				products: []entities.Product{
+					{ID: 3, Name: "X-Bacon", SkuId: "XB", Category: "Lanche", Price: 20, UpdatedAt: updatedAt, CreatedBy: "joao", UpdatedBy: "maria"},
+					{ID: 1, Name: "Batata Frita", SkuId: "333", Category: "Acompanhamento", Price: 9.99, UpdatedAt: updatedAt, CreatedBy: "maria", UpdatedBy: "maria"},
+				},
+			},
+		},
+		{
+			name:  "should return bad request when the actor is missing",
+			query: "?from=2024-01-01T00:00:00Z&to=2024-02-01T00:00:00Z",
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"[actor] query parameter is required","error":"actor is missing"}`,
+			},
+		},
+		{
+			name:  "should return bad request when the range is inverted",
+			query: "?actor=maria&from=2024-02-01T00:00:00Z&to=2024-01-01T00:00:00Z",
+			want: want{
+				statusCode: 400,
+				respBody:   `{"message":"invalid query parameters","error":"from [2024-02-01T00:00:00Z] must be before to [2024-01-01T00:00:00Z]"}`,
+			},
+		},
+		{
+			name:  "should return internal server error when the products can not be listed",
+			query: "?actor=joao&from=2024-01-01T00:00:00Z&to=2024-02-01T00:00:00Z",
+			want: want{
+				statusCode: 500,
+				respBody:   `{"message":"failed to get products changed by actor","error":"connection refused"}`,
+			},
+			productUseCaseCall: productUseCaseCall{
+				actor: "joao",
+				times: 1,
+				err:   errors.New("connection refused"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			productUseCase.
+				EXPECT().
+				GetProductsChangedBy(tt.productUseCaseCall.actor, from, to).
+				Times(tt.productUseCaseCall.times).
+				Return(tt.productUseCaseCall.products, tt.productUseCaseCall.err)
+
+			c.Request, _ = http.NewRequest(http.MethodGet, "/v1/products/audit"+tt.query, nil)
+			rr := httptest.NewRecorder()
+			e.ServeHTTP(rr, c.Request)
+
+			assert.Equal(t, tt.want.statusCode, rr.Code)
+			assert.Equal(t, tt.want.respBody, rr.Body.String())
+		})
+	}
+}
+
+func TestProductController_GetMenu(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	productUseCase := mock_usecases.NewMockProductUsecase(ctrl)
+	productController := NewProductController(productUseCase, dto.PageLimits{})
+
+	gin.SetMode(gin.TestMode)
+	c, e := gin.CreateTestContext(httptest.NewRecorder())
+	e.GET("/v1/menu", productController.GetMenu)
+
+	tests := []struct {
+		name         string
+		menu         dto.MenuDTO
+		err          error
+		wantStatus   int
+		wantRespBody string
+	}{
+		{
+			name: "should return the products grouped by category",
+			menu: dto.MenuDTO{Categories: []dto.MenuCategoryDTO{
+				{Name: "Acompanhamento", Products: []entities.Product{{ID: 222, Name: "Batata Frita", Category: "Acompanhamento", Price: 9.99}}},
+				{Name: "Lanche", Products: []entities.Product{
+					{ID: 1, Name: "X-Bacon", Category: "Lanche", Price: 25},
+					{ID: 2, Name: "X-Burguer", Category: "Lanche", Price: 20},
+				}},
+			}},
+			wantStatus: 200,
+			wantRespBody: `{"categories":[` +
+				`{"name":"Acompanhamento","products":[{"id":222,"name":"Batata Frita","skuId":"","description":"","category":"Acompanhamento","price":9.99,"createdAt":null,"updatedAt":null}]},` +
+				`{"name":"Lanche","products":[{"id":1,"name":"X-Bacon","skuId":"","description":"","category":"Lanche","price":25,"createdAt":null,"updatedAt":null},` +
+				`{"id":2,"name":"X-Burguer","skuId":"","description":"","category":"Lanche","price":20,"createdAt":null,"updatedAt":null}]}]}`,
+		},
+		{
+			name:         "should return an empty menu when no product is available",
+			menu:         dto.MenuDTO{Categories: []dto.MenuCategoryDTO{}},
+			wantStatus:   200,
+			wantRespBody: `{"categories":[]}`,
+		},
+		{
+			name:         "should return internal server error when the menu can not be read",
+			err:          errors.New("connection refused"),
+			wantStatus:   500,
+			wantRespBody: `{"message":"failed to get menu","error":"connection refused"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			productUseCase.EXPECT().GetMenu().Return(tt.menu, tt.err).Times(1)
+
+			c.Request, _ = http.NewRequest(http.MethodGet, "/v1/menu", nil)
+			rr := httptest.NewRecorder()
+			e.ServeHTTP(rr, c.Request)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			assert.Equal(t, tt.wantRespBody, rr.Body.String())
+		})
+	}
+}
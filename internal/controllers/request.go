@@ -1,25 +1,298 @@
 package controllers
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/g73-techchallenge-order/internal/core/usecases/dto"
 	"github.com/gin-gonic/gin"
 )
 
-func getPageParams(c *gin.Context) (dto.PageParams, error) {
+const unknownFieldErrorPrefix = "json: unknown field "
+
+type JSONArrayTooLargeError struct {
+	MaxElements int
+}
+
+func (e JSONArrayTooLargeError) Error() string {
+	return fmt.Sprintf("json arrays are limited to %d elements", e.MaxElements)
+}
+
+func bindJSON(c *gin.Context, obj any) error {
+	if maxElements := c.GetInt(maxJSONArrayElementsKey); maxElements > 0 {
+		if err := checkJSONArraysLength(c, maxElements); err != nil {
+			return err
+		}
+	}
+
+	if !c.GetBool(strictJSONBindingKey) {
+		return c.ShouldBindJSON(obj)
+	}
+
+	if c.Request == nil || c.Request.Body == nil {
+		return errors.New("invalid request")
+	}
+
+	decoder := json.NewDecoder(c.Request.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(obj)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), unknownFieldErrorPrefix) {
+			return fmt.Errorf("unknown field [%s]", strings.Trim(strings.TrimPrefix(err.Error(), unknownFieldErrorPrefix), `"`))
+		}
+		return err
+	}
+
+	return nil
+}
+
+// checkJSONArraysLength walks the body token by token, so an oversized array is rejected without decoding its elements.
+// The bytes read are put back on the request, leaving malformed payloads to be reported by the binding.
+func checkJSONArraysLength(c *gin.Context, maxElements int) error {
+	if c.Request == nil || c.Request.Body == nil {
+		return nil
+	}
+
+	var scanned bytes.Buffer
+	body := c.Request.Body
+	defer func() {
+		c.Request.Body = io.NopCloser(io.MultiReader(&scanned, body))
+	}()
+
+	decoder := json.NewDecoder(io.TeeReader(body, &scanned))
+	// element count of each open array, -1 for the open objects
+	var counts []int
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil
+		}
+
+		if delim, ok := token.(json.Delim); ok && (delim == ']' || delim == '}') {
+			if len(counts) > 0 {
+				counts = counts[:len(counts)-1]
+			}
+			if len(counts) == 0 {
+				return nil
+			}
+			continue
+		}
+
+		if last := len(counts) - 1; last >= 0 && counts[last] >= 0 {
+			counts[last]++
+			if counts[last] > maxElements {
+				return JSONArrayTooLargeError{MaxElements: maxElements}
+			}
+		}
+
+		switch token {
+		case json.Delim('['):
+			counts = append(counts, 0)
+		case json.Delim('{'):
+			counts = append(counts, -1)
+		}
+	}
+}
+
+// getPageParams reads the limit and offset query parameters, returning an error for each one invalid.
+func getPageParams(c *gin.Context, limits dto.PageLimits) (dto.PageParams, []dto.FieldError) {
 	limitQueryParam := c.Query("limit")
 	offsetQueryParam := c.Query("offset")
 
+	var fieldErrors []dto.FieldError
 	limit, err := strconv.Atoi(limitQueryParam)
 	if limitQueryParam != "" && err != nil {
-		return dto.PageParams{}, err
+		fieldErrors = append(fieldErrors, dto.FieldError{Field: "limit", Err: err})
 	}
 
 	offset, err := strconv.Atoi(offsetQueryParam)
 	if offsetQueryParam != "" && err != nil {
-		return dto.PageParams{}, err
+		fieldErrors = append(fieldErrors, dto.FieldError{Field: "offset", Err: err})
+	}
+
+	if len(fieldErrors) > 0 {
+		return dto.PageParams{}, fieldErrors
+	}
+
+	pageParams := limits.NewPageParams(offset, limit)
+	if limits.WarnClamped && pageParams.LimitClamped() {
+		c.Header(resultTruncatedHeader, "true")
+	}
+
+	return pageParams, nil
+}
+
+func getBoolQueryParam(c *gin.Context, name string) (bool, error) {
+	queryParam := c.Query(name)
+	if queryParam == "" {
+		return false, nil
+	}
+
+	return strconv.ParseBool(queryParam)
+}
+
+// hasExpandQueryParam reads the comma separated expand query parameter, which only accepts the given expansion.
+func hasExpandQueryParam(c *gin.Context, expansion string) (bool, error) {
+	queryParam := c.Query("expand")
+	if queryParam == "" {
+		return false, nil
+	}
+
+	for _, value := range strings.Split(queryParam, ",") {
+		if strings.TrimSpace(value) != expansion {
+			return false, fmt.Errorf("unknown expansion [%s]", value)
+		}
+	}
+
+	return true, nil
+}
+
+// getAuthSubject returns who sent the request, empty when it carries no known bearer token.
+func getAuthSubject(c *gin.Context) string {
+	return c.GetString(authSubjectKey)
+}
+
+// getCorrelationID returns the id given to the request by the CorrelationIDMiddleware, empty when it is not in use.
+func getCorrelationID(c *gin.Context) string {
+	return c.GetString(correlationIDKey)
+}
+
+// notModifiedSince tells whether the If-Modified-Since of the request is not older than modifiedAt, compared at the
+// seconds precision of the header. A missing or malformed header is always modified.
+func notModifiedSince(c *gin.Context, modifiedAt time.Time) bool {
+	header := c.GetHeader("If-Modified-Since")
+	if header == "" || modifiedAt.IsZero() {
+		return false
+	}
+
+	since, err := http.ParseTime(header)
+	if err != nil {
+		return false
+	}
+
+	return !modifiedAt.Truncate(time.Second).After(since)
+}
+
+func getLocale(c *gin.Context) dto.Locale {
+	return dto.ParseLocale(c.GetHeader("Accept-Language"))
+}
+
+func getTimeRangeParams(c *gin.Context) (time.Time, time.Time, error) {
+	fromQueryParam := c.Query("from")
+	toQueryParam := c.Query("to")
+
+	var from time.Time
+	if fromQueryParam != "" {
+		parsedFrom, err := time.Parse(time.RFC3339, fromQueryParam)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		from = parsedFrom
+	}
+
+	to := time.Now()
+	if toQueryParam != "" {
+		parsedTo, err := time.Parse(time.RFC3339, toQueryParam)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		to = parsedTo
+	}
+
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, fmt.Errorf("from [%s] must be before to [%s]", from.Format(time.RFC3339), to.Format(time.RFC3339))
+	}
+
+	return from, to, nil
+}
+
+// getOrderFilters reads the filters of the order lists, returning an error for each query parameter invalid.
+func getOrderFilters(c *gin.Context) (dto.OrderFilters, []dto.FieldError) {
+	var fieldErrors []dto.FieldError
+	fulfillmentType := c.Query("fulfillmentType")
+	if fulfillmentType != "" && !dto.IsValidFulfillmentType(fulfillmentType) {
+		fieldErrors = append(fieldErrors, dto.FieldError{Field: "fulfillmentType", Err: fmt.Errorf("fulfillmentType [%s] is invalid", fulfillmentType)})
+	}
+
+	var statuses []string
+	for _, statusQueryParam := range c.QueryArray("status") {
+		for _, status := range strings.Split(statusQueryParam, ",") {
+			status = strings.TrimSpace(status)
+			if status == "" {
+				continue
+			}
+			if !dto.IsValidOrderStatus(status) {
+				fieldErrors = append(fieldErrors, dto.FieldError{Field: "status", Err: fmt.Errorf("status [%s] is invalid", status)})
+				continue
+			}
+			statuses = append(statuses, status)
+		}
+	}
+
+	today, err := getBoolQueryParam(c, "today")
+	if err != nil {
+		fieldErrors = append(fieldErrors, dto.FieldError{Field: "today", Err: fmt.Errorf("today [%s] is invalid", c.Query("today"))})
+	}
+
+	sort := c.Query("sort")
+	if sort != "" && !dto.IsValidOrderSort(sort) {
+		fieldErrors = append(fieldErrors, dto.FieldError{Field: "sort", Err: fmt.Errorf("sort [%s] is invalid", sort)})
+	}
+
+	if len(fieldErrors) > 0 {
+		return dto.OrderFilters{}, fieldErrors
+	}
+
+	return dto.OrderFilters{
+		FulfillmentType: fulfillmentType,
+		Statuses:        statuses,
+		Sort:            dto.OrderSort(sort),
+		Today:           today,
+	}, nil
+}
+
+var errNonPositiveId = errors.New("id must be greater than zero")
+
+// parseIdParam parses an id sent on the path or query, ids are database keys so zero and negative values are rejected.
+func parseIdParam(value string) (int, error) {
+	id, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, err
+	}
+
+	if id < 1 {
+		return 0, errNonPositiveId
+	}
+
+	return id, nil
+}
+
+func getIdsQueryParam(c *gin.Context, name string) ([]int, error) {
+	var ids []int
+	for _, idsQueryParam := range c.QueryArray(name) {
+		for _, id := range strings.Split(idsQueryParam, ",") {
+			id = strings.TrimSpace(id)
+			if id == "" {
+				continue
+			}
+			parsed, err := parseIdParam(id)
+			if err != nil {
+				return nil, fmt.Errorf("id [%s] is invalid", id)
+			}
+			ids = append(ids, parsed)
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("[%s] query parameter is required", name)
 	}
 
-	return dto.NewPageParams(offset, limit), nil
+	return ids, nil
 }
@@ -3,42 +3,114 @@ package controllers
 import (
 	"net/http"
 
+	"github.com/g73-techchallenge-order/internal/core/usecases/dto"
 	"github.com/gin-gonic/gin"
 )
 
+// ErrorResponse carries the correlation id of the request as RequestID, so support can find the failure in the logs.
 type ErrorResponse struct {
-	Message string `json:"message"`
-	Err     string `json:"error"`
+	Message   string `json:"message"`
+	Err       string `json:"error"`
+	RequestID string `json:"requestId,omitempty"`
+}
+
+type ValidationErrorResponse struct {
+	Message   string              `json:"message"`
+	Errors    []dto.FieldErrorDTO `json:"errors"`
+	RequestID string              `json:"requestId,omitempty"`
 }
 
 func handleBadRequestResponse(c *gin.Context, message string, err error) {
 	badRequestError := ErrorResponse{
-		Message: message,
-		Err:     err.Error(),
+		Message:   message,
+		Err:       err.Error(),
+		RequestID: getCorrelationID(c),
 	}
 	c.JSON(http.StatusBadRequest, badRequestError)
 }
 
+func handleValidationErrorsResponse(c *gin.Context, message string, fieldErrors []dto.FieldErrorDTO) {
+	validationError := ValidationErrorResponse{
+		Message:   message,
+		Errors:    fieldErrors,
+		RequestID: getCorrelationID(c),
+	}
+	c.JSON(http.StatusBadRequest, validationError)
+}
+
+// handleQueryParamErrorsResponse answers every invalid query parameter of the request at once.
+func handleQueryParamErrorsResponse(c *gin.Context, fieldErrors []dto.FieldError) {
+	handleValidationErrorsResponse(c, "invalid query parameters", dto.LocalizeFieldErrors(fieldErrors, getLocale(c)))
+}
+
 func handleNotFoundResponse(c *gin.Context, message string, err error) {
 	notFoundError := ErrorResponse{
-		Message: message,
-		Err:     err.Error(),
+		Message:   message,
+		Err:       err.Error(),
+		RequestID: getCorrelationID(c),
 	}
 	c.JSON(http.StatusNotFound, notFoundError)
 }
 
+func handleUnauthenticatedResponse(c *gin.Context, message string, err error) {
+	unauthenticatedError := ErrorResponse{
+		Message:   message,
+		Err:       err.Error(),
+		RequestID: getCorrelationID(c),
+	}
+	c.JSON(http.StatusUnauthorized, unauthenticatedError)
+}
+
 func handleUnauthorizedResponse(c *gin.Context, message string, err error) {
 	unauthorizedError := ErrorResponse{
-		Message: message,
-		Err:     err.Error(),
+		Message:   message,
+		Err:       err.Error(),
+		RequestID: getCorrelationID(c),
 	}
 	c.JSON(http.StatusForbidden, unauthorizedError)
 }
 
+func handleConflictResponse(c *gin.Context, message string, err error) {
+	conflictError := ErrorResponse{
+		Message:   message,
+		Err:       err.Error(),
+		RequestID: getCorrelationID(c),
+	}
+	c.JSON(http.StatusConflict, conflictError)
+}
+
+func handleMethodNotAllowedResponse(c *gin.Context, message string, err error) {
+	methodNotAllowedError := ErrorResponse{
+		Message:   message,
+		Err:       err.Error(),
+		RequestID: getCorrelationID(c),
+	}
+	c.JSON(http.StatusMethodNotAllowed, methodNotAllowedError)
+}
+
+func handleTooManyRequestsResponse(c *gin.Context, message string, err error) {
+	tooManyRequestsError := ErrorResponse{
+		Message:   message,
+		Err:       err.Error(),
+		RequestID: getCorrelationID(c),
+	}
+	c.JSON(http.StatusTooManyRequests, tooManyRequestsError)
+}
+
 func handleInternalServerResponse(c *gin.Context, message string, err error) {
 	internalServerError := ErrorResponse{
-		Message: message,
-		Err:     err.Error(),
+		Message:   message,
+		Err:       err.Error(),
+		RequestID: getCorrelationID(c),
 	}
 	c.JSON(http.StatusInternalServerError, internalServerError)
 }
+
+func handleServiceUnavailableResponse(c *gin.Context, message string, err error) {
+	serviceUnavailableError := ErrorResponse{
+		Message:   message,
+		Err:       err.Error(),
+		RequestID: getCorrelationID(c),
+	}
+	c.JSON(http.StatusServiceUnavailable, serviceUnavailableError)
+}
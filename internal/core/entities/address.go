@@ -0,0 +1,9 @@
+package entities
+
+type Address struct {
+	Street     string `json:"street"`
+	Number     string `json:"number"`
+	Complement string `json:"complement,omitempty"`
+	Zip        string `json:"zip"`
+	City       string `json:"city"`
+}
@@ -0,0 +1,13 @@
+package entities
+
+import "time"
+
+type Coupon struct {
+	Code string `json:"code"`
+	Type string `json:"type"`
+	// Value is in cents for FIXED coupons and in basis points, hundredths of a percent, for PERCENT coupons.
+	Value int64 `json:"value"`
+	// MinTotal is in cents.
+	MinTotal  int64      `json:"minTotal"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
@@ -1,12 +1,10 @@
 package entities
 
-import "time"
-
 type Customer struct {
 	ID        int       `json:"id"`
 	Name      string    `json:"name"`
 	Cpf       string    `json:"cpf"`
 	Email     string    `json:"email"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	CreatedAt Timestamp `json:"createdAt"`
+	UpdatedAt Timestamp `json:"updatedAt"`
 }
@@ -1,22 +1,30 @@
 package entities
 
-import (
-	"time"
-)
-
 type Order struct {
-	ID          int         `json:"id"`
-	Items       []OrderItem `json:"items"`
-	Coupon      string      `json:"coupon"`
-	TotalAmount float64     `json:"totalAmount"`
-	Customer    Customer    `json:"customer"`
-	Status      string      `json:"status"`
-	CreatedAt   time.Time   `json:"createdAt"`
+	ID             int         `json:"id"`
+	Number         string      `json:"number,omitempty"`
+	Items          []OrderItem `json:"items"`
+	Coupon         string      `json:"coupon"`
+	SubtotalAmount float64     `json:"subtotalAmount,omitempty"`
+	TaxAmount      float64     `json:"taxAmount,omitempty"`
+	TotalAmount    float64     `json:"totalAmount"`
+	// DiscountAmount is the coupon discount taken off the total.
+	DiscountAmount float64 `json:"discountAmount,omitempty"`
+	// StoreCreditAmount is the loyalty credit taken off the total, which is the amount left to pay.
+	StoreCreditAmount float64   `json:"storeCreditAmount,omitempty"`
+	Customer          Customer  `json:"customer"`
+	Status            string    `json:"status"`
+	FulfillmentType   string    `json:"fulfillmentType"`
+	DeliveryAddress   *Address  `json:"deliveryAddress,omitempty"`
+	Notes             string    `json:"notes,omitempty"`
+	CreatedAt         Timestamp `json:"createdAt"`
 }
 
 type OrderItem struct {
-	ID       int     `json:"id"`
-	Product  Product `json:"product"`
-	Quantity int     `json:"quantity"`
-	Type     string  `json:"type"`
+	ID       int             `json:"id"`
+	Product  Product         `json:"product"`
+	Variant  *ProductVariant `json:"variant,omitempty"`
+	Quantity int             `json:"quantity"`
+	Type     string          `json:"type"`
+	Status   string          `json:"status,omitempty"`
 }
@@ -0,0 +1,9 @@
+package entities
+
+type OrderFeedback struct {
+	ID        int       `json:"id"`
+	OrderID   int       `json:"orderId"`
+	Rating    int       `json:"rating"`
+	Comment   string    `json:"comment,omitempty"`
+	CreatedAt Timestamp `json:"createdAt"`
+}
@@ -1,14 +1,23 @@
 package entities
 
-import "time"
-
 type Product struct {
-	ID          int       `json:"id"`
-	Name        string    `json:"name"`
-	SkuId       string    `json:"skuId"`
-	Description string    `json:"description"`
-	Category    string    `json:"category"`
-	Price       float64   `json:"price"`
-	CreatedAt   time.Time `json:"createdAt"`
-	UpdatedAt   time.Time `json:"updatedAt"`
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	SkuId       string `json:"skuId"`
+	Description string `json:"description"`
+	Category    string `json:"category"`
+	// Categories lists every category the product is shown under, the primary Category first.
+	Categories  []string         `json:"categories,omitempty"`
+	TaxCategory string           `json:"taxCategory,omitempty"`
+	Price       float64          `json:"price"`
+	PriceNet    float64          `json:"priceNet,omitempty"`
+	PriceGross  float64          `json:"priceGross,omitempty"`
+	Variants    []ProductVariant `json:"variants,omitempty"`
+	MinQty      int              `json:"minQty,omitempty"`
+	MaxQty      int              `json:"maxQty,omitempty"`
+	Unavailable bool             `json:"unavailable,omitempty"`
+	CreatedAt   Timestamp        `json:"createdAt"`
+	UpdatedAt   Timestamp        `json:"updatedAt"`
+	CreatedBy   string           `json:"createdBy,omitempty"`
+	UpdatedBy   string           `json:"updatedBy,omitempty"`
 }
@@ -0,0 +1,7 @@
+package entities
+
+type ProductPriceChange struct {
+	ProductID int       `json:"productId"`
+	Price     float64   `json:"price"`
+	ChangedAt Timestamp `json:"changedAt"`
+}
@@ -0,0 +1,14 @@
+package entities
+
+type ProductVariant struct {
+	ID          int       `json:"id"`
+	ProductID   int       `json:"productId"`
+	Name        string    `json:"name"`
+	SkuId       string    `json:"skuId"`
+	Price       float64   `json:"price"`
+	PriceNet    float64   `json:"priceNet,omitempty"`
+	PriceGross  float64   `json:"priceGross,omitempty"`
+	TaxCategory string    `json:"taxCategory,omitempty"`
+	CreatedAt   Timestamp `json:"createdAt"`
+	UpdatedAt   Timestamp `json:"updatedAt"`
+}
@@ -0,0 +1,56 @@
+package entities
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+	"time"
+)
+
+// Timestamp is serialized as RFC 3339 in UTC, and as null when it holds the zero time.
+type Timestamp struct {
+	time.Time
+}
+
+func NewTimestamp(t time.Time) Timestamp {
+	return Timestamp{Time: t}
+}
+
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+
+	return []byte(`"` + t.UTC().Format(time.RFC3339) + `"`), nil
+}
+
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		t.Time = time.Time{}
+		return nil
+	}
+
+	parsed, err := time.Parse(`"`+time.RFC3339+`"`, string(data))
+	if err != nil {
+		return err
+	}
+
+	t.Time = parsed.UTC()
+	return nil
+}
+
+func (t *Timestamp) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		t.Time = time.Time{}
+	case time.Time:
+		t.Time = v
+	default:
+		return fmt.Errorf("cannot scan %T into Timestamp", value)
+	}
+	return nil
+}
+
+func (t Timestamp) Value() (driver.Value, error) {
+	return t.Time, nil
+}
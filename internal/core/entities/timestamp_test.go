@@ -0,0 +1,47 @@
+package entities
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimestamp_MarshalJSON(t *testing.T) {
+	saoPaulo := time.FixedZone("America/Sao_Paulo", -3*60*60)
+
+	tests := []struct {
+		name      string
+		timestamp Timestamp
+		want      string
+	}{
+		{
+			name:      "should serialize as RFC 3339 in UTC",
+			timestamp: NewTimestamp(time.Date(2024, 3, 10, 21, 30, 15, 123456789, saoPaulo)),
+			want:      `"2024-03-11T00:30:15Z"`,
+		},
+		{
+			name:      "should serialize the zero time as null",
+			timestamp: Timestamp{},
+			want:      `null`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.timestamp)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestTimestamp_UnmarshalJSON(t *testing.T) {
+	var product Product
+	err := json.Unmarshal([]byte(`{"createdAt":"2024-03-10T21:30:15-03:00","updatedAt":null}`), &product)
+
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 3, 11, 0, 30, 15, 0, time.UTC), product.CreatedAt.Time)
+	assert.True(t, product.UpdatedAt.IsZero())
+}
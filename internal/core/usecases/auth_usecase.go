@@ -1,8 +1,11 @@
 package usecases
 
 import (
+	"errors"
 	"g37-lanchonete/internal/core/usecases/dto"
 	"g37-lanchonete/internal/infra/drivers/auth"
+	"sync"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -13,20 +16,104 @@ type AuthorizerUsecase interface {
 
 type authorizerUsecase struct {
 	authorizer auth.Authorizer
+	cache      *authorizationCache
 }
 
-func NewAuthorizerUsecase(authorizer auth.Authorizer) AuthorizerUsecase {
+type authorizationCacheEntry struct {
+	response  dto.AuthorizerResponse
+	err       error
+	expiresAt time.Time
+}
+
+// authorizationCache keeps the authorizer answers by the digits of the CPF, so the formatted and the bare CPF share an
+// entry. The expired entries are swept on the writes, so the CPFs never looked up again do not pile up.
+type authorizationCache struct {
+	mu          sync.Mutex
+	entries     map[string]authorizationCacheEntry
+	positiveTTL time.Duration
+	negativeTTL time.Duration
+	clock       Clock
+	lastSweep   time.Time
+}
+
+func NewAuthorizerUsecase(authorizer auth.Authorizer, positiveTTL, negativeTTL time.Duration, clock Clock) AuthorizerUsecase {
 	return authorizerUsecase{
 		authorizer: authorizer,
+		cache: &authorizationCache{
+			entries:     map[string]authorizationCacheEntry{},
+			positiveTTL: positiveTTL,
+			negativeTTL: negativeTTL,
+			clock:       clock,
+			lastSweep:   clock.Now(),
+		},
 	}
 }
 
 func (u authorizerUsecase) AuthorizeUser(cpf string) (dto.AuthorizerResponse, error) {
+	key := cpfDigits(cpf)
+	if entry, found := u.cache.get(key); found {
+		return entry.response, entry.err
+	}
+
 	authorizerResponse, err := u.authorizer.AuthorizeUser(cpf)
 	if err != nil {
 		log.Errorf("failed to authorize user, error: %v", err)
+		if errors.Is(err, auth.ErrUnauthorized) {
+			u.cache.put(key, dto.AuthorizerResponse{}, err, u.cache.negativeTTL)
+		}
 		return dto.AuthorizerResponse{}, err
 	}
 
+	u.cache.put(key, authorizerResponse, nil, u.cache.positiveTTL)
 	return authorizerResponse, nil
-}
\ No newline at end of file
+}
+
+func (c *authorizationCache) get(key string) (authorizationCacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, found := c.entries[key]
+	if !found {
+		return authorizationCacheEntry{}, false
+	}
+
+	if !c.clock.Now().Before(entry.expiresAt) {
+		delete(c.entries, key)
+		return authorizationCacheEntry{}, false
+	}
+
+	return entry, true
+}
+
+func (c *authorizationCache) put(key string, response dto.AuthorizerResponse, err error, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.clock.Now()
+	// the expired entries are dropped once per the longest ttl, by then every entry older than the last sweep expired
+	if now.Sub(c.lastSweep) >= c.sweepInterval() {
+		for cachedKey, entry := range c.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(c.entries, cachedKey)
+			}
+		}
+		c.lastSweep = now
+	}
+
+	c.entries[key] = authorizationCacheEntry{
+		response:  response,
+		err:       err,
+		expiresAt: now.Add(ttl),
+	}
+}
+
+func (c *authorizationCache) sweepInterval() time.Duration {
+	if c.negativeTTL > c.positiveTTL {
+		return c.negativeTTL
+	}
+	return c.positiveTTL
+}
@@ -0,0 +1,162 @@
+package usecases
+
+import (
+	"errors"
+	"g37-lanchonete/internal/core/usecases/dto"
+	"g37-lanchonete/internal/infra/drivers/auth"
+	mock_auth "g37-lanchonete/internal/infra/drivers/auth/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func TestAuthorizerUsecase_AuthorizeUser(t *testing.T) {
+	const cpf = "00551146010"
+	authorizedResponse := dto.AuthorizerResponse{UserId: 1, IsAuthorized: true}
+
+	type authorizerCall struct {
+		response dto.AuthorizerResponse
+		err      error
+	}
+	type step struct {
+		advance        time.Duration
+		authorizerCall *authorizerCall
+		wantResponse   dto.AuthorizerResponse
+		wantErr        error
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "should skip the authorizer for a cached positive result within ttl and check again after expiry",
+			steps: []step{
+				{
+					authorizerCall: &authorizerCall{response: authorizedResponse},
+					wantResponse:   authorizedResponse,
+				},
+				{
+					advance:      4 * time.Minute,
+					wantResponse: authorizedResponse,
+				},
+				{
+					advance:        time.Minute,
+					authorizerCall: &authorizerCall{response: authorizedResponse},
+					wantResponse:   authorizedResponse,
+				},
+			},
+		},
+		{
+			name: "should cache a negative result for a shorter ttl",
+			steps: []step{
+				{
+					authorizerCall: &authorizerCall{err: auth.ErrUnauthorized},
+					wantErr:        auth.ErrUnauthorized,
+				},
+				{
+					advance: 20 * time.Second,
+					wantErr: auth.ErrUnauthorized,
+				},
+				{
+					advance:        10 * time.Second,
+					authorizerCall: &authorizerCall{response: authorizedResponse},
+					wantResponse:   authorizedResponse,
+				},
+			},
+		},
+		{
+			name: "should not cache unexpected authorizer errors",
+			steps: []step{
+				{
+					authorizerCall: &authorizerCall{err: errors.New("connection refused")},
+					wantErr:        errors.New("connection refused"),
+				},
+				{
+					authorizerCall: &authorizerCall{response: authorizedResponse},
+					wantResponse:   authorizedResponse,
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		ctrl := gomock.NewController(t)
+		authorizer := mock_auth.NewMockAuthorizer(ctrl)
+		clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+		authorizerUsecase := NewAuthorizerUsecase(authorizer, 5*time.Minute, 30*time.Second, clock)
+
+		for _, s := range tt.steps {
+			clock.Advance(s.advance)
+			if s.authorizerCall != nil {
+				authorizer.
+					EXPECT().
+					AuthorizeUser(gomock.Eq(cpf)).
+					Times(1).
+					Return(s.authorizerCall.response, s.authorizerCall.err)
+			}
+
+			response, err := authorizerUsecase.AuthorizeUser(cpf)
+
+			assert.Equal(t, s.wantResponse, response, tt.name)
+			assert.Equal(t, s.wantErr, err, tt.name)
+		}
+		ctrl.Finish()
+	}
+}
+
+func TestAuthorizerUsecase_AuthorizeUser_FormattedCPF(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	authorizer := mock_auth.NewMockAuthorizer(ctrl)
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	authorizerUsecase := NewAuthorizerUsecase(authorizer, 5*time.Minute, 30*time.Second, clock)
+	authorizedResponse := dto.AuthorizerResponse{UserId: 1, IsAuthorized: true}
+
+	authorizer.EXPECT().AuthorizeUser("005.511.460-10").Return(authorizedResponse, nil).Times(1)
+
+	_, err := authorizerUsecase.AuthorizeUser("005.511.460-10")
+	assert.NoError(t, err)
+	response, err := authorizerUsecase.AuthorizeUser("00551146010")
+
+	assert.NoError(t, err)
+	assert.Equal(t, authorizedResponse, response)
+}
+
+func TestAuthorizerUsecase_AuthorizeUser_SweepsExpiredEntries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	authorizer := mock_auth.NewMockAuthorizer(ctrl)
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	authorizerUsecase := NewAuthorizerUsecase(authorizer, 5*time.Minute, 30*time.Second, clock).(authorizerUsecase)
+
+	authorizer.EXPECT().AuthorizeUser("00551146010").Return(dto.AuthorizerResponse{UserId: 1, IsAuthorized: true}, nil)
+	authorizer.EXPECT().AuthorizeUser("11144477735").Return(dto.AuthorizerResponse{}, auth.ErrUnauthorized)
+	authorizer.EXPECT().AuthorizeUser("52998224725").Return(dto.AuthorizerResponse{UserId: 2, IsAuthorized: true}, nil)
+
+	_, _ = authorizerUsecase.AuthorizeUser("00551146010")
+	_, _ = authorizerUsecase.AuthorizeUser("11144477735")
+	clock.Advance(5 * time.Minute)
+	_, _ = authorizerUsecase.AuthorizeUser("52998224725")
+
+	assert.Equal(t, []string{"52998224725"}, cachedKeys(authorizerUsecase.cache))
+}
+
+func cachedKeys(cache *authorizationCache) []string {
+	keys := make([]string, 0, len(cache.entries))
+	for key := range cache.entries {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,120 @@
+package usecases
+
+import (
+	"fmt"
+	"g37-lanchonete/internal/core/usecases/dto"
+	"strings"
+	"time"
+)
+
+var weekdaysByName = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// BusinessHours decides whether the store takes orders at a given time.
+type BusinessHours interface {
+	CheckOpen(now time.Time) error
+	Day(now time.Time) (start time.Time, end time.Time)
+}
+
+// openingHours are the minutes since midnight the store opens and closes on a weekday.
+type openingHours struct {
+	open  int
+	close int
+}
+
+type businessHours struct {
+	days     map[time.Weekday]openingHours
+	location *time.Location
+}
+
+// NewBusinessHours parses the opening hours of each weekday, given as "HH:MM-HH:MM" by the lowercase english name
+// of the day, in the timezone. Days not listed are closed, and no days at all keep the store always open.
+func NewBusinessHours(days map[string]string, timezone string) (BusinessHours, error) {
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business hours timezone [%s], error: %v", timezone, err)
+	}
+
+	hours := make(map[time.Weekday]openingHours, len(days))
+	for name, value := range days {
+		weekday, found := weekdaysByName[strings.ToLower(strings.TrimSpace(name))]
+		if !found {
+			return nil, fmt.Errorf("invalid business hours weekday [%s]", name)
+		}
+
+		opening, err := parseOpeningHours(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid business hours of %s, error: %v", name, err)
+		}
+		hours[weekday] = opening
+	}
+
+	return businessHours{days: hours, location: location}, nil
+}
+
+func parseOpeningHours(value string) (openingHours, error) {
+	openValue, closeValue, found := strings.Cut(value, "-")
+	if !found {
+		return openingHours{}, fmt.Errorf("[%s] is not in the HH:MM-HH:MM format", value)
+	}
+
+	opensAt, err := time.Parse("15:04", strings.TrimSpace(openValue))
+	if err != nil {
+		return openingHours{}, fmt.Errorf("[%s] is not in the HH:MM-HH:MM format", value)
+	}
+
+	closesAt, err := time.Parse("15:04", strings.TrimSpace(closeValue))
+	if err != nil {
+		return openingHours{}, fmt.Errorf("[%s] is not in the HH:MM-HH:MM format", value)
+	}
+
+	hours := openingHours{open: opensAt.Hour()*60 + opensAt.Minute(), close: closesAt.Hour()*60 + closesAt.Minute()}
+	if hours.close <= hours.open {
+		return openingHours{}, fmt.Errorf("[%s] closes before opening", value)
+	}
+
+	return hours, nil
+}
+
+// CheckOpen returns dto.ErrStoreClosed, with the next opening time in the message, when the store is closed at now.
+func (b businessHours) CheckOpen(now time.Time) error {
+	if len(b.days) == 0 {
+		return nil
+	}
+
+	now = now.In(b.location)
+	minutes := now.Hour()*60 + now.Minute()
+	if hours, found := b.days[now.Weekday()]; found && minutes >= hours.open && minutes < hours.close {
+		return nil
+	}
+
+	// a week ahead covers every listed day, and the same weekday of next week when it already closed today
+	for i := 0; i <= 7; i++ {
+		day := now.AddDate(0, 0, i)
+		hours, found := b.days[day.Weekday()]
+		if !found {
+			continue
+		}
+
+		opening := time.Date(day.Year(), day.Month(), day.Day(), hours.open/60, hours.open%60, 0, 0, b.location)
+		if opening.After(now) {
+			return fmt.Errorf("%w, orders are accepted again at %s", dto.ErrStoreClosed, opening.Format(time.RFC3339))
+		}
+	}
+
+	return dto.ErrStoreClosed
+}
+
+// Day returns the bounds of the day of now in the store timezone, from its midnight to the next one.
+func (b businessHours) Day(now time.Time) (time.Time, time.Time) {
+	now = now.In(b.location)
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, b.location)
+	return start, start.AddDate(0, 0, 1)
+}
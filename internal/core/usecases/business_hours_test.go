@@ -0,0 +1,94 @@
+package usecases
+
+import (
+	"g37-lanchonete/internal/core/usecases/dto"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBusinessHours_CheckOpen(t *testing.T) {
+	saoPaulo, _ := time.LoadLocation("America/Sao_Paulo")
+	days := map[string]string{
+		"monday":   "11:00-23:00",
+		"tuesday":  "11:00-23:00",
+		"Saturday": "12:00-15:30",
+	}
+
+	tests := []struct {
+		name        string
+		days        map[string]string
+		now         time.Time
+		wantMessage string
+	}{
+		{
+			name: "should accept orders at the opening time",
+			days: days,
+			now:  time.Date(2024, 1, 1, 11, 0, 0, 0, saoPaulo),
+		},
+		{
+			name:        "should reject orders at the closing time until the next day opens",
+			days:        days,
+			now:         time.Date(2024, 1, 1, 23, 0, 0, 0, saoPaulo),
+			wantMessage: "store is closed, orders are accepted again at 2024-01-02T11:00:00-03:00",
+		},
+		{
+			name:        "should reject orders before the opening of the same day",
+			days:        days,
+			now:         time.Date(2024, 1, 1, 10, 59, 0, 0, saoPaulo),
+			wantMessage: "store is closed, orders are accepted again at 2024-01-01T11:00:00-03:00",
+		},
+		{
+			name:        "should skip the closed days to the next opening",
+			days:        days,
+			now:         time.Date(2024, 1, 3, 15, 0, 0, 0, saoPaulo),
+			wantMessage: "store is closed, orders are accepted again at 2024-01-06T12:00:00-03:00",
+		},
+		{
+			name:        "should reopen on the same weekday of the next week",
+			days:        map[string]string{"monday": "11:00-23:00"},
+			now:         time.Date(2024, 1, 2, 3, 0, 0, 0, saoPaulo),
+			wantMessage: "store is closed, orders are accepted again at 2024-01-08T11:00:00-03:00",
+		},
+		{
+			name: "should accept orders at any time without business hours",
+			now:  time.Date(2024, 1, 3, 15, 0, 0, 0, saoPaulo),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			businessHours, err := NewBusinessHours(tt.days, "America/Sao_Paulo")
+			assert.NoError(t, err)
+
+			err = businessHours.CheckOpen(tt.now)
+
+			if tt.wantMessage == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, dto.ErrStoreClosed)
+			assert.EqualError(t, err, tt.wantMessage)
+		})
+	}
+}
+
+func TestNewBusinessHours_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		days     map[string]string
+		timezone string
+	}{
+		{name: "should reject unknown weekdays", days: map[string]string{"someday": "11:00-23:00"}, timezone: "UTC"},
+		{name: "should reject hours out of format", days: map[string]string{"monday": "11h-23h"}, timezone: "UTC"},
+		{name: "should reject hours closing before opening", days: map[string]string{"monday": "23:00-02:00"}, timezone: "UTC"},
+		{name: "should reject unknown timezones", timezone: "Mars/Olympus_Mons"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewBusinessHours(tt.days, tt.timezone)
+
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package usecases
+
+import (
+	"g37-lanchonete/internal/core/usecases/dto"
+	"strings"
+)
+
+const (
+	UncategorizedModeNone    = ""
+	UncategorizedModeDefault = "default"
+	UncategorizedModeReject  = "reject"
+)
+
+// CategoryPolicy decides what happens to products saved without a category.
+type CategoryPolicy interface {
+	Apply(category string) (string, error)
+}
+
+type categoryPolicy struct {
+	mode            string
+	defaultCategory string
+}
+
+func NewCategoryPolicy(mode string, defaultCategory string) CategoryPolicy {
+	return categoryPolicy{
+		mode:            strings.ToLower(strings.TrimSpace(mode)),
+		defaultCategory: defaultCategory,
+	}
+}
+
+func (p categoryPolicy) Apply(category string) (string, error) {
+	if strings.TrimSpace(category) != "" {
+		return category, nil
+	}
+
+	switch p.mode {
+	case UncategorizedModeDefault:
+		return p.defaultCategory, nil
+	case UncategorizedModeReject:
+		return "", dto.ErrProductCategoryRequired
+	}
+
+	return category, nil
+}
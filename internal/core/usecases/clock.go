@@ -0,0 +1,17 @@
+package usecases
+
+import "time"
+
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func NewSystemClock() Clock {
+	return systemClock{}
+}
+
+func (c systemClock) Now() time.Time {
+	return time.Now()
+}
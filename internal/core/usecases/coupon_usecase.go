@@ -0,0 +1,100 @@
+package usecases
+
+import (
+	"errors"
+	"g37-lanchonete/internal/core/entities"
+	"g37-lanchonete/internal/core/usecases/dto"
+	"g37-lanchonete/internal/infra/drivers/sql"
+	"g37-lanchonete/internal/infra/gateways"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type CouponUsecase interface {
+	CheckCoupon(code string, checkDTO dto.CouponCheckDTO) (dto.CouponEligibilityDTO, error)
+	GetCouponUsage(from, to time.Time) (dto.CouponUsageReportDTO, error)
+}
+
+type couponUsecase struct {
+	couponRepositoryGateway gateways.CouponRepositoryGateway
+	orderUsecase            OrderUsecase
+	clock                   Clock
+}
+
+func NewCouponUsecase(couponRepositoryGateway gateways.CouponRepositoryGateway, orderUsecase OrderUsecase, clock Clock) CouponUsecase {
+	return couponUsecase{
+		couponRepositoryGateway: couponRepositoryGateway,
+		orderUsecase:            orderUsecase,
+		clock:                   clock,
+	}
+}
+
+// CheckCoupon previews the coupon against a cart priced as an order would be, without applying it. Unknown, expired
+// and below minimum total coupons are reported as not eligible with their reason instead of failing.
+func (u couponUsecase) CheckCoupon(code string, checkDTO dto.CouponCheckDTO) (dto.CouponEligibilityDTO, error) {
+	code = dto.NormalizeCouponCode(code)
+
+	totals, err := u.orderUsecase.PriceItems(checkDTO.Items)
+	if err != nil {
+		log.Errorf("failed to price cart to check coupon [%s], error: %v", code, err)
+		return dto.CouponEligibilityDTO{}, err
+	}
+
+	eligibility := dto.CouponEligibilityDTO{Code: code, Total: totals.Total}
+
+	coupon, err := u.couponRepositoryGateway.FindCouponByCode(code)
+	if err != nil {
+		if errors.Is(err, sql.ErrNotFound) {
+			eligibility.Reason = dto.CouponReasonNotFound
+			return eligibility, nil
+		}
+		log.Errorf("failed to find coupon [%s], error: %v", code, err)
+		return dto.CouponEligibilityDTO{}, err
+	}
+
+	eligibility.Reason = couponIneligibility(coupon, totals.Total, u.clock.Now())
+	if eligibility.Reason == "" {
+		eligibility.Eligible = true
+		eligibility.Discount = couponDiscount(coupon, totals.Total)
+	}
+
+	return eligibility, nil
+}
+
+// GetCouponUsage reports the coupons redeemed by the orders created between from and to, the most used first.
+func (u couponUsecase) GetCouponUsage(from, to time.Time) (dto.CouponUsageReportDTO, error) {
+	usage, err := u.couponRepositoryGateway.GetCouponUsage(from, to)
+	if err != nil {
+		log.Errorf("failed to get coupon usage, error: %v", err)
+		return dto.CouponUsageReportDTO{}, err
+	}
+
+	return dto.CouponUsageReportDTO{From: from, To: to, Coupons: usage}, nil
+}
+
+// couponIneligibility tells why the coupon does not apply to the total at the given time, "" when it does.
+func couponIneligibility(coupon entities.Coupon, total float64, at time.Time) dto.CouponIneligibilityReason {
+	switch {
+	case coupon.ExpiresAt != nil && !at.Before(*coupon.ExpiresAt):
+		return dto.CouponReasonExpired
+	case toCents(total) < coupon.MinTotal:
+		return dto.CouponReasonMinTotalNotMet
+	default:
+		return ""
+	}
+}
+
+// couponDiscount never exceeds the total, so a fixed coupon larger than the cart makes it free. It is computed in
+// cents, rounding half a cent up, so the float precision of the total does not shift the discount by a cent.
+func couponDiscount(coupon entities.Coupon, total float64) float64 {
+	totalCents := toCents(total)
+	if dto.CouponType(coupon.Type) == dto.CouponTypePercent {
+		return fromCents((totalCents*coupon.Value + basisPointsPerUnit/2) / basisPointsPerUnit)
+	}
+
+	if coupon.Value > totalCents {
+		return fromCents(totalCents)
+	}
+	return fromCents(coupon.Value)
+}
@@ -0,0 +1,147 @@
+package usecases
+
+import (
+	"g37-lanchonete/internal/core/entities"
+	"g37-lanchonete/internal/core/usecases/dto"
+	"g37-lanchonete/internal/infra/drivers/sql"
+	mock_gateways "g37-lanchonete/internal/infra/gateways/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCouponUsecase_CheckCoupon(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	yesterday := now.Add(-24 * time.Hour)
+	tomorrow := now.Add(24 * time.Hour)
+
+	tests := []struct {
+		name      string
+		code      string
+		price     float64
+		quantity  int
+		coupon    entities.Coupon
+		couponErr error
+		want      dto.CouponEligibilityDTO
+	}{
+		{
+			name:     "should apply a percent coupon to an eligible cart",
+			code:     " app10 ",
+			quantity: 2,
+			coupon:   entities.Coupon{Code: "APP10", Type: "PERCENT", Value: 1000, MinTotal: 1500, ExpiresAt: &tomorrow},
+			want:     dto.CouponEligibilityDTO{Code: "APP10", Eligible: true, Total: 20, Discount: 2},
+		},
+		{
+			name:     "should cap a fixed coupon to the cart total",
+			code:     "FREE50",
+			quantity: 1,
+			coupon:   entities.Coupon{Code: "FREE50", Type: "FIXED", Value: 5000},
+			want:     dto.CouponEligibilityDTO{Code: "FREE50", Eligible: true, Total: 10, Discount: 10},
+		},
+		{
+			name:     "should round half a cent up on a fractional percent coupon",
+			code:     "APP12",
+			price:    10.04,
+			quantity: 1,
+			coupon:   entities.Coupon{Code: "APP12", Type: "PERCENT", Value: 1250},
+			want:     dto.CouponEligibilityDTO{Code: "APP12", Eligible: true, Total: 10.04, Discount: 1.26},
+		},
+		{
+			name:     "should apply a fixed coupon with cents to a cart of the same minimum total",
+			code:     "MENOS333",
+			price:    1.11,
+			quantity: 3,
+			coupon:   entities.Coupon{Code: "MENOS333", Type: "FIXED", Value: 333, MinTotal: 333},
+			want:     dto.CouponEligibilityDTO{Code: "MENOS333", Eligible: true, Total: 3.33, Discount: 3.33},
+		},
+		{
+			name:     "should not apply an expired coupon",
+			code:     "APP10",
+			quantity: 2,
+			coupon:   entities.Coupon{Code: "APP10", Type: "PERCENT", Value: 1000, ExpiresAt: &yesterday},
+			want:     dto.CouponEligibilityDTO{Code: "APP10", Total: 20, Reason: dto.CouponReasonExpired},
+		},
+		{
+			name:     "should not apply a coupon to a cart below its minimum total",
+			code:     "APP10",
+			quantity: 1,
+			coupon:   entities.Coupon{Code: "APP10", Type: "PERCENT", Value: 1000, MinTotal: 1500},
+			want:     dto.CouponEligibilityDTO{Code: "APP10", Total: 10, Reason: dto.CouponReasonMinTotalNotMet},
+		},
+		{
+			name:      "should report an unknown coupon as not eligible",
+			code:      "NOPE",
+			quantity:  1,
+			couponErr: sql.ErrNotFound,
+			want:      dto.CouponEligibilityDTO{Code: "NOPE", Total: 10, Reason: dto.CouponReasonNotFound},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			productRepositoryGateway := mock_gateways.NewMockProductRepositoryGateway(ctrl)
+			productVariantRepositoryGateway := mock_gateways.NewMockProductVariantRepositoryGateway(ctrl)
+			couponRepositoryGateway := mock_gateways.NewMockCouponRepositoryGateway(ctrl)
+			clock := &fakeClock{now: now}
+
+			price := tt.price
+			if price == 0 {
+				price = 10
+			}
+			productRepositoryGateway.EXPECT().FindProductById(1).Return(entities.Product{ID: 1, Name: "X-Burguer", Price: price}, nil).Times(1)
+			productVariantRepositoryGateway.EXPECT().FindVariantsByProductIds([]int{1}).Return([]entities.ProductVariant{}, nil).Times(1)
+			couponRepositoryGateway.EXPECT().FindCouponByCode(tt.want.Code).Return(tt.coupon, tt.couponErr).Times(1)
+
+			productUsecase := NewProductUsecase(productRepositoryGateway, nil, productVariantRepositoryGateway, NewTaxCalculator(0, nil, 0), NewCategoryPolicy("", ""))
+			orderUsecase := NewOrderUsecase(nil, nil, productUsecase, nil, nil, NewTaxCalculator(0, nil, 0), nil, nil, nil, nil, nil, nil, nil, 0, clock)
+			couponUsecase := NewCouponUsecase(couponRepositoryGateway, orderUsecase, clock)
+
+			got, err := couponUsecase.CheckCoupon(tt.code, dto.CouponCheckDTO{
+				Items: []dto.OrderItemDTO{{ProductId: 1, Quantity: tt.quantity, Type: dto.OrderItemTypeUnit}},
+			})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCouponUsecase_CheckCoupon_MissingProduct(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	productRepositoryGateway := mock_gateways.NewMockProductRepositoryGateway(ctrl)
+	couponRepositoryGateway := mock_gateways.NewMockCouponRepositoryGateway(ctrl)
+	clock := &fakeClock{}
+
+	productRepositoryGateway.EXPECT().FindProductById(99).Return(entities.Product{}, sql.ErrNotFound).Times(2)
+	couponRepositoryGateway.EXPECT().FindCouponByCode(gomock.Any()).Times(0)
+
+	productUsecase := NewProductUsecase(productRepositoryGateway, nil, nil, NewTaxCalculator(0, nil, 0), NewCategoryPolicy("", ""))
+	orderUsecase := NewOrderUsecase(nil, nil, productUsecase, nil, nil, NewTaxCalculator(0, nil, 0), nil, nil, nil, nil, nil, nil, nil, 0, clock)
+	couponUsecase := NewCouponUsecase(couponRepositoryGateway, orderUsecase, clock)
+
+	_, err := couponUsecase.CheckCoupon("APP10", dto.CouponCheckDTO{
+		Items: []dto.OrderItemDTO{{ProductId: 99, Quantity: 1, Type: dto.OrderItemTypeUnit}},
+	})
+
+	assert.Equal(t, dto.OrderItemProductNotFoundError{ProductID: 99}, err)
+}
+
+func TestCouponUsecase_GetCouponUsage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	couponRepositoryGateway := mock_gateways.NewMockCouponRepositoryGateway(ctrl)
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	usage := []dto.CouponUsageDTO{
+		{Code: "APP10", Redemptions: 12, DiscountTotal: 540.5},
+		{Code: "FREE50", Redemptions: 3, DiscountTotal: 97.2},
+	}
+	couponRepositoryGateway.EXPECT().GetCouponUsage(from, to).Return(usage, nil).Times(1)
+	couponUsecase := NewCouponUsecase(couponRepositoryGateway, nil, &fakeClock{})
+
+	report, err := couponUsecase.GetCouponUsage(from, to)
+
+	assert.NoError(t, err)
+	assert.Equal(t, dto.CouponUsageReportDTO{From: from, To: to, Coupons: usage}, report)
+}
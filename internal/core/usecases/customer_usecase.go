@@ -27,8 +27,8 @@ func NewCustomerUsecase(customerRepository gateways.CustomerRepositoryGateway) C
 
 func (u customerUsecase) CreateCustomer(customerDTO dto.CustomerDTO) error {
 	customer := customerDTO.ToCustomer()
-	customer.CreatedAt = time.Now()
-	customer.UpdatedAt = time.Now()
+	customer.CreatedAt = entities.NewTimestamp(time.Now())
+	customer.UpdatedAt = entities.NewTimestamp(time.Now())
 
 	err := u.customerRepositoryGateway.SaveCustomer(customer)
 	if err != nil {
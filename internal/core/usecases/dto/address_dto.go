@@ -0,0 +1,62 @@
+package dto
+
+import (
+	"fmt"
+	"g37-lanchonete/internal/core/entities"
+	"regexp"
+
+	"github.com/asaskevich/govalidator"
+)
+
+var cepRegex = regexp.MustCompile(`^\d{5}-?\d{3}$`)
+
+type AddressDTO struct {
+	Street     string `json:"street" valid:"required~Street is required,length(1|150)~Street length should be less than 150 characters"`
+	Number     string `json:"number" valid:"required~Number is required,length(1|20)~Number length should be less than 20 characters"`
+	Complement string `json:"complement" valid:"length(0|100)~Complement length should be less than 100 characters"`
+	Zip        string `json:"zip" valid:"required~Zip is required"`
+	City       string `json:"city" valid:"required~City is required,length(1|100)~City length should be less than 100 characters"`
+}
+
+type InvalidCEPError struct {
+	CEP string
+}
+
+func (e InvalidCEPError) Error() string {
+	return fmt.Sprintf("invalid CEP [%s]", e.CEP)
+}
+
+func (a AddressDTO) ToAddress() entities.Address {
+	return entities.Address{
+		Street:     a.Street,
+		Number:     a.Number,
+		Complement: a.Complement,
+		Zip:        a.Zip,
+		City:       a.City,
+	}
+}
+
+func (a AddressDTO) ValidateAddress() (bool, error) {
+	if _, err := govalidator.ValidateStruct(a); err != nil {
+		return false, err
+	}
+
+	if !cepRegex.MatchString(a.Zip) {
+		return false, InvalidCEPError{CEP: a.Zip}
+	}
+
+	return true, nil
+}
+
+func (a AddressDTO) collectValidationErrors(prefix string) []FieldError {
+	fieldErrors := []FieldError{}
+	if _, err := govalidator.ValidateStruct(a); err != nil {
+		fieldErrors = append(fieldErrors, collectFieldErrors(err, prefix)...)
+	}
+
+	if a.Zip != "" && !cepRegex.MatchString(a.Zip) {
+		fieldErrors = append(fieldErrors, FieldError{Field: joinFieldPath(prefix, "zip"), Err: InvalidCEPError{CEP: a.Zip}})
+	}
+
+	return fieldErrors
+}
@@ -0,0 +1,81 @@
+package dto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddressDTO_ValidateAddress(t *testing.T) {
+	type want struct {
+		valid  bool
+		errMsg string
+	}
+	tests := []struct {
+		name    string
+		address AddressDTO
+		want
+	}{
+		{
+			name: "should validate a complete address",
+			address: AddressDTO{
+				Street:     "Rua das Flores",
+				Number:     "123",
+				Complement: "Apto 42",
+				Zip:        "01310-100",
+				City:       "São Paulo",
+			},
+			want: want{
+				valid: true,
+			},
+		},
+		{
+			name: "should validate a zip without hyphen",
+			address: AddressDTO{
+				Street: "Rua das Flores",
+				Number: "123",
+				Zip:    "01310100",
+				City:   "São Paulo",
+			},
+			want: want{
+				valid: true,
+			},
+		},
+		{
+			name: "should not validate an invalid CEP",
+			address: AddressDTO{
+				Street: "Rua das Flores",
+				Number: "123",
+				Zip:    "1310-10A",
+				City:   "São Paulo",
+			},
+			want: want{
+				valid:  false,
+				errMsg: "invalid CEP [1310-10A]",
+			},
+		},
+		{
+			name: "should not validate an address without street",
+			address: AddressDTO{
+				Number: "123",
+				Zip:    "01310-100",
+				City:   "São Paulo",
+			},
+			want: want{
+				valid:  false,
+				errMsg: "Street is required",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		valid, err := tt.address.ValidateAddress()
+
+		assert.Equal(t, tt.want.valid, valid, tt.name)
+		if tt.want.errMsg == "" {
+			assert.NoError(t, err, tt.name)
+		} else {
+			assert.EqualError(t, err, tt.want.errMsg, tt.name)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package dto
+
+import (
+	"errors"
+	"time"
+)
+
+type CouponType string
+
+const (
+	CouponTypeFixed   CouponType = "FIXED"
+	CouponTypePercent CouponType = "PERCENT"
+)
+
+type CouponIneligibilityReason string
+
+const (
+	CouponReasonNotFound       CouponIneligibilityReason = "NOT_FOUND"
+	CouponReasonExpired        CouponIneligibilityReason = "EXPIRED"
+	CouponReasonMinTotalNotMet CouponIneligibilityReason = "MIN_TOTAL_NOT_MET"
+)
+
+var ErrCouponCartEmpty = errors.New("items must not be empty")
+
+type CouponCheckDTO struct {
+	Items []OrderItemDTO `json:"items"`
+}
+
+func (c CouponCheckDTO) Validate() (bool, error) {
+	if len(c.Items) == 0 {
+		return false, ErrCouponCartEmpty
+	}
+
+	for _, item := range c.Items {
+		if _, err := item.Validate(); err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// CouponEligibilityDTO tells whether the coupon applies to a cart. The discount is only set when it does, and the
+// reason only when it does not.
+type CouponEligibilityDTO struct {
+	Code     string                    `json:"code"`
+	Eligible bool                      `json:"eligible"`
+	Total    float64                   `json:"total"`
+	Discount float64                   `json:"discount"`
+	Reason   CouponIneligibilityReason `json:"reason,omitempty"`
+}
+
+// CouponUsageDTO sums the discounts granted by a coupon, the orders still waiting for payment or cancelled are not counted.
+type CouponUsageDTO struct {
+	Code          string  `json:"code"`
+	Redemptions   int     `json:"redemptions"`
+	DiscountTotal float64 `json:"discountTotal"`
+}
+
+type CouponUsageReportDTO struct {
+	From    time.Time        `json:"from"`
+	To      time.Time        `json:"to"`
+	Coupons []CouponUsageDTO `json:"coupons"`
+}
@@ -28,34 +28,55 @@ func (c CustomerDTO) ValidateCustomer() (bool, error) {
 	}
 
 	// Validate CPF using a custom function
-	if !isValidCPF(c.CPF) {
-		return false, fmt.Errorf("invalid CPF [%s]", c.CPF)
+	if err := ValidateCPF(c.CPF); err != nil {
+		return false, err
 	}
 
 	return true, nil
 }
 
-func isValidCPF(cpf string) bool {
-	cpf = strings.Replace(cpf, ".", "", -1)
-	cpf = strings.Replace(cpf, "-", "", -1)
+type InvalidCPFReason string
+
+const (
+	InvalidCPFLength         InvalidCPFReason = "must have 11 digits"
+	InvalidCPFCharacters     InvalidCPFReason = "must contain only digits"
+	InvalidCPFRepeatedDigits InvalidCPFReason = "must not have all digits the same"
+	InvalidCPFCheckDigits    InvalidCPFReason = "check digits do not match"
+)
+
+type InvalidCPFError struct {
+	CPF    string
+	Reason InvalidCPFReason
+}
+
+func (e InvalidCPFError) Error() string {
+	return fmt.Sprintf("invalid CPF [%s], %s", e.CPF, e.Reason)
+}
+
+// ValidateCPF returns an InvalidCPFError telling why the CPF was rejected, or nil when it is valid.
+func ValidateCPF(cpf string) error {
+	digits := strings.Replace(cpf, ".", "", -1)
+	digits = strings.Replace(digits, "-", "", -1)
 
-	if len(cpf) != 11 {
-		return false
+	if len(digits) != 11 {
+		return InvalidCPFError{CPF: cpf, Reason: InvalidCPFLength}
 	}
 
-	if strings.Count(cpf, string(cpf[0])) == 11 {
-		return false
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return InvalidCPFError{CPF: cpf, Reason: InvalidCPFCharacters}
+		}
 	}
 
-	// Check if all digits are the same
-	if cpf == "00000000000" {
-		return false
+	// Repeated digits, like 11111111111, pass the check digits algorithm but are not valid CPFs
+	if strings.Count(digits, string(digits[0])) == 11 {
+		return InvalidCPFError{CPF: cpf, Reason: InvalidCPFRepeatedDigits}
 	}
 
 	// Validate CPF using the standard algorithm
 	var sum1, sum2 int
 	for i := 0; i < 9; i++ {
-		digit := int(cpf[i] - '0')
+		digit := int(digits[i] - '0')
 		sum1 += digit * (10 - i)
 		sum2 += digit * (11 - i)
 	}
@@ -75,5 +96,9 @@ func isValidCPF(cpf string) bool {
 		sum2 = 11 - sum2
 	}
 
-	return cpf[9]-'0' == byte(sum1) && cpf[10]-'0' == byte(sum2)
+	if digits[9]-'0' != byte(sum1) || digits[10]-'0' != byte(sum2) {
+		return InvalidCPFError{CPF: cpf, Reason: InvalidCPFCheckDigits}
+	}
+
+	return nil
 }
@@ -0,0 +1,109 @@
+package dto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateCPF(t *testing.T) {
+	tests := []struct {
+		name string
+		cpf  string
+		want error
+	}{
+		{
+			name: "should validate a CPF with only digits",
+			cpf:  "52998224725",
+		},
+		{
+			name: "should validate a formatted CPF",
+			cpf:  "529.982.247-25",
+		},
+		{
+			name: "should reject a CPF with less than 11 digits",
+			cpf:  "5299822472",
+			want: InvalidCPFError{CPF: "5299822472", Reason: InvalidCPFLength},
+		},
+		{
+			name: "should reject a CPF with more than 11 digits",
+			cpf:  "529982247250",
+			want: InvalidCPFError{CPF: "529982247250", Reason: InvalidCPFLength},
+		},
+		{
+			name: "should reject an empty CPF",
+			cpf:  "",
+			want: InvalidCPFError{CPF: "", Reason: InvalidCPFLength},
+		},
+		{
+			name: "should reject a CPF with letters",
+			cpf:  "5299822472A",
+			want: InvalidCPFError{CPF: "5299822472A", Reason: InvalidCPFCharacters},
+		},
+		{
+			name: "should reject a CPF with all digits the same",
+			cpf:  "11111111111",
+			want: InvalidCPFError{CPF: "11111111111", Reason: InvalidCPFRepeatedDigits},
+		},
+		{
+			name: "should reject a formatted CPF with all digits zero",
+			cpf:  "000.000.000-00",
+			want: InvalidCPFError{CPF: "000.000.000-00", Reason: InvalidCPFRepeatedDigits},
+		},
+		{
+			name: "should reject a CPF with a wrong first check digit",
+			cpf:  "52998224735",
+			want: InvalidCPFError{CPF: "52998224735", Reason: InvalidCPFCheckDigits},
+		},
+		{
+			name: "should reject a CPF with a wrong second check digit",
+			cpf:  "52998224726",
+			want: InvalidCPFError{CPF: "52998224726", Reason: InvalidCPFCheckDigits},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCPF(tt.cpf)
+
+			assert.Equal(t, tt.want, err)
+		})
+	}
+}
+
+func TestLocalizeValidationError_InvalidCPF(t *testing.T) {
+	tests := []struct {
+		name   string
+		reason InvalidCPFReason
+		want   string
+	}{
+		{
+			name:   "should translate the wrong length reason",
+			reason: InvalidCPFLength,
+			want:   "CPF inválido [123], deve ter 11 dígitos",
+		},
+		{
+			name:   "should translate the invalid characters reason",
+			reason: InvalidCPFCharacters,
+			want:   "CPF inválido [123], deve conter apenas números",
+		},
+		{
+			name:   "should translate the repeated digits reason",
+			reason: InvalidCPFRepeatedDigits,
+			want:   "CPF inválido [123], não pode ter todos os dígitos iguais",
+		},
+		{
+			name:   "should translate the check digits reason",
+			reason: InvalidCPFCheckDigits,
+			want:   "CPF inválido [123], dígitos verificadores não conferem",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := LocalizeValidationError(InvalidCPFError{CPF: "123", Reason: tt.reason}, LocalePtBR)
+
+			assert.EqualError(t, err, tt.want)
+		})
+	}
+}
@@ -0,0 +1,7 @@
+package dto
+
+type OrderConfirmationMessage struct {
+	Subject string
+	HTML    string
+	Text    string
+}
@@ -0,0 +1,49 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const MaxBatchOrders = 50
+
+type BatchOrderStatus string
+
+const (
+	BatchOrderStatusCreated BatchOrderStatus = "CREATED"
+	BatchOrderStatusInvalid BatchOrderStatus = "INVALID"
+	BatchOrderStatusFailed  BatchOrderStatus = "FAILED"
+)
+
+type BatchOrdersDTO struct {
+	Orders []OrderDTO `json:"orders"`
+}
+
+// Validate only checks the size of the batch, each order is validated on its own when created.
+func (b BatchOrdersDTO) Validate() (bool, error) {
+	if len(b.Orders) == 0 {
+		return false, errors.New("orders must not be empty")
+	}
+
+	if len(b.Orders) > MaxBatchOrders {
+		return false, fmt.Errorf("at most %d orders are allowed per request", MaxBatchOrders)
+	}
+
+	return true, nil
+}
+
+// BatchOrderResultDTO reports the order at Index of the batch, with the fields of OrderCreationResponse once created.
+type BatchOrderResultDTO struct {
+	Index     int              `json:"index"`
+	Status    BatchOrderStatus `json:"status"`
+	OrderID   int              `json:"orderId,omitempty"`
+	Number    string           `json:"orderNumber,omitempty"`
+	QRCode    string           `json:"qrCode,omitempty"`
+	ExpiresAt *time.Time       `json:"expiresAt,omitempty"`
+	Error     string           `json:"error,omitempty"`
+}
+
+type BatchOrdersResponseDTO struct {
+	Results []BatchOrderResultDTO `json:"results"`
+}
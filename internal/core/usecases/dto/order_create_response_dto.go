@@ -1,6 +1,24 @@
 package dto
 
+import (
+	"g37-lanchonete/internal/core/entities"
+	"time"
+)
+
 type OrderCreationResponse struct {
-	QRCode  string `json:"qrCode"`
-	OrderID int    `json:"orderId"`
+	QRCode string `json:"qrCode,omitempty"`
+	// ExpiresAt is when the payment qrcode can no longer be paid, omitted when the payments do not expire.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	OrderID   int        `json:"orderId"`
+	Number    string     `json:"orderNumber,omitempty"`
+}
+
+type OrderItemsUpdateResponse struct {
+	OrderID        int                  `json:"orderId"`
+	Items          []entities.OrderItem `json:"items"`
+	SubtotalAmount float64              `json:"subtotalAmount"`
+	TaxAmount      float64              `json:"taxAmount"`
+	TotalAmount    float64              `json:"totalAmount"`
+	QRCode         string               `json:"qrCode,omitempty"`
+	ExpiresAt      *time.Time           `json:"expiresAt,omitempty"`
 }
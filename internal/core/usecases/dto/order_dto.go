@@ -1,8 +1,10 @@
 package dto
 
 import (
+	"errors"
 	"fmt"
 	"g37-lanchonete/internal/core/entities"
+	"strings"
 	"time"
 
 	"github.com/asaskevich/govalidator"
@@ -17,10 +19,86 @@ const (
 	OrderStatusInProgress OrderStatus = "IN_PROGRESS"
 	OrderStatusReady      OrderStatus = "READY"
 	OrderStatusDone       OrderStatus = "DONE"
+	// OrderStatusCancelled is final and stays out of the configurable flow, only unpaid orders are cancelled.
+	OrderStatusCancelled OrderStatus = "CANCELLED"
 )
 
 type OrderStatusDTO struct {
 	Status OrderStatus `json:"status" valid:"in(CREATED|PAID|RECEIVED|IN_PROGRESS|READY|DONE),required~Status is invalid"`
+	// UpdatedAt is when the order got into its status, answered in the Last-Modified header.
+	UpdatedAt time.Time `json:"-" valid:"-"`
+}
+
+type OrderStatusUpdateDTO struct {
+	OrderStatusDTO `valid:"-"`
+	// ExpectedStatus makes the update apply only while the order is still in it, so concurrent terminals do not
+	// clobber each other's updates.
+	ExpectedStatus OrderStatus `json:"expectedStatus,omitempty" valid:"in(CREATED|PAID|RECEIVED|IN_PROGRESS|READY|DONE)~Expected status is invalid"`
+}
+
+// Normalize tolerates the statuses sent in lowercase or with surrounding spaces, so " paid" is "PAID".
+func (o OrderStatusUpdateDTO) Normalize() OrderStatusUpdateDTO {
+	o.Status = NormalizeOrderStatus(o.Status)
+	o.ExpectedStatus = NormalizeOrderStatus(o.ExpectedStatus)
+	return o
+}
+
+func (o OrderStatusUpdateDTO) Validate() (bool, error) {
+	if valid, err := o.OrderStatusDTO.Validate(); !valid {
+		return false, err
+	}
+
+	if _, err := govalidator.ValidateStruct(o); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+type OrderStatusWithNextDTO struct {
+	OrderStatusDTO
+	AllowedNext []OrderStatus `json:"allowedNext"`
+}
+
+const MaxOrderStatusesIds = 100
+
+type OrderStatusesDTO struct {
+	Statuses map[int]OrderStatus `json:"statuses"`
+	NotFound []int               `json:"notFound"`
+}
+
+const (
+	// DefaultCompletedOrdersWindow is used when the completed orders are requested without a start time.
+	DefaultCompletedOrdersWindow = 30 * time.Minute
+	MaxCompletedOrders           = 50
+)
+
+// StatusTransitionStatsDTO tells how long the orders stayed in From before moving to To, in seconds.
+type StatusTransitionStatsDTO struct {
+	From           OrderStatus `json:"from"`
+	To             OrderStatus `json:"to"`
+	Count          int         `json:"count"`
+	AverageSeconds float64     `json:"averageSeconds"`
+	P50Seconds     float64     `json:"p50Seconds"`
+	P90Seconds     float64     `json:"p90Seconds"`
+	P95Seconds     float64     `json:"p95Seconds"`
+}
+
+type OrderThroughputDTO struct {
+	From        time.Time                  `json:"from"`
+	To          time.Time                  `json:"to"`
+	Transitions []StatusTransitionStatsDTO `json:"transitions"`
+}
+
+type UnpaidOrdersCancellationDTO struct {
+	CustomerCPF string `json:"customerCpf"`
+	Cancelled   int    `json:"cancelled"`
+}
+
+type CompletedOrderDTO struct {
+	OrderID     int       `json:"orderId"`
+	Number      string    `json:"orderNumber,omitempty"`
+	CompletedAt time.Time `json:"completedAt"`
 }
 
 func (o OrderStatusDTO) Validate() (bool, error) {
@@ -28,9 +106,110 @@ func (o OrderStatusDTO) Validate() (bool, error) {
 		return false, err
 	}
 
+	if err := validateOrderStatusEnabled(o.Status); err != nil {
+		return false, err
+	}
+
 	return true, nil
 }
 
+type FulfillmentType string
+
+const (
+	FulfillmentTypeDineIn   FulfillmentType = "DINE_IN"
+	FulfillmentTypeTakeaway FulfillmentType = "TAKEAWAY"
+	FulfillmentTypeDelivery FulfillmentType = "DELIVERY"
+)
+
+var (
+	ErrDeliveryAddressRequired = errors.New("delivery address is required for DELIVERY orders")
+	ErrOrderQRCodeNotFound     = errors.New("order has no payment qrcode")
+	ErrOrderPaymentNotPending  = errors.New("order payment is not pending")
+	ErrOrderNotEditable        = errors.New("order is no longer editable")
+	ErrOrderItemNotFound       = errors.New("order item not found")
+	ErrOrderLastItem           = errors.New("order must keep at least one item")
+	ErrStoreClosed             = errors.New("store is closed")
+	ErrOrderNotReopenable      = errors.New("order can not be reopened")
+	ErrStoreCreditUnavailable  = errors.New("store credit is not available")
+	ErrStoreCreditExceeded     = errors.New("store credit exceeds the customer balance")
+	ErrUnsupportedCurrency     = errors.New("currency is not supported")
+	ErrOrderStatusConflict     = errors.New("order is no longer in the expected status")
+	ErrUnknownStation          = errors.New("station is unknown")
+)
+
+func IsValidFulfillmentType(fulfillmentType string) bool {
+	switch FulfillmentType(fulfillmentType) {
+	case FulfillmentTypeDineIn, FulfillmentTypeTakeaway, FulfillmentTypeDelivery:
+		return true
+	}
+	return false
+}
+
+// DisplayTotalDTO is the order total converted to the currency asked by the client, along with the base amount charged.
+type DisplayTotalDTO struct {
+	BaseCurrency string  `json:"baseCurrency"`
+	BaseAmount   float64 `json:"baseAmount"`
+	Currency     string  `json:"currency"`
+	Rate         float64 `json:"rate"`
+	Amount       float64 `json:"amount"`
+}
+
+type OrderDetailDTO struct {
+	entities.Order
+	DisplayTotal *DisplayTotalDTO `json:"displayTotal,omitempty"`
+}
+
+// LineTotalLimitError is returned when the quantity times the price of an order item goes over the allowed amount.
+type LineTotalLimitError struct {
+	ProductID    int
+	Quantity     int
+	MaxLineTotal float64
+}
+
+func (e LineTotalLimitError) Error() string {
+	return fmt.Sprintf("product [%d] with %d units goes over the maximum item total of %.2f", e.ProductID, e.Quantity, e.MaxLineTotal)
+}
+
+type OrderTotals struct {
+	Subtotal float64
+	TaxRate  float64
+	Tax      float64
+	Discount float64
+	// StoreCredit is the credit taken off the total, clamped to what was left to pay.
+	StoreCredit float64
+	Total       float64
+}
+
+type OrderSort string
+
+const (
+	OrderSortNewest OrderSort = "newest"
+	OrderSortOldest OrderSort = "oldest"
+	// OrderSortPriority lists the READY orders first, then IN_PROGRESS and RECEIVED, the oldest first within them.
+	OrderSortPriority OrderSort = "priority"
+)
+
+func IsValidOrderSort(sort string) bool {
+	switch OrderSort(sort) {
+	case OrderSortNewest, OrderSortOldest, OrderSortPriority:
+		return true
+	}
+	return false
+}
+
+type OrderFilters struct {
+	FulfillmentType string
+	Statuses        []string
+	// Sort overrides the default sort of the orders when set.
+	Sort OrderSort
+	// Today bounds the orders to the ones created in the current day, filling CreatedFrom and CreatedBefore.
+	Today         bool
+	CreatedFrom   time.Time
+	CreatedBefore time.Time
+	// Station lists on the queue only the orders with items prepared at it, and only those items.
+	Station string
+}
+
 type OrderItemType string
 
 const (
@@ -41,51 +220,146 @@ const (
 
 type OrderItemDTO struct {
 	ProductId int           `json:"productId"`
+	VariantId int           `json:"variantId,omitempty"`
 	Quantity  int           `json:"quantity" valid:"int,required~Quantity is required|range(1|)~Quantity greater than 0"`
 	Type      OrderItemType `json:"type" valid:"in(UNIT|COMBO|CUSTOM_COMBO),required~Type is invalid"`
 }
 
-func (o OrderItemDTO) toOrderItem() entities.OrderItem {
+func (o OrderItemDTO) Validate() (bool, error) {
+	if _, err := govalidator.ValidateStruct(o); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (o OrderItemDTO) ToOrderItem() entities.OrderItem {
+	var variant *entities.ProductVariant
+	if o.VariantId != 0 {
+		variant = &entities.ProductVariant{ID: o.VariantId, ProductID: o.ProductId}
+	}
+
 	return entities.OrderItem{
 		Product: entities.Product{
 			ID: o.ProductId,
 		},
+		Variant:  variant,
 		Quantity: o.Quantity,
 		Type:     string(o.Type),
 	}
 }
 
 type OrderDTO struct {
-	Items       []OrderItemDTO `json:"items"`
-	Coupon      string         `json:"coupon" valid:"length(0|100)~Description length should be less than 100 characters"`
-	CustomerCPF string         `json:"customerCpf"`
-	Status      OrderStatus    `json:"status" valid:"in(CREATED|PAID|RECEIVED|IN_PROGRESS|READY|DONE),required~Status is invalid"`
+	Items           []OrderItemDTO  `json:"items"`
+	Coupon          string          `json:"coupon" valid:"length(0|100)~Description length should be less than 100 characters"`
+	CustomerCPF     string          `json:"customerCpf"`
+	Status          OrderStatus     `json:"status" valid:"in(CREATED|PAID|RECEIVED|IN_PROGRESS|READY|DONE),required~Status is invalid"`
+	FulfillmentType FulfillmentType `json:"fulfillmentType" valid:"in(DINE_IN|TAKEAWAY|DELIVERY)~Fulfillment type is invalid"`
+	DeliveryAddress *AddressDTO     `json:"deliveryAddress" valid:"-"`
+	Notes           string          `json:"notes" valid:"length(0|500)~Notes length should be less than 500 characters"`
+	// StoreCreditToApply is the amount of the customer store credit used to pay part of the order.
+	StoreCreditToApply float64 `json:"storeCreditToApply,omitempty" valid:"range(0|)~Store credit should not be negative"`
 }
 
 func (o OrderDTO) ToOrder(customer entities.Customer) entities.Order {
 	orderItems := make([]entities.OrderItem, len(o.Items))
 	for i, item := range o.Items {
-		orderItems[i] = item.toOrderItem()
+		orderItems[i] = item.ToOrderItem()
+	}
+
+	fulfillmentType := o.FulfillmentType
+	if fulfillmentType == "" {
+		fulfillmentType = FulfillmentTypeDineIn
+	}
+
+	var deliveryAddress *entities.Address
+	if fulfillmentType == FulfillmentTypeDelivery && o.DeliveryAddress != nil {
+		address := o.DeliveryAddress.ToAddress()
+		deliveryAddress = &address
 	}
 
 	return entities.Order{
-		Items:     orderItems,
-		Coupon:    o.Coupon,
-		Customer:  customer,
-		Status:    string(o.Status),
-		CreatedAt: time.Now(),
+		Items:           orderItems,
+		Coupon:          NormalizeCouponCode(o.Coupon),
+		Customer:        customer,
+		Status:          string(o.Status),
+		FulfillmentType: string(fulfillmentType),
+		DeliveryAddress: deliveryAddress,
+		Notes:           o.Notes,
+		CreatedAt:       entities.NewTimestamp(time.Now()),
 	}
 }
 
+func NormalizeOrderStatus(status OrderStatus) OrderStatus {
+	return OrderStatus(strings.ToUpper(strings.TrimSpace(string(status))))
+}
+
+// NormalizeCouponCode makes coupon codes match regardless of letter case and surrounding spaces, so "app10" is "APP10".
+func NormalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
 func (o OrderDTO) ValidateOrder() (bool, error) {
 	if _, err := govalidator.ValidateStruct(o); err != nil {
 		return false, err
 	}
 
+	if err := validateOrderStatusEnabled(o.Status); err != nil {
+		return false, err
+	}
+
 	// Validate CPF using a custom function
-	if !isValidCPF(o.CustomerCPF) {
-		return false, fmt.Errorf("invalid CPF [%s]", o.CustomerCPF)
+	if err := ValidateCPF(o.CustomerCPF); err != nil {
+		return false, err
+	}
+
+	if o.FulfillmentType == FulfillmentTypeDelivery {
+		if o.DeliveryAddress == nil {
+			return false, ErrDeliveryAddressRequired
+		}
+
+		if valid, err := o.DeliveryAddress.ValidateAddress(); !valid {
+			return false, err
+		}
 	}
 
 	return true, nil
-}
\ No newline at end of file
+}
+
+// CollectValidationErrors runs the same validations of ValidateOrder, but reports all the failures instead of the first one.
+func (o OrderDTO) CollectValidationErrors() []FieldError {
+	// the items are validated one by one, so their errors point to the item index
+	order := o
+	order.Items = nil
+
+	fieldErrors := []FieldError{}
+	if _, err := govalidator.ValidateStruct(order); err != nil {
+		fieldErrors = append(fieldErrors, collectFieldErrors(err, "")...)
+	}
+
+	for i, item := range o.Items {
+		if _, err := govalidator.ValidateStruct(item); err != nil {
+			fieldErrors = append(fieldErrors, collectFieldErrors(err, fmt.Sprintf("items[%d]", i))...)
+		}
+	}
+
+	if isKnownOrderStatus(o.Status) {
+		if err := validateOrderStatusEnabled(o.Status); err != nil {
+			fieldErrors = append(fieldErrors, FieldError{Field: "status", Err: err})
+		}
+	}
+
+	if err := ValidateCPF(o.CustomerCPF); err != nil {
+		fieldErrors = append(fieldErrors, FieldError{Field: "customerCpf", Err: err})
+	}
+
+	if o.FulfillmentType == FulfillmentTypeDelivery {
+		if o.DeliveryAddress == nil {
+			fieldErrors = append(fieldErrors, FieldError{Field: "deliveryAddress", Err: ErrDeliveryAddressRequired})
+		} else {
+			fieldErrors = append(fieldErrors, o.DeliveryAddress.collectValidationErrors("deliveryAddress")...)
+		}
+	}
+
+	return fieldErrors
+}
@@ -0,0 +1,162 @@
+package dto
+
+import (
+	"g37-lanchonete/internal/core/entities"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderDTO_ValidateOrder(t *testing.T) {
+	type args struct {
+		fulfillmentType FulfillmentType
+		deliveryAddress *AddressDTO
+	}
+	type want struct {
+		valid bool
+		err   error
+	}
+	tests := []struct {
+		name string
+		args
+		want
+	}{
+		{
+			name: "should validate a dine in order",
+			args: args{
+				fulfillmentType: FulfillmentTypeDineIn,
+			},
+			want: want{
+				valid: true,
+			},
+		},
+		{
+			name: "should validate a takeaway order",
+			args: args{
+				fulfillmentType: FulfillmentTypeTakeaway,
+			},
+			want: want{
+				valid: true,
+			},
+		},
+		{
+			name: "should validate a delivery order with address",
+			args: args{
+				fulfillmentType: FulfillmentTypeDelivery,
+				deliveryAddress: &AddressDTO{
+					Street: "Rua das Flores",
+					Number: "123",
+					Zip:    "01310-100",
+					City:   "São Paulo",
+				},
+			},
+			want: want{
+				valid: true,
+			},
+		},
+		{
+			name: "should validate an order without fulfillment type",
+			args: args{},
+			want: want{
+				valid: true,
+			},
+		},
+		{
+			name: "should not validate a delivery order with an invalid address",
+			args: args{
+				fulfillmentType: FulfillmentTypeDelivery,
+				deliveryAddress: &AddressDTO{
+					Street: "Rua das Flores",
+					Number: "123",
+					Zip:    "0131",
+					City:   "São Paulo",
+				},
+			},
+			want: want{
+				valid: false,
+				err:   InvalidCEPError{CEP: "0131"},
+			},
+		},
+		{
+			name: "should not validate a delivery order without address",
+			args: args{
+				fulfillmentType: FulfillmentTypeDelivery,
+			},
+			want: want{
+				valid: false,
+				err:   ErrDeliveryAddressRequired,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		order := OrderDTO{
+			Items:           []OrderItemDTO{{ProductId: 1, Quantity: 1, Type: OrderItemTypeUnit}},
+			CustomerCPF:     "00551146010",
+			Status:          OrderStatusCreated,
+			FulfillmentType: tt.args.fulfillmentType,
+			DeliveryAddress: tt.args.deliveryAddress,
+		}
+
+		valid, err := order.ValidateOrder()
+
+		assert.Equal(t, tt.want.valid, valid, tt.name)
+		assert.Equal(t, tt.want.err, err, tt.name)
+	}
+}
+
+func TestOrderDTO_ToOrder_DefaultFulfillmentType(t *testing.T) {
+	order := OrderDTO{Status: OrderStatusCreated}.ToOrder(entities.Customer{ID: 1})
+
+	assert.Equal(t, string(FulfillmentTypeDineIn), order.FulfillmentType)
+}
+
+func TestOrderDTO_ToOrder_CouponCode(t *testing.T) {
+	order := OrderDTO{Coupon: " app10 ", Status: OrderStatusCreated}.ToOrder(entities.Customer{ID: 1})
+
+	assert.Equal(t, "APP10", order.Coupon)
+	assert.Equal(t, NormalizeCouponCode("APP10"), order.Coupon)
+}
+
+func TestOrderDTO_CollectValidationErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		order OrderDTO
+		want  []FieldErrorDTO
+	}{
+		{
+			name: "should report no errors for a valid order",
+			order: OrderDTO{
+				Items:       []OrderItemDTO{{ProductId: 1, Quantity: 1, Type: OrderItemTypeUnit}},
+				CustomerCPF: "00551146010",
+				Status:      OrderStatusCreated,
+			},
+			want: []FieldErrorDTO{},
+		},
+		{
+			name: "should report every invalid field",
+			order: OrderDTO{
+				Items:           []OrderItemDTO{{ProductId: 1, Quantity: 0, Type: OrderItemTypeUnit}},
+				CustomerCPF:     "11111111111",
+				Status:          "UNKNOWN",
+				FulfillmentType: FulfillmentTypeDelivery,
+				DeliveryAddress: &AddressDTO{Number: "123", Zip: "0131", City: "São Paulo"},
+			},
+			want: []FieldErrorDTO{
+				{Field: "items[0].quantity", Message: "non zero value required"},
+				{Field: "status", Message: "UNKNOWN does not validate as in(CREATED|PAID|RECEIVED|IN_PROGRESS|READY|DONE)"},
+				{Field: "customerCpf", Message: "invalid CPF [11111111111], must not have all digits the same"},
+				{Field: "deliveryAddress.street", Message: "Street is required"},
+				{Field: "deliveryAddress.zip", Message: "invalid CEP [0131]"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fieldErrors := tt.order.CollectValidationErrors()
+
+			assert.ElementsMatch(t, tt.want, LocalizeFieldErrors(fieldErrors, LocaleEn))
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package dto
+
+import (
+	"errors"
+	"g37-lanchonete/internal/core/entities"
+
+	"github.com/asaskevich/govalidator"
+)
+
+const (
+	MinFeedbackRating = 1
+	MaxFeedbackRating = 5
+)
+
+var (
+	ErrFeedbackRatingOutOfRange = errors.New("rating must be between 1 and 5")
+	ErrOrderNotDone             = errors.New("order is not done yet")
+	ErrOrderFeedbackAlreadySent = errors.New("order feedback was already sent")
+)
+
+type OrderFeedbackDTO struct {
+	Rating  int    `json:"rating"`
+	Comment string `json:"comment" valid:"length(0|500)~Comment length should be less than 500 characters"`
+}
+
+func (f OrderFeedbackDTO) ToOrderFeedback(orderId int) entities.OrderFeedback {
+	return entities.OrderFeedback{
+		OrderID: orderId,
+		Rating:  f.Rating,
+		Comment: f.Comment,
+	}
+}
+
+func (f OrderFeedbackDTO) Validate() (bool, error) {
+	if _, err := govalidator.ValidateStruct(f); err != nil {
+		return false, err
+	}
+
+	if f.Rating < MinFeedbackRating || f.Rating > MaxFeedbackRating {
+		return false, ErrFeedbackRatingOutOfRange
+	}
+
+	return true, nil
+}
+
+type ProductRatingsDTO struct {
+	ProductID     int     `json:"productId"`
+	RatingsCount  int     `json:"ratingsCount"`
+	AverageRating float64 `json:"averageRating"`
+}
@@ -0,0 +1,35 @@
+package dto
+
+import (
+	"errors"
+
+	"github.com/asaskevich/govalidator"
+)
+
+type OrderItemStatus string
+
+const (
+	OrderItemStatusPending OrderItemStatus = "PENDING"
+	OrderItemStatusReady   OrderItemStatus = "READY"
+)
+
+var ErrOrderNotInProgress = errors.New("order is not in progress")
+
+type OrderItemStatusDTO struct {
+	Status OrderItemStatus `json:"status" valid:"in(PENDING|READY),required~Item status is invalid"`
+}
+
+func (o OrderItemStatusDTO) Validate() (bool, error) {
+	if _, err := govalidator.ValidateStruct(o); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+type OrderItemStatusResponseDTO struct {
+	OrderID     int             `json:"orderId"`
+	ItemID      int             `json:"itemId"`
+	ItemStatus  OrderItemStatus `json:"itemStatus"`
+	OrderStatus OrderStatus     `json:"orderStatus"`
+}
@@ -0,0 +1,65 @@
+package dto
+
+import (
+	"g37-lanchonete/internal/core/entities"
+	"sort"
+	"strings"
+)
+
+// QueueOrderDTO is the slim projection of an order shown on the kitchen queue, leaving out the customer,
+// amounts and the product details.
+type QueueOrderDTO struct {
+	ID              int                 `json:"id"`
+	Number          string              `json:"number,omitempty"`
+	Status          string              `json:"status"`
+	FulfillmentType string              `json:"fulfillmentType"`
+	Notes           string              `json:"notes,omitempty"`
+	CreatedAt       entities.Timestamp  `json:"createdAt"`
+	Items           []QueueOrderItemDTO `json:"items"`
+}
+
+type QueueOrderItemDTO struct {
+	Name     string `json:"name"`
+	Variant  string `json:"variant,omitempty"`
+	Quantity int    `json:"quantity"`
+	Type     string `json:"type"`
+	// Station prepares the item, derived from the category of its product, empty when no station does.
+	Station string `json:"station,omitempty"`
+}
+
+// Stations maps each preparation station, e.g. "grill", to the categories of the products prepared at it.
+type Stations map[string][]string
+
+// NewStations lowercases the stations and their categories, so both match regardless of letter case.
+func NewStations(stations map[string][]string) Stations {
+	normalized := make(Stations, len(stations))
+	for station, categories := range stations {
+		station = strings.ToLower(strings.TrimSpace(station))
+		for _, category := range categories {
+			normalized[station] = append(normalized[station], strings.ToLower(strings.TrimSpace(category)))
+		}
+	}
+	return normalized
+}
+
+// StationOf returns the station preparing the first of the categories mapped to one, the first station in alphabetical
+// order when more than one prepares that category and "" when none does.
+func (s Stations) StationOf(categories []string) string {
+	names := make([]string, 0, len(s))
+	for station := range s {
+		names = append(names, station)
+	}
+	sort.Strings(names)
+
+	for _, category := range categories {
+		category = strings.ToLower(strings.TrimSpace(category))
+		for _, station := range names {
+			for _, stationCategory := range s[station] {
+				if stationCategory == category {
+					return station
+				}
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,43 @@
+package dto
+
+import (
+	"encoding/json"
+	"g37-lanchonete/internal/core/entities"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueueOrderDTO_OmitsHeavyFields(t *testing.T) {
+	queueOrder := QueueOrderDTO{
+		ID:              7,
+		Number:          "042",
+		Status:          string(OrderStatusInProgress),
+		FulfillmentType: string(FulfillmentTypeTakeaway),
+		CreatedAt:       entities.NewTimestamp(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)),
+		Items:           []QueueOrderItemDTO{{Name: "X-Burger", Variant: "Duplo", Quantity: 2, Type: string(OrderItemTypeUnit)}},
+	}
+
+	data, err := json.Marshal(queueOrder)
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"id": 7,
+		"number": "042",
+		"status": "IN_PROGRESS",
+		"fulfillmentType": "TAKEAWAY",
+		"createdAt": "2024-01-01T12:00:00Z",
+		"items": [{"name": "X-Burger", "variant": "Duplo", "quantity": 2, "type": "UNIT"}]
+	}`, string(data))
+
+	var fields map[string]any
+	assert.NoError(t, json.Unmarshal(data, &fields))
+	for _, heavy := range []string{"customer", "totalAmount", "subtotalAmount", "taxAmount", "deliveryAddress", "coupon"} {
+		assert.NotContains(t, fields, heavy)
+	}
+	item := fields["items"].([]any)[0].(map[string]any)
+	for _, heavy := range []string{"product", "description", "price", "skuId", "category", "createdAt", "updatedAt"} {
+		assert.NotContains(t, item, heavy)
+	}
+}
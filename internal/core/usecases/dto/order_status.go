@@ -0,0 +1,109 @@
+package dto
+
+import (
+	"fmt"
+	"sync"
+)
+
+var DefaultOrderStatuses = []OrderStatus{
+	OrderStatusCreated,
+	OrderStatusPaid,
+	OrderStatusReceived,
+	OrderStatusInProgress,
+	OrderStatusReady,
+	OrderStatusDone,
+}
+
+// the flow depends on these statuses, so a deployment can not disable them
+var requiredOrderStatuses = []OrderStatus{OrderStatusCreated, OrderStatusPaid, OrderStatusDone}
+
+var orderStatuses = struct {
+	sync.RWMutex
+	active []OrderStatus
+}{
+	active: DefaultOrderStatuses,
+}
+
+type OrderStatusNotEnabledError struct {
+	Status OrderStatus
+}
+
+func (e OrderStatusNotEnabledError) Error() string {
+	return fmt.Sprintf("status [%s] is not enabled", e.Status)
+}
+
+// SetOrderStatuses replaces the active statuses of the deployment. The given order defines the transitions,
+// each status moves to the next one. An empty list restores DefaultOrderStatuses.
+func SetOrderStatuses(statuses []string) error {
+	active := DefaultOrderStatuses
+	if len(statuses) > 0 {
+		active = make([]OrderStatus, 0, len(statuses))
+		seen := map[OrderStatus]bool{}
+		for _, s := range statuses {
+			status := OrderStatus(s)
+			if !isKnownOrderStatus(status) {
+				return fmt.Errorf("unknown order status [%s]", s)
+			}
+			if seen[status] {
+				return fmt.Errorf("duplicated order status [%s]", s)
+			}
+			seen[status] = true
+			active = append(active, status)
+		}
+
+		for _, required := range requiredOrderStatuses {
+			if !seen[required] {
+				return fmt.Errorf("order status [%s] is required", required)
+			}
+		}
+	}
+
+	orderStatuses.Lock()
+	defer orderStatuses.Unlock()
+	orderStatuses.active = active
+	return nil
+}
+
+func ActiveOrderStatuses() []OrderStatus {
+	orderStatuses.RLock()
+	defer orderStatuses.RUnlock()
+
+	active := make([]OrderStatus, len(orderStatuses.active))
+	copy(active, orderStatuses.active)
+	return active
+}
+
+func IsValidOrderStatus(status string) bool {
+	for _, active := range ActiveOrderStatuses() {
+		if active == OrderStatus(status) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s OrderStatus) AllowedNext() []OrderStatus {
+	active := ActiveOrderStatuses()
+	for i, status := range active {
+		if status == s && i+1 < len(active) {
+			return []OrderStatus{active[i+1]}
+		}
+	}
+	return []OrderStatus{}
+}
+
+func isKnownOrderStatus(status OrderStatus) bool {
+	for _, known := range DefaultOrderStatuses {
+		if known == status {
+			return true
+		}
+	}
+	return false
+}
+
+func validateOrderStatusEnabled(status OrderStatus) error {
+	if status != "" && !IsValidOrderStatus(string(status)) {
+		return OrderStatusNotEnabledError{Status: status}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package dto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetOrderStatuses_ReducedStatusSet(t *testing.T) {
+	err := SetOrderStatuses([]string{"CREATED", "PAID", "IN_PROGRESS", "READY", "DONE"})
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		_ = SetOrderStatuses(nil)
+	})
+
+	valid, err := OrderStatusDTO{Status: OrderStatusReceived}.Validate()
+	assert.False(t, valid)
+	assert.Equal(t, OrderStatusNotEnabledError{Status: OrderStatusReceived}, err)
+	assert.EqualError(t, LocalizeValidationError(err, LocalePtBR), "status [RECEIVED] não está habilitado")
+
+	valid, err = OrderStatusDTO{Status: OrderStatusInProgress}.Validate()
+	assert.True(t, valid)
+	assert.NoError(t, err)
+
+	assert.False(t, IsValidOrderStatus("RECEIVED"))
+	assert.Equal(t, []OrderStatus{OrderStatusInProgress}, OrderStatusPaid.AllowedNext())
+	assert.Equal(t, []OrderStatus{}, OrderStatusDone.AllowedNext())
+}
+
+func TestSetOrderStatuses_DefaultStatusSet(t *testing.T) {
+	assert.NoError(t, SetOrderStatuses(nil))
+
+	assert.Equal(t, DefaultOrderStatuses, ActiveOrderStatuses())
+	assert.Equal(t, []OrderStatus{OrderStatusReceived}, OrderStatusPaid.AllowedNext())
+	assert.True(t, IsValidOrderStatus("RECEIVED"))
+}
+
+func TestSetOrderStatuses_InvalidConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []string
+		wantErr  string
+	}{
+		{
+			name:     "should reject an unknown status",
+			statuses: []string{"CREATED", "PAID", "COOKING", "DONE"},
+			wantErr:  "unknown order status [COOKING]",
+		},
+		{
+			name:     "should reject a duplicated status",
+			statuses: []string{"CREATED", "PAID", "PAID", "DONE"},
+			wantErr:  "duplicated order status [PAID]",
+		},
+		{
+			name:     "should require the statuses the order flow depends on",
+			statuses: []string{"CREATED", "READY", "DONE"},
+			wantErr:  "order status [PAID] is required",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.EqualError(t, SetOrderStatuses(tt.statuses), tt.wantErr)
+			assert.Equal(t, DefaultOrderStatuses, ActiveOrderStatuses())
+		})
+	}
+}
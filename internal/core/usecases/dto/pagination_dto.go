@@ -2,25 +2,59 @@ package dto
 
 const DEFAULT_LIMIT = 100
 
-type PageParams struct {
-	offset int
-	limit  int
+// PageLimits holds the page size used when no limit is sent and the largest one accepted, zero values fall back to DEFAULT_LIMIT.
+type PageLimits struct {
+	Default int
+	Max     int
+	// WarnClamped tells the clients their limit was lowered to Max, instead of clamping it silently.
+	WarnClamped bool
 }
 
-func NewPageParams(offset, limit int) PageParams {
+func (l PageLimits) NewPageParams(offset, limit int) PageParams {
+	maxLimit := l.Max
+	if maxLimit < 1 {
+		maxLimit = DEFAULT_LIMIT
+	}
+
+	defaultLimit := l.Default
+	if defaultLimit < 1 || defaultLimit > maxLimit {
+		defaultLimit = maxLimit
+	}
+
 	return PageParams{
-		offset: offset,
-		limit:  limit,
+		offset:       offset,
+		limit:        limit,
+		defaultLimit: defaultLimit,
+		maxLimit:     maxLimit,
 	}
 }
 
+type PageParams struct {
+	offset       int
+	limit        int
+	defaultLimit int
+	maxLimit     int
+}
+
+func NewPageParams(offset, limit int) PageParams {
+	return PageLimits{}.NewPageParams(offset, limit)
+}
+
 func (p PageParams) GetLimit() int {
-	if p.limit < 1 || p.limit > DEFAULT_LIMIT {
-		return DEFAULT_LIMIT
+	if p.limit < 1 {
+		return p.defaultLimit
+	}
+	if p.limit > p.maxLimit {
+		return p.maxLimit
 	}
 	return p.limit
 }
 
+// LimitClamped tells whether the requested limit was over the largest one accepted.
+func (p PageParams) LimitClamped() bool {
+	return p.limit > p.maxLimit
+}
+
 func (p PageParams) GetOffset() int {
 	if p.offset < 0 {
 		return 1
@@ -34,7 +68,7 @@ type Page[T any] struct {
 }
 
 func BuildPage[T any](list []T, params PageParams) Page[T] {
-	if len(list) > 0 && len(list) == params.limit {
+	if len(list) > 0 && len(list) == params.GetLimit() {
 		next := params.GetOffset() + params.GetLimit()
 		return Page[T]{
 			Result: list,
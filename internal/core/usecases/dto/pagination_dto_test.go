@@ -0,0 +1,60 @@
+package dto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPageLimits_NewPageParams(t *testing.T) {
+	tests := []struct {
+		name      string
+		limits    PageLimits
+		limit     int
+		wantLimit int
+	}{
+		{
+			name:      "should fall back to DEFAULT_LIMIT when no limits are configured",
+			limit:     0,
+			wantLimit: DEFAULT_LIMIT,
+		},
+		{
+			name:      "should use the configured default when limit is omitted",
+			limits:    PageLimits{Default: 20, Max: 200},
+			limit:     0,
+			wantLimit: 20,
+		},
+		{
+			name:      "should accept a limit above DEFAULT_LIMIT when the max allows it",
+			limits:    PageLimits{Default: 20, Max: 200},
+			limit:     150,
+			wantLimit: 150,
+		},
+		{
+			name:      "should cap the limit to the configured max",
+			limits:    PageLimits{Default: 20, Max: 200},
+			limit:     300,
+			wantLimit: 200,
+		},
+		{
+			name:      "should cap a default greater than the max",
+			limits:    PageLimits{Default: 80, Max: 50},
+			limit:     0,
+			wantLimit: 50,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantLimit, tt.limits.NewPageParams(0, tt.limit).GetLimit())
+		})
+	}
+}
+
+func TestBuildPage_NextWithDefaultLimit(t *testing.T) {
+	page := BuildPage([]int{1, 2}, PageLimits{Default: 2}.NewPageParams(4, 0))
+
+	assert.Equal(t, []int{1, 2}, page.Result)
+	if assert.NotNil(t, page.Next) {
+		assert.Equal(t, 6, *page.Next)
+	}
+}
@@ -1,6 +1,7 @@
 package dto
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/asaskevich/govalidator"
@@ -13,6 +14,7 @@ type PaymentQRCodeRequest struct {
 	TotalAmount       float64              `json:"total_amount"`
 	Items             []PaymentItemRequest `json:"items"`
 	Sponsor           string               `json:"sponsor"`
+	ExpirationDate    *time.Time           `json:"expiration_date,omitempty"`
 }
 
 type PaymentItemRequest struct {
@@ -35,8 +37,10 @@ type PaymentQRCodeResponse struct {
 	StoreOrderId string `json:"in_store_order_id"`
 }
 
+// PaymentQRCode is the qrcode to be paid until ExpiresAt, which is nil when the payments do not expire.
 type PaymentQRCode struct {
-	QRCode string `json:"qrcode"`
+	QRCode    string     `json:"qrcode"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
 }
 
 type PaymentNotificationDTO struct {
@@ -61,3 +65,68 @@ func (p PaymentNotificationDTO) ValidatePaymentNotification() (bool, error) {
 
 	return true, nil
 }
+
+// PaymentReplayDTO is a payment webhook sent again by the support, with the order its notification was addressed to.
+type PaymentReplayDTO struct {
+	OrderID      int                    `json:"orderId" valid:"required~Order id is required"`
+	Notification PaymentNotificationDTO `json:"notification" valid:"-"`
+}
+
+func (p PaymentReplayDTO) Validate() (bool, error) {
+	if _, err := govalidator.ValidateStruct(p); err != nil {
+		return false, err
+	}
+
+	return p.Notification.ValidatePaymentNotification()
+}
+
+type PaymentReplayResponseDTO struct {
+	OrderID int `json:"orderId"`
+	// Confirmed is false when the replay was a no-op, the payment of the order having been confirmed before.
+	Confirmed bool        `json:"confirmed"`
+	Status    OrderStatus `json:"status"`
+}
+
+type PaymentMethod string
+
+const (
+	PaymentMethodMercadoPagoQRCode PaymentMethod = "MERCADO_PAGO_QRCODE"
+)
+
+// DefaultPaymentMethods is used when the deployment does not configure any, the qrcode flow is always available.
+var DefaultPaymentMethods = []PaymentMethod{PaymentMethodMercadoPagoQRCode}
+
+type PaymentMethodsDTO struct {
+	Methods []PaymentMethod `json:"methods"`
+}
+
+// ParsePaymentMethods checks the enabled methods of the deployment, keeping the configured order. An empty list returns DefaultPaymentMethods.
+func ParsePaymentMethods(methods []string) ([]PaymentMethod, error) {
+	if len(methods) == 0 {
+		return DefaultPaymentMethods, nil
+	}
+
+	parsed := make([]PaymentMethod, 0, len(methods))
+	seen := map[PaymentMethod]bool{}
+	for _, m := range methods {
+		method := PaymentMethod(m)
+		if !isKnownPaymentMethod(method) {
+			return nil, fmt.Errorf("unknown payment method [%s]", m)
+		}
+		if seen[method] {
+			return nil, fmt.Errorf("duplicated payment method [%s]", m)
+		}
+		seen[method] = true
+		parsed = append(parsed, method)
+	}
+
+	return parsed, nil
+}
+
+func isKnownPaymentMethod(method PaymentMethod) bool {
+	switch method {
+	case PaymentMethodMercadoPagoQRCode:
+		return true
+	}
+	return false
+}
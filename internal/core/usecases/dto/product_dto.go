@@ -1,33 +1,257 @@
 package dto
 
 import (
+	"errors"
+	"fmt"
 	"g37-lanchonete/internal/core/entities"
+	"reflect"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/asaskevich/govalidator"
 )
 
+var (
+	ErrEmptyProductPayload      = errors.New("product payload has no fields set")
+	ErrProductCategoryRequired  = errors.New("product category is required")
+	ErrProductInOrder           = errors.New("product is part of an order")
+	ErrProductQuantityRange     = errors.New("maximum quantity should not be less than the minimum quantity")
+	ErrUnknownTaxCategory       = errors.New("tax category has no configured rate")
+	ErrProductCategoryLength    = errors.New("each category should have less than 60 characters")
+	ErrTooManyProductCategories = fmt.Errorf("product can have at most %d categories", MaxProductCategories)
+	ErrProductSKUTaken          = errors.New("sku is already used by another product")
+)
+
+const MaxProductCategories = 10
+
+// SystemActor is credited with the changes of unauthenticated requests.
+const SystemActor = "system"
+
 type ProductDTO struct {
-	Name        string  `json:"name" valid:"length(0|100)~Name length should be less than 100 characters"`
-	SkuId       string  `json:"skuId" valid:"length(0|50)~Sku length should be less than 50 characters"`
-	Description string  `json:"description" valid:"length(0|2000)~Description length should be less than 2000 characters"`
-	Category    string  `json:"category" valid:"length(0|60)~Category length should be less than 60 characters"`
-	Price       float64 `json:"price" valid:"float,required~Price is required|range(0.01|)~Price greater than 0.00"`
+	Name        string `json:"name" valid:"length(0|100)~Name length should be less than 100 characters"`
+	SkuId       string `json:"skuId" valid:"length(0|50)~Sku length should be less than 50 characters"`
+	Description string `json:"description" valid:"length(0|2000)~Description length should be less than 2000 characters"`
+	Category    string `json:"category" valid:"length(0|60)~Category length should be less than 60 characters"`
+	// Categories are the other categories the product is shown under, besides the primary Category. Without a
+	// Category, the first one is the primary.
+	Categories  []string `json:"categories" valid:"-"`
+	TaxCategory string   `json:"taxCategory" valid:"length(0|30)~Tax category length should be less than 30 characters"`
+	Price       float64  `json:"price" valid:"float,required~Price is required|range(0.01|)~Price greater than 0.00"`
+	MinQty      int      `json:"minQty" valid:"range(0|)~Minimum quantity should not be negative"`
+	MaxQty      int      `json:"maxQty" valid:"range(0|)~Maximum quantity should not be negative"`
+}
+
+// DuplicateSKUSuffix is appended to the sku of the product duplicated when no sku is given for the copy.
+const DuplicateSKUSuffix = "-COPY"
+
+type ProductDuplicateDTO struct {
+	SkuId string `json:"skuId" valid:"length(0|50)~Sku length should be less than 50 characters"`
+}
+
+func (p ProductDuplicateDTO) Validate() (bool, error) {
+	if _, err := govalidator.ValidateStruct(p); err != nil {
+		return false, err
+	}
+
+	return true, nil
 }
 
 func (p ProductDTO) ToProduct() entities.Product {
+	category := p.Category
+	if strings.TrimSpace(category) == "" && len(p.Categories) > 0 {
+		category = p.Categories[0]
+	}
+
 	return entities.Product{
 		Name:        p.Name,
 		SkuId:       p.SkuId,
 		Description: p.Description,
-		Category:    p.Category,
+		Category:    category,
+		Categories:  p.Categories,
+		TaxCategory: p.TaxCategory,
 		Price:       p.Price,
+		MinQty:      p.MinQty,
+		MaxQty:      p.MaxQty,
 	}
 }
 
+func (p ProductDTO) IsEmpty() bool {
+	// the categories slice keeps the DTO from being compared with ==
+	categories := p.Categories
+	p.Categories = nil
+	return len(categories) == 0 && reflect.ValueOf(p).IsZero()
+}
+
 func (p ProductDTO) ValidateProduct() (bool, error) {
 	if _, err := govalidator.ValidateStruct(p); err != nil {
 		return false, err
 	}
 
+	// a zero maximum means the product has no upper limit
+	if p.MaxQty > 0 && p.MaxQty < p.MinQty {
+		return false, ErrProductQuantityRange
+	}
+
+	if len(p.Categories) > MaxProductCategories {
+		return false, ErrTooManyProductCategories
+	}
+
+	for _, category := range p.Categories {
+		if utf8.RuneCountInString(category) > 60 {
+			return false, ErrProductCategoryLength
+		}
+	}
+
+	return true, nil
+}
+
+type ProductVariantDTO struct {
+	Name  string  `json:"name" valid:"required~Variant name is required,length(1|60)~Variant name length should be less than 60 characters"`
+	SkuId string  `json:"skuId" valid:"length(0|50)~Sku length should be less than 50 characters"`
+	Price float64 `json:"price" valid:"float,required~Price is required|range(0.01|)~Price greater than 0.00"`
+}
+
+func (v ProductVariantDTO) ToProductVariant(productId int) entities.ProductVariant {
+	return entities.ProductVariant{
+		ProductID: productId,
+		Name:      v.Name,
+		SkuId:     v.SkuId,
+		Price:     v.Price,
+	}
+}
+
+func (v ProductVariantDTO) ValidateProductVariant() (bool, error) {
+	if _, err := govalidator.ValidateStruct(v); err != nil {
+		return false, err
+	}
+
 	return true, nil
 }
+
+// ProductVariantRequiredError is returned when an order item does not pick one of the variants of its product.
+type ProductVariantRequiredError struct {
+	ProductID int
+}
+
+func (e ProductVariantRequiredError) Error() string {
+	return fmt.Sprintf("product [%d] requires a variant", e.ProductID)
+}
+
+type InvalidProductVariantError struct {
+	ProductID int
+	VariantID int
+}
+
+func (e InvalidProductVariantError) Error() string {
+	return fmt.Sprintf("variant [%d] does not belong to product [%d]", e.VariantID, e.ProductID)
+}
+
+// ProductQuantityLimitError is returned when an order has fewer or more units of a product than it allows per order.
+type ProductQuantityLimitError struct {
+	ProductID int
+	Quantity  int
+	MinQty    int
+	MaxQty    int
+}
+
+func (e ProductQuantityLimitError) Error() string {
+	if e.Quantity < e.MinQty {
+		return fmt.Sprintf("product [%d] requires at least %d units per order, got %d", e.ProductID, e.MinQty, e.Quantity)
+	}
+	return fmt.Sprintf("product [%d] allows at most %d units per order, got %d", e.ProductID, e.MaxQty, e.Quantity)
+}
+
+// CheckProductQuantity checks the units of the product in an order against its limits, a zero limit is not enforced.
+func CheckProductQuantity(product entities.Product, quantity int) error {
+	if quantity < product.MinQty || (product.MaxQty > 0 && quantity > product.MaxQty) {
+		return ProductQuantityLimitError{
+			ProductID: product.ID,
+			Quantity:  quantity,
+			MinQty:    product.MinQty,
+			MaxQty:    product.MaxQty,
+		}
+	}
+
+	return nil
+}
+
+type ProductPopularityDTO struct {
+	ProductID   int       `json:"productId"`
+	OrdersCount int       `json:"ordersCount"`
+	UnitsSold   int       `json:"unitsSold"`
+	From        time.Time `json:"from"`
+	To          time.Time `json:"to"`
+}
+
+type MenuDTO struct {
+	Categories []MenuCategoryDTO `json:"categories"`
+}
+
+type MenuCategoryDTO struct {
+	Name     string             `json:"name"`
+	Products []entities.Product `json:"products"`
+}
+
+const MaxBulkDeleteProducts = 100
+
+type BulkDeleteStatus string
+
+const (
+	BulkDeleteStatusDeleted  BulkDeleteStatus = "DELETED"
+	BulkDeleteStatusBlocked  BulkDeleteStatus = "BLOCKED"
+	BulkDeleteStatusNotFound BulkDeleteStatus = "NOT_FOUND"
+	BulkDeleteStatusFailed   BulkDeleteStatus = "FAILED"
+)
+
+// BulkDeleteFailedMessage is reported for the unexpected failures, whose cause is only logged.
+const BulkDeleteFailedMessage = "failed to delete product"
+
+type BulkDeleteProductsDTO struct {
+	IDs []int `json:"ids"`
+}
+
+func (b BulkDeleteProductsDTO) Validate() (bool, error) {
+	if len(b.IDs) == 0 {
+		return false, errors.New("ids must not be empty")
+	}
+
+	if len(b.IDs) > MaxBulkDeleteProducts {
+		return false, fmt.Errorf("at most %d ids are allowed per request", MaxBulkDeleteProducts)
+	}
+
+	return true, nil
+}
+
+type BulkDeleteResultDTO struct {
+	ID     int              `json:"id"`
+	Status BulkDeleteStatus `json:"status"`
+	Error  string           `json:"error,omitempty"`
+}
+
+type BulkDeleteResponseDTO struct {
+	Results []BulkDeleteResultDTO `json:"results"`
+}
+
+type CategoryAvailabilityDTO struct {
+	Category  string `json:"category"`
+	Available *bool  `json:"available"`
+}
+
+func (c CategoryAvailabilityDTO) Validate() (bool, error) {
+	if strings.TrimSpace(c.Category) == "" {
+		return false, errors.New("category must not be empty")
+	}
+
+	if c.Available == nil {
+		return false, errors.New("available is required")
+	}
+
+	return true, nil
+}
+
+type CategoryAvailabilityResponseDTO struct {
+	Category   string `json:"category"`
+	Available  bool   `json:"available"`
+	ProductIDs []int  `json:"productIds"`
+}
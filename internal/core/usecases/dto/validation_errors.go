@@ -0,0 +1,82 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/asaskevich/govalidator"
+)
+
+// FieldError binds a validation error to the payload field that caused it.
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+type FieldErrorDTO struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type OrderItemProductNotFoundError struct {
+	ProductID int
+}
+
+func (e OrderItemProductNotFoundError) Error() string {
+	return fmt.Sprintf("product [%d] not found", e.ProductID)
+}
+
+// LocalizeFieldErrors translates the messages of the field errors, keeping the field paths as sent by the client.
+func LocalizeFieldErrors(fieldErrors []FieldError, locale Locale) []FieldErrorDTO {
+	result := make([]FieldErrorDTO, len(fieldErrors))
+	for i, fieldErr := range fieldErrors {
+		result[i] = FieldErrorDTO{
+			Field:   fieldErr.Field,
+			Message: localizeFieldMessage(fieldErr.Err, locale),
+		}
+	}
+	return result
+}
+
+func localizeFieldMessage(err error, locale Locale) string {
+	var validationErr govalidator.Error
+	if errors.As(err, &validationErr) {
+		if locale == LocaleEn {
+			return validationErr.Err.Error()
+		}
+		if message, ok := customMessagesPtBR[validationErr.Err.Error()]; ok {
+			return message
+		}
+		return translateValidatorMessage(validationErr.Err.Error())
+	}
+
+	return LocalizeValidationError(err, locale).Error()
+}
+
+// collectFieldErrors flattens the errors returned by govalidator.ValidateStruct, prefixing their paths.
+func collectFieldErrors(err error, prefix string) []FieldError {
+	var validationErrs govalidator.Errors
+	if errors.As(err, &validationErrs) {
+		fieldErrors := []FieldError{}
+		for _, e := range validationErrs.Errors() {
+			fieldErrors = append(fieldErrors, collectFieldErrors(e, prefix)...)
+		}
+		return fieldErrors
+	}
+
+	var validationErr govalidator.Error
+	if errors.As(err, &validationErr) {
+		return []FieldError{{Field: joinFieldPath(prefix, append(validationErr.Path, validationErr.Name)...), Err: validationErr}}
+	}
+
+	return []FieldError{{Field: prefix, Err: err}}
+}
+
+func joinFieldPath(prefix string, names ...string) string {
+	path := strings.Join(names, ".")
+	if prefix == "" {
+		return path
+	}
+	return prefix + "." + path
+}
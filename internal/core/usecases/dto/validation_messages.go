@@ -0,0 +1,176 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/asaskevich/govalidator"
+)
+
+type Locale string
+
+const (
+	LocalePtBR Locale = "pt-BR"
+	LocaleEn   Locale = "en"
+
+	DefaultLocale = LocalePtBR
+)
+
+var customMessagesPtBR = map[string]string{
+	"Name length should be less than 100 characters":         "Nome deve ter menos de 100 caracteres",
+	"Sku length should be less than 50 characters":           "Sku deve ter menos de 50 caracteres",
+	"Description length should be less than 2000 characters": "Descrição deve ter menos de 2000 caracteres",
+	"Description length should be less than 100 characters":  "Cupom deve ter menos de 100 caracteres",
+	"Category length should be less than 60 characters":      "Categoria deve ter menos de 60 caracteres",
+	"Tax category length should be less than 30 characters":  "Categoria tributária deve ter menos de 30 caracteres",
+	"Price is required":                                    "Preço é obrigatório",
+	"Price greater than 0.00":                              "Preço deve ser maior que 0.00",
+	"Store credit should not be negative":                  "Crédito da loja não pode ser negativo",
+	"Minimum quantity should not be negative":              "Quantidade mínima não pode ser negativa",
+	"Maximum quantity should not be negative":              "Quantidade máxima não pode ser negativa",
+	"Quantity is required":                                 "Quantidade é obrigatória",
+	"Quantity greater than 0":                              "Quantidade deve ser maior que 0",
+	"Type is invalid":                                      "Tipo inválido",
+	"Status is invalid":                                    "Status inválido",
+	"Expected status is invalid":                           "Status esperado inválido",
+	"Item status is invalid":                               "Status do item inválido",
+	"Fulfillment type is invalid":                          "Tipo de atendimento inválido",
+	"Street is required":                                   "Logradouro é obrigatório",
+	"Street length should be less than 150 characters":     "Logradouro deve ter menos de 150 caracteres",
+	"Number is required":                                   "Número é obrigatório",
+	"Number length should be less than 20 characters":      "Número deve ter menos de 20 caracteres",
+	"Complement length should be less than 100 characters": "Complemento deve ter menos de 100 caracteres",
+	"Zip is required":                                      "CEP é obrigatório",
+	"City is required":                                     "Cidade é obrigatória",
+	"City length should be less than 100 characters":       "Cidade deve ter menos de 100 caracteres",
+	"Notes length should be less than 500 characters":      "Observações devem ter menos de 500 caracteres",
+
+	"Variant name is required":                              "Nome da variação é obrigatório",
+	"Variant name length should be less than 60 characters": "Nome da variação deve ter menos de 60 caracteres",
+
+	"Comment length should be less than 500 characters": "Comentário deve ter menos de 500 caracteres",
+}
+
+var invalidCPFReasonsPtBR = map[InvalidCPFReason]string{
+	InvalidCPFLength:         "deve ter 11 dígitos",
+	InvalidCPFCharacters:     "deve conter apenas números",
+	InvalidCPFRepeatedDigits: "não pode ter todos os dígitos iguais",
+	InvalidCPFCheckDigits:    "dígitos verificadores não conferem",
+}
+
+var doesNotValidateRegex = regexp.MustCompile(`^(.*) does not validate as (.*)$`)
+
+func ParseLocale(acceptLanguage string) Locale {
+	for _, tag := range strings.Split(acceptLanguage, ",") {
+		tag = strings.ToLower(strings.TrimSpace(strings.SplitN(tag, ";", 2)[0]))
+		switch {
+		case tag == "en" || strings.HasPrefix(tag, "en-"):
+			return LocaleEn
+		case tag == "pt" || strings.HasPrefix(tag, "pt-"):
+			return LocalePtBR
+		}
+	}
+
+	return DefaultLocale
+}
+
+// LocalizeValidationError translates the errors returned by the DTO validations.
+// The validation messages are written in english, so they are returned as is for LocaleEn.
+func LocalizeValidationError(err error, locale Locale) error {
+	if err == nil || locale == LocaleEn {
+		return err
+	}
+
+	return errors.New(translateToPtBR(err))
+}
+
+func translateToPtBR(err error) string {
+	switch e := err.(type) {
+	case govalidator.Errors:
+		messages := make([]string, 0, len(e))
+		for _, validationErr := range e {
+			messages = append(messages, translateToPtBR(validationErr))
+		}
+		sort.Strings(messages)
+		return strings.Join(messages, ";")
+	case govalidator.Error:
+		if e.CustomErrorMessageExists {
+			if message, ok := customMessagesPtBR[e.Err.Error()]; ok {
+				return message
+			}
+			return e.Err.Error()
+		}
+
+		name := e.Name
+		if len(e.Path) > 0 {
+			name = strings.Join(append(e.Path, e.Name), ".")
+		}
+		return name + ": " + translateValidatorMessage(e.Err.Error())
+	case InvalidCPFError:
+		return fmt.Sprintf("CPF inválido [%s], %s", e.CPF, invalidCPFReasonsPtBR[e.Reason])
+	case InvalidCEPError:
+		return fmt.Sprintf("CEP inválido [%s]", e.CEP)
+	case OrderStatusNotEnabledError:
+		return fmt.Sprintf("status [%s] não está habilitado", e.Status)
+	case ProductVariantRequiredError:
+		return fmt.Sprintf("produto [%d] exige uma variação", e.ProductID)
+	case InvalidProductVariantError:
+		return fmt.Sprintf("variação [%d] não pertence ao produto [%d]", e.VariantID, e.ProductID)
+	case OrderItemProductNotFoundError:
+		return fmt.Sprintf("produto [%d] não encontrado", e.ProductID)
+	case ProductQuantityLimitError:
+		if e.Quantity < e.MinQty {
+			return fmt.Sprintf("produto [%d] exige ao menos %d unidades por pedido, informado %d", e.ProductID, e.MinQty, e.Quantity)
+		}
+		return fmt.Sprintf("produto [%d] permite no máximo %d unidades por pedido, informado %d", e.ProductID, e.MaxQty, e.Quantity)
+	}
+
+	if errors.Is(err, ErrDeliveryAddressRequired) {
+		return "endereço de entrega é obrigatório para pedidos DELIVERY"
+	}
+
+	if errors.Is(err, ErrEmptyProductPayload) {
+		return "nenhum campo do produto foi informado"
+	}
+
+	if errors.Is(err, ErrProductCategoryRequired) {
+		return "categoria do produto é obrigatória"
+	}
+
+	if errors.Is(err, ErrProductCategoryLength) {
+		return "cada categoria deve ter menos de 60 caracteres"
+	}
+
+	if errors.Is(err, ErrTooManyProductCategories) {
+		return fmt.Sprintf("produto pode ter no máximo %d categorias", MaxProductCategories)
+	}
+
+	if errors.Is(err, ErrUnknownTaxCategory) {
+		return "categoria tributária não possui alíquota configurada"
+	}
+
+	if errors.Is(err, ErrProductQuantityRange) {
+		return "quantidade máxima não pode ser menor que a quantidade mínima"
+	}
+
+	if errors.Is(err, ErrFeedbackRatingOutOfRange) {
+		return "nota deve estar entre 1 e 5"
+	}
+
+	return err.Error()
+}
+
+func translateValidatorMessage(message string) string {
+	if message == "non zero value required" {
+		return "valor obrigatório"
+	}
+
+	if matches := doesNotValidateRegex.FindStringSubmatch(message); matches != nil {
+		return fmt.Sprintf("%s não é válido para %s", matches[1], matches[2])
+	}
+
+	return message
+}
@@ -0,0 +1,53 @@
+package usecases
+
+import (
+	"fmt"
+	"g37-lanchonete/internal/core/usecases/dto"
+	"strings"
+)
+
+// ExchangeRateProvider gives how much of a currency one unit of the base currency of the prices buys.
+type ExchangeRateProvider interface {
+	BaseCurrency() string
+	Rate(currency string) (float64, error)
+}
+
+type staticExchangeRateProvider struct {
+	baseCurrency string
+	rates        map[string]float64
+}
+
+// NewStaticExchangeRateProvider serves the configured rates, whose currency codes are matched ignoring letter case.
+func NewStaticExchangeRateProvider(baseCurrency string, rates map[string]float64) ExchangeRateProvider {
+	normalizedRates := make(map[string]float64, len(rates))
+	for currency, rate := range rates {
+		normalizedRates[normalizeCurrency(currency)] = rate
+	}
+
+	return staticExchangeRateProvider{
+		baseCurrency: normalizeCurrency(baseCurrency),
+		rates:        normalizedRates,
+	}
+}
+
+func (p staticExchangeRateProvider) BaseCurrency() string {
+	return p.baseCurrency
+}
+
+func (p staticExchangeRateProvider) Rate(currency string) (float64, error) {
+	currency = normalizeCurrency(currency)
+	if currency == p.baseCurrency {
+		return 1, nil
+	}
+
+	rate, found := p.rates[currency]
+	if !found || rate <= 0 {
+		return 0, fmt.Errorf("%w [%s]", dto.ErrUnsupportedCurrency, currency)
+	}
+
+	return rate, nil
+}
+
+func normalizeCurrency(currency string) string {
+	return strings.ToUpper(strings.TrimSpace(currency))
+}
@@ -0,0 +1,60 @@
+package usecases
+
+import (
+	"regexp"
+	"strings"
+)
+
+var notePIIPatterns = []*regexp.Regexp{
+	// e-mail
+	regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+	// CPF, with or without punctuation
+	regexp.MustCompile(`\b\d{3}\.?\d{3}\.?\d{3}-?\d{2}\b`),
+	// phone numbers, e.g. (11) 91234-5678 or 11912345678
+	regexp.MustCompile(`\(?\b\d{2}\)?\s?9?\d{4}-?\d{4}\b`),
+}
+
+type NoteRedactor interface {
+	Redact(note string) string
+}
+
+type noteRedactor struct {
+	patterns []*regexp.Regexp
+}
+
+// NewNoteRedactor returns a redactor that masks the given words and common PII (e-mail, CPF and phone numbers)
+// with asterisks. When disabled, notes are returned untouched.
+func NewNoteRedactor(enabled bool, words []string) NoteRedactor {
+	if !enabled {
+		return noopNoteRedactor{}
+	}
+
+	patterns := make([]*regexp.Regexp, 0, len(words)+len(notePIIPatterns))
+	for _, word := range words {
+		word = strings.TrimSpace(word)
+		if word == "" {
+			continue
+		}
+		patterns = append(patterns, regexp.MustCompile(`(?i)\b`+regexp.QuoteMeta(word)+`\b`))
+	}
+	patterns = append(patterns, notePIIPatterns...)
+
+	return noteRedactor{
+		patterns: patterns,
+	}
+}
+
+func (r noteRedactor) Redact(note string) string {
+	for _, pattern := range r.patterns {
+		note = pattern.ReplaceAllStringFunc(note, func(match string) string {
+			return strings.Repeat("*", len([]rune(match)))
+		})
+	}
+	return note
+}
+
+type noopNoteRedactor struct{}
+
+func (noopNoteRedactor) Redact(note string) string {
+	return note
+}
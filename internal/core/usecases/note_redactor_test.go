@@ -0,0 +1,50 @@
+package usecases
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNoteRedactor_Redact(t *testing.T) {
+	words := []string{"idiota", "droga"}
+
+	tests := []struct {
+		name    string
+		enabled bool
+		note    string
+		want    string
+	}{
+		{
+			name:    "should keep the note untouched when redaction is disabled",
+			enabled: false,
+			note:    "Sem cebola, seu idiota. Me liga em (11) 91234-5678",
+			want:    "Sem cebola, seu idiota. Me liga em (11) 91234-5678",
+		},
+		{
+			name:    "should keep a clean note untouched",
+			enabled: true,
+			note:    "Sem cebola, por favor",
+			want:    "Sem cebola, por favor",
+		},
+		{
+			name:    "should redact configured words ignoring case",
+			enabled: true,
+			note:    "Sem cebola, seu IDIOTA! Que droga",
+			want:    "Sem cebola, seu ******! Que *****",
+		},
+		{
+			name:    "should redact e-mail, CPF and phone numbers",
+			enabled: true,
+			note:    "Cliente joao@email.com, CPF 123.456.789-09, tel 11912345678",
+			want:    "Cliente **************, CPF **************, tel ***********",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redactor := NewNoteRedactor(tt.enabled, words)
+
+			assert.Equal(t, tt.want, redactor.Redact(tt.note))
+		})
+	}
+}
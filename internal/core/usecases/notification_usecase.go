@@ -0,0 +1,121 @@
+package usecases
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"g37-lanchonete/internal/core/entities"
+	"g37-lanchonete/internal/core/usecases/dto"
+	htmlTemplate "html/template"
+	"io/fs"
+	"os"
+	textTemplate "text/template"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	orderConfirmationHTMLTemplate = "order_confirmation.html.tmpl"
+	orderConfirmationTextTemplate = "order_confirmation.txt.tmpl"
+)
+
+//go:embed templates/*.tmpl
+var embeddedTemplates embed.FS
+
+type NotificationUsecase interface {
+	RenderOrderConfirmation(order entities.Order) (dto.OrderConfirmationMessage, error)
+}
+
+type notificationUsecase struct {
+	htmlTemplate      *htmlTemplate.Template
+	textTemplate      *textTemplate.Template
+	estimatedPrepTime time.Duration
+	etaRounding       time.Duration
+	clock             Clock
+}
+
+type orderConfirmationData struct {
+	Order entities.Order
+	ETA   time.Time
+}
+
+// NewNotificationUsecase parses the embedded templates, or the ones found in templatesDir when it is set.
+// The preparation estimate is rounded up to a multiple of etaRounding, 0 keeping it as is.
+func NewNotificationUsecase(templatesDir string, estimatedPrepTime time.Duration, etaRounding time.Duration, clock Clock) (NotificationUsecase, error) {
+	templates, err := fs.Sub(embeddedTemplates, "templates")
+	if err != nil {
+		return nil, err
+	}
+	if templatesDir != "" {
+		templates = os.DirFS(templatesDir)
+	}
+
+	funcs := map[string]any{
+		"money": formatMoney,
+	}
+
+	html, err := htmlTemplate.New(orderConfirmationHTMLTemplate).Funcs(funcs).ParseFS(templates, orderConfirmationHTMLTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template [%s], error: %w", orderConfirmationHTMLTemplate, err)
+	}
+
+	text, err := textTemplate.New(orderConfirmationTextTemplate).Funcs(funcs).ParseFS(templates, orderConfirmationTextTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template [%s], error: %w", orderConfirmationTextTemplate, err)
+	}
+
+	return notificationUsecase{
+		htmlTemplate:      html,
+		textTemplate:      text,
+		estimatedPrepTime: estimatedPrepTime,
+		etaRounding:       etaRounding,
+		clock:             clock,
+	}, nil
+}
+
+func (u notificationUsecase) RenderOrderConfirmation(order entities.Order) (dto.OrderConfirmationMessage, error) {
+	createdAt := order.CreatedAt.Time
+	if createdAt.IsZero() {
+		createdAt = u.clock.Now()
+	}
+	data := orderConfirmationData{
+		Order: order,
+		ETA:   createdAt.Add(roundUpDuration(u.estimatedPrepTime, u.etaRounding)),
+	}
+
+	var html bytes.Buffer
+	if err := u.htmlTemplate.Execute(&html, data); err != nil {
+		log.Errorf("failed to render html confirmation of order [%d], error: %v", order.ID, err)
+		return dto.OrderConfirmationMessage{}, err
+	}
+
+	var text bytes.Buffer
+	if err := u.textTemplate.Execute(&text, data); err != nil {
+		log.Errorf("failed to render text confirmation of order [%d], error: %v", order.ID, err)
+		return dto.OrderConfirmationMessage{}, err
+	}
+
+	return dto.OrderConfirmationMessage{
+		Subject: fmt.Sprintf("Pedido #%d confirmado", order.ID),
+		HTML:    html.String(),
+		Text:    text.String(),
+	}, nil
+}
+
+// roundUpDuration rounds the duration up to the next multiple of increment, e.g. 7m18s is 10m in 5m increments.
+func roundUpDuration(duration time.Duration, increment time.Duration) time.Duration {
+	if increment <= 0 {
+		return duration
+	}
+
+	rounded := duration.Truncate(increment)
+	if rounded < duration {
+		rounded += increment
+	}
+	return rounded
+}
+
+func formatMoney(amount float64) string {
+	return fmt.Sprintf("R$ %.2f", amount)
+}
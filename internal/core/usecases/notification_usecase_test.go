@@ -0,0 +1,118 @@
+package usecases
+
+import (
+	"g37-lanchonete/internal/core/entities"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sampleConfirmationOrder() entities.Order {
+	return entities.Order{
+		ID: 42,
+		Items: []entities.OrderItem{
+			{Product: entities.Product{Name: "X-Burguer"}, Quantity: 2},
+			{Product: entities.Product{Name: "Batata <Frita>"}, Quantity: 1},
+		},
+		TotalAmount: 45.9,
+		Customer:    entities.Customer{Name: "Maria"},
+		CreatedAt:   entities.NewTimestamp(time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)),
+	}
+}
+
+func TestNotificationUsecase_RenderOrderConfirmation(t *testing.T) {
+	order := sampleConfirmationOrder()
+	order.Items[0].Product.Price = 18.5
+	order.Items[1].Product.Price = 8.9
+
+	notificationUsecase, err := NewNotificationUsecase("", 25*time.Minute, 0, &fakeClock{})
+	assert.NoError(t, err)
+
+	message, err := notificationUsecase.RenderOrderConfirmation(order)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Pedido #42 confirmado", message.Subject)
+	for _, body := range []string{message.HTML, message.Text} {
+		assert.Contains(t, body, "Pedido #42 confirmado")
+		assert.Contains(t, body, "Maria")
+		assert.Contains(t, body, "X-Burguer")
+		assert.Contains(t, body, "R$ 18.50")
+		assert.Contains(t, body, "Total: ")
+		assert.Contains(t, body, "R$ 45.90")
+		assert.Contains(t, body, "Previsão de entrega: 12:25")
+	}
+	assert.Contains(t, message.Text, "2x X-Burguer")
+	assert.Contains(t, message.HTML, "Batata &lt;Frita&gt;")
+}
+
+func TestNotificationUsecase_RenderOrderConfirmation_OverriddenTemplates(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, orderConfirmationHTMLTemplate), []byte(`<p>#{{.Order.ID}} {{money .Order.TotalAmount}}</p>`), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, orderConfirmationTextTemplate), []byte(`#{{.Order.ID}} ETA {{.ETA.Format "15:04"}}`), 0o600))
+
+	notificationUsecase, err := NewNotificationUsecase(dir, 10*time.Minute, 0, &fakeClock{})
+	assert.NoError(t, err)
+
+	message, err := notificationUsecase.RenderOrderConfirmation(sampleConfirmationOrder())
+
+	assert.NoError(t, err)
+	assert.Equal(t, "<p>#42 R$ 45.90</p>", message.HTML)
+	assert.Equal(t, "#42 ETA 12:10", message.Text)
+}
+
+func TestNotificationUsecase_MissingOverriddenTemplate(t *testing.T) {
+	_, err := NewNotificationUsecase(t.TempDir(), 10*time.Minute, 0, &fakeClock{})
+
+	assert.Error(t, err)
+}
+
+func TestNotificationUsecase_RenderOrderConfirmation_ETARounding(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, orderConfirmationHTMLTemplate), []byte(`<p>#{{.Order.ID}}</p>`), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, orderConfirmationTextTemplate), []byte(`ETA {{.ETA.Format "15:04:05"}}`), 0o600))
+
+	// 7.3 minutes
+	notificationUsecase, err := NewNotificationUsecase(dir, 7*time.Minute+18*time.Second, 5*time.Minute, &fakeClock{})
+	assert.NoError(t, err)
+
+	message, err := notificationUsecase.RenderOrderConfirmation(sampleConfirmationOrder())
+
+	assert.NoError(t, err)
+	assert.Equal(t, "ETA 12:10:00", message.Text)
+}
+
+func TestRoundUpDuration(t *testing.T) {
+	tests := []struct {
+		name      string
+		duration  time.Duration
+		increment time.Duration
+		want      time.Duration
+	}{
+		{
+			name:      "should round 7.3 minutes up to 10 minutes",
+			duration:  7*time.Minute + 18*time.Second,
+			increment: 5 * time.Minute,
+			want:      10 * time.Minute,
+		},
+		{
+			name:      "should keep 5 minutes already on the increment",
+			duration:  5 * time.Minute,
+			increment: 5 * time.Minute,
+			want:      5 * time.Minute,
+		},
+		{
+			name:      "should keep the duration when rounding is disabled",
+			duration:  7*time.Minute + 18*time.Second,
+			increment: 0,
+			want:      7*time.Minute + 18*time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, roundUpDuration(tt.duration, tt.increment))
+		})
+	}
+}
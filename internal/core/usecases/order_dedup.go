@@ -0,0 +1,112 @@
+package usecases
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"g37-lanchonete/internal/core/usecases/dto"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// orderSubmission is an order creation in flight or answered within the window, shared by the identical submissions.
+type orderSubmission struct {
+	done        chan struct{}
+	response    dto.OrderCreationResponse
+	err         error
+	completedAt time.Time
+}
+
+type dedupOrderUsecase struct {
+	OrderUsecase
+	window      time.Duration
+	clock       Clock
+	mu          sync.Mutex
+	submissions map[string]*orderSubmission
+}
+
+// NewDedupOrderUsecase wraps the usecase so an order submitted again by the same customer with the same items, while
+// the first is being created or within the window after it, gets the response of the first instead of a second order.
+// A double tap on the totem creates a single order. Orders without a customer cpf and a zero window are not deduplicated.
+func NewDedupOrderUsecase(orderUsecase OrderUsecase, window time.Duration, clock Clock) OrderUsecase {
+	if window <= 0 {
+		return orderUsecase
+	}
+
+	return &dedupOrderUsecase{
+		OrderUsecase: orderUsecase,
+		window:       window,
+		clock:        clock,
+		submissions:  map[string]*orderSubmission{},
+	}
+}
+
+func (u *dedupOrderUsecase) CreateOrder(orderDTO dto.OrderDTO) (dto.OrderCreationResponse, error) {
+	cpf := cpfDigits(orderDTO.CustomerCPF)
+	if cpf == "" {
+		return u.OrderUsecase.CreateOrder(orderDTO)
+	}
+	key := cpf + ":" + hashOrderItems(orderDTO.Items)
+
+	u.mu.Lock()
+	u.removeExpired()
+	if submission, found := u.submissions[key]; found {
+		u.mu.Unlock()
+		<-submission.done
+		log.Infof("order submitted again within %s, answering order id [%d]", u.window, submission.response.OrderID)
+		return submission.response, submission.err
+	}
+
+	submission := &orderSubmission{done: make(chan struct{})}
+	u.submissions[key] = submission
+	u.mu.Unlock()
+
+	submission.response, submission.err = u.OrderUsecase.CreateOrder(orderDTO)
+
+	u.mu.Lock()
+	submission.completedAt = u.clock.Now()
+	// a failed submission is not kept, so the customer can retry it right away
+	if submission.err != nil {
+		delete(u.submissions, key)
+	}
+	u.mu.Unlock()
+	close(submission.done)
+
+	return submission.response, submission.err
+}
+
+// removeExpired drops the submissions answered longer than the window ago, the ones in flight are kept.
+func (u *dedupOrderUsecase) removeExpired() {
+	now := u.clock.Now()
+	for key, submission := range u.submissions {
+		if !submission.completedAt.IsZero() && now.Sub(submission.completedAt) >= u.window {
+			delete(u.submissions, key)
+		}
+	}
+}
+
+// cpfDigits keeps only the digits of the cpf, so the formatted and the plain cpf are the same customer.
+func cpfDigits(cpf string) string {
+	return strings.Map(func(r rune) rune {
+		if r < '0' || r > '9' {
+			return -1
+		}
+		return r
+	}, cpf)
+}
+
+// hashOrderItems identifies the items regardless of the order they were sent in.
+func hashOrderItems(items []dto.OrderItemDTO) string {
+	keys := make([]string, len(items))
+	for i, item := range items {
+		keys[i] = fmt.Sprintf("%d/%d/%d/%s", item.ProductId, item.VariantId, item.Quantity, item.Type)
+	}
+	sort.Strings(keys)
+
+	hash := sha256.Sum256([]byte(strings.Join(keys, ";")))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,146 @@
+package usecases
+
+import (
+	"errors"
+	"g37-lanchonete/internal/core/usecases/dto"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countingOrderUsecase creates a new order id on each call, failing while err is set.
+type countingOrderUsecase struct {
+	OrderUsecase
+	created atomic.Int32
+	err     error
+}
+
+func (u *countingOrderUsecase) CreateOrder(orderDTO dto.OrderDTO) (dto.OrderCreationResponse, error) {
+	if u.err != nil {
+		return dto.OrderCreationResponse{}, u.err
+	}
+	id := int(u.created.Add(1))
+	return dto.OrderCreationResponse{QRCode: "mercadopago123456", OrderID: id}, nil
+}
+
+func TestDedupOrderUsecase_CreateOrder_Concurrent(t *testing.T) {
+	const submissions = 2
+	inner := &countingOrderUsecase{}
+	orderUsecase := NewDedupOrderUsecase(inner, 5*time.Second, &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)})
+	orderDTO := dto.OrderDTO{
+		CustomerCPF: "00551146010",
+		Items:       []dto.OrderItemDTO{{ProductId: 1, Quantity: 2, Type: dto.OrderItemTypeUnit}, {ProductId: 2, Quantity: 1, Type: dto.OrderItemTypeUnit}},
+	}
+
+	start := make(chan struct{})
+	responses := make([]dto.OrderCreationResponse, submissions)
+	errs := make([]error, submissions)
+	var wg sync.WaitGroup
+	for i := 0; i < submissions; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			responses[i], errs[i] = orderUsecase.CreateOrder(orderDTO)
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), inner.created.Load())
+	assert.Equal(t, []error{nil, nil}, errs)
+	assert.Equal(t, responses[0], responses[1])
+}
+
+func TestDedupOrderUsecase_CreateOrder(t *testing.T) {
+	items := []dto.OrderItemDTO{{ProductId: 1, Quantity: 2, Type: dto.OrderItemTypeUnit}, {ProductId: 2, Quantity: 1, Type: dto.OrderItemTypeUnit}}
+	reorderedItems := []dto.OrderItemDTO{items[1], items[0]}
+
+	tests := []struct {
+		name        string
+		first       dto.OrderDTO
+		second      dto.OrderDTO
+		elapsed     time.Duration
+		wantCreated int32
+	}{
+		{
+			name:        "should answer the first order when the items are sent in another order",
+			first:       dto.OrderDTO{CustomerCPF: "00551146010", Items: items},
+			second:      dto.OrderDTO{CustomerCPF: " 00551146010 ", Items: reorderedItems},
+			elapsed:     4 * time.Second,
+			wantCreated: 1,
+		},
+		{
+			name:        "should answer the first order when the cpf is sent formatted",
+			first:       dto.OrderDTO{CustomerCPF: "00551146010", Items: items},
+			second:      dto.OrderDTO{CustomerCPF: "005.511.460-10", Items: items},
+			elapsed:     4 * time.Second,
+			wantCreated: 1,
+		},
+		{
+			name:        "should create another order once the window is over",
+			first:       dto.OrderDTO{CustomerCPF: "00551146010", Items: items},
+			second:      dto.OrderDTO{CustomerCPF: "00551146010", Items: items},
+			elapsed:     5 * time.Second,
+			wantCreated: 2,
+		},
+		{
+			name:        "should create another order when the quantities differ",
+			first:       dto.OrderDTO{CustomerCPF: "00551146010", Items: items},
+			second:      dto.OrderDTO{CustomerCPF: "00551146010", Items: []dto.OrderItemDTO{{ProductId: 1, Quantity: 3, Type: dto.OrderItemTypeUnit}}},
+			wantCreated: 2,
+		},
+		{
+			name:        "should create another order for another customer",
+			first:       dto.OrderDTO{CustomerCPF: "00551146010", Items: items},
+			second:      dto.OrderDTO{CustomerCPF: "11144477735", Items: items},
+			wantCreated: 2,
+		},
+		{
+			name:        "should not deduplicate the orders without a customer",
+			first:       dto.OrderDTO{Items: items},
+			second:      dto.OrderDTO{Items: items},
+			wantCreated: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := &countingOrderUsecase{}
+			clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+			orderUsecase := NewDedupOrderUsecase(inner, 5*time.Second, clock)
+
+			first, err := orderUsecase.CreateOrder(tt.first)
+			assert.NoError(t, err)
+			clock.Advance(tt.elapsed)
+			second, err := orderUsecase.CreateOrder(tt.second)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tt.wantCreated, inner.created.Load())
+			assert.Equal(t, tt.wantCreated == 1, first == second)
+		})
+	}
+}
+
+func TestDedupOrderUsecase_CreateOrder_RetriesFailure(t *testing.T) {
+	inner := &countingOrderUsecase{err: errors.New("connection refused")}
+	orderUsecase := NewDedupOrderUsecase(inner, 5*time.Second, &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)})
+	orderDTO := dto.OrderDTO{CustomerCPF: "00551146010", Items: []dto.OrderItemDTO{{ProductId: 1, Quantity: 1, Type: dto.OrderItemTypeUnit}}}
+
+	_, err := orderUsecase.CreateOrder(orderDTO)
+	assert.EqualError(t, err, "connection refused")
+
+	inner.err = nil
+	response, err := orderUsecase.CreateOrder(orderDTO)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, response.OrderID)
+}
+
+func TestNewDedupOrderUsecase_ZeroWindow(t *testing.T) {
+	inner := &countingOrderUsecase{}
+
+	assert.Same(t, inner, NewDedupOrderUsecase(inner, 0, nil))
+}
@@ -0,0 +1,88 @@
+package usecases
+
+import (
+	"fmt"
+	"g37-lanchonete/internal/core/entities"
+	"strconv"
+	"strings"
+)
+
+const (
+	OrderNumberModeID     = ""
+	OrderNumberModeDaily  = "daily"
+	OrderNumberModeHashed = "hashed"
+)
+
+const (
+	// unambiguous characters only, so the code can be read aloud at the counter
+	hashedOrderNumberAlphabet = "23456789ABCDEFGHJKLMNPQRSTUVWXYZ"
+	hashedOrderNumberLength   = 6
+	hashedOrderNumberSpace    = 1 << (5 * hashedOrderNumberLength)
+	// odd multiplier, so the scrambling is a bijection over the code space and two ids never share a code
+	hashedOrderNumberMultiplier = 0x2545F491
+	hashedOrderNumberMask       = 0x15A4E35
+)
+
+// OrderNumberGenerator builds the number shown to the customers, so the database id is not exposed on receipts.
+type OrderNumberGenerator interface {
+	Generate(order entities.Order, nextDailySequence func() (int, error)) (string, error)
+	Normalize(number string) string
+}
+
+type orderNumberGenerator struct {
+	mode string
+}
+
+func NewOrderNumberGenerator(mode string) OrderNumberGenerator {
+	return orderNumberGenerator{
+		mode: strings.ToLower(strings.TrimSpace(mode)),
+	}
+}
+
+// Generate is meant to run while the order is saved, as a gateways.OrderNumberFunc, so the daily sequence is only
+// taken along with the order.
+func (g orderNumberGenerator) Generate(order entities.Order, nextDailySequence func() (int, error)) (string, error) {
+	switch g.mode {
+	case OrderNumberModeDaily:
+		sequence, err := nextDailySequence()
+		if err != nil {
+			return "", fmt.Errorf("failed to get the daily order sequence, error %w", err)
+		}
+		return fmt.Sprintf("%03d", sequence), nil
+	case OrderNumberModeHashed:
+		return hashOrderNumber(order.ID), nil
+	}
+
+	return strconv.Itoa(order.ID), nil
+}
+
+// Normalize turns a number typed from a receipt into the stored format, so "42" finds the daily number "042"
+// and the hashed codes ignore letter case.
+func (g orderNumberGenerator) Normalize(number string) string {
+	number = strings.TrimSpace(number)
+	switch g.mode {
+	case OrderNumberModeDaily:
+		if sequence, err := strconv.Atoi(number); err == nil && sequence >= 0 {
+			return fmt.Sprintf("%03d", sequence)
+		}
+	case OrderNumberModeHashed:
+		return strings.ToUpper(number)
+	default:
+		if id, err := strconv.Atoi(number); err == nil && id >= 0 {
+			return strconv.Itoa(id)
+		}
+	}
+
+	return number
+}
+
+func hashOrderNumber(id int) string {
+	value := (uint64(id)*hashedOrderNumberMultiplier ^ hashedOrderNumberMask) % hashedOrderNumberSpace
+
+	code := make([]byte, hashedOrderNumberLength)
+	for i := hashedOrderNumberLength - 1; i >= 0; i-- {
+		code[i] = hashedOrderNumberAlphabet[value%32]
+		value /= 32
+	}
+	return string(code)
+}
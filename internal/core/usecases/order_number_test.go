@@ -0,0 +1,109 @@
+package usecases
+
+import (
+	"errors"
+	"g37-lanchonete/internal/core/entities"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderNumberGenerator_Generate(t *testing.T) {
+	createdAt := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	type sequenceCall struct {
+		times    int
+		sequence int
+		err      error
+	}
+	type want struct {
+		number string
+		err    bool
+	}
+	tests := []struct {
+		name         string
+		mode         string
+		sequenceCall sequenceCall
+		want         want
+	}{
+		{
+			name: "should use the order id when no mode is configured",
+			mode: OrderNumberModeID,
+			want: want{number: "98765"},
+		},
+		{
+			name:         "should use the daily sequence padded to three digits",
+			mode:         OrderNumberModeDaily,
+			sequenceCall: sequenceCall{times: 1, sequence: 7},
+			want:         want{number: "007"},
+		},
+		{
+			name:         "should fail when the daily sequence can not be incremented",
+			mode:         OrderNumberModeDaily,
+			sequenceCall: sequenceCall{times: 1, err: errors.New("connection refused")},
+			want:         want{err: true},
+		},
+		{
+			name: "should use a hashed code",
+			mode: OrderNumberModeHashed,
+			want: want{number: hashOrderNumber(98765)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sequenceCalls := 0
+			nextDailySequence := func() (int, error) {
+				sequenceCalls++
+				return tt.sequenceCall.sequence, tt.sequenceCall.err
+			}
+
+			number, err := NewOrderNumberGenerator(tt.mode).
+				Generate(entities.Order{ID: 98765, CreatedAt: entities.NewTimestamp(createdAt)}, nextDailySequence)
+
+			assert.Equal(t, tt.want.err, err != nil)
+			assert.Equal(t, tt.want.number, number)
+			assert.Equal(t, tt.sequenceCall.times, sequenceCalls)
+		})
+	}
+}
+
+func TestOrderNumberGenerator_Normalize(t *testing.T) {
+	tests := []struct {
+		name   string
+		mode   string
+		number string
+		want   string
+	}{
+		{name: "should pad the daily numbers typed without the leading zeros", mode: OrderNumberModeDaily, number: " 42", want: "042"},
+		{name: "should keep the daily numbers above three digits", mode: OrderNumberModeDaily, number: "1234", want: "1234"},
+		{name: "should uppercase the hashed codes", mode: OrderNumberModeHashed, number: "k7mq2x", want: "K7MQ2X"},
+		{name: "should drop the leading zeros of order ids", mode: OrderNumberModeID, number: "00123", want: "123"},
+		{name: "should keep anything else as typed", mode: OrderNumberModeDaily, number: "A-12", want: "A-12"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NewOrderNumberGenerator(tt.mode).Normalize(tt.number))
+		})
+	}
+}
+
+func TestHashOrderNumber(t *testing.T) {
+	seen := map[string]int{}
+	for id := 1; id <= 100000; id++ {
+		number := hashOrderNumber(id)
+
+		assert.Len(t, number, hashedOrderNumberLength)
+		for _, c := range number {
+			assert.True(t, strings.ContainsRune(hashedOrderNumberAlphabet, c), "unexpected character %q in %s", c, number)
+		}
+		if previous, ok := seen[number]; ok {
+			t.Fatalf("ids %d and %d share the number %s", previous, id, number)
+		}
+		seen[number] = id
+	}
+
+	assert.NotEqual(t, hashOrderNumber(1)[:3], hashOrderNumber(2)[:3])
+}
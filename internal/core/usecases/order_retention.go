@@ -0,0 +1,47 @@
+package usecases
+
+import (
+	"g37-lanchonete/internal/infra/gateways"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// OrderRetentionUsecase anonymizes the personal data of the finished orders once they are older than the retention period.
+type OrderRetentionUsecase interface {
+	AnonymizeExpiredOrders() (int, error)
+}
+
+type orderRetentionUsecase struct {
+	orderRepositoryGateway gateways.OrderRepositoryGateway
+	retention              time.Duration
+	clock                  Clock
+}
+
+// NewOrderRetentionUsecase keeps the DONE orders personal data for the retention period, a retention of zero disables the anonymization.
+func NewOrderRetentionUsecase(orderRepositoryGateway gateways.OrderRepositoryGateway, retention time.Duration, clock Clock) OrderRetentionUsecase {
+	return orderRetentionUsecase{
+		orderRepositoryGateway: orderRepositoryGateway,
+		retention:              retention,
+		clock:                  clock,
+	}
+}
+
+func (u orderRetentionUsecase) AnonymizeExpiredOrders() (int, error) {
+	if u.retention <= 0 {
+		return 0, nil
+	}
+
+	now := u.clock.Now()
+	anonymized, err := u.orderRepositoryGateway.AnonymizeDoneOrders(now.Add(-u.retention), now)
+	if err != nil {
+		log.Errorf("failed to anonymize orders older than [%s], error: %v", u.retention, err)
+		return 0, err
+	}
+
+	if anonymized > 0 {
+		log.Infof("anonymized [%d] done orders older than [%s]", anonymized, u.retention)
+	}
+
+	return anonymized, nil
+}
@@ -0,0 +1,102 @@
+package usecases
+
+import (
+	"errors"
+	"g37-lanchonete/internal/core/entities"
+	mock_gateways "g37-lanchonete/internal/infra/gateways/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// fakeOrderStore emulates the anonymization of the orders table in memory.
+type fakeOrderStore struct {
+	orders     []entities.Order
+	anonymized map[int]time.Time
+}
+
+func (s *fakeOrderStore) AnonymizeDoneOrders(createdBefore time.Time, anonymizedAt time.Time) (int, error) {
+	count := 0
+	for i, order := range s.orders {
+		if _, done := s.anonymized[order.ID]; done || order.Status != "DONE" || !order.CreatedAt.Before(createdBefore) {
+			continue
+		}
+		s.orders[i].Customer = entities.Customer{}
+		s.orders[i].DeliveryAddress = nil
+		s.orders[i].Notes = ""
+		s.anonymized[order.ID] = anonymizedAt
+		count++
+	}
+	return count, nil
+}
+
+func TestOrderRetentionUsecase_AnonymizeExpiredOrders(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	customer := entities.Customer{ID: 1, Name: "Maria", Cpf: "00551146010", Email: "maria@email.com"}
+	address := &entities.Address{Street: "Rua A", Number: "10"}
+	store := &fakeOrderStore{
+		anonymized: map[int]time.Time{},
+		orders: []entities.Order{
+			{ID: 1, Status: "DONE", TotalAmount: 25, Customer: customer, DeliveryAddress: address, Notes: "ligar para 11912345678", CreatedAt: entities.NewTimestamp(start.AddDate(0, 0, -40))},
+			{ID: 2, Status: "DONE", TotalAmount: 18, Customer: customer, CreatedAt: entities.NewTimestamp(start.AddDate(0, 0, -10))},
+			{ID: 3, Status: "READY", TotalAmount: 12, Customer: customer, CreatedAt: entities.NewTimestamp(start.AddDate(0, 0, -40))},
+		},
+	}
+
+	ctrl := gomock.NewController(t)
+	orderRepositoryGateway := mock_gateways.NewMockOrderRepositoryGateway(ctrl)
+	orderRepositoryGateway.EXPECT().
+		AnonymizeDoneOrders(gomock.Any(), gomock.Any()).
+		DoAndReturn(store.AnonymizeDoneOrders).
+		AnyTimes()
+	clock := &fakeClock{now: start}
+	orderRetentionUsecase := NewOrderRetentionUsecase(orderRepositoryGateway, 30*24*time.Hour, clock)
+
+	anonymized, err := orderRetentionUsecase.AnonymizeExpiredOrders()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, anonymized)
+	assert.Equal(t, entities.Order{ID: 1, Status: "DONE", TotalAmount: 25, CreatedAt: entities.NewTimestamp(start.AddDate(0, 0, -40))}, store.orders[0])
+	assert.Equal(t, start, store.anonymized[1])
+	assert.Equal(t, customer, store.orders[1].Customer)
+	assert.Equal(t, customer, store.orders[2].Customer)
+
+	// a new run on the same day finds nothing left to anonymize
+	anonymized, err = orderRetentionUsecase.AnonymizeExpiredOrders()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, anonymized)
+
+	// twenty days later the second order is past the retention period, the order not done is kept
+	clock.now = start.AddDate(0, 0, 21)
+	anonymized, err = orderRetentionUsecase.AnonymizeExpiredOrders()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, anonymized)
+	assert.Equal(t, entities.Customer{}, store.orders[1].Customer)
+	assert.Equal(t, float64(18), store.orders[1].TotalAmount)
+	assert.Equal(t, customer, store.orders[2].Customer)
+}
+
+func TestOrderRetentionUsecase_AnonymizeExpiredOrders_Disabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	orderRepositoryGateway := mock_gateways.NewMockOrderRepositoryGateway(ctrl)
+	orderRepositoryGateway.EXPECT().AnonymizeDoneOrders(gomock.Any(), gomock.Any()).Times(0)
+
+	anonymized, err := NewOrderRetentionUsecase(orderRepositoryGateway, 0, &fakeClock{}).AnonymizeExpiredOrders()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, anonymized)
+}
+
+func TestOrderRetentionUsecase_AnonymizeExpiredOrders_Error(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	orderRepositoryGateway := mock_gateways.NewMockOrderRepositoryGateway(ctrl)
+	storeErr := errors.New("connection refused")
+	orderRepositoryGateway.EXPECT().AnonymizeDoneOrders(gomock.Any(), gomock.Any()).Return(0, storeErr).Times(1)
+
+	_, err := NewOrderRetentionUsecase(orderRepositoryGateway, time.Hour, &fakeClock{}).AnonymizeExpiredOrders()
+
+	assert.ErrorIs(t, err, storeErr)
+}
@@ -1,39 +1,92 @@
 package usecases
 
 import (
+	"errors"
+	"fmt"
 	"g37-lanchonete/internal/core/entities"
 	"g37-lanchonete/internal/core/usecases/dto"
+	"g37-lanchonete/internal/infra/drivers/events"
+	"g37-lanchonete/internal/infra/drivers/loyalty"
+	"g37-lanchonete/internal/infra/drivers/qrcode"
+	"g37-lanchonete/internal/infra/drivers/sql"
 	"g37-lanchonete/internal/infra/gateways"
+	"math"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 )
 
 type OrderUsecase interface {
-	GetAllOrders(pageParameters dto.PageParams) (dto.Page[entities.Order], error)
+	GetAllOrders(pageParameters dto.PageParams, filters dto.OrderFilters) (dto.Page[entities.Order], error)
+	GetOrderQueue(pageParameters dto.PageParams, filters dto.OrderFilters) (dto.Page[dto.QueueOrderDTO], error)
+	GetOrderByNumber(number string) (entities.Order, error)
+	ConvertOrderTotal(order entities.Order, currency string) (dto.DisplayTotalDTO, error)
 	GetOrderStatus(orderId int) (dto.OrderStatusDTO, error)
-	UpdateOrderStatus(orderId int, orderStatus string) error
+	GetOrderStatuses(orderIds []int) (dto.OrderStatusesDTO, error)
+	GetCompletedOrders(since time.Time) ([]dto.CompletedOrderDTO, error)
+	GetOrderThroughput(from, to time.Time) (dto.OrderThroughputDTO, error)
+	UpdateOrderStatus(orderId int, orderStatus string, expectedStatus string) error
 	CreateOrder(orderDTO dto.OrderDTO) (dto.OrderCreationResponse, error)
-	CreateOrderPayment(orderId int) error
+	BatchCreateOrders(orderDTOs []dto.OrderDTO) []dto.BatchOrderResultDTO
+	ValidateOrder(orderDTO dto.OrderDTO) ([]dto.FieldError, error)
+	PriceItems(itemDTOs []dto.OrderItemDTO) (dto.OrderTotals, error)
+	AddOrderItem(orderId int, itemDTO dto.OrderItemDTO) (dto.OrderItemsUpdateResponse, error)
+	RemoveOrderItem(orderId int, itemId int) (dto.OrderItemsUpdateResponse, error)
+	RecalculateOrder(orderId int) (dto.OrderItemsUpdateResponse, error)
+	UpdateOrderItemStatus(orderId int, itemId int, itemStatus dto.OrderItemStatus) (dto.OrderItemStatusResponseDTO, error)
+	ConfirmOrderPayment(orderId int) error
+	ReplayOrderPayment(orderId int) (dto.PaymentReplayResponseDTO, error)
+	ReopenOrder(orderId int) error
+	CancelUnpaidOrders(cpf string) (dto.UnpaidOrdersCancellationDTO, error)
+	GetOrderQRCodePNG(orderId int) ([]byte, error)
+	GetOrderPaymentQRCode(orderId int) (dto.PaymentQRCode, error)
+	SendOrderFeedback(orderId int, feedbackDTO dto.OrderFeedbackDTO) (entities.OrderFeedback, error)
 }
 
 type orderUsecase struct {
-	authorizerUsecase      AuthorizerUsecase
-	paymentUsecase         PaymentUsecase
-	productUsecase         ProductUsecase
-	orderRepositoryGateway gateways.OrderRepositoryGateway
+	authorizerUsecase       AuthorizerUsecase
+	paymentUsecase          PaymentUsecase
+	productUsecase          ProductUsecase
+	orderRepositoryGateway  gateways.OrderRepositoryGateway
+	couponRepositoryGateway gateways.CouponRepositoryGateway
+	taxCalculator           TaxCalculator
+	noteRedactor            NoteRedactor
+	eventPublisher          events.Publisher
+	qrCodeRenderer          qrcode.Renderer
+	orderNumberGenerator    OrderNumberGenerator
+	businessHours           BusinessHours
+	storeCreditProvider     loyalty.StoreCreditProvider
+	exchangeRateProvider    ExchangeRateProvider
+	reopenGracePeriod       time.Duration
+	clock                   Clock
 }
 
-func NewOrderUsecase(authorizerUsecase AuthorizerUsecase, paymentUsecase PaymentUsecase, productUsecase ProductUsecase, orderRepositoryGateway gateways.OrderRepositoryGateway) OrderUsecase {
+func NewOrderUsecase(authorizerUsecase AuthorizerUsecase, paymentUsecase PaymentUsecase, productUsecase ProductUsecase, orderRepositoryGateway gateways.OrderRepositoryGateway, couponRepositoryGateway gateways.CouponRepositoryGateway, taxCalculator TaxCalculator, noteRedactor NoteRedactor, eventPublisher events.Publisher, qrCodeRenderer qrcode.Renderer, orderNumberGenerator OrderNumberGenerator, businessHours BusinessHours, storeCreditProvider loyalty.StoreCreditProvider, exchangeRateProvider ExchangeRateProvider, reopenGracePeriod time.Duration, clock Clock) OrderUsecase {
 	return orderUsecase{
-		authorizerUsecase:      authorizerUsecase,
-		paymentUsecase:         paymentUsecase,
-		productUsecase:         productUsecase,
-		orderRepositoryGateway: orderRepositoryGateway,
+		authorizerUsecase:       authorizerUsecase,
+		paymentUsecase:          paymentUsecase,
+		productUsecase:          productUsecase,
+		orderRepositoryGateway:  orderRepositoryGateway,
+		couponRepositoryGateway: couponRepositoryGateway,
+		taxCalculator:           taxCalculator,
+		noteRedactor:            noteRedactor,
+		eventPublisher:          eventPublisher,
+		qrCodeRenderer:          qrCodeRenderer,
+		orderNumberGenerator:    orderNumberGenerator,
+		businessHours:           businessHours,
+		storeCreditProvider:     storeCreditProvider,
+		exchangeRateProvider:    exchangeRateProvider,
+		reopenGracePeriod:       reopenGracePeriod,
+		clock:                   clock,
 	}
 }
 
-func (u orderUsecase) GetAllOrders(pageParams dto.PageParams) (dto.Page[entities.Order], error) {
-	orders, err := u.orderRepositoryGateway.FindAllOrders(pageParams)
+func (u orderUsecase) GetAllOrders(pageParams dto.PageParams, filters dto.OrderFilters) (dto.Page[entities.Order], error) {
+	if filters.Today {
+		filters.CreatedFrom, filters.CreatedBefore = u.businessHours.Day(u.clock.Now())
+	}
+
+	orders, err := u.orderRepositoryGateway.FindAllOrders(pageParams, filters)
 	if err != nil {
 		log.Errorf("failed to get all orders, error: %v", err)
 		return dto.Page[entities.Order]{}, err
@@ -43,7 +96,29 @@ func (u orderUsecase) GetAllOrders(pageParams dto.PageParams) (dto.Page[entities
 	return page, nil
 }
 
+// GetOrderQueue lists the same orders of GetAllOrders in the slim projection of the kitchen queue.
+func (u orderUsecase) GetOrderQueue(pageParams dto.PageParams, filters dto.OrderFilters) (dto.Page[dto.QueueOrderDTO], error) {
+	if filters.Today {
+		filters.CreatedFrom, filters.CreatedBefore = u.businessHours.Day(u.clock.Now())
+	}
+
+	orders, err := u.orderRepositoryGateway.FindQueueOrders(pageParams, filters)
+	if err != nil {
+		log.Errorf("failed to get the order queue, error: %v", err)
+		return dto.Page[dto.QueueOrderDTO]{}, err
+	}
+
+	return dto.BuildPage[dto.QueueOrderDTO](orders, pageParams), nil
+}
+
 func (u orderUsecase) CreateOrder(orderDTO dto.OrderDTO) (dto.OrderCreationResponse, error) {
+	// Pedidos só são aceitos com a loja aberta
+	err := u.businessHours.CheckOpen(u.clock.Now())
+	if err != nil {
+		log.Infof("order rejected outside the business hours, error: %v", err)
+		return dto.OrderCreationResponse{}, err
+	}
+
 	// Authorize user
 	user, err := u.authorizerUsecase.AuthorizeUser(orderDTO.CustomerCPF)
 	if err != nil {
@@ -53,24 +128,63 @@ func (u orderUsecase) CreateOrder(orderDTO dto.OrderDTO) (dto.OrderCreationRespo
 
 	// Criar um pedido a partir do DTO
 	order := orderDTO.ToOrder(entities.Customer{ID: user.UserId})
+	order.Notes = u.noteRedactor.Redact(order.Notes)
 
 	// Calcular o total dos produtos
-	totalAmount, err := u.calculateProducts(order.Items)
+	totals, err := u.calculateProducts(order.Items)
 	if err != nil {
 		log.Errorf("failed to calculate products, error: %v", err)
 		return dto.OrderCreationResponse{}, err
 	}
 
+	// Descontar o cupom do total
+	totals, err = u.applyCoupon(order.Coupon, totals, u.clock.Now())
+	if err != nil {
+		return dto.OrderCreationResponse{}, err
+	}
+	order.DiscountAmount = totals.Discount
+
+	// Descontar o crédito da loja do total a pagar
+	err = u.checkStoreCredit(user.UserId, orderDTO.StoreCreditToApply)
+	if err != nil {
+		log.Errorf("failed to apply store credit of customer [%d], error: %v", user.UserId, err)
+		return dto.OrderCreationResponse{}, err
+	}
+	totals, order.StoreCreditAmount = applyStoreCredit(totals, orderDTO.StoreCreditToApply)
+
 	// Definir o total no pedido
-	order.TotalAmount = totalAmount
+	order.SubtotalAmount = totals.Subtotal
+	order.TaxAmount = totals.Tax
+	order.TotalAmount = totals.Total
+
+	// Pedidos sem valor a pagar não passam pelo pagamento
+	freeOrder := order.TotalAmount <= 0
+	if freeOrder {
+		order.Status = string(dto.OrderStatusPaid)
+	}
 
-	// Salvar o pedido no banco de dados
-	order.ID, err = u.saveOrder(order)
+	// Salvar o pedido no banco de dados junto com o número exibido para o cliente
+	order.ID, order.Number, err = u.saveOrder(order)
 	if err != nil {
 		log.Errorf("failed to save order, error: %v", err)
 		return dto.OrderCreationResponse{}, err
 	}
 
+	u.publishEvent(events.Event{
+		Type:    events.OrderCreated,
+		OrderID: order.ID,
+		Payload: map[string]interface{}{
+			"number": order.Number,
+			"status": order.Status,
+			"total":  order.TotalAmount,
+		},
+	})
+
+	if freeOrder {
+		log.Infof("order id [%d] has no amount to pay, skipping payment", order.ID)
+		return dto.OrderCreationResponse{OrderID: order.ID, Number: order.Number}, nil
+	}
+
 	// Gerar o código QR para o pagamento
 	paymentQRCode, err := u.paymentUsecase.GeneratePaymentQRCode(order)
 	if err != nil {
@@ -78,28 +192,496 @@ func (u orderUsecase) CreateOrder(orderDTO dto.OrderDTO) (dto.OrderCreationRespo
 		return dto.OrderCreationResponse{}, err
 	}
 
+	// Guardar o código QR para que possa ser consultado como imagem depois
+	err = u.orderRepositoryGateway.UpdateOrderQRCode(order.ID, paymentQRCode)
+	if err != nil {
+		log.Errorf("failed to save payment qrcode from order id [%d], error: %v", order.ID, err)
+	}
+
 	// Construir a resposta com o código QR e o ID do pedido
 	response := dto.OrderCreationResponse{
-		QRCode:  paymentQRCode,
-		OrderID: order.ID,
+		QRCode:    paymentQRCode.QRCode,
+		ExpiresAt: paymentQRCode.ExpiresAt,
+		OrderID:   order.ID,
+		Number:    order.Number,
 	}
 
 	return response, nil
 }
 
-func (u orderUsecase) calculateProducts(items []entities.OrderItem) (float64, error) {
+// BatchCreateOrders validates and creates each order on its own, so an invalid or failed order does not stop the others.
+func (u orderUsecase) BatchCreateOrders(orderDTOs []dto.OrderDTO) []dto.BatchOrderResultDTO {
+	results := make([]dto.BatchOrderResultDTO, 0, len(orderDTOs))
+	for i, orderDTO := range orderDTOs {
+		result := dto.BatchOrderResultDTO{Index: i, Status: dto.BatchOrderStatusCreated}
+
+		if valid, err := orderDTO.ValidateOrder(); !valid {
+			result.Status = dto.BatchOrderStatusInvalid
+			result.Error = err.Error()
+			results = append(results, result)
+			continue
+		}
+
+		response, err := u.CreateOrder(orderDTO)
+		if err != nil {
+			log.Errorf("failed to create order [%d] of the batch, error: %v", i, err)
+			result.Status = dto.BatchOrderStatusFailed
+			result.Error = err.Error()
+		} else {
+			result.OrderID = response.OrderID
+			result.Number = response.Number
+			result.QRCode = response.QRCode
+			result.ExpiresAt = response.ExpiresAt
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// ValidateOrder checks the payload and the availability of its products, without pricing or saving the order.
+// The returned error is only set when the validation itself could not be performed.
+func (u orderUsecase) ValidateOrder(orderDTO dto.OrderDTO) ([]dto.FieldError, error) {
+	fieldErrors := orderDTO.CollectValidationErrors()
+
+	products := map[int]entities.Product{}
+	quantities := map[int]int{}
+	for i, item := range orderDTO.Items {
+		product, err := u.productUsecase.GetProductById(item.ProductId)
+		if err != nil {
+			if errors.Is(err, sql.ErrNotFound) {
+				fieldErrors = append(fieldErrors, dto.FieldError{
+					Field: fmt.Sprintf("items[%d].productId", i),
+					Err:   dto.OrderItemProductNotFoundError{ProductID: item.ProductId},
+				})
+				continue
+			}
+			log.Errorf("failed to find product [%d] to validate order, error: %v", item.ProductId, err)
+			return nil, err
+		}
+		products[item.ProductId] = product
+		quantities[item.ProductId] += item.Quantity
+
+		_, err = u.resolveVariant(item.ProductId, item.VariantId)
+		if err != nil {
+			if isProductVariantError(err) {
+				fieldErrors = append(fieldErrors, dto.FieldError{Field: fmt.Sprintf("items[%d].variantId", i), Err: err})
+				continue
+			}
+			log.Errorf("failed to find variants of product [%d] to validate order, error: %v", item.ProductId, err)
+			return nil, err
+		}
+	}
+
+	// the limits apply to the units of the whole order, so they are reported once, on the first item of the product
+	for i, item := range orderDTO.Items {
+		product, found := products[item.ProductId]
+		if !found {
+			continue
+		}
+		delete(products, item.ProductId)
+
+		if err := dto.CheckProductQuantity(product, quantities[item.ProductId]); err != nil {
+			fieldErrors = append(fieldErrors, dto.FieldError{Field: fmt.Sprintf("items[%d].quantity", i), Err: err})
+		}
+	}
+
+	return fieldErrors, nil
+}
+
+// PriceItems prices a cart the same way CreateOrder does, without authorizing the customer or saving anything.
+func (u orderUsecase) PriceItems(itemDTOs []dto.OrderItemDTO) (dto.OrderTotals, error) {
+	items := make([]entities.OrderItem, len(itemDTOs))
+	for i, itemDTO := range itemDTOs {
+		items[i] = itemDTO.ToOrderItem()
+	}
+
+	totals, err := u.calculateProducts(items)
+	if err != nil {
+		// a missing product is a mistake in the cart, so the one missing is looked up to be reported
+		if errors.Is(err, sql.ErrNotFound) {
+			for _, itemDTO := range itemDTOs {
+				if _, findErr := u.productUsecase.GetProductById(itemDTO.ProductId); errors.Is(findErr, sql.ErrNotFound) {
+					return dto.OrderTotals{}, dto.OrderItemProductNotFoundError{ProductID: itemDTO.ProductId}
+				}
+			}
+		}
+		return dto.OrderTotals{}, err
+	}
+
+	return totals, nil
+}
+
+// AddOrderItem appends an item to an order still waiting for payment, checking the product and variant picked,
+// recalculating the totals and refreshing the payment qrcode to the new amount.
+func (u orderUsecase) AddOrderItem(orderId int, itemDTO dto.OrderItemDTO) (dto.OrderItemsUpdateResponse, error) {
+	order, err := u.findEditableOrder(orderId)
+	if err != nil {
+		return dto.OrderItemsUpdateResponse{}, err
+	}
+
+	order.Items = append(order.Items, itemDTO.ToOrderItem())
+	totals, err := u.calculateProducts(order.Items)
+	if err != nil {
+		// the products of the current items can't be deleted while the order is active, so only the new one is missing
+		if errors.Is(err, sql.ErrNotFound) {
+			return dto.OrderItemsUpdateResponse{}, dto.OrderItemProductNotFoundError{ProductID: itemDTO.ProductId}
+		}
+		log.Errorf("failed to calculate products of order id [%d], error: %v", orderId, err)
+		return dto.OrderItemsUpdateResponse{}, err
+	}
+	totals, err = u.applyCoupon(order.Coupon, totals, order.CreatedAt.Time)
+	if err != nil {
+		return dto.OrderItemsUpdateResponse{}, err
+	}
+	// the credit applied on the creation keeps paying part of the new total, up to the new total
+	totals, _ = applyStoreCredit(totals, order.StoreCreditAmount)
+
+	newItem := len(order.Items) - 1
+	order.Items[newItem].ID, err = u.orderRepositoryGateway.AddOrderItem(orderId, order.Items[newItem], totals)
+	if err != nil {
+		log.Errorf("failed to add item to order id [%d], error: %v", orderId, err)
+		return dto.OrderItemsUpdateResponse{}, err
+	}
+
+	return u.refreshOrderPayment(order, totals)
+}
+
+// RemoveOrderItem removes an item from an order still waiting for payment, recalculating its totals and
+// refreshing the payment qrcode to the new amount. Removing the last item is rejected, an order keeps at least one.
+func (u orderUsecase) RemoveOrderItem(orderId int, itemId int) (dto.OrderItemsUpdateResponse, error) {
+	order, err := u.findEditableOrder(orderId)
+	if err != nil {
+		return dto.OrderItemsUpdateResponse{}, err
+	}
+
+	found := false
+	items := make([]entities.OrderItem, 0, len(order.Items))
+	for _, item := range order.Items {
+		if item.ID == itemId {
+			found = true
+			continue
+		}
+		items = append(items, item)
+	}
+
+	if !found {
+		return dto.OrderItemsUpdateResponse{}, dto.ErrOrderItemNotFound
+	}
+
+	if len(items) == 0 {
+		return dto.OrderItemsUpdateResponse{}, dto.ErrOrderLastItem
+	}
+
+	order.Items = items
+	totals, err := u.calculateProducts(order.Items)
+	if err != nil {
+		log.Errorf("failed to calculate products of order id [%d], error: %v", orderId, err)
+		return dto.OrderItemsUpdateResponse{}, err
+	}
+	totals, err = u.applyCoupon(order.Coupon, totals, order.CreatedAt.Time)
+	if err != nil {
+		return dto.OrderItemsUpdateResponse{}, err
+	}
+	// the credit applied on the creation keeps paying part of the new total, up to the new total
+	totals, _ = applyStoreCredit(totals, order.StoreCreditAmount)
+
+	err = u.orderRepositoryGateway.RemoveOrderItem(orderId, itemId, totals)
+	if err != nil {
+		log.Errorf("failed to remove item [%d] from order id [%d], error: %v", itemId, orderId, err)
+		return dto.OrderItemsUpdateResponse{}, err
+	}
+
+	return u.refreshOrderPayment(order, totals)
+}
+
+// RecalculateOrder prices the items of an order still waiting for payment again from the current products and applies
+// its coupon to the new total, so its stored totals and payment qrcode follow price changes made after the order was
+// placed.
+func (u orderUsecase) RecalculateOrder(orderId int) (dto.OrderItemsUpdateResponse, error) {
+	order, err := u.findEditableOrder(orderId)
+	if err != nil {
+		return dto.OrderItemsUpdateResponse{}, err
+	}
+
+	totals, err := u.calculateProducts(order.Items)
+	if err != nil {
+		log.Errorf("failed to calculate products of order id [%d], error: %v", orderId, err)
+		return dto.OrderItemsUpdateResponse{}, err
+	}
+	totals, err = u.applyCoupon(order.Coupon, totals, order.CreatedAt.Time)
+	if err != nil {
+		return dto.OrderItemsUpdateResponse{}, err
+	}
+	// the credit applied on the creation keeps paying part of the new total, up to the new total
+	totals, _ = applyStoreCredit(totals, order.StoreCreditAmount)
+
+	err = u.orderRepositoryGateway.UpdateOrderTotals(orderId, totals)
+	if err != nil {
+		log.Errorf("failed to update totals of order id [%d], error: %v", orderId, err)
+		return dto.OrderItemsUpdateResponse{}, err
+	}
+
+	return u.refreshOrderPayment(order, totals)
+}
+
+// findEditableOrder loads the order, which can only have its items changed before the payment.
+func (u orderUsecase) findEditableOrder(orderId int) (entities.Order, error) {
+	order, err := u.orderRepositoryGateway.FindOrderById(orderId)
+	if err != nil {
+		log.Errorf("failed to find order id [%d], error: %v", orderId, err)
+		return entities.Order{}, err
+	}
+
+	if dto.OrderStatus(order.Status) != dto.OrderStatusCreated {
+		return entities.Order{}, dto.ErrOrderNotEditable
+	}
+
+	return order, nil
+}
+
+// UpdateOrderItemStatus tracks the items the kitchen already finished, the order moves to READY along with its last
+// pending item.
+func (u orderUsecase) UpdateOrderItemStatus(orderId int, itemId int, itemStatus dto.OrderItemStatus) (dto.OrderItemStatusResponseDTO, error) {
+	advanced, err := u.orderRepositoryGateway.UpdateOrderItemStatus(orderId, itemId, string(itemStatus))
+	if err != nil {
+		log.Errorf("failed to update status of item [%d] from order id [%d], error: %v", itemId, orderId, err)
+		return dto.OrderItemStatusResponseDTO{}, err
+	}
+
+	orderStatus := dto.OrderStatusInProgress
+	if advanced {
+		orderStatus = dto.OrderStatusReady
+		u.publishEvent(events.Event{
+			Type:    events.OrderStatusChanged,
+			OrderID: orderId,
+			Payload: map[string]interface{}{
+				"from": string(dto.OrderStatusInProgress),
+				"to":   string(dto.OrderStatusReady),
+			},
+		})
+	}
+
+	return dto.OrderItemStatusResponseDTO{
+		OrderID:     orderId,
+		ItemID:      itemId,
+		ItemStatus:  itemStatus,
+		OrderStatus: orderStatus,
+	}, nil
+}
+
+// refreshOrderPayment generates a new payment qrcode after the items of the order changed, since the previous one
+// charges the old total. An order left with nothing to pay is confirmed as paid instead, as on its creation.
+func (u orderUsecase) refreshOrderPayment(order entities.Order, totals dto.OrderTotals) (dto.OrderItemsUpdateResponse, error) {
+	order.SubtotalAmount = totals.Subtotal
+	order.TaxAmount = totals.Tax
+	order.DiscountAmount = totals.Discount
+	order.StoreCreditAmount = totals.StoreCredit
+	order.TotalAmount = totals.Total
+
+	if order.TotalAmount <= 0 {
+		log.Infof("order id [%d] has no amount left to pay, skipping payment", order.ID)
+		_, _, err := u.confirmOrderPayment(order.ID)
+		if err != nil {
+			return dto.OrderItemsUpdateResponse{}, err
+		}
+
+		return dto.OrderItemsUpdateResponse{
+			OrderID:        order.ID,
+			Items:          order.Items,
+			SubtotalAmount: order.SubtotalAmount,
+			TaxAmount:      order.TaxAmount,
+			TotalAmount:    order.TotalAmount,
+		}, nil
+	}
+
+	paymentQRCode, err := u.paymentUsecase.GeneratePaymentQRCode(order)
+	if err != nil {
+		log.Errorf("failed to process payment of order id [%d], error: %v", order.ID, err)
+		return dto.OrderItemsUpdateResponse{}, err
+	}
+
+	err = u.orderRepositoryGateway.UpdateOrderQRCode(order.ID, paymentQRCode)
+	if err != nil {
+		log.Errorf("failed to save payment qrcode from order id [%d], error: %v", order.ID, err)
+	}
+
+	return dto.OrderItemsUpdateResponse{
+		OrderID:        order.ID,
+		Items:          order.Items,
+		SubtotalAmount: order.SubtotalAmount,
+		TaxAmount:      order.TaxAmount,
+		TotalAmount:    order.TotalAmount,
+		QRCode:         paymentQRCode.QRCode,
+		ExpiresAt:      paymentQRCode.ExpiresAt,
+	}, nil
+}
+
+// SendOrderFeedback stores the rating given by the customer, which is only accepted once the order is DONE.
+func (u orderUsecase) SendOrderFeedback(orderId int, feedbackDTO dto.OrderFeedbackDTO) (entities.OrderFeedback, error) {
+	status, err := u.orderRepositoryGateway.GetOrderStatus(orderId)
+	if err != nil {
+		log.Errorf("failed to get status of order id [%d] to send feedback, error: %v", orderId, err)
+		return entities.OrderFeedback{}, err
+	}
+
+	if dto.OrderStatus(status) != dto.OrderStatusDone {
+		return entities.OrderFeedback{}, dto.ErrOrderNotDone
+	}
+
+	feedback := feedbackDTO.ToOrderFeedback(orderId)
+	feedback.CreatedAt = entities.NewTimestamp(u.clock.Now())
+
+	feedback.ID, err = u.orderRepositoryGateway.SaveOrderFeedback(feedback)
+	if err != nil {
+		log.Errorf("failed to save feedback of order id [%d], error: %v", orderId, err)
+		return entities.OrderFeedback{}, err
+	}
+
+	return feedback, nil
+}
+
+func (u orderUsecase) calculateProducts(items []entities.OrderItem) (dto.OrderTotals, error) {
 	for i, item := range items {
 		product, err := u.getProduct(item.Product.ID)
 		if err != nil {
 			log.Errorf("failed to find products to process order, error: %v", err)
-			return 0.0, err
+			return dto.OrderTotals{}, err
+		}
+
+		var variantId int
+		if item.Variant != nil {
+			variantId = item.Variant.ID
+		}
+
+		variant, err := u.resolveVariant(product.ID, variantId)
+		if err != nil {
+			log.Errorf("failed to resolve variant of product [%d] to process order, error: %v", product.ID, err)
+			return dto.OrderTotals{}, err
+		}
+
+		// a sized variant has its own price, which replaces the one of the product for this item
+		if variant != nil {
+			product.Price = variant.Price
+			product.PriceNet = variant.PriceNet
+			product.PriceGross = variant.PriceGross
 		}
 		item.Product = product
+		item.Variant = variant
 		items[i] = item
 	}
 
-	totalAmount := u.calculateTotal(items)
-	return totalAmount, nil
+	if err := checkQuantityLimits(items); err != nil {
+		return dto.OrderTotals{}, err
+	}
+
+	totals, err := u.taxCalculator.CalculateOrderTotals(items)
+	if err != nil {
+		log.Errorf("failed to calculate order totals, error: %v", err)
+		return dto.OrderTotals{}, err
+	}
+
+	return totals, nil
+}
+
+// checkStoreCredit rejects credit over the balance of the customer, instead of charging the difference silently.
+func (u orderUsecase) checkStoreCredit(customerId int, credit float64) error {
+	if credit <= 0 {
+		return nil
+	}
+
+	if u.storeCreditProvider == nil {
+		return dto.ErrStoreCreditUnavailable
+	}
+
+	balance, err := u.storeCreditProvider.GetBalance(customerId)
+	if err != nil {
+		return err
+	}
+
+	if credit > balance {
+		return fmt.Errorf("%w, balance is %.2f", dto.ErrStoreCreditExceeded, balance)
+	}
+
+	return nil
+}
+
+// applyCoupon takes the discount of the coupon off the total when the coupon applies at the time the order was placed.
+// An order placed with a coupon that is unknown, expired or whose minimum total is not met keeps the code without any
+// discount.
+func (u orderUsecase) applyCoupon(code string, totals dto.OrderTotals, placedAt time.Time) (dto.OrderTotals, error) {
+	totals.Discount = 0
+	if code == "" {
+		return totals, nil
+	}
+
+	coupon, err := u.couponRepositoryGateway.FindCouponByCode(code)
+	if errors.Is(err, sql.ErrNotFound) {
+		log.Infof("coupon [%s] not found, order placed without discount", code)
+		return totals, nil
+	}
+	if err != nil {
+		log.Errorf("failed to find coupon [%s], error: %v", code, err)
+		return dto.OrderTotals{}, err
+	}
+
+	if reason := couponIneligibility(coupon, totals.Total, placedAt); reason != "" {
+		log.Infof("coupon [%s] does not apply, reason: %s, order placed without discount", code, reason)
+		return totals, nil
+	}
+
+	totals.Discount = couponDiscount(coupon, totals.Total)
+	totals.Total = roundMoney(totals.Total - totals.Discount)
+	return totals, nil
+}
+
+// applyStoreCredit takes the credit off the order total, clamped to the total so nothing is left to refund, and
+// returns the credit actually applied, which is kept on the totals as well.
+func applyStoreCredit(totals dto.OrderTotals, credit float64) (dto.OrderTotals, float64) {
+	applied := roundMoney(math.Min(credit, totals.Total))
+	totals.Total = roundMoney(totals.Total - applied)
+	totals.StoreCredit = applied
+	return totals, applied
+}
+
+// checkQuantityLimits sums the units of each product across the items, since its limits apply to the whole order.
+func checkQuantityLimits(items []entities.OrderItem) error {
+	quantities := map[int]int{}
+	for _, item := range items {
+		quantities[item.Product.ID] += item.Quantity
+	}
+
+	for _, item := range items {
+		if err := dto.CheckProductQuantity(item.Product, quantities[item.Product.ID]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resolveVariant checks the variant picked for the item, which is required when the product has variants.
+func (u orderUsecase) resolveVariant(productId int, variantId int) (*entities.ProductVariant, error) {
+	variants, err := u.productUsecase.GetProductVariants(productId)
+	if err != nil {
+		return nil, err
+	}
+
+	if variantId == 0 {
+		if len(variants) > 0 {
+			return nil, dto.ProductVariantRequiredError{ProductID: productId}
+		}
+		return nil, nil
+	}
+
+	for _, variant := range variants {
+		if variant.ID == variantId {
+			return &variant, nil
+		}
+	}
+
+	return nil, dto.InvalidProductVariantError{ProductID: productId, VariantID: variantId}
 }
 
 func (u orderUsecase) getProduct(id int) (entities.Product, error) {
@@ -112,49 +694,323 @@ func (u orderUsecase) getProduct(id int) (entities.Product, error) {
 	return product, nil
 }
 
-func (u orderUsecase) calculateTotal(items []entities.OrderItem) float64 {
-	var total float64
-	for _, item := range items {
-		total += item.Product.Price * float64(item.Quantity)
+func (u orderUsecase) saveOrder(order entities.Order) (int, string, error) {
+	orderId, number, err := u.orderRepositoryGateway.SaveOrder(order, u.orderNumberGenerator.Generate)
+	if err != nil {
+		return 0, "", err
 	}
-	return total
+
+	return orderId, number, nil
 }
 
-func (u orderUsecase) saveOrder(order entities.Order) (int, error) {
-	orderId, err := u.orderRepositoryGateway.SaveOrder(order)
+// GetOrderByNumber finds the order by the number printed on its receipt, the most recent one when the number repeats.
+func (u orderUsecase) GetOrderByNumber(number string) (entities.Order, error) {
+	order, err := u.orderRepositoryGateway.FindOrderByNumber(u.orderNumberGenerator.Normalize(number))
 	if err != nil {
-		return 0, err
+		log.Errorf("failed to find order number [%s], error: %v", number, err)
+		return entities.Order{}, err
 	}
 
-	return orderId, nil
+	return order, nil
+}
+
+// ConvertOrderTotal shows the order total in another currency, the order is still charged in the base currency.
+func (u orderUsecase) ConvertOrderTotal(order entities.Order, currency string) (dto.DisplayTotalDTO, error) {
+	if u.exchangeRateProvider == nil {
+		return dto.DisplayTotalDTO{}, fmt.Errorf("%w [%s]", dto.ErrUnsupportedCurrency, currency)
+	}
+
+	rate, err := u.exchangeRateProvider.Rate(currency)
+	if err != nil {
+		log.Errorf("failed to get the exchange rate of currency [%s], error: %v", currency, err)
+		return dto.DisplayTotalDTO{}, err
+	}
+
+	return dto.DisplayTotalDTO{
+		BaseCurrency: u.exchangeRateProvider.BaseCurrency(),
+		BaseAmount:   order.TotalAmount,
+		Currency:     normalizeCurrency(currency),
+		Rate:         rate,
+		Amount:       roundMoney(order.TotalAmount * rate),
+	}, nil
 }
 
 func (u orderUsecase) GetOrderStatus(orderId int) (dto.OrderStatusDTO, error) {
-	status, err := u.orderRepositoryGateway.GetOrderStatus(orderId)
+	status, updatedAt, err := u.orderRepositoryGateway.GetOrderStatusChange(orderId)
 	if err != nil {
 		return dto.OrderStatusDTO{}, err
 	}
 
 	return dto.OrderStatusDTO{
-		Status: dto.OrderStatus(status),
+		Status:    dto.OrderStatus(status),
+		UpdatedAt: updatedAt,
 	}, nil
 }
 
-func (u orderUsecase) UpdateOrderStatus(orderId int, orderStatus string) error {
-	err := u.orderRepositoryGateway.UpdateOrderStatus(orderId, orderStatus)
+// GetCompletedOrders lists the orders completed since the given time, for the pickup board. A zero since
+// falls back to the last dto.DefaultCompletedOrdersWindow.
+func (u orderUsecase) GetCompletedOrders(since time.Time) ([]dto.CompletedOrderDTO, error) {
+	if since.IsZero() {
+		since = u.clock.Now().Add(-dto.DefaultCompletedOrdersWindow)
+	}
+
+	orders, err := u.orderRepositoryGateway.FindCompletedOrders(since, dto.MaxCompletedOrders)
+	if err != nil {
+		log.Errorf("failed to find orders completed since [%s], error: %v", since.Format(time.RFC3339), err)
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+// GetOrderThroughput reports how long the orders stay in each status, aggregated per transition recorded between from and to.
+func (u orderUsecase) GetOrderThroughput(from, to time.Time) (dto.OrderThroughputDTO, error) {
+	transitions, err := u.orderRepositoryGateway.GetStatusTransitionStats(from, to)
+	if err != nil {
+		log.Errorf("failed to get order throughput, error: %v", err)
+		return dto.OrderThroughputDTO{}, err
+	}
+
+	return dto.OrderThroughputDTO{From: from, To: to, Transitions: transitions}, nil
+}
+
+// GetOrderStatuses fetches the statuses of several orders at once. Ids without a matching order are
+// reported in NotFound instead of failing the whole lookup.
+func (u orderUsecase) GetOrderStatuses(orderIds []int) (dto.OrderStatusesDTO, error) {
+	statuses, err := u.orderRepositoryGateway.GetOrderStatuses(orderIds)
+	if err != nil {
+		log.Errorf("failed to get order statuses, error: %v", err)
+		return dto.OrderStatusesDTO{}, err
+	}
+
+	response := dto.OrderStatusesDTO{
+		Statuses: make(map[int]dto.OrderStatus, len(statuses)),
+		NotFound: []int{},
+	}
+	seen := make(map[int]bool, len(orderIds))
+	for _, orderId := range orderIds {
+		if seen[orderId] {
+			continue
+		}
+		seen[orderId] = true
+
+		status, found := statuses[orderId]
+		if !found {
+			response.NotFound = append(response.NotFound, orderId)
+			continue
+		}
+		response.Statuses[orderId] = dto.OrderStatus(status)
+	}
+
+	return response, nil
+}
+
+// UpdateOrderStatus moves the order to orderStatus, which must be one of the statuses allowed after the current one,
+// otherwise dto.ErrOrderStatusConflict is returned. With an expectedStatus, the update only applies while the order is
+// still in it, the terminal that lost a concurrent update gets dto.ErrOrderStatusConflict. Without it, the current
+// status is read and expected, so the order can not skip a status moved by a concurrent update either.
+func (u orderUsecase) UpdateOrderStatus(orderId int, orderStatus string, expectedStatus string) error {
+	if expectedStatus == "" {
+		status, err := u.orderRepositoryGateway.GetOrderStatus(orderId)
+		if err != nil {
+			log.Errorf("failed to get order status from order id [%d], error: %v", orderId, err)
+			return err
+		}
+		expectedStatus = status
+	}
+
+	if !isAllowedNextStatus(dto.OrderStatus(expectedStatus), dto.OrderStatus(orderStatus)) {
+		return fmt.Errorf("%w, order can not move from [%s] to [%s]", dto.ErrOrderStatusConflict, expectedStatus, orderStatus)
+	}
+
+	err := u.orderRepositoryGateway.UpdateOrderStatus(orderId, orderStatus, expectedStatus)
+	if errors.Is(err, sql.ErrNotFound) {
+		// nothing changed, either the order does not exist or another update moved it first
+		status, statusErr := u.orderRepositoryGateway.GetOrderStatus(orderId)
+		if statusErr != nil {
+			log.Errorf("failed to get order status from order id [%d], error: %v", orderId, statusErr)
+			return statusErr
+		}
+
+		return fmt.Errorf("%w, expected [%s] but order is [%s]", dto.ErrOrderStatusConflict, expectedStatus, status)
+	}
 	if err != nil {
 		return err
 	}
 
+	u.publishEvent(events.Event{
+		Type:    events.OrderStatusChanged,
+		OrderID: orderId,
+		Payload: map[string]interface{}{
+			"from": expectedStatus,
+			"to":   orderStatus,
+		},
+	})
+
 	return nil
 }
 
-func (u orderUsecase) CreateOrderPayment(orderId int) error {
-	err := u.orderRepositoryGateway.UpdateOrderStatus(orderId, string(dto.OrderStatusPaid))
+// isAllowedNextStatus tells whether the order can move from the status to next within the active statuses.
+func isAllowedNextStatus(status dto.OrderStatus, next dto.OrderStatus) bool {
+	for _, allowed := range status.AllowedNext() {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// ConfirmOrderPayment moves the order from CREATED to PAID. Only the first confirmation performs the transition,
+// so concurrent confirmations from the webhook and the polling paths are no-ops returning success.
+func (u orderUsecase) ConfirmOrderPayment(orderId int) error {
+	_, _, err := u.confirmOrderPayment(orderId)
+	return err
+}
+
+// ReplayOrderPayment runs the confirmation of a payment webhook again, telling whether it changed the order.
+func (u orderUsecase) ReplayOrderPayment(orderId int) (dto.PaymentReplayResponseDTO, error) {
+	confirmed, status, err := u.confirmOrderPayment(orderId)
+	if err != nil {
+		return dto.PaymentReplayResponseDTO{}, err
+	}
+
+	return dto.PaymentReplayResponseDTO{OrderID: orderId, Confirmed: confirmed, Status: dto.OrderStatus(status)}, nil
+}
+
+func (u orderUsecase) confirmOrderPayment(orderId int) (bool, string, error) {
+	confirmed, err := u.orderRepositoryGateway.ConfirmOrderPayment(orderId)
 	if err != nil {
-		log.Errorf("failed to update order status from order id [%d], error: %v", orderId, err)
+		log.Errorf("failed to confirm payment from order id [%d], error: %v", orderId, err)
+		return false, "", err
+	}
+
+	if !confirmed {
+		// nothing changed, either the order does not exist or its payment was already confirmed
+		status, err := u.orderRepositoryGateway.GetOrderStatus(orderId)
+		if err != nil {
+			log.Errorf("failed to get order status from order id [%d], error: %v", orderId, err)
+			return false, "", err
+		}
+
+		log.Infof("payment from order id [%d] already confirmed, current status [%s]", orderId, status)
+		return false, status, nil
+	}
+
+	u.publishEvent(events.Event{
+		Type:    events.OrderStatusChanged,
+		OrderID: orderId,
+		Payload: map[string]interface{}{
+			"from": string(dto.OrderStatusCreated),
+			"to":   string(dto.OrderStatusPaid),
+		},
+	})
+
+	return true, string(dto.OrderStatusPaid), nil
+}
+
+// ReopenOrder moves an order marked DONE by mistake back to READY, which is only allowed within the grace period
+// counted from its completion.
+func (u orderUsecase) ReopenOrder(orderId int) error {
+	now := u.clock.Now()
+	reopened, err := u.orderRepositoryGateway.ReopenOrder(orderId, now.Add(-u.reopenGracePeriod), now)
+	if err != nil {
+		log.Errorf("failed to reopen order id [%d], error: %v", orderId, err)
 		return err
 	}
 
+	if !reopened {
+		status, err := u.orderRepositoryGateway.GetOrderStatus(orderId)
+		if err != nil {
+			log.Errorf("failed to get order status from order id [%d], error: %v", orderId, err)
+			return err
+		}
+
+		if dto.OrderStatus(status) != dto.OrderStatusDone {
+			return fmt.Errorf("%w, order is [%s]", dto.ErrOrderNotReopenable, status)
+		}
+		return fmt.Errorf("%w, the grace period of %s since its completion has expired", dto.ErrOrderNotReopenable, u.reopenGracePeriod)
+	}
+
+	u.publishEvent(events.Event{
+		Type:    events.OrderStatusChanged,
+		OrderID: orderId,
+		Payload: map[string]interface{}{
+			"from": string(dto.OrderStatusDone),
+			"to":   string(dto.OrderStatusReady),
+		},
+	})
+
 	return nil
-}
\ No newline at end of file
+}
+
+// CancelUnpaidOrders cancels the orders of the customer still waiting for their payment, the orders already paid are
+// left as they are.
+func (u orderUsecase) CancelUnpaidOrders(cpf string) (dto.UnpaidOrdersCancellationDTO, error) {
+	orderIds, err := u.orderRepositoryGateway.CancelUnpaidOrders(cpf, u.clock.Now())
+	if err != nil {
+		log.Errorf("failed to cancel unpaid orders of customer [%s], error: %v", cpf, err)
+		return dto.UnpaidOrdersCancellationDTO{}, err
+	}
+
+	for _, orderId := range orderIds {
+		u.publishEvent(events.Event{
+			Type:    events.OrderStatusChanged,
+			OrderID: orderId,
+			Payload: map[string]interface{}{
+				"from": string(dto.OrderStatusCreated),
+				"to":   string(dto.OrderStatusCancelled),
+			},
+		})
+	}
+
+	return dto.UnpaidOrdersCancellationDTO{CustomerCPF: cpf, Cancelled: len(orderIds)}, nil
+}
+
+// GetOrderQRCodePNG renders the stored payment qrcode, only while the order is still waiting for its payment.
+func (u orderUsecase) GetOrderQRCodePNG(orderId int) ([]byte, error) {
+	qrCode, err := u.GetOrderPaymentQRCode(orderId)
+	if err != nil {
+		return nil, err
+	}
+
+	png, err := u.qrCodeRenderer.RenderPNG(qrCode.QRCode)
+	if err != nil {
+		log.Errorf("failed to render payment qrcode from order id [%d], error: %v", orderId, err)
+		return nil, err
+	}
+
+	return png, nil
+}
+
+// GetOrderPaymentQRCode returns the stored payment qrcode with its expiration, only while the order is still waiting
+// for its payment.
+func (u orderUsecase) GetOrderPaymentQRCode(orderId int) (dto.PaymentQRCode, error) {
+	qrCode, status, err := u.orderRepositoryGateway.GetOrderQRCode(orderId)
+	if err != nil {
+		log.Errorf("failed to get payment qrcode from order id [%d], error: %v", orderId, err)
+		return dto.PaymentQRCode{}, err
+	}
+
+	if status != string(dto.OrderStatusCreated) {
+		return dto.PaymentQRCode{}, dto.ErrOrderPaymentNotPending
+	}
+
+	if qrCode.QRCode == "" {
+		return dto.PaymentQRCode{}, dto.ErrOrderQRCodeNotFound
+	}
+
+	return qrCode, nil
+}
+
+func isProductVariantError(err error) bool {
+	var requiredErr dto.ProductVariantRequiredError
+	var invalidErr dto.InvalidProductVariantError
+	return errors.As(err, &requiredErr) || errors.As(err, &invalidErr)
+}
+
+func (u orderUsecase) publishEvent(event events.Event) {
+	event.OccurredAt = u.clock.Now()
+	if err := u.eventPublisher.Publish(event); err != nil {
+		log.Errorf("failed to publish event [%s] from order id [%d], error: %v", event.Type, event.OrderID, err)
+	}
+}
@@ -0,0 +1,1914 @@
+package usecases
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"g37-lanchonete/internal/core/entities"
+	"g37-lanchonete/internal/core/usecases/dto"
+	mock_auth "g37-lanchonete/internal/infra/drivers/auth/mocks"
+	"g37-lanchonete/internal/infra/drivers/events"
+	mock_events "g37-lanchonete/internal/infra/drivers/events/mocks"
+	"g37-lanchonete/internal/infra/drivers/loyalty"
+	mock_payment "g37-lanchonete/internal/infra/drivers/payment/mocks"
+	"g37-lanchonete/internal/infra/drivers/qrcode"
+	"g37-lanchonete/internal/infra/drivers/sql"
+	"g37-lanchonete/internal/infra/gateways"
+	mock_gateways "g37-lanchonete/internal/infra/gateways/mocks"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestOrderUsecase_CreateOrder(t *testing.T) {
+	variants := []entities.ProductVariant{
+		{ID: 11, ProductID: 1, Name: "Pequeno", SkuId: "1-P", Price: 8},
+		{ID: 12, ProductID: 1, Name: "Grande", SkuId: "1-G", Price: 12},
+	}
+	expiresAt := time.Date(2024, 1, 1, 12, 15, 0, 0, time.UTC)
+
+	type want struct {
+		response     dto.OrderCreationResponse
+		paymentTitle string
+		totalAmount  float64
+		status       string
+		skipsPayment bool
+		err          error
+	}
+	tests := []struct {
+		name     string
+		item     dto.OrderItemDTO
+		price    float64
+		minQty   int
+		maxQty   int
+		variants []entities.ProductVariant
+		// the clock is on a monday at 12:00 UTC
+		businessHours     map[string]string
+		paymentExpiration time.Duration
+		want              want
+	}{
+		{
+			name:  "should create an order of a product without variants",
+			item:  dto.OrderItemDTO{ProductId: 1, Quantity: 2, Type: dto.OrderItemTypeUnit},
+			price: 10,
+			want: want{
+				response:     dto.OrderCreationResponse{QRCode: "mercadopago123456", OrderID: 98765, Number: "042"},
+				paymentTitle: "Refrigerante",
+				totalAmount:  20,
+				status:       "CREATED",
+			},
+		},
+		{
+			name:  "should skip the payment of an order with nothing to pay",
+			item:  dto.OrderItemDTO{ProductId: 1, Quantity: 2, Type: dto.OrderItemTypeUnit},
+			price: 0,
+			want: want{
+				response:     dto.OrderCreationResponse{OrderID: 98765, Number: "042"},
+				totalAmount:  0,
+				status:       "PAID",
+				skipsPayment: true,
+			},
+		},
+		{
+			name:     "should price the item with the selected variant",
+			item:     dto.OrderItemDTO{ProductId: 1, VariantId: 12, Quantity: 2, Type: dto.OrderItemTypeUnit},
+			price:    10,
+			variants: variants,
+			want: want{
+				response:     dto.OrderCreationResponse{QRCode: "mercadopago123456", OrderID: 98765, Number: "042"},
+				paymentTitle: "Refrigerante (Grande)",
+				totalAmount:  24,
+				status:       "CREATED",
+			},
+		},
+		{
+			name:     "should not create the order when the product requires a variant",
+			item:     dto.OrderItemDTO{ProductId: 1, Quantity: 2, Type: dto.OrderItemTypeUnit},
+			price:    10,
+			variants: variants,
+			want:     want{err: dto.ProductVariantRequiredError{ProductID: 1}},
+		},
+		{
+			name:     "should not create the order with a variant of another product",
+			item:     dto.OrderItemDTO{ProductId: 1, VariantId: 21, Quantity: 2, Type: dto.OrderItemTypeUnit},
+			price:    10,
+			variants: variants,
+			want:     want{err: dto.InvalidProductVariantError{ProductID: 1, VariantID: 21}},
+		},
+		{
+			name:   "should create an order with the minimum quantity of the product",
+			item:   dto.OrderItemDTO{ProductId: 1, Quantity: 2, Type: dto.OrderItemTypeUnit},
+			price:  10,
+			minQty: 2,
+			want: want{
+				response:     dto.OrderCreationResponse{QRCode: "mercadopago123456", OrderID: 98765, Number: "042"},
+				paymentTitle: "Refrigerante",
+				totalAmount:  20,
+				status:       "CREATED",
+			},
+		},
+		{
+			name:   "should not create an order below the minimum quantity of the product",
+			item:   dto.OrderItemDTO{ProductId: 1, Quantity: 2, Type: dto.OrderItemTypeUnit},
+			price:  10,
+			minQty: 3,
+			want:   want{err: dto.ProductQuantityLimitError{ProductID: 1, Quantity: 2, MinQty: 3}},
+		},
+		{
+			name:   "should create an order with the maximum quantity of the product",
+			item:   dto.OrderItemDTO{ProductId: 1, Quantity: 2, Type: dto.OrderItemTypeUnit},
+			price:  10,
+			maxQty: 2,
+			want: want{
+				response:     dto.OrderCreationResponse{QRCode: "mercadopago123456", OrderID: 98765, Number: "042"},
+				paymentTitle: "Refrigerante",
+				totalAmount:  20,
+				status:       "CREATED",
+			},
+		},
+		{
+			name:   "should not create an order above the maximum quantity of the product",
+			item:   dto.OrderItemDTO{ProductId: 1, Quantity: 2, Type: dto.OrderItemTypeUnit},
+			price:  10,
+			maxQty: 1,
+			want:   want{err: dto.ProductQuantityLimitError{ProductID: 1, Quantity: 2, MaxQty: 1}},
+		},
+		{
+			name:          "should create an order inside the business hours",
+			item:          dto.OrderItemDTO{ProductId: 1, Quantity: 2, Type: dto.OrderItemTypeUnit},
+			price:         10,
+			businessHours: map[string]string{"monday": "11:00-23:00"},
+			want: want{
+				response:     dto.OrderCreationResponse{QRCode: "mercadopago123456", OrderID: 98765, Number: "042"},
+				paymentTitle: "Refrigerante",
+				totalAmount:  20,
+				status:       "CREATED",
+			},
+		},
+		{
+			name:              "should answer when the payment qrcode expires",
+			item:              dto.OrderItemDTO{ProductId: 1, Quantity: 2, Type: dto.OrderItemTypeUnit},
+			price:             10,
+			paymentExpiration: 15 * time.Minute,
+			want: want{
+				response:     dto.OrderCreationResponse{QRCode: "mercadopago123456", ExpiresAt: &expiresAt, OrderID: 98765, Number: "042"},
+				paymentTitle: "Refrigerante",
+				totalAmount:  20,
+				status:       "CREATED",
+			},
+		},
+		{
+			name:          "should not create an order outside the business hours",
+			item:          dto.OrderItemDTO{ProductId: 1, Quantity: 2, Type: dto.OrderItemTypeUnit},
+			price:         10,
+			businessHours: map[string]string{"monday": "18:00-23:00"},
+			want:          want{err: fmt.Errorf("%w, orders are accepted again at 2024-01-01T18:00:00Z", dto.ErrStoreClosed)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			authorizer := mock_auth.NewMockAuthorizer(ctrl)
+			paymentBroker := mock_payment.NewMockPaymentBroker(ctrl)
+			productRepositoryGateway := mock_gateways.NewMockProductRepositoryGateway(ctrl)
+			productVariantRepositoryGateway := mock_gateways.NewMockProductVariantRepositoryGateway(ctrl)
+			orderRepositoryGateway := mock_gateways.NewMockOrderRepositoryGateway(ctrl)
+			eventPublisher := mock_events.NewMockPublisher(ctrl)
+			clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+
+			// the order is only saved once all its items are valid
+			creationCalls := 0
+			if tt.want.err == nil {
+				creationCalls = 1
+			}
+			paymentCalls := creationCalls
+			if tt.want.skipsPayment {
+				paymentCalls = 0
+			}
+			// orders outside the business hours are rejected before anything else
+			pricingCalls := 1
+			if errors.Is(tt.want.err, dto.ErrStoreClosed) {
+				pricingCalls = 0
+			}
+			businessHours, err := NewBusinessHours(tt.businessHours, "UTC")
+			assert.NoError(t, err)
+
+			authorizer.EXPECT().AuthorizeUser("00551146010").Return(dto.AuthorizerResponse{UserId: 1, IsAuthorized: true}, nil).Times(pricingCalls)
+			productRepositoryGateway.EXPECT().FindProductById(1).Return(entities.Product{ID: 1, Name: "Refrigerante", Price: tt.price, MinQty: tt.minQty, MaxQty: tt.maxQty}, nil).Times(pricingCalls)
+			productVariantRepositoryGateway.EXPECT().FindVariantsByProductIds([]int{1}).Return(append([]entities.ProductVariant{}, tt.variants...), nil).Times(pricingCalls)
+			orderRepositoryGateway.EXPECT().
+				SaveOrder(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(order entities.Order, number gateways.OrderNumberFunc) (int, string, error) {
+					assert.Equal(t, tt.want.totalAmount, order.TotalAmount)
+					assert.Equal(t, tt.want.status, order.Status)
+					return numberSavedOrder(order, 98765, number)
+				}).
+				Times(creationCalls)
+			paymentBroker.EXPECT().
+				GeneratePaymentQRCode(gomock.Any()).
+				DoAndReturn(func(request dto.PaymentQRCodeRequest) (dto.PaymentQRCodeResponse, error) {
+					assert.Equal(t, tt.want.paymentTitle, request.Items[0].Title)
+					return dto.PaymentQRCodeResponse{QrData: "mercadopago123456"}, nil
+				}).
+				Times(paymentCalls)
+			orderRepositoryGateway.EXPECT().UpdateOrderQRCode(98765, dto.PaymentQRCode{QRCode: "mercadopago123456", ExpiresAt: tt.want.response.ExpiresAt}).Return(nil).Times(paymentCalls)
+			eventPublisher.EXPECT().
+				Publish(events.Event{
+					Type:       events.OrderCreated,
+					OrderID:    98765,
+					Payload:    map[string]interface{}{"number": "042", "status": tt.want.status, "total": tt.want.totalAmount},
+					OccurredAt: clock.Now(),
+				}).
+				Return(nil).
+				Times(creationCalls)
+
+			orderUsecase := NewOrderUsecase(
+				NewAuthorizerUsecase(authorizer, time.Minute, time.Minute, clock),
+				NewPaymentUsecase("https://g37-lanches", "12345", paymentBroker, dto.DefaultPaymentMethods, tt.paymentExpiration, clock),
+				NewProductUsecase(productRepositoryGateway, nil, productVariantRepositoryGateway, NewTaxCalculator(0, nil, 0), NewCategoryPolicy("", "")),
+				orderRepositoryGateway,
+				nil,
+				NewTaxCalculator(0, nil, 0),
+				NewNoteRedactor(false, nil),
+				eventPublisher,
+				nil,
+				NewOrderNumberGenerator(OrderNumberModeDaily),
+				businessHours,
+				nil,
+				nil,
+				0,
+				clock,
+			)
+
+			response, err := orderUsecase.CreateOrder(dto.OrderDTO{
+				Items:       []dto.OrderItemDTO{tt.item},
+				CustomerCPF: "00551146010",
+				Status:      dto.OrderStatusCreated,
+			})
+
+			assert.Equal(t, tt.want.err, err)
+			assert.Equal(t, tt.want.response, response)
+		})
+	}
+}
+
+func TestOrderUsecase_AddOrderItem(t *testing.T) {
+	burger := entities.OrderItem{ID: 1, Product: entities.Product{ID: 1, Name: "X-Burguer", Price: 10}, Quantity: 2, Type: "UNIT"}
+	soda := entities.Product{ID: 2, Name: "Refrigerante", Price: 5}
+
+	type want struct {
+		totals dto.OrderTotals
+		items  int
+		err    error
+	}
+	tests := []struct {
+		name       string
+		order      entities.Order
+		product    entities.Product
+		productErr error
+		want       want
+	}{
+		{
+			name:    "should add the item and recalculate the order total",
+			order:   entities.Order{ID: 7, Status: "CREATED", Customer: entities.Customer{ID: 3}, Items: []entities.OrderItem{burger}},
+			product: soda,
+			want:    want{totals: dto.OrderTotals{Subtotal: 25, Total: 25}, items: 2},
+		},
+		{
+			name:  "should not add items to an order already in preparation",
+			order: entities.Order{ID: 7, Status: "IN_PROGRESS", Items: []entities.OrderItem{burger}},
+			want:  want{err: dto.ErrOrderNotEditable},
+		},
+		{
+			name:       "should fail when the product of the new item does not exist",
+			order:      entities.Order{ID: 7, Status: "CREATED", Items: []entities.OrderItem{burger}},
+			productErr: sql.ErrNotFound,
+			want:       want{err: dto.OrderItemProductNotFoundError{ProductID: 2}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			paymentBroker := mock_payment.NewMockPaymentBroker(ctrl)
+			productRepositoryGateway := mock_gateways.NewMockProductRepositoryGateway(ctrl)
+			productVariantRepositoryGateway := mock_gateways.NewMockProductVariantRepositoryGateway(ctrl)
+			orderRepositoryGateway := mock_gateways.NewMockOrderRepositoryGateway(ctrl)
+
+			editable := tt.order.Status == "CREATED"
+			pricingCalls, additionCalls := 0, 0
+			if editable {
+				pricingCalls = 1
+			}
+			if tt.want.err == nil {
+				additionCalls = 1
+			}
+
+			orderRepositoryGateway.EXPECT().FindOrderById(7).Return(tt.order, nil).Times(1)
+			productRepositoryGateway.EXPECT().FindProductById(1).Return(burger.Product, nil).Times(pricingCalls)
+			productVariantRepositoryGateway.EXPECT().FindVariantsByProductIds([]int{1}).Return([]entities.ProductVariant{}, nil).Times(pricingCalls)
+			productRepositoryGateway.EXPECT().FindProductById(2).Return(tt.product, tt.productErr).Times(pricingCalls)
+			productVariantRepositoryGateway.EXPECT().FindVariantsByProductIds([]int{2}).Return([]entities.ProductVariant{}, nil).Times(additionCalls)
+			orderRepositoryGateway.EXPECT().
+				AddOrderItem(7, gomock.Any(), tt.want.totals).
+				DoAndReturn(func(orderId int, item entities.OrderItem, totals dto.OrderTotals) (int, error) {
+					assert.Equal(t, soda.ID, item.Product.ID)
+					assert.Equal(t, 1, item.Quantity)
+					return 3, nil
+				}).
+				Times(additionCalls)
+			paymentBroker.EXPECT().
+				GeneratePaymentQRCode(gomock.Any()).
+				DoAndReturn(func(request dto.PaymentQRCodeRequest) (dto.PaymentQRCodeResponse, error) {
+					assert.Equal(t, float64(25), request.TotalAmount)
+					return dto.PaymentQRCodeResponse{QrData: "mercadopago654321"}, nil
+				}).
+				Times(additionCalls)
+			orderRepositoryGateway.EXPECT().UpdateOrderQRCode(7, dto.PaymentQRCode{QRCode: "mercadopago654321"}).Return(nil).Times(additionCalls)
+
+			orderUsecase := NewOrderUsecase(
+				nil,
+				NewPaymentUsecase("https://g37-lanches", "12345", paymentBroker, dto.DefaultPaymentMethods, 0, nil),
+				NewProductUsecase(productRepositoryGateway, nil, productVariantRepositoryGateway, NewTaxCalculator(0, nil, 0), NewCategoryPolicy("", "")),
+				orderRepositoryGateway,
+				nil,
+				NewTaxCalculator(0, nil, 0),
+				NewNoteRedactor(false, nil),
+				nil,
+				nil,
+				nil,
+				nil,
+				nil,
+				nil,
+				0,
+				nil,
+			)
+
+			response, err := orderUsecase.AddOrderItem(7, dto.OrderItemDTO{ProductId: 2, Quantity: 1, Type: dto.OrderItemTypeUnit})
+
+			assert.Equal(t, tt.want.err, err)
+			assert.Len(t, response.Items, tt.want.items)
+			assert.Equal(t, tt.want.totals.Total, response.TotalAmount)
+			if tt.want.err == nil {
+				assert.Equal(t, 3, response.Items[1].ID)
+				assert.Equal(t, "mercadopago654321", response.QRCode)
+			}
+		})
+	}
+}
+
+func TestOrderUsecase_RemoveOrderItem(t *testing.T) {
+	burger := entities.OrderItem{ID: 1, Product: entities.Product{ID: 1, Name: "X-Burguer", Price: 10}, Quantity: 2, Type: "UNIT"}
+	soda := entities.OrderItem{ID: 2, Product: entities.Product{ID: 2, Name: "Refrigerante", Price: 5}, Quantity: 1, Type: "UNIT"}
+	pricedBurger := burger
+	pricedBurger.Product.PriceNet, pricedBurger.Product.PriceGross = 10, 10
+
+	type want struct {
+		response dto.OrderItemsUpdateResponse
+		err      error
+	}
+	tests := []struct {
+		name        string
+		itemId      int
+		order       entities.Order
+		wantRemoval bool
+		want        want
+	}{
+		{
+			name:        "should remove the item and recalculate the order total",
+			itemId:      2,
+			order:       entities.Order{ID: 7, Status: "CREATED", Customer: entities.Customer{ID: 3}, Items: []entities.OrderItem{burger, soda}},
+			wantRemoval: true,
+			want: want{response: dto.OrderItemsUpdateResponse{
+				OrderID:        7,
+				Items:          []entities.OrderItem{pricedBurger},
+				SubtotalAmount: 20,
+				TotalAmount:    20,
+				QRCode:         "mercadopago654321",
+			}},
+		},
+		{
+			name:   "should not remove items of an order already paid",
+			itemId: 2,
+			order:  entities.Order{ID: 7, Status: "PAID", Items: []entities.OrderItem{burger, soda}},
+			want:   want{err: dto.ErrOrderNotEditable},
+		},
+		{
+			name:   "should not remove the last item of the order",
+			itemId: 1,
+			order:  entities.Order{ID: 7, Status: "CREATED", Items: []entities.OrderItem{burger}},
+			want:   want{err: dto.ErrOrderLastItem},
+		},
+		{
+			name:   "should fail when the item is not part of the order",
+			itemId: 9,
+			order:  entities.Order{ID: 7, Status: "CREATED", Items: []entities.OrderItem{burger, soda}},
+			want:   want{err: dto.ErrOrderItemNotFound},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			paymentBroker := mock_payment.NewMockPaymentBroker(ctrl)
+			productRepositoryGateway := mock_gateways.NewMockProductRepositoryGateway(ctrl)
+			productVariantRepositoryGateway := mock_gateways.NewMockProductVariantRepositoryGateway(ctrl)
+			orderRepositoryGateway := mock_gateways.NewMockOrderRepositoryGateway(ctrl)
+
+			removalCalls := 0
+			if tt.wantRemoval {
+				removalCalls = 1
+			}
+
+			orderRepositoryGateway.EXPECT().FindOrderById(7).Return(tt.order, nil).Times(1)
+			productRepositoryGateway.EXPECT().FindProductById(1).Return(burger.Product, nil).Times(removalCalls)
+			productVariantRepositoryGateway.EXPECT().FindVariantsByProductIds([]int{1}).Return([]entities.ProductVariant{}, nil).Times(removalCalls)
+			orderRepositoryGateway.EXPECT().
+				RemoveOrderItem(7, tt.itemId, dto.OrderTotals{Subtotal: 20, Total: 20}).
+				Return(nil).
+				Times(removalCalls)
+			paymentBroker.EXPECT().
+				GeneratePaymentQRCode(gomock.Any()).
+				DoAndReturn(func(request dto.PaymentQRCodeRequest) (dto.PaymentQRCodeResponse, error) {
+					assert.Equal(t, float64(20), request.TotalAmount)
+					assert.Len(t, request.Items, 1)
+					return dto.PaymentQRCodeResponse{QrData: "mercadopago654321"}, nil
+				}).
+				Times(removalCalls)
+			orderRepositoryGateway.EXPECT().UpdateOrderQRCode(7, dto.PaymentQRCode{QRCode: "mercadopago654321"}).Return(nil).Times(removalCalls)
+
+			orderUsecase := NewOrderUsecase(
+				nil,
+				NewPaymentUsecase("https://g37-lanches", "12345", paymentBroker, dto.DefaultPaymentMethods, 0, nil),
+				NewProductUsecase(productRepositoryGateway, nil, productVariantRepositoryGateway, NewTaxCalculator(0, nil, 0), NewCategoryPolicy("", "")),
+				orderRepositoryGateway,
+				nil,
+				NewTaxCalculator(0, nil, 0),
+				NewNoteRedactor(false, nil),
+				nil,
+				nil,
+				nil,
+				nil,
+				nil,
+				nil,
+				0,
+				nil,
+			)
+
+			response, err := orderUsecase.RemoveOrderItem(7, tt.itemId)
+
+			assert.Equal(t, tt.want.err, err)
+			assert.Equal(t, tt.want.response, response)
+		})
+	}
+}
+
+func TestOrderUsecase_RemoveOrderItem_StoreCredit(t *testing.T) {
+	burger := entities.OrderItem{ID: 1, Product: entities.Product{ID: 1, Name: "X-Burguer", Price: 10}, Quantity: 2, Type: "UNIT"}
+	soda := entities.OrderItem{ID: 2, Product: entities.Product{ID: 2, Name: "Refrigerante", Price: 5}, Quantity: 1, Type: "UNIT"}
+	pricedBurger := burger
+	pricedBurger.Product.PriceNet, pricedBurger.Product.PriceGross = 10, 10
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+
+	ctrl := gomock.NewController(t)
+	paymentBroker := mock_payment.NewMockPaymentBroker(ctrl)
+	productRepositoryGateway := mock_gateways.NewMockProductRepositoryGateway(ctrl)
+	productVariantRepositoryGateway := mock_gateways.NewMockProductVariantRepositoryGateway(ctrl)
+	orderRepositoryGateway := mock_gateways.NewMockOrderRepositoryGateway(ctrl)
+	eventPublisher := mock_events.NewMockPublisher(ctrl)
+
+	// the credit of 25 paid the whole order, only 20 of it is left to pay for the burger
+	order := entities.Order{ID: 7, Status: "CREATED", StoreCreditAmount: 25, Items: []entities.OrderItem{burger, soda}}
+	orderRepositoryGateway.EXPECT().FindOrderById(7).Return(order, nil).Times(1)
+	productRepositoryGateway.EXPECT().FindProductById(1).Return(burger.Product, nil).Times(1)
+	productVariantRepositoryGateway.EXPECT().FindVariantsByProductIds([]int{1}).Return([]entities.ProductVariant{}, nil).Times(1)
+	orderRepositoryGateway.EXPECT().
+		RemoveOrderItem(7, 2, dto.OrderTotals{Subtotal: 20, StoreCredit: 20, Total: 0}).
+		Return(nil).
+		Times(1)
+	// nothing is left to pay, so the order is paid without asking for a qrcode
+	paymentBroker.EXPECT().GeneratePaymentQRCode(gomock.Any()).Times(0)
+	orderRepositoryGateway.EXPECT().ConfirmOrderPayment(7).Return(true, nil).Times(1)
+	eventPublisher.EXPECT().
+		Publish(events.Event{
+			Type:       events.OrderStatusChanged,
+			OrderID:    7,
+			Payload:    map[string]interface{}{"from": "CREATED", "to": "PAID"},
+			OccurredAt: clock.Now(),
+		}).
+		Return(nil).
+		Times(1)
+
+	orderUsecase := NewOrderUsecase(
+		nil,
+		NewPaymentUsecase("https://g37-lanches", "12345", paymentBroker, dto.DefaultPaymentMethods, 0, nil),
+		NewProductUsecase(productRepositoryGateway, nil, productVariantRepositoryGateway, NewTaxCalculator(0, nil, 0), NewCategoryPolicy("", "")),
+		orderRepositoryGateway,
+		nil,
+		NewTaxCalculator(0, nil, 0),
+		NewNoteRedactor(false, nil),
+		eventPublisher,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		0,
+		clock,
+	)
+
+	response, err := orderUsecase.RemoveOrderItem(7, 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, dto.OrderItemsUpdateResponse{
+		OrderID:        7,
+		Items:          []entities.OrderItem{pricedBurger},
+		SubtotalAmount: 20,
+		TotalAmount:    0,
+	}, response)
+}
+
+func TestOrderUsecase_RecalculateOrder(t *testing.T) {
+	// the items keep the prices of when the order was placed, the products have been repriced since
+	burger := entities.OrderItem{ID: 1, Product: entities.Product{ID: 1, Name: "X-Burguer", Price: 8}, Quantity: 2, Type: "UNIT"}
+	soda := entities.OrderItem{ID: 2, Product: entities.Product{ID: 2, Name: "Refrigerante", Price: 4}, Quantity: 1, Type: "UNIT"}
+	currentBurger := entities.Product{ID: 1, Name: "X-Burguer", Price: 10}
+	currentSoda := entities.Product{ID: 2, Name: "Refrigerante", Price: 5}
+	pricedBurger, pricedSoda := burger, soda
+	pricedBurger.Product = currentBurger
+	pricedBurger.Product.PriceNet, pricedBurger.Product.PriceGross = 10, 10
+	pricedSoda.Product = currentSoda
+	pricedSoda.Product.PriceNet, pricedSoda.Product.PriceGross = 5, 5
+	// the coupon expired after the order was placed, which keeps it applied
+	placedAt := entities.NewTimestamp(time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC))
+	expiredAt := time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC)
+	coupon := entities.Coupon{Code: "APP10", Type: "PERCENT", Value: 1000, MinTotal: 2100, ExpiresAt: &expiredAt}
+
+	type want struct {
+		totals   dto.OrderTotals
+		response dto.OrderItemsUpdateResponse
+		err      error
+	}
+	tests := []struct {
+		name        string
+		order       entities.Order
+		wantCalls   int
+		couponCalls int
+		want        want
+	}{
+		{
+			name:      "should price the items again from the current products and store the new total",
+			order:     entities.Order{ID: 7, Status: "CREATED", Customer: entities.Customer{ID: 3}, Items: []entities.OrderItem{burger, soda}, TotalAmount: 20},
+			wantCalls: 1,
+			want: want{
+				totals: dto.OrderTotals{Subtotal: 25, Total: 25},
+				response: dto.OrderItemsUpdateResponse{
+					OrderID:        7,
+					Items:          []entities.OrderItem{pricedBurger, pricedSoda},
+					SubtotalAmount: 25,
+					TotalAmount:    25,
+					QRCode:         "mercadopago654321",
+				},
+			},
+		},
+		{
+			name: "should apply the coupon of the order to the new total",
+			order: entities.Order{ID: 7, Status: "CREATED", Customer: entities.Customer{ID: 3}, Items: []entities.OrderItem{burger, soda}, Coupon: "APP10",
+				DiscountAmount: 2, TotalAmount: 18, CreatedAt: placedAt},
+			wantCalls:   1,
+			couponCalls: 1,
+			want: want{
+				totals: dto.OrderTotals{Subtotal: 25, Discount: 2.5, Total: 22.5},
+				response: dto.OrderItemsUpdateResponse{
+					OrderID:        7,
+					Items:          []entities.OrderItem{pricedBurger, pricedSoda},
+					SubtotalAmount: 25,
+					TotalAmount:    22.5,
+					QRCode:         "mercadopago654321",
+				},
+			},
+		},
+		{
+			name:  "should not recalculate an order already paid",
+			order: entities.Order{ID: 7, Status: "PAID", Items: []entities.OrderItem{burger, soda}},
+			want:  want{err: dto.ErrOrderNotEditable},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			paymentBroker := mock_payment.NewMockPaymentBroker(ctrl)
+			productRepositoryGateway := mock_gateways.NewMockProductRepositoryGateway(ctrl)
+			productVariantRepositoryGateway := mock_gateways.NewMockProductVariantRepositoryGateway(ctrl)
+			orderRepositoryGateway := mock_gateways.NewMockOrderRepositoryGateway(ctrl)
+			couponRepositoryGateway := mock_gateways.NewMockCouponRepositoryGateway(ctrl)
+
+			orderRepositoryGateway.EXPECT().FindOrderById(7).Return(tt.order, nil).Times(1)
+			productRepositoryGateway.EXPECT().FindProductById(1).Return(currentBurger, nil).Times(tt.wantCalls)
+			productRepositoryGateway.EXPECT().FindProductById(2).Return(currentSoda, nil).Times(tt.wantCalls)
+			productVariantRepositoryGateway.EXPECT().FindVariantsByProductIds(gomock.Any()).Return([]entities.ProductVariant{}, nil).Times(2 * tt.wantCalls)
+			couponRepositoryGateway.EXPECT().FindCouponByCode("APP10").Return(coupon, nil).Times(tt.couponCalls)
+			orderRepositoryGateway.EXPECT().
+				UpdateOrderTotals(7, tt.want.totals).
+				Return(nil).
+				Times(tt.wantCalls)
+			paymentBroker.EXPECT().
+				GeneratePaymentQRCode(gomock.Any()).
+				DoAndReturn(func(request dto.PaymentQRCodeRequest) (dto.PaymentQRCodeResponse, error) {
+					assert.Equal(t, tt.want.totals.Total, request.TotalAmount)
+					return dto.PaymentQRCodeResponse{QrData: "mercadopago654321"}, nil
+				}).
+				Times(tt.wantCalls)
+			orderRepositoryGateway.EXPECT().UpdateOrderQRCode(7, dto.PaymentQRCode{QRCode: "mercadopago654321"}).Return(nil).Times(tt.wantCalls)
+
+			orderUsecase := NewOrderUsecase(
+				nil,
+				NewPaymentUsecase("https://g37-lanches", "12345", paymentBroker, dto.DefaultPaymentMethods, 0, nil),
+				NewProductUsecase(productRepositoryGateway, nil, productVariantRepositoryGateway, NewTaxCalculator(0, nil, 0), NewCategoryPolicy("", "")),
+				orderRepositoryGateway,
+				couponRepositoryGateway,
+				NewTaxCalculator(0, nil, 0),
+				NewNoteRedactor(false, nil),
+				nil,
+				nil,
+				nil,
+				nil,
+				nil,
+				nil,
+				0,
+				nil,
+			)
+
+			response, err := orderUsecase.RecalculateOrder(7)
+
+			assert.Equal(t, tt.want.err, err)
+			assert.Equal(t, tt.want.response, response)
+		})
+	}
+}
+
+func TestOrderUsecase_ConfirmOrderPayment_Concurrent(t *testing.T) {
+	const (
+		orderId       = 1
+		confirmations = 20
+	)
+	ctrl := gomock.NewController(t)
+	orderRepositoryGateway := mock_gateways.NewMockOrderRepositoryGateway(ctrl)
+	eventPublisher := mock_events.NewMockPublisher(ctrl)
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+
+	// emulates UPDATE ... WHERE status = 'CREATED', only the first confirmation changes the row
+	var paid atomic.Bool
+	var transitions atomic.Int32
+	orderRepositoryGateway.EXPECT().
+		ConfirmOrderPayment(orderId).
+		DoAndReturn(func(int) (bool, error) {
+			if paid.CompareAndSwap(false, true) {
+				transitions.Add(1)
+				return true, nil
+			}
+			return false, nil
+		}).
+		Times(confirmations)
+	orderRepositoryGateway.EXPECT().
+		GetOrderStatus(orderId).
+		Return("PAID", nil).
+		Times(confirmations - 1)
+	eventPublisher.EXPECT().
+		Publish(events.Event{
+			Type:       events.OrderStatusChanged,
+			OrderID:    orderId,
+			Payload:    map[string]interface{}{"from": "CREATED", "to": "PAID"},
+			OccurredAt: clock.Now(),
+		}).
+		Return(nil).
+		Times(1)
+
+	orderUsecase := NewOrderUsecase(nil, nil, nil, orderRepositoryGateway, nil, NewTaxCalculator(0, nil, 0), NewNoteRedactor(false, nil), eventPublisher, nil, nil, nil, nil, nil, 0, clock)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, confirmations)
+	for i := 0; i < confirmations; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- orderUsecase.ConfirmOrderPayment(orderId)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), transitions.Load())
+}
+
+func TestOrderUsecase_ReplayOrderPayment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	orderRepositoryGateway := mock_gateways.NewMockOrderRepositoryGateway(ctrl)
+	eventPublisher := mock_events.NewMockPublisher(ctrl)
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	orderUsecase := NewOrderUsecase(nil, nil, nil, orderRepositoryGateway, nil, NewTaxCalculator(0, nil, 0), NewNoteRedactor(false, nil), eventPublisher, nil, nil, nil, nil, nil, 0, clock)
+
+	t.Run("should confirm the payment of an order still waiting for it", func(t *testing.T) {
+		orderRepositoryGateway.EXPECT().ConfirmOrderPayment(1).Return(true, nil).Times(1)
+		eventPublisher.EXPECT().
+			Publish(events.Event{
+				Type:       events.OrderStatusChanged,
+				OrderID:    1,
+				Payload:    map[string]interface{}{"from": "CREATED", "to": "PAID"},
+				OccurredAt: clock.Now(),
+			}).
+			Return(nil).
+			Times(1)
+
+		response, err := orderUsecase.ReplayOrderPayment(1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, dto.PaymentReplayResponseDTO{OrderID: 1, Confirmed: true, Status: dto.OrderStatusPaid}, response)
+	})
+
+	t.Run("should change nothing when the order is already paid", func(t *testing.T) {
+		orderRepositoryGateway.EXPECT().ConfirmOrderPayment(2).Return(false, nil).Times(1)
+		orderRepositoryGateway.EXPECT().GetOrderStatus(2).Return("IN_PROGRESS", nil).Times(1)
+
+		response, err := orderUsecase.ReplayOrderPayment(2)
+
+		assert.NoError(t, err)
+		assert.Equal(t, dto.PaymentReplayResponseDTO{OrderID: 2, Confirmed: false, Status: dto.OrderStatusInProgress}, response)
+	})
+}
+
+func TestOrderUsecase_UpdateOrderStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	orderRepositoryGateway := mock_gateways.NewMockOrderRepositoryGateway(ctrl)
+	eventPublisher := mock_events.NewMockPublisher(ctrl)
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	orderUsecase := NewOrderUsecase(nil, nil, nil, orderRepositoryGateway, nil, NewTaxCalculator(0, nil, 0), NewNoteRedactor(false, nil), eventPublisher, nil, nil, nil, nil, nil, 0, clock)
+
+	t.Run("should publish the status change so the partners are notified", func(t *testing.T) {
+		orderRepositoryGateway.EXPECT().GetOrderStatus(1).Return("IN_PROGRESS", nil).Times(1)
+		orderRepositoryGateway.EXPECT().UpdateOrderStatus(1, "READY", "IN_PROGRESS").Return(nil).Times(1)
+		eventPublisher.EXPECT().
+			Publish(events.Event{
+				Type:       events.OrderStatusChanged,
+				OrderID:    1,
+				Payload:    map[string]interface{}{"from": "IN_PROGRESS", "to": "READY"},
+				OccurredAt: clock.Now(),
+			}).
+			Return(nil).
+			Times(1)
+
+		err := orderUsecase.UpdateOrderStatus(1, "READY", "")
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("should not publish when the order does not exist", func(t *testing.T) {
+		orderRepositoryGateway.EXPECT().GetOrderStatus(2).Return("", sql.ErrNotFound).Times(1)
+
+		err := orderUsecase.UpdateOrderStatus(2, "READY", "")
+
+		assert.ErrorIs(t, err, sql.ErrNotFound)
+	})
+
+	t.Run("should return conflict when the order skips a status", func(t *testing.T) {
+		orderRepositoryGateway.EXPECT().GetOrderStatus(5).Return("PAID", nil).Times(1)
+
+		err := orderUsecase.UpdateOrderStatus(5, "READY", "")
+
+		assert.ErrorIs(t, err, dto.ErrOrderStatusConflict)
+		assert.EqualError(t, err, "order is no longer in the expected status, order can not move from [PAID] to [READY]")
+	})
+
+	t.Run("should return conflict when the expected status does not move to the status", func(t *testing.T) {
+		err := orderUsecase.UpdateOrderStatus(6, "DONE", "IN_PROGRESS")
+
+		assert.ErrorIs(t, err, dto.ErrOrderStatusConflict)
+	})
+
+	t.Run("should return conflict when the order moved since its status was read", func(t *testing.T) {
+		orderRepositoryGateway.EXPECT().GetOrderStatus(7).Return("IN_PROGRESS", nil).Times(1)
+		orderRepositoryGateway.EXPECT().UpdateOrderStatus(7, "READY", "IN_PROGRESS").Return(sql.ErrNotFound).Times(1)
+		orderRepositoryGateway.EXPECT().GetOrderStatus(7).Return("READY", nil).Times(1)
+
+		err := orderUsecase.UpdateOrderStatus(7, "READY", "")
+
+		assert.ErrorIs(t, err, dto.ErrOrderStatusConflict)
+	})
+
+	t.Run("should return conflict when the order is no longer in the expected status", func(t *testing.T) {
+		orderRepositoryGateway.EXPECT().UpdateOrderStatus(3, "READY", "IN_PROGRESS").Return(sql.ErrNotFound).Times(1)
+		orderRepositoryGateway.EXPECT().GetOrderStatus(3).Return("DONE", nil).Times(1)
+
+		err := orderUsecase.UpdateOrderStatus(3, "READY", "IN_PROGRESS")
+
+		assert.ErrorIs(t, err, dto.ErrOrderStatusConflict)
+	})
+
+	t.Run("should return not found when the order with an expected status does not exist", func(t *testing.T) {
+		orderRepositoryGateway.EXPECT().UpdateOrderStatus(4, "READY", "IN_PROGRESS").Return(sql.ErrNotFound).Times(1)
+		orderRepositoryGateway.EXPECT().GetOrderStatus(4).Return("", sql.ErrNotFound).Times(1)
+
+		err := orderUsecase.UpdateOrderStatus(4, "READY", "IN_PROGRESS")
+
+		assert.ErrorIs(t, err, sql.ErrNotFound)
+	})
+}
+
+func TestOrderUsecase_UpdateOrderStatus_ReducedStatusSet(t *testing.T) {
+	assert.NoError(t, dto.SetOrderStatuses([]string{"CREATED", "PAID", "IN_PROGRESS", "READY", "DONE"}))
+	t.Cleanup(func() {
+		_ = dto.SetOrderStatuses(nil)
+	})
+	ctrl := gomock.NewController(t)
+	orderRepositoryGateway := mock_gateways.NewMockOrderRepositoryGateway(ctrl)
+	eventPublisher := mock_events.NewMockPublisher(ctrl)
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	orderUsecase := NewOrderUsecase(nil, nil, nil, orderRepositoryGateway, nil, NewTaxCalculator(0, nil, 0), NewNoteRedactor(false, nil), eventPublisher, nil, nil, nil, nil, nil, 0, clock)
+
+	t.Run("should move a paid order straight to IN_PROGRESS without RECEIVED", func(t *testing.T) {
+		orderRepositoryGateway.EXPECT().UpdateOrderStatus(1, "IN_PROGRESS", "PAID").Return(nil).Times(1)
+		eventPublisher.EXPECT().Publish(gomock.Any()).Return(nil).Times(1)
+
+		err := orderUsecase.UpdateOrderStatus(1, "IN_PROGRESS", "PAID")
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("should reject a paid order skipping IN_PROGRESS", func(t *testing.T) {
+		orderRepositoryGateway.EXPECT().GetOrderStatus(2).Return("PAID", nil).Times(1)
+
+		err := orderUsecase.UpdateOrderStatus(2, "READY", "")
+
+		assert.ErrorIs(t, err, dto.ErrOrderStatusConflict)
+		assert.EqualError(t, err, "order is no longer in the expected status, order can not move from [PAID] to [READY]")
+	})
+}
+
+func TestOrderUsecase_UpdateOrderItemStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	orderRepositoryGateway := mock_gateways.NewMockOrderRepositoryGateway(ctrl)
+	eventPublisher := mock_events.NewMockPublisher(ctrl)
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	orderUsecase := NewOrderUsecase(nil, nil, nil, orderRepositoryGateway, nil, NewTaxCalculator(0, nil, 0), NewNoteRedactor(false, nil), eventPublisher, nil, nil, nil, nil, nil, 0, clock)
+
+	t.Run("should keep the order IN_PROGRESS when other items are pending", func(t *testing.T) {
+		orderRepositoryGateway.EXPECT().UpdateOrderItemStatus(1, 10, "READY").Return(false, nil).Times(1)
+
+		response, err := orderUsecase.UpdateOrderItemStatus(1, 10, dto.OrderItemStatusReady)
+
+		assert.NoError(t, err)
+		assert.Equal(t, dto.OrderItemStatusResponseDTO{OrderID: 1, ItemID: 10, ItemStatus: dto.OrderItemStatusReady, OrderStatus: dto.OrderStatusInProgress}, response)
+	})
+
+	t.Run("should publish the order moving to READY with its last item", func(t *testing.T) {
+		orderRepositoryGateway.EXPECT().UpdateOrderItemStatus(1, 11, "READY").Return(true, nil).Times(1)
+		eventPublisher.EXPECT().
+			Publish(events.Event{
+				Type:       events.OrderStatusChanged,
+				OrderID:    1,
+				Payload:    map[string]interface{}{"from": "IN_PROGRESS", "to": "READY"},
+				OccurredAt: clock.Now(),
+			}).
+			Return(nil).
+			Times(1)
+
+		response, err := orderUsecase.UpdateOrderItemStatus(1, 11, dto.OrderItemStatusReady)
+
+		assert.NoError(t, err)
+		assert.Equal(t, dto.OrderItemStatusResponseDTO{OrderID: 1, ItemID: 11, ItemStatus: dto.OrderItemStatusReady, OrderStatus: dto.OrderStatusReady}, response)
+	})
+}
+
+func TestOrderUsecase_GetOrderByNumber(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	orderRepositoryGateway := mock_gateways.NewMockOrderRepositoryGateway(ctrl)
+	orderNumberGenerator := NewOrderNumberGenerator(OrderNumberModeDaily)
+	orderUsecase := NewOrderUsecase(nil, nil, nil, orderRepositoryGateway, nil, NewTaxCalculator(0, nil, 0), NewNoteRedactor(false, nil), nil, nil, orderNumberGenerator, nil, nil, nil, 0, nil)
+
+	t.Run("should find the order by the number typed from the receipt", func(t *testing.T) {
+		order := entities.Order{ID: 98765, Number: "042", Status: "READY"}
+		orderRepositoryGateway.EXPECT().FindOrderByNumber("042").Return(order, nil).Times(1)
+
+		got, err := orderUsecase.GetOrderByNumber("42")
+
+		assert.NoError(t, err)
+		assert.Equal(t, order, got)
+	})
+
+	t.Run("should fail when no order has the number", func(t *testing.T) {
+		orderRepositoryGateway.EXPECT().FindOrderByNumber("999").Return(entities.Order{}, sql.ErrNotFound).Times(1)
+
+		_, err := orderUsecase.GetOrderByNumber("999")
+
+		assert.ErrorIs(t, err, sql.ErrNotFound)
+	})
+}
+
+func TestOrderUsecase_ReopenOrder(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	gracePeriod := 10 * time.Minute
+
+	tests := []struct {
+		name        string
+		reopened    bool
+		status      string
+		wantPublish bool
+		wantErr     error
+	}{
+		{
+			name:        "should reopen an order completed within the grace period",
+			reopened:    true,
+			wantPublish: true,
+		},
+		{
+			name:    "should not reopen an order completed before the grace period",
+			status:  "DONE",
+			wantErr: fmt.Errorf("%w, the grace period of 10m0s since its completion has expired", dto.ErrOrderNotReopenable),
+		},
+		{
+			name:    "should not reopen an order that is not DONE",
+			status:  "READY",
+			wantErr: fmt.Errorf("%w, order is [READY]", dto.ErrOrderNotReopenable),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			orderRepositoryGateway := mock_gateways.NewMockOrderRepositoryGateway(ctrl)
+			eventPublisher := mock_events.NewMockPublisher(ctrl)
+			clock := &fakeClock{now: now}
+			orderUsecase := NewOrderUsecase(nil, nil, nil, orderRepositoryGateway, nil, NewTaxCalculator(0, nil, 0), NewNoteRedactor(false, nil), eventPublisher, nil, nil, nil, nil, nil, gracePeriod, clock)
+
+			statusCalls, publishCalls := 1, 0
+			if tt.reopened {
+				statusCalls = 0
+			}
+			if tt.wantPublish {
+				publishCalls = 1
+			}
+
+			// only orders completed since 11:50 are still in the grace period
+			orderRepositoryGateway.EXPECT().ReopenOrder(7, now.Add(-gracePeriod), now).Return(tt.reopened, nil).Times(1)
+			orderRepositoryGateway.EXPECT().GetOrderStatus(7).Return(tt.status, nil).Times(statusCalls)
+			eventPublisher.EXPECT().
+				Publish(events.Event{
+					Type:       events.OrderStatusChanged,
+					OrderID:    7,
+					Payload:    map[string]interface{}{"from": "DONE", "to": "READY"},
+					OccurredAt: now,
+				}).
+				Return(nil).
+				Times(publishCalls)
+
+			err := orderUsecase.ReopenOrder(7)
+
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestOrderUsecase_CancelUnpaidOrders(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		cancelledIds  []int
+		err           error
+		wantResponse  dto.UnpaidOrdersCancellationDTO
+		wantPublished []int
+		wantErr       error
+	}{
+		{
+			name:          "should cancel the unpaid orders of the customer",
+			cancelledIds:  []int{7, 9},
+			wantResponse:  dto.UnpaidOrdersCancellationDTO{CustomerCPF: "00551146010", Cancelled: 2},
+			wantPublished: []int{7, 9},
+		},
+		{
+			name:         "should answer zero when the customer has no unpaid order",
+			cancelledIds: []int{},
+			wantResponse: dto.UnpaidOrdersCancellationDTO{CustomerCPF: "00551146010", Cancelled: 0},
+		},
+		{
+			name:    "should fail when the orders can not be cancelled",
+			err:     errors.New("connection refused"),
+			wantErr: errors.New("connection refused"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			orderRepositoryGateway := mock_gateways.NewMockOrderRepositoryGateway(ctrl)
+			eventPublisher := mock_events.NewMockPublisher(ctrl)
+			clock := &fakeClock{now: now}
+			orderUsecase := NewOrderUsecase(nil, nil, nil, orderRepositoryGateway, nil, NewTaxCalculator(0, nil, 0), NewNoteRedactor(false, nil), eventPublisher, nil, nil, nil, nil, nil, 0, clock)
+
+			orderRepositoryGateway.EXPECT().CancelUnpaidOrders("00551146010", now).Return(tt.cancelledIds, tt.err).Times(1)
+			for _, orderId := range tt.wantPublished {
+				eventPublisher.EXPECT().
+					Publish(events.Event{
+						Type:       events.OrderStatusChanged,
+						OrderID:    orderId,
+						Payload:    map[string]interface{}{"from": "CREATED", "to": "CANCELLED"},
+						OccurredAt: now,
+					}).
+					Return(nil).
+					Times(1)
+			}
+
+			response, err := orderUsecase.CancelUnpaidOrders("00551146010")
+
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantResponse, response)
+		})
+	}
+}
+
+func TestOrderUsecase_GetOrderQRCodePNG(t *testing.T) {
+	type args struct {
+		orderId int
+	}
+	type want struct {
+		png bool
+		err error
+	}
+	type getOrderQRCodeCall struct {
+		qrCode string
+		status string
+		err    error
+	}
+	tests := []struct {
+		name               string
+		args               args
+		want               want
+		getOrderQRCodeCall getOrderQRCodeCall
+	}{
+		{
+			name: "should render the qrcode as png for an order waiting for payment",
+			args: args{orderId: 1},
+			want: want{png: true},
+			getOrderQRCodeCall: getOrderQRCodeCall{
+				qrCode: "00020101021243650016COM.MERCADOLIBRE02013063638f1192a-5fd1-4180-a180-8bcae3556bc35204000053039865802BR5925IZABEL AAAA DE MELO6009SAO PAULO62070503***63040B6D",
+				status: "CREATED",
+			},
+		},
+		{
+			name: "should fail when the order payment is not pending",
+			args: args{orderId: 1},
+			want: want{err: dto.ErrOrderPaymentNotPending},
+			getOrderQRCodeCall: getOrderQRCodeCall{
+				qrCode: "00020101021243650016COM.MERCADOLIBRE",
+				status: "PAID",
+			},
+		},
+		{
+			name:               "should fail when the order has no qrcode",
+			args:               args{orderId: 1},
+			want:               want{err: dto.ErrOrderQRCodeNotFound},
+			getOrderQRCodeCall: getOrderQRCodeCall{status: "CREATED"},
+		},
+		{
+			name:               "should fail when the order does not exist",
+			args:               args{orderId: 1},
+			want:               want{err: sql.ErrNotFound},
+			getOrderQRCodeCall: getOrderQRCodeCall{err: sql.ErrNotFound},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			orderRepositoryGateway := mock_gateways.NewMockOrderRepositoryGateway(ctrl)
+			orderRepositoryGateway.EXPECT().
+				GetOrderQRCode(tt.args.orderId).
+				Return(dto.PaymentQRCode{QRCode: tt.getOrderQRCodeCall.qrCode}, tt.getOrderQRCodeCall.status, tt.getOrderQRCodeCall.err).
+				Times(1)
+
+			orderUsecase := NewOrderUsecase(nil, nil, nil, orderRepositoryGateway, nil, NewTaxCalculator(0, nil, 0), NewNoteRedactor(false, nil), nil, qrcode.NewRenderer(256), nil, nil, nil, nil, 0, nil)
+
+			png, err := orderUsecase.GetOrderQRCodePNG(tt.args.orderId)
+
+			assert.ErrorIs(t, err, tt.want.err)
+			assert.Equal(t, tt.want.png, bytes.HasPrefix(png, []byte("\x89PNG\r\n\x1a\n")))
+		})
+	}
+}
+
+func TestOrderUsecase_GetOrderPaymentQRCode(t *testing.T) {
+	expiresAt := time.Date(2024, 1, 1, 12, 15, 0, 0, time.UTC)
+	qrCode := dto.PaymentQRCode{QRCode: "mercadopago123456", ExpiresAt: &expiresAt}
+
+	tests := []struct {
+		name    string
+		qrCode  dto.PaymentQRCode
+		status  string
+		err     error
+		want    dto.PaymentQRCode
+		wantErr error
+	}{
+		{
+			name:   "should return the qrcode with its expiration for an order waiting for payment",
+			qrCode: qrCode,
+			status: "CREATED",
+			want:   qrCode,
+		},
+		{
+			name:    "should fail when the order is already paid",
+			qrCode:  qrCode,
+			status:  "PAID",
+			wantErr: dto.ErrOrderPaymentNotPending,
+		},
+		{
+			name:    "should fail when the order does not exist",
+			err:     sql.ErrNotFound,
+			wantErr: sql.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			orderRepositoryGateway := mock_gateways.NewMockOrderRepositoryGateway(ctrl)
+			orderRepositoryGateway.EXPECT().GetOrderQRCode(7).Return(tt.qrCode, tt.status, tt.err).Times(1)
+
+			orderUsecase := NewOrderUsecase(nil, nil, nil, orderRepositoryGateway, nil, NewTaxCalculator(0, nil, 0), NewNoteRedactor(false, nil), nil, nil, nil, nil, nil, nil, 0, nil)
+
+			got, err := orderUsecase.GetOrderPaymentQRCode(7)
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestOrderUsecase_GetAllOrders_Today(t *testing.T) {
+	saoPaulo, _ := time.LoadLocation("America/Sao_Paulo")
+	// still the 1st in Sao Paulo, while it is already the 2nd in UTC
+	now := time.Date(2024, 1, 2, 1, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		filters dto.OrderFilters
+		want    dto.OrderFilters
+	}{
+		{
+			name:    "should bound the orders to the current day of the store timezone",
+			filters: dto.OrderFilters{Today: true, Statuses: []string{"RECEIVED", "READY"}},
+			want: dto.OrderFilters{
+				Today:         true,
+				Statuses:      []string{"RECEIVED", "READY"},
+				CreatedFrom:   time.Date(2024, 1, 1, 0, 0, 0, 0, saoPaulo),
+				CreatedBefore: time.Date(2024, 1, 2, 0, 0, 0, 0, saoPaulo),
+			},
+		},
+		{
+			name:    "should not bound the orders when today is not requested",
+			filters: dto.OrderFilters{Statuses: []string{"READY"}},
+			want:    dto.OrderFilters{Statuses: []string{"READY"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			orderRepositoryGateway := mock_gateways.NewMockOrderRepositoryGateway(ctrl)
+			businessHours, err := NewBusinessHours(nil, "America/Sao_Paulo")
+			assert.NoError(t, err)
+			orderUsecase := NewOrderUsecase(nil, nil, nil, orderRepositoryGateway, nil, NewTaxCalculator(0, nil, 0), NewNoteRedactor(false, nil), nil, nil, nil, businessHours, nil, nil, 0, &fakeClock{now: now})
+
+			orderRepositoryGateway.EXPECT().
+				FindAllOrders(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(pageParams dto.PageParams, filters dto.OrderFilters) ([]entities.Order, error) {
+					assert.Equal(t, tt.want.Statuses, filters.Statuses)
+					assert.True(t, tt.want.CreatedFrom.Equal(filters.CreatedFrom), filters.CreatedFrom)
+					assert.True(t, tt.want.CreatedBefore.Equal(filters.CreatedBefore), filters.CreatedBefore)
+					return []entities.Order{}, nil
+				}).
+				Times(1)
+
+			_, err = orderUsecase.GetAllOrders(dto.NewPageParams(0, 10), tt.filters)
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestOrderUsecase_GetCompletedOrders(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	completed := []dto.CompletedOrderDTO{{OrderID: 7, Number: "042", CompletedAt: now.Add(-5 * time.Minute)}}
+
+	tests := []struct {
+		name      string
+		since     time.Time
+		wantSince time.Time
+	}{
+		{
+			name:      "should list the orders completed since the given time",
+			since:     now.Add(-2 * time.Hour),
+			wantSince: now.Add(-2 * time.Hour),
+		},
+		{
+			name:      "should fall back to the default window when since is not given",
+			wantSince: now.Add(-dto.DefaultCompletedOrdersWindow),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			orderRepositoryGateway := mock_gateways.NewMockOrderRepositoryGateway(ctrl)
+			orderRepositoryGateway.EXPECT().FindCompletedOrders(tt.wantSince, dto.MaxCompletedOrders).Return(completed, nil).Times(1)
+			orderUsecase := NewOrderUsecase(nil, nil, nil, orderRepositoryGateway, nil, NewTaxCalculator(0, nil, 0), NewNoteRedactor(false, nil), nil, nil, nil, nil, nil, nil, 0, &fakeClock{now: now})
+
+			orders, err := orderUsecase.GetCompletedOrders(tt.since)
+
+			assert.NoError(t, err)
+			assert.Equal(t, completed, orders)
+		})
+	}
+}
+
+func TestOrderUsecase_GetOrderStatuses(t *testing.T) {
+	type args struct {
+		orderIds []int
+	}
+	type want struct {
+		result dto.OrderStatusesDTO
+		err    error
+	}
+	type getOrderStatusesCall struct {
+		statuses map[int]string
+		err      error
+	}
+	tests := []struct {
+		name                 string
+		args                 args
+		want                 want
+		getOrderStatusesCall getOrderStatusesCall
+	}{
+		{
+			name: "should return the status of every order when all are found",
+			args: args{orderIds: []int{1, 2, 3}},
+			want: want{result: dto.OrderStatusesDTO{
+				Statuses: map[int]dto.OrderStatus{1: dto.OrderStatusCreated, 2: dto.OrderStatusInProgress, 3: dto.OrderStatusReady},
+				NotFound: []int{},
+			}},
+			getOrderStatusesCall: getOrderStatusesCall{statuses: map[int]string{1: "CREATED", 2: "IN_PROGRESS", 3: "READY"}},
+		},
+		{
+			name: "should report unknown orders separately when only some are found",
+			args: args{orderIds: []int{1, 7, 2, 9, 7}},
+			want: want{result: dto.OrderStatusesDTO{
+				Statuses: map[int]dto.OrderStatus{1: dto.OrderStatusPaid, 2: dto.OrderStatusDone},
+				NotFound: []int{7, 9},
+			}},
+			getOrderStatusesCall: getOrderStatusesCall{statuses: map[int]string{1: "PAID", 2: "DONE"}},
+		},
+		{
+			name:                 "should fail when the statuses cannot be fetched",
+			args:                 args{orderIds: []int{1}},
+			want:                 want{err: errors.New("connection refused")},
+			getOrderStatusesCall: getOrderStatusesCall{err: errors.New("connection refused")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			orderRepositoryGateway := mock_gateways.NewMockOrderRepositoryGateway(ctrl)
+			orderRepositoryGateway.EXPECT().
+				GetOrderStatuses(tt.args.orderIds).
+				Return(tt.getOrderStatusesCall.statuses, tt.getOrderStatusesCall.err).
+				Times(1)
+
+			orderUsecase := NewOrderUsecase(nil, nil, nil, orderRepositoryGateway, nil, NewTaxCalculator(0, nil, 0), NewNoteRedactor(false, nil), nil, nil, nil, nil, nil, nil, 0, nil)
+
+			result, err := orderUsecase.GetOrderStatuses(tt.args.orderIds)
+
+			assert.Equal(t, tt.want.err, err)
+			assert.Equal(t, tt.want.result, result)
+		})
+	}
+}
+
+func TestOrderUsecase_SendOrderFeedback(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	type want struct {
+		feedback entities.OrderFeedback
+		err      error
+	}
+	tests := []struct {
+		name      string
+		status    string
+		saveCalls int
+		saveErr   error
+		want      want
+	}{
+		{
+			name:      "should save the feedback of a done order",
+			status:    "DONE",
+			saveCalls: 1,
+			want:      want{feedback: entities.OrderFeedback{ID: 1, OrderID: 7, Rating: 4, Comment: "Batata fria", CreatedAt: entities.NewTimestamp(now)}},
+		},
+		{
+			name:   "should not accept feedback before the order is done",
+			status: "READY",
+			want:   want{err: dto.ErrOrderNotDone},
+		},
+		{
+			name:      "should not accept a second feedback for the same order",
+			status:    "DONE",
+			saveCalls: 1,
+			saveErr:   dto.ErrOrderFeedbackAlreadySent,
+			want:      want{err: dto.ErrOrderFeedbackAlreadySent},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			orderRepositoryGateway := mock_gateways.NewMockOrderRepositoryGateway(ctrl)
+			orderRepositoryGateway.EXPECT().GetOrderStatus(7).Return(tt.status, nil).Times(1)
+			orderRepositoryGateway.EXPECT().
+				SaveOrderFeedback(entities.OrderFeedback{OrderID: 7, Rating: 4, Comment: "Batata fria", CreatedAt: entities.NewTimestamp(now)}).
+				Return(1, tt.saveErr).
+				Times(tt.saveCalls)
+
+			orderUsecase := NewOrderUsecase(nil, nil, nil, orderRepositoryGateway, nil, NewTaxCalculator(0, nil, 0), NewNoteRedactor(false, nil), nil, nil, nil, nil, nil, nil, 0, &fakeClock{now: now})
+
+			feedback, err := orderUsecase.SendOrderFeedback(7, dto.OrderFeedbackDTO{Rating: 4, Comment: "Batata fria"})
+
+			assert.Equal(t, tt.want.err, err)
+			assert.Equal(t, tt.want.feedback, feedback)
+		})
+	}
+}
+
+func TestOrderUsecase_ValidateOrder(t *testing.T) {
+	type findProductCall struct {
+		productId int
+		maxQty    int
+		variants  []entities.ProductVariant
+		err       error
+	}
+	type want struct {
+		fieldErrors []dto.FieldError
+		err         error
+	}
+	tests := []struct {
+		name             string
+		order            dto.OrderDTO
+		findProductCalls []findProductCall
+		want             want
+	}{
+		{
+			name: "should report no errors for a valid order with available products",
+			order: dto.OrderDTO{
+				Items:       []dto.OrderItemDTO{{ProductId: 1, Quantity: 1, Type: dto.OrderItemTypeUnit}},
+				CustomerCPF: "00551146010",
+				Status:      dto.OrderStatusCreated,
+			},
+			findProductCalls: []findProductCall{{productId: 1}},
+			want:             want{fieldErrors: []dto.FieldError{}},
+		},
+		{
+			name: "should report the payload errors along with the missing products",
+			order: dto.OrderDTO{
+				Items: []dto.OrderItemDTO{
+					{ProductId: 1, Quantity: 1, Type: dto.OrderItemTypeUnit},
+					{ProductId: 99, Quantity: 1, Type: dto.OrderItemTypeUnit},
+				},
+				CustomerCPF: "00551146011",
+				Status:      dto.OrderStatusCreated,
+			},
+			findProductCalls: []findProductCall{{productId: 1}, {productId: 99, err: sql.ErrNotFound}},
+			want: want{fieldErrors: []dto.FieldError{
+				{Field: "customerCpf", Err: dto.InvalidCPFError{CPF: "00551146011", Reason: dto.InvalidCPFCheckDigits}},
+				{Field: "items[1].productId", Err: dto.OrderItemProductNotFoundError{ProductID: 99}},
+			}},
+		},
+		{
+			name: "should report the items without a valid variant",
+			order: dto.OrderDTO{
+				Items: []dto.OrderItemDTO{
+					{ProductId: 1, Quantity: 1, Type: dto.OrderItemTypeUnit},
+					{ProductId: 2, VariantId: 11, Quantity: 1, Type: dto.OrderItemTypeUnit},
+					{ProductId: 2, VariantId: 21, Quantity: 1, Type: dto.OrderItemTypeUnit},
+				},
+				CustomerCPF: "00551146010",
+				Status:      dto.OrderStatusCreated,
+			},
+			findProductCalls: []findProductCall{
+				{productId: 1, variants: []entities.ProductVariant{{ID: 11, ProductID: 1}}},
+				{productId: 2, variants: []entities.ProductVariant{{ID: 21, ProductID: 2}}},
+				{productId: 2, variants: []entities.ProductVariant{{ID: 21, ProductID: 2}}},
+			},
+			want: want{fieldErrors: []dto.FieldError{
+				{Field: "items[0].variantId", Err: dto.ProductVariantRequiredError{ProductID: 1}},
+				{Field: "items[1].variantId", Err: dto.InvalidProductVariantError{ProductID: 2, VariantID: 11}},
+			}},
+		},
+		{
+			name: "should report once the units of a product above its limit across the items",
+			order: dto.OrderDTO{
+				Items: []dto.OrderItemDTO{
+					{ProductId: 1, Quantity: 6, Type: dto.OrderItemTypeUnit},
+					{ProductId: 1, Quantity: 5, Type: dto.OrderItemTypeUnit},
+				},
+				CustomerCPF: "00551146010",
+				Status:      dto.OrderStatusCreated,
+			},
+			findProductCalls: []findProductCall{{productId: 1, maxQty: 10}, {productId: 1, maxQty: 10}},
+			want: want{fieldErrors: []dto.FieldError{
+				{Field: "items[0].quantity", Err: dto.ProductQuantityLimitError{ProductID: 1, Quantity: 11, MaxQty: 10}},
+			}},
+		},
+		{
+			name: "should fail when the products can not be checked",
+			order: dto.OrderDTO{
+				Items:       []dto.OrderItemDTO{{ProductId: 1, Quantity: 1, Type: dto.OrderItemTypeUnit}},
+				CustomerCPF: "00551146010",
+				Status:      dto.OrderStatusCreated,
+			},
+			findProductCalls: []findProductCall{{productId: 1, err: errors.New("connection refused")}},
+			want:             want{err: errors.New("connection refused")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			productRepositoryGateway := mock_gateways.NewMockProductRepositoryGateway(ctrl)
+			productVariantRepositoryGateway := mock_gateways.NewMockProductVariantRepositoryGateway(ctrl)
+			for _, call := range tt.findProductCalls {
+				productRepositoryGateway.EXPECT().
+					FindProductById(call.productId).
+					Return(entities.Product{ID: call.productId, Price: 10, MaxQty: call.maxQty}, call.err).
+					Times(1)
+				if call.err == nil {
+					productVariantRepositoryGateway.EXPECT().
+						FindVariantsByProductIds([]int{call.productId}).
+						Return(append([]entities.ProductVariant{}, call.variants...), nil).
+						Times(1)
+				}
+			}
+			productUsecase := NewProductUsecase(productRepositoryGateway, nil, productVariantRepositoryGateway, NewTaxCalculator(0, nil, 0), NewCategoryPolicy("", ""))
+			orderUsecase := NewOrderUsecase(nil, nil, productUsecase, nil, nil, NewTaxCalculator(0, nil, 0), NewNoteRedactor(false, nil), nil, nil, nil, nil, nil, nil, 0, nil)
+
+			fieldErrors, err := orderUsecase.ValidateOrder(tt.order)
+
+			assert.Equal(t, tt.want.err, err)
+			assert.Equal(t, tt.want.fieldErrors, fieldErrors)
+		})
+	}
+}
+
+func TestOrderUsecase_BatchCreateOrders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	authorizer := mock_auth.NewMockAuthorizer(ctrl)
+	paymentBroker := mock_payment.NewMockPaymentBroker(ctrl)
+	productRepositoryGateway := mock_gateways.NewMockProductRepositoryGateway(ctrl)
+	productVariantRepositoryGateway := mock_gateways.NewMockProductVariantRepositoryGateway(ctrl)
+	orderRepositoryGateway := mock_gateways.NewMockOrderRepositoryGateway(ctrl)
+	eventPublisher := mock_events.NewMockPublisher(ctrl)
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	businessHours, err := NewBusinessHours(nil, "UTC")
+	assert.NoError(t, err)
+
+	// the first order is created, the second misses its status and the third has a product that does not exist
+	authorizer.EXPECT().AuthorizeUser("00551146010").Return(dto.AuthorizerResponse{UserId: 1, IsAuthorized: true}, nil).MinTimes(1)
+	productRepositoryGateway.EXPECT().FindProductById(1).Return(entities.Product{ID: 1, Name: "Refrigerante", Price: 10}, nil).Times(1)
+	productRepositoryGateway.EXPECT().FindProductById(2).Return(entities.Product{}, sql.ErrNotFound).Times(1)
+	productVariantRepositoryGateway.EXPECT().FindVariantsByProductIds(gomock.Any()).Return([]entities.ProductVariant{}, nil).AnyTimes()
+	orderRepositoryGateway.EXPECT().SaveOrder(gomock.Any(), gomock.Any()).DoAndReturn(func(order entities.Order, number gateways.OrderNumberFunc) (int, string, error) {
+		return numberSavedOrder(order, 98765, number)
+	}).Times(1)
+	paymentBroker.EXPECT().GeneratePaymentQRCode(gomock.Any()).Return(dto.PaymentQRCodeResponse{QrData: "mercadopago123456"}, nil).Times(1)
+	orderRepositoryGateway.EXPECT().UpdateOrderQRCode(98765, dto.PaymentQRCode{QRCode: "mercadopago123456"}).Return(nil).Times(1)
+	// only the order created is announced
+	eventPublisher.EXPECT().
+		Publish(events.Event{
+			Type:       events.OrderCreated,
+			OrderID:    98765,
+			Payload:    map[string]interface{}{"number": "042", "status": "CREATED", "total": 20.0},
+			OccurredAt: clock.Now(),
+		}).
+		Return(nil).
+		Times(1)
+
+	orderUsecase := NewOrderUsecase(
+		NewAuthorizerUsecase(authorizer, time.Minute, time.Minute, clock),
+		NewPaymentUsecase("https://g37-lanches", "12345", paymentBroker, dto.DefaultPaymentMethods, 0, nil),
+		NewProductUsecase(productRepositoryGateway, nil, productVariantRepositoryGateway, NewTaxCalculator(0, nil, 0), NewCategoryPolicy("", "")),
+		orderRepositoryGateway,
+		nil,
+		NewTaxCalculator(0, nil, 0),
+		NewNoteRedactor(false, nil),
+		eventPublisher,
+		nil,
+		NewOrderNumberGenerator(OrderNumberModeDaily),
+		businessHours,
+		nil,
+		nil,
+		0,
+		clock,
+	)
+
+	results := orderUsecase.BatchCreateOrders([]dto.OrderDTO{
+		{Items: []dto.OrderItemDTO{{ProductId: 1, Quantity: 2, Type: dto.OrderItemTypeUnit}}, CustomerCPF: "00551146010", Status: dto.OrderStatusCreated},
+		{Items: []dto.OrderItemDTO{{ProductId: 1, Quantity: 1, Type: dto.OrderItemTypeUnit}}, CustomerCPF: "00551146010"},
+		{Items: []dto.OrderItemDTO{{ProductId: 2, Quantity: 1, Type: dto.OrderItemTypeUnit}}, CustomerCPF: "00551146010", Status: dto.OrderStatusCreated},
+	})
+
+	assert.Equal(t, []dto.BatchOrderResultDTO{
+		{Index: 0, Status: dto.BatchOrderStatusCreated, OrderID: 98765, Number: "042", QRCode: "mercadopago123456"},
+		{Index: 1, Status: dto.BatchOrderStatusInvalid, Error: "Status is invalid"},
+		{Index: 2, Status: dto.BatchOrderStatusFailed, Error: "entity not found"},
+	}, results)
+}
+
+type fakeStoreCreditProvider struct {
+	balance float64
+}
+
+func (p fakeStoreCreditProvider) GetBalance(customerId int) (float64, error) {
+	return p.balance, nil
+}
+
+func TestOrderUsecase_CreateOrder_StoreCredit(t *testing.T) {
+	type want struct {
+		response     dto.OrderCreationResponse
+		totalAmount  float64
+		storeCredit  float64
+		status       string
+		skipsPayment bool
+		err          error
+	}
+	tests := []struct {
+		name   string
+		credit float64
+		// the order costs 20 and the customer has a balance of 30
+		provider loyalty.StoreCreditProvider
+		want     want
+	}{
+		{
+			name:     "should charge the total left after a partial credit",
+			credit:   7.5,
+			provider: fakeStoreCreditProvider{balance: 30},
+			want: want{
+				response:    dto.OrderCreationResponse{QRCode: "mercadopago123456", OrderID: 98765, Number: "042"},
+				totalAmount: 12.5,
+				storeCredit: 7.5,
+				status:      "CREATED",
+			},
+		},
+		{
+			name:     "should skip the payment of an order fully paid with credit",
+			credit:   20,
+			provider: fakeStoreCreditProvider{balance: 30},
+			want: want{
+				response:     dto.OrderCreationResponse{OrderID: 98765, Number: "042"},
+				totalAmount:  0,
+				storeCredit:  20,
+				status:       "PAID",
+				skipsPayment: true,
+			},
+		},
+		{
+			name:     "should clamp the credit to the order total",
+			credit:   25,
+			provider: fakeStoreCreditProvider{balance: 30},
+			want: want{
+				response:     dto.OrderCreationResponse{OrderID: 98765, Number: "042"},
+				totalAmount:  0,
+				storeCredit:  20,
+				status:       "PAID",
+				skipsPayment: true,
+			},
+		},
+		{
+			name:     "should not create the order with credit over the customer balance",
+			credit:   35,
+			provider: fakeStoreCreditProvider{balance: 30},
+			want:     want{err: fmt.Errorf("%w, balance is 30.00", dto.ErrStoreCreditExceeded)},
+		},
+		{
+			name:   "should not create the order with credit when there is no loyalty program",
+			credit: 5,
+			want:   want{err: dto.ErrStoreCreditUnavailable},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			authorizer := mock_auth.NewMockAuthorizer(ctrl)
+			paymentBroker := mock_payment.NewMockPaymentBroker(ctrl)
+			productRepositoryGateway := mock_gateways.NewMockProductRepositoryGateway(ctrl)
+			productVariantRepositoryGateway := mock_gateways.NewMockProductVariantRepositoryGateway(ctrl)
+			orderRepositoryGateway := mock_gateways.NewMockOrderRepositoryGateway(ctrl)
+			eventPublisher := mock_events.NewMockPublisher(ctrl)
+			clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+			businessHours, err := NewBusinessHours(nil, "UTC")
+			assert.NoError(t, err)
+
+			creationCalls := 0
+			if tt.want.err == nil {
+				creationCalls = 1
+			}
+			paymentCalls := creationCalls
+			if tt.want.skipsPayment {
+				paymentCalls = 0
+			}
+
+			authorizer.EXPECT().AuthorizeUser("00551146010").Return(dto.AuthorizerResponse{UserId: 1, IsAuthorized: true}, nil).Times(1)
+			productRepositoryGateway.EXPECT().FindProductById(1).Return(entities.Product{ID: 1, Name: "Refrigerante", Price: 10}, nil).Times(1)
+			productVariantRepositoryGateway.EXPECT().FindVariantsByProductIds([]int{1}).Return([]entities.ProductVariant{}, nil).Times(1)
+			orderRepositoryGateway.EXPECT().
+				SaveOrder(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(order entities.Order, number gateways.OrderNumberFunc) (int, string, error) {
+					assert.Equal(t, 20.0, order.SubtotalAmount)
+					assert.Equal(t, tt.want.totalAmount, order.TotalAmount)
+					assert.Equal(t, tt.want.storeCredit, order.StoreCreditAmount)
+					assert.Equal(t, tt.want.status, order.Status)
+					return numberSavedOrder(order, 98765, number)
+				}).
+				Times(creationCalls)
+			paymentBroker.EXPECT().
+				GeneratePaymentQRCode(gomock.Any()).
+				DoAndReturn(func(request dto.PaymentQRCodeRequest) (dto.PaymentQRCodeResponse, error) {
+					assert.Equal(t, tt.want.totalAmount, request.TotalAmount)
+					return dto.PaymentQRCodeResponse{QrData: "mercadopago123456"}, nil
+				}).
+				Times(paymentCalls)
+			orderRepositoryGateway.EXPECT().UpdateOrderQRCode(98765, dto.PaymentQRCode{QRCode: "mercadopago123456"}).Return(nil).Times(paymentCalls)
+			eventPublisher.EXPECT().Publish(gomock.Any()).Return(nil).Times(creationCalls)
+
+			orderUsecase := NewOrderUsecase(
+				NewAuthorizerUsecase(authorizer, time.Minute, time.Minute, clock),
+				NewPaymentUsecase("https://g37-lanches", "12345", paymentBroker, dto.DefaultPaymentMethods, 0, nil),
+				NewProductUsecase(productRepositoryGateway, nil, productVariantRepositoryGateway, NewTaxCalculator(0, nil, 0), NewCategoryPolicy("", "")),
+				orderRepositoryGateway,
+				nil,
+				NewTaxCalculator(0, nil, 0),
+				NewNoteRedactor(false, nil),
+				eventPublisher,
+				nil,
+				NewOrderNumberGenerator(OrderNumberModeDaily),
+				businessHours,
+				tt.provider,
+				nil,
+				0,
+				clock,
+			)
+
+			response, err := orderUsecase.CreateOrder(dto.OrderDTO{
+				Items:              []dto.OrderItemDTO{{ProductId: 1, Quantity: 2, Type: dto.OrderItemTypeUnit}},
+				CustomerCPF:        "00551146010",
+				Status:             dto.OrderStatusCreated,
+				StoreCreditToApply: tt.credit,
+			})
+
+			assert.Equal(t, tt.want.err, err)
+			assert.Equal(t, tt.want.response, response)
+		})
+	}
+}
+
+func TestOrderUsecase_CreateOrder_Coupon(t *testing.T) {
+	expiredAt := time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC)
+	errCouponsUnavailable := errors.New("connection refused")
+
+	type want struct {
+		response     dto.OrderCreationResponse
+		totalAmount  float64
+		discount     float64
+		status       string
+		skipsPayment bool
+		err          error
+	}
+	tests := []struct {
+		name string
+		// the order costs 20
+		coupon    entities.Coupon
+		couponErr error
+		want      want
+	}{
+		{
+			name:   "should take the percent of the coupon off the total",
+			coupon: entities.Coupon{Code: "APP10", Type: "PERCENT", Value: 1000},
+			want: want{
+				response:    dto.OrderCreationResponse{QRCode: "mercadopago123456", OrderID: 98765, Number: "042"},
+				totalAmount: 18,
+				discount:    2,
+				status:      "CREATED",
+			},
+		},
+		{
+			name:   "should skip the payment of an order fully paid with a fixed coupon",
+			coupon: entities.Coupon{Code: "APP10", Type: "FIXED", Value: 2500},
+			want: want{
+				response:     dto.OrderCreationResponse{OrderID: 98765, Number: "042"},
+				totalAmount:  0,
+				discount:     20,
+				status:       "PAID",
+				skipsPayment: true,
+			},
+		},
+		{
+			name:      "should create the order without discount when the coupon does not exist",
+			couponErr: sql.ErrNotFound,
+			want: want{
+				response:    dto.OrderCreationResponse{QRCode: "mercadopago123456", OrderID: 98765, Number: "042"},
+				totalAmount: 20,
+				status:      "CREATED",
+			},
+		},
+		{
+			name:   "should create the order without discount when the coupon expired",
+			coupon: entities.Coupon{Code: "APP10", Type: "PERCENT", Value: 1000, ExpiresAt: &expiredAt},
+			want: want{
+				response:    dto.OrderCreationResponse{QRCode: "mercadopago123456", OrderID: 98765, Number: "042"},
+				totalAmount: 20,
+				status:      "CREATED",
+			},
+		},
+		{
+			name:   "should create the order without discount below the minimum total of the coupon",
+			coupon: entities.Coupon{Code: "APP10", Type: "PERCENT", Value: 1000, MinTotal: 3000},
+			want: want{
+				response:    dto.OrderCreationResponse{QRCode: "mercadopago123456", OrderID: 98765, Number: "042"},
+				totalAmount: 20,
+				status:      "CREATED",
+			},
+		},
+		{
+			name:      "should not create the order when the coupon can not be read",
+			couponErr: errCouponsUnavailable,
+			want:      want{err: errCouponsUnavailable},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			authorizer := mock_auth.NewMockAuthorizer(ctrl)
+			paymentBroker := mock_payment.NewMockPaymentBroker(ctrl)
+			productRepositoryGateway := mock_gateways.NewMockProductRepositoryGateway(ctrl)
+			productVariantRepositoryGateway := mock_gateways.NewMockProductVariantRepositoryGateway(ctrl)
+			orderRepositoryGateway := mock_gateways.NewMockOrderRepositoryGateway(ctrl)
+			couponRepositoryGateway := mock_gateways.NewMockCouponRepositoryGateway(ctrl)
+			eventPublisher := mock_events.NewMockPublisher(ctrl)
+			clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+			businessHours, err := NewBusinessHours(nil, "UTC")
+			assert.NoError(t, err)
+
+			creationCalls := 0
+			if tt.want.err == nil {
+				creationCalls = 1
+			}
+			paymentCalls := creationCalls
+			if tt.want.skipsPayment {
+				paymentCalls = 0
+			}
+
+			authorizer.EXPECT().AuthorizeUser("00551146010").Return(dto.AuthorizerResponse{UserId: 1, IsAuthorized: true}, nil).Times(1)
+			productRepositoryGateway.EXPECT().FindProductById(1).Return(entities.Product{ID: 1, Name: "Refrigerante", Price: 10}, nil).Times(1)
+			productVariantRepositoryGateway.EXPECT().FindVariantsByProductIds([]int{1}).Return([]entities.ProductVariant{}, nil).Times(1)
+			couponRepositoryGateway.EXPECT().FindCouponByCode("APP10").Return(tt.coupon, tt.couponErr).Times(1)
+			orderRepositoryGateway.EXPECT().
+				SaveOrder(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(order entities.Order, number gateways.OrderNumberFunc) (int, string, error) {
+					assert.Equal(t, "APP10", order.Coupon)
+					assert.Equal(t, 20.0, order.SubtotalAmount)
+					assert.Equal(t, tt.want.discount, order.DiscountAmount)
+					assert.Equal(t, tt.want.totalAmount, order.TotalAmount)
+					assert.Equal(t, tt.want.status, order.Status)
+					return numberSavedOrder(order, 98765, number)
+				}).
+				Times(creationCalls)
+			paymentBroker.EXPECT().
+				GeneratePaymentQRCode(gomock.Any()).
+				DoAndReturn(func(request dto.PaymentQRCodeRequest) (dto.PaymentQRCodeResponse, error) {
+					assert.Equal(t, tt.want.totalAmount, request.TotalAmount)
+					return dto.PaymentQRCodeResponse{QrData: "mercadopago123456"}, nil
+				}).
+				Times(paymentCalls)
+			orderRepositoryGateway.EXPECT().UpdateOrderQRCode(98765, dto.PaymentQRCode{QRCode: "mercadopago123456"}).Return(nil).Times(paymentCalls)
+			eventPublisher.EXPECT().Publish(gomock.Any()).Return(nil).Times(creationCalls)
+
+			orderUsecase := NewOrderUsecase(
+				NewAuthorizerUsecase(authorizer, time.Minute, time.Minute, clock),
+				NewPaymentUsecase("https://g37-lanches", "12345", paymentBroker, dto.DefaultPaymentMethods, 0, nil),
+				NewProductUsecase(productRepositoryGateway, nil, productVariantRepositoryGateway, NewTaxCalculator(0, nil, 0), NewCategoryPolicy("", "")),
+				orderRepositoryGateway,
+				couponRepositoryGateway,
+				NewTaxCalculator(0, nil, 0),
+				NewNoteRedactor(false, nil),
+				eventPublisher,
+				nil,
+				NewOrderNumberGenerator(OrderNumberModeDaily),
+				businessHours,
+				nil,
+				nil,
+				0,
+				clock,
+			)
+
+			response, err := orderUsecase.CreateOrder(dto.OrderDTO{
+				Items:       []dto.OrderItemDTO{{ProductId: 1, Quantity: 2, Type: dto.OrderItemTypeUnit}},
+				CustomerCPF: "00551146010",
+				Coupon:      "app10",
+				Status:      dto.OrderStatusCreated,
+			})
+
+			assert.Equal(t, tt.want.err, err)
+			assert.Equal(t, tt.want.response, response)
+		})
+	}
+}
+
+type fakeExchangeRateProvider struct {
+	rates map[string]float64
+}
+
+func (p fakeExchangeRateProvider) BaseCurrency() string {
+	return "BRL"
+}
+
+func (p fakeExchangeRateProvider) Rate(currency string) (float64, error) {
+	rate, found := p.rates[currency]
+	if !found {
+		return 0, dto.ErrUnsupportedCurrency
+	}
+	return rate, nil
+}
+
+func TestOrderUsecase_ConvertOrderTotal(t *testing.T) {
+	tests := []struct {
+		name     string
+		currency string
+		provider ExchangeRateProvider
+		want     dto.DisplayTotalDTO
+		wantErr  error
+	}{
+		{
+			name:     "should convert the total to the display currency",
+			currency: "USD",
+			provider: fakeExchangeRateProvider{rates: map[string]float64{"USD": 0.1837}},
+			want:     dto.DisplayTotalDTO{BaseCurrency: "BRL", BaseAmount: 45.9, Currency: "USD", Rate: 0.1837, Amount: 8.43},
+		},
+		{
+			name:     "should return unsupported currency when the provider has no rate for it",
+			currency: "JPY",
+			provider: fakeExchangeRateProvider{rates: map[string]float64{"USD": 0.1837}},
+			wantErr:  dto.ErrUnsupportedCurrency,
+		},
+		{
+			name:     "should return unsupported currency when there is no provider",
+			currency: "USD",
+			wantErr:  dto.ErrUnsupportedCurrency,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orderUsecase := NewOrderUsecase(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, tt.provider, 0, nil)
+
+			displayTotal, err := orderUsecase.ConvertOrderTotal(entities.Order{Number: "042", TotalAmount: 45.9}, tt.currency)
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, displayTotal)
+		})
+	}
+}
+
+func TestStaticExchangeRateProvider_Rate(t *testing.T) {
+	provider := NewStaticExchangeRateProvider("brl", map[string]float64{"usd": 0.18})
+
+	rate, err := provider.Rate("USD")
+	assert.NoError(t, err)
+	assert.Equal(t, 0.18, rate)
+
+	rate, err = provider.Rate("BRL")
+	assert.NoError(t, err)
+	assert.Equal(t, 1.0, rate)
+
+	_, err = provider.Rate("EUR")
+	assert.ErrorIs(t, err, dto.ErrUnsupportedCurrency)
+	assert.Equal(t, "BRL", provider.BaseCurrency())
+}
+
+// numberSavedOrder numbers the order saved with the id as the gateway does, with the daily sequence at 42.
+func numberSavedOrder(order entities.Order, id int, number gateways.OrderNumberFunc) (int, string, error) {
+	order.ID = id
+	orderNumber, err := number(order, func() (int, error) {
+		return 42, nil
+	})
+	return id, orderNumber, err
+}
@@ -6,37 +6,58 @@ import (
 	"g37-lanchonete/internal/core/usecases/dto"
 	"g37-lanchonete/internal/infra/drivers/payment"
 	"strconv"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 )
 
 type PaymentUsecase interface {
-	GeneratePaymentQRCode(order entities.Order) (string, error)
+	GeneratePaymentQRCode(order entities.Order) (dto.PaymentQRCode, error)
+	GetPaymentMethods() dto.PaymentMethodsDTO
 }
 
 type paymentUsecase struct {
 	notificationUrl string
 	sponsorId       string
 	paymentBroker   payment.PaymentBroker
+	paymentMethods  []dto.PaymentMethod
+	expiration      time.Duration
+	clock           Clock
 }
 
-func NewPaymentUsecase(notificationUrl, sponsorId string, paymentBroker payment.PaymentBroker) PaymentUsecase {
+func NewPaymentUsecase(notificationUrl, sponsorId string, paymentBroker payment.PaymentBroker, paymentMethods []dto.PaymentMethod, expiration time.Duration, clock Clock) PaymentUsecase {
 	return paymentUsecase{
 		notificationUrl: notificationUrl,
 		sponsorId:       sponsorId,
 		paymentBroker:   paymentBroker,
+		paymentMethods:  paymentMethods,
+		expiration:      expiration,
+		clock:           clock,
 	}
 }
 
-func (u paymentUsecase) GeneratePaymentQRCode(order entities.Order) (string, error) {
+// GeneratePaymentQRCode asks the broker for a qrcode expiring after the configured expiration, counted from now.
+func (u paymentUsecase) GeneratePaymentQRCode(order entities.Order) (dto.PaymentQRCode, error) {
 	paymentRequest := u.createPaymentRequest(order)
+	if u.expiration > 0 {
+		expiresAt := u.clock.Now().Add(u.expiration)
+		paymentRequest.ExpirationDate = &expiresAt
+	}
+
 	paymentResponse, err := u.paymentBroker.GeneratePaymentQRCode(paymentRequest)
 	if err != nil {
 		log.Errorf("failed to generate payment qrcode for the order [%d], error: %v", order.ID, err)
-		return "", err
+		return dto.PaymentQRCode{}, err
 	}
 
-	return paymentResponse.QrData, nil
+	return dto.PaymentQRCode{QRCode: paymentResponse.QrData, ExpiresAt: paymentRequest.ExpirationDate}, nil
+}
+
+func (u paymentUsecase) GetPaymentMethods() dto.PaymentMethodsDTO {
+	methods := make([]dto.PaymentMethod, len(u.paymentMethods))
+	copy(methods, u.paymentMethods)
+
+	return dto.PaymentMethodsDTO{Methods: methods}
 }
 
 func (u paymentUsecase) createPaymentRequest(order entities.Order) dto.PaymentQRCodeRequest {
@@ -56,15 +77,27 @@ func (u paymentUsecase) createPaymentRequest(order entities.Order) dto.PaymentQR
 }
 
 func createPaymentItem(item entities.OrderItem) dto.PaymentItemRequest {
+	unitPrice := item.Product.Price
+	if item.Product.PriceGross > 0 {
+		unitPrice = item.Product.PriceGross
+	}
+
+	skuNumber := item.Product.SkuId
+	title := item.Product.Name
+	if item.Variant != nil {
+		skuNumber = item.Variant.SkuId
+		title = fmt.Sprintf("%s (%s)", item.Product.Name, item.Variant.Name)
+	}
+
 	paymentItem := dto.PaymentItemRequest{
-		SkuNumber:   item.Product.SkuId,
+		SkuNumber:   skuNumber,
 		Category:    item.Product.Category,
-		Title:       item.Product.Name,
+		Title:       title,
 		Description: item.Product.Description,
-		UnitPrice:   item.Product.Price,
+		UnitPrice:   unitPrice,
 		Quantity:    item.Quantity,
 		UnitMeasure: getUnitMeasure(item.Type),
-		TotalAmount: item.Product.Price * float64(item.Quantity),
+		TotalAmount: unitPrice * float64(item.Quantity),
 	}
 
 	return paymentItem
@@ -0,0 +1,101 @@
+package usecases
+
+import (
+	"g37-lanchonete/internal/core/entities"
+	"g37-lanchonete/internal/core/usecases/dto"
+	mock_payment "g37-lanchonete/internal/infra/drivers/payment/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestPaymentUsecase_GetPaymentMethods(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured []string
+		want       dto.PaymentMethodsDTO
+		wantErr    string
+	}{
+		{
+			name:       "should list the enabled methods from config",
+			configured: []string{"MERCADO_PAGO_QRCODE"},
+			want:       dto.PaymentMethodsDTO{Methods: []dto.PaymentMethod{dto.PaymentMethodMercadoPagoQRCode}},
+		},
+		{
+			name:       "should list the default methods when none is enabled",
+			configured: nil,
+			want:       dto.PaymentMethodsDTO{Methods: dto.DefaultPaymentMethods},
+		},
+		{
+			name:       "should reject an unknown method",
+			configured: []string{"MERCADO_PAGO_QRCODE", "CHEQUE"},
+			wantErr:    "unknown payment method [CHEQUE]",
+		},
+		{
+			name:       "should reject a duplicated method",
+			configured: []string{"MERCADO_PAGO_QRCODE", "MERCADO_PAGO_QRCODE"},
+			wantErr:    "duplicated payment method [MERCADO_PAGO_QRCODE]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			methods, err := dto.ParsePaymentMethods(tt.configured)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+
+			paymentUsecase := NewPaymentUsecase("https://g37-lanches", "12345", nil, methods, 0, nil)
+
+			assert.Equal(t, tt.want, paymentUsecase.GetPaymentMethods())
+		})
+	}
+}
+
+func TestPaymentUsecase_GeneratePaymentQRCode_Expiration(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	expiresAt := now.Add(15 * time.Minute)
+
+	tests := []struct {
+		name          string
+		expiration    time.Duration
+		wantExpiresAt *time.Time
+	}{
+		{
+			name:          "should expire the qrcode after the configured expiration",
+			expiration:    15 * time.Minute,
+			wantExpiresAt: &expiresAt,
+		},
+		{
+			name:       "should not expire the qrcode when no expiration is configured",
+			expiration: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			paymentBroker := mock_payment.NewMockPaymentBroker(ctrl)
+			clock := &fakeClock{now: now}
+
+			var request dto.PaymentQRCodeRequest
+			paymentBroker.EXPECT().
+				GeneratePaymentQRCode(gomock.Any()).
+				DoAndReturn(func(r dto.PaymentQRCodeRequest) (dto.PaymentQRCodeResponse, error) {
+					request = r
+					return dto.PaymentQRCodeResponse{QrData: "mercadopago123456"}, nil
+				}).
+				Times(1)
+
+			paymentUsecase := NewPaymentUsecase("https://g37-lanches", "12345", paymentBroker, dto.DefaultPaymentMethods, tt.expiration, clock)
+			qrCode, err := paymentUsecase.GeneratePaymentQRCode(entities.Order{ID: 7, TotalAmount: 20})
+
+			assert.NoError(t, err)
+			assert.Equal(t, "mercadopago123456", qrCode.QRCode)
+			assert.Equal(t, tt.wantExpiresAt, qrCode.ExpiresAt)
+			assert.Equal(t, tt.wantExpiresAt, request.ExpirationDate)
+		})
+	}
+}
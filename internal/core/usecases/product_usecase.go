@@ -1,52 +1,94 @@
 package usecases
 
 import (
+	"errors"
+	"fmt"
 	"g37-lanchonete/internal/core/entities"
 	"g37-lanchonete/internal/core/usecases/dto"
+	"g37-lanchonete/internal/infra/drivers/sql"
 	"g37-lanchonete/internal/infra/gateways"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 )
 
 type ProductUsecase interface {
-	GetAllProducts(pageParameters dto.PageParams) (dto.Page[entities.Product], error)
-	GetProductsByCategory(pageParameters dto.PageParams, category string) (dto.Page[entities.Product], error)
+	GetAllProducts(pageParameters dto.PageParams, expandVariants bool) (dto.Page[entities.Product], error)
+	GetProductsByCategory(pageParameters dto.PageParams, category string, expandVariants bool) (dto.Page[entities.Product], error)
 	GetProductById(id int) (entities.Product, error)
-	CreateProduct(productDTO dto.ProductDTO) error
-	UpdateProduct(id string, productDTO dto.ProductDTO) error
+	GetProductBySKU(skuId string) (entities.Product, error)
+	GetMenu() (dto.MenuDTO, error)
+	GetLowStockProducts(threshold int) ([]entities.Product, error)
+	GetProductsChangedBy(actor string, from, to time.Time) ([]entities.Product, error)
+	CreateProduct(productDTO dto.ProductDTO, actor string) error
+	DuplicateProduct(id int, duplicateDTO dto.ProductDuplicateDTO, actor string) (entities.Product, error)
+	UpdateProduct(id string, productDTO dto.ProductDTO, actor string) error
 	DeleteProduct(id string) error
+	BulkDeleteProducts(ids []int) []dto.BulkDeleteResultDTO
+	SetCategoryAvailability(availabilityDTO dto.CategoryAvailabilityDTO) (dto.CategoryAvailabilityResponseDTO, error)
+	GetProductPopularity(id int, from, to time.Time) (dto.ProductPopularityDTO, error)
+	GetProductRatings(id int) (dto.ProductRatingsDTO, error)
+	GetProductPriceHistory(id int) ([]entities.ProductPriceChange, error)
+	CreateProductVariant(productId int, variantDTO dto.ProductVariantDTO) error
+	GetProductVariants(productId int) ([]entities.ProductVariant, error)
 }
 
 type productUsecase struct {
-	productRepositoryGateway gateways.ProductRepositoryGateway
+	productRepositoryGateway             gateways.ProductRepositoryGateway
+	productPriceHistoryRepositoryGateway gateways.ProductPriceHistoryRepositoryGateway
+	productVariantRepositoryGateway      gateways.ProductVariantRepositoryGateway
+	taxCalculator                        TaxCalculator
+	categoryPolicy                       CategoryPolicy
 }
 
-func NewProductUsecase(productRepositoryGateway gateways.ProductRepositoryGateway) ProductUsecase {
+func NewProductUsecase(productRepositoryGateway gateways.ProductRepositoryGateway, productPriceHistoryRepositoryGateway gateways.ProductPriceHistoryRepositoryGateway,
+	productVariantRepositoryGateway gateways.ProductVariantRepositoryGateway, taxCalculator TaxCalculator, categoryPolicy CategoryPolicy) ProductUsecase {
 	return productUsecase{
-		productRepositoryGateway: productRepositoryGateway,
+		productRepositoryGateway:             productRepositoryGateway,
+		productPriceHistoryRepositoryGateway: productPriceHistoryRepositoryGateway,
+		productVariantRepositoryGateway:      productVariantRepositoryGateway,
+		taxCalculator:                        taxCalculator,
+		categoryPolicy:                       categoryPolicy,
 	}
 }
 
-func (u productUsecase) GetAllProducts(pageParameters dto.PageParams) (dto.Page[entities.Product], error) {
+func (u productUsecase) GetAllProducts(pageParameters dto.PageParams, expandVariants bool) (dto.Page[entities.Product], error) {
 	products, err := u.productRepositoryGateway.FindAllProducts(pageParameters)
 	if err != nil {
 		log.Errorf("failed to get all products, error: %v", err)
 		return dto.Page[entities.Product]{}, err
 	}
 
+	if expandVariants {
+		err = u.setVariants(products)
+		if err != nil {
+			return dto.Page[entities.Product]{}, err
+		}
+	}
+
+	u.setPrices(products)
 	page := dto.BuildPage[entities.Product](products, pageParameters)
 	return page, nil
 }
 
-func (u productUsecase) GetProductsByCategory(pageParameters dto.PageParams, category string) (dto.Page[entities.Product], error) {
+func (u productUsecase) GetProductsByCategory(pageParameters dto.PageParams, category string, expandVariants bool) (dto.Page[entities.Product], error) {
 	products, err := u.productRepositoryGateway.FindProductsByCategory(pageParameters, category)
 	if err != nil {
 		log.Errorf("failed to get products by category, error: %v", err)
 		return dto.Page[entities.Product]{}, err
 	}
 
+	if expandVariants {
+		err = u.setVariants(products)
+		if err != nil {
+			return dto.Page[entities.Product]{}, err
+		}
+	}
+
+	u.setPrices(products)
 	page := dto.BuildPage[entities.Product](products, pageParameters)
 	return page, nil
 }
@@ -58,15 +100,129 @@ func (u productUsecase) GetProductById(id int) (entities.Product, error) {
 		return entities.Product{}, err
 	}
 
-	return product, nil
+	return u.withPrices(product), nil
+}
+
+func (u productUsecase) GetProductBySKU(skuId string) (entities.Product, error) {
+	product, err := u.productRepositoryGateway.FindProductBySKU(skuId)
+	if err != nil {
+		log.Errorf("failed to get product by sku [%s], error: %v", skuId, err)
+		return entities.Product{}, err
+	}
+
+	return u.withPrices(product), nil
+}
+
+// GetLowStockProducts lists the products to be restocked. The products only track whether they are available, so the
+// unavailable ones are out of stock, which is at or below any threshold.
+func (u productUsecase) GetLowStockProducts(threshold int) ([]entities.Product, error) {
+	products, err := u.productRepositoryGateway.FindUnavailableProducts()
+	if err != nil {
+		log.Errorf("failed to get products at or below the stock threshold [%d], error: %v", threshold, err)
+		return nil, err
+	}
+
+	u.setPrices(products)
+	return products, nil
+}
+
+// GetProductsChangedBy lists the products whose latest change was made by the actor within the range, for the audit
+// review of the catalog.
+func (u productUsecase) GetProductsChangedBy(actor string, from, to time.Time) ([]entities.Product, error) {
+	products, err := u.productRepositoryGateway.FindProductsChangedBy(actor, from, to)
+	if err != nil {
+		log.Errorf("failed to get products changed by [%s], error: %v", actor, err)
+		return nil, err
+	}
+
+	u.setPrices(products)
+	return products, nil
 }
 
-func (u productUsecase) CreateProduct(productDTO dto.ProductDTO) error {
+// GetMenu groups every available product, with its variants, by category in a single read for the homepage.
+func (u productUsecase) GetMenu() (dto.MenuDTO, error) {
+	products, err := u.productRepositoryGateway.FindMenuProducts()
+	if err != nil {
+		log.Errorf("failed to get menu products, error: %v", err)
+		return dto.MenuDTO{}, err
+	}
+
+	err = u.setVariants(products)
+	if err != nil {
+		return dto.MenuDTO{}, err
+	}
+
+	u.setPrices(products)
+
+	// a product is listed under each of its categories, keeping the name order of the products within them
+	menu := dto.MenuDTO{Categories: []dto.MenuCategoryDTO{}}
+	categoryIndexes := map[string]int{}
+	for _, product := range products {
+		categories := withPrimaryCategory(product.Category, product.Categories)
+		if len(categories) == 0 {
+			// the uncategorized products are still listed, under a blank category
+			categories = []string{product.Category}
+		}
+
+		for _, category := range categories {
+			index, ok := categoryIndexes[category]
+			if !ok {
+				index = len(menu.Categories)
+				categoryIndexes[category] = index
+				menu.Categories = append(menu.Categories, dto.MenuCategoryDTO{Name: category})
+			}
+			menu.Categories[index].Products = append(menu.Categories[index].Products, product)
+		}
+	}
+
+	sort.SliceStable(menu.Categories, func(i, j int) bool {
+		return menu.Categories[i].Name < menu.Categories[j].Name
+	})
+
+	return menu, nil
+}
+
+// withPrimaryCategory lists the primary category first and then the other ones, skipping blanks and repetitions.
+// Products saved before having several categories only have the primary one.
+func withPrimaryCategory(primary string, categories []string) []string {
+	merged := []string{}
+	seen := map[string]bool{}
+	for _, category := range append([]string{primary}, categories...) {
+		category = strings.TrimSpace(category)
+		if category == "" || seen[category] {
+			continue
+		}
+		seen[category] = true
+		merged = append(merged, category)
+	}
+
+	return merged
+}
+
+// CreateProduct credits the product to the actor, the auth subject of the request, or to dto.SystemActor when it is empty.
+func (u productUsecase) CreateProduct(productDTO dto.ProductDTO, actor string) error {
 	product := productDTO.ToProduct()
-	product.CreatedAt = time.Now()
-	product.UpdatedAt = time.Now()
+	category, err := u.categoryPolicy.Apply(product.Category)
+	if err != nil {
+		log.Errorf("failed to create product [%s], error: %v", product.SkuId, err)
+		return err
+	}
+
+	product.Category = category
+	product.Categories = withPrimaryCategory(product.Category, product.Categories)
+
+	err = u.taxCalculator.ValidateTaxCategory(product.TaxCategory)
+	if err != nil {
+		log.Errorf("failed to create product [%s], error: %v", product.SkuId, err)
+		return err
+	}
+
+	product.CreatedAt = entities.NewTimestamp(time.Now())
+	product.UpdatedAt = entities.NewTimestamp(time.Now())
+	product.CreatedBy = actorOrSystem(actor)
+	product.UpdatedBy = product.CreatedBy
 
-	err := u.productRepositoryGateway.SaveProduct(product)
+	err = u.productRepositoryGateway.SaveProduct(product)
 	if err != nil {
 		log.Errorf("failed to save product, error: %v", err)
 		return err
@@ -75,7 +231,41 @@ func (u productUsecase) CreateProduct(productDTO dto.ProductDTO) error {
 	return nil
 }
 
-func (u productUsecase) UpdateProduct(idStr string, productDTO dto.ProductDTO) error {
+// DuplicateProduct copies the product as a template for a similar one. The copy gets the sku given, or the original sku
+// suffixed, and stays unavailable until reviewed. The variants are not copied.
+func (u productUsecase) DuplicateProduct(id int, duplicateDTO dto.ProductDuplicateDTO, actor string) (entities.Product, error) {
+	product, err := u.productRepositoryGateway.FindProductById(id)
+	if err != nil {
+		log.Errorf("failed to find product [%d] to duplicate, error: %v", id, err)
+		return entities.Product{}, err
+	}
+
+	skuId := strings.TrimSpace(duplicateDTO.SkuId)
+	if skuId == "" && product.SkuId != "" {
+		skuId = product.SkuId + dto.DuplicateSKUSuffix
+	}
+
+	if skuId != "" {
+		_, err = u.productRepositoryGateway.FindProductBySKU(skuId)
+		if err == nil {
+			return entities.Product{}, fmt.Errorf("%w, sku [%s]", dto.ErrProductSKUTaken, skuId)
+		}
+		if !errors.Is(err, sql.ErrNotFound) {
+			log.Errorf("failed to check sku [%s] to duplicate product [%d], error: %v", skuId, id, err)
+			return entities.Product{}, err
+		}
+	}
+
+	duplicateId, err := u.productRepositoryGateway.DuplicateProduct(id, skuId, time.Now(), actorOrSystem(actor))
+	if err != nil {
+		log.Errorf("failed to duplicate product [%d], error: %v", id, err)
+		return entities.Product{}, err
+	}
+
+	return u.GetProductById(duplicateId)
+}
+
+func (u productUsecase) UpdateProduct(idStr string, productDTO dto.ProductDTO, actor string) error {
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
 		log.Errorf("failed to parse id [%s], error: %v", idStr, err)
@@ -83,16 +273,53 @@ func (u productUsecase) UpdateProduct(idStr string, productDTO dto.ProductDTO) e
 	}
 
 	product := productDTO.ToProduct()
-	product.UpdatedAt = time.Now()
+	product.Category, err = u.categoryPolicy.Apply(product.Category)
+	if err != nil {
+		log.Errorf("failed to update product [%d], error: %v", id, err)
+		return err
+	}
+	product.Categories = withPrimaryCategory(product.Category, product.Categories)
+
+	err = u.taxCalculator.ValidateTaxCategory(product.TaxCategory)
+	if err != nil {
+		log.Errorf("failed to update product [%d], error: %v", id, err)
+		return err
+	}
+
+	// the previous price is only used to detect a price change, the update reports a missing product
+	current, findErr := u.productRepositoryGateway.FindProductById(id)
+
+	product.UpdatedAt = entities.NewTimestamp(time.Now())
+	product.UpdatedBy = actorOrSystem(actor)
 	err = u.productRepositoryGateway.UpdateProduct(id, product)
 	if err != nil {
 		log.Errorf("failed to update product, error: %v", err)
 		return err
 	}
 
+	if findErr != nil || current.Price != product.Price {
+		err = u.productPriceHistoryRepositoryGateway.SavePriceChange(entities.ProductPriceChange{
+			ProductID: id,
+			Price:     product.Price,
+			ChangedAt: product.UpdatedAt,
+		})
+		if err != nil {
+			log.Errorf("failed to record price change of product [%d], error: %v", id, err)
+			return err
+		}
+	}
+
 	return nil
 }
 
+func actorOrSystem(actor string) string {
+	if actor == "" {
+		return dto.SystemActor
+	}
+
+	return actor
+}
+
 func (u productUsecase) DeleteProduct(idStr string) error {
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
@@ -100,6 +327,68 @@ func (u productUsecase) DeleteProduct(idStr string) error {
 		return err
 	}
 
+	return u.deleteProduct(id)
+}
+
+// BulkDeleteProducts deletes each product on its own, so a blocked or missing product does not stop the others.
+func (u productUsecase) BulkDeleteProducts(ids []int) []dto.BulkDeleteResultDTO {
+	results := make([]dto.BulkDeleteResultDTO, 0, len(ids))
+	for _, id := range ids {
+		result := dto.BulkDeleteResultDTO{ID: id, Status: dto.BulkDeleteStatusDeleted}
+
+		err := u.deleteProduct(id)
+		switch {
+		case err == nil:
+		case errors.Is(err, dto.ErrProductInOrder):
+			result.Status = dto.BulkDeleteStatusBlocked
+			result.Error = err.Error()
+		case errors.Is(err, sql.ErrNotFound):
+			result.Status = dto.BulkDeleteStatusNotFound
+			result.Error = err.Error()
+		default:
+			result.Status = dto.BulkDeleteStatusFailed
+			result.Error = dto.BulkDeleteFailedMessage
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// SetCategoryAvailability marks all the products of a category as available or unavailable at once, e.g. when the
+// equipment preparing them breaks. A category without products is reported as not found.
+func (u productUsecase) SetCategoryAvailability(availabilityDTO dto.CategoryAvailabilityDTO) (dto.CategoryAvailabilityResponseDTO, error) {
+	available := *availabilityDTO.Available
+	ids, err := u.productRepositoryGateway.SetCategoryAvailability(availabilityDTO.Category, available, time.Now())
+	if err != nil {
+		log.Errorf("failed to set availability of category [%s], error: %v", availabilityDTO.Category, err)
+		return dto.CategoryAvailabilityResponseDTO{}, err
+	}
+
+	if len(ids) == 0 {
+		return dto.CategoryAvailabilityResponseDTO{}, sql.ErrNotFound
+	}
+
+	return dto.CategoryAvailabilityResponseDTO{
+		Category:   availabilityDTO.Category,
+		Available:  available,
+		ProductIDs: ids,
+	}, nil
+}
+
+func (u productUsecase) deleteProduct(id int) error {
+	hasOrders, err := u.productRepositoryGateway.HasOrders(id)
+	if err != nil {
+		log.Errorf("failed to check orders of product [%d], error: %v", id, err)
+		return err
+	}
+
+	if hasOrders {
+		log.Errorf("failed to delete product [%d], error: %v", id, dto.ErrProductInOrder)
+		return dto.ErrProductInOrder
+	}
+
 	err = u.productRepositoryGateway.DeleteProduct(id)
 	if err != nil {
 		log.Errorf("failed to delete product, error: %v", err)
@@ -108,3 +397,114 @@ func (u productUsecase) DeleteProduct(idStr string) error {
 
 	return nil
 }
+
+func (u productUsecase) GetProductPopularity(id int, from, to time.Time) (dto.ProductPopularityDTO, error) {
+	// the popularity query counts zero for any id, so a missing product is told apart from one never ordered here
+	_, err := u.productRepositoryGateway.FindProductById(id)
+	if err != nil {
+		log.Errorf("failed to find product [%d] to get its popularity, error: %v", id, err)
+		return dto.ProductPopularityDTO{}, err
+	}
+
+	popularity, err := u.productRepositoryGateway.GetProductPopularity(id, from, to)
+	if err != nil {
+		log.Errorf("failed to get product [%d] popularity, error: %v", id, err)
+		return dto.ProductPopularityDTO{}, err
+	}
+
+	return popularity, nil
+}
+
+func (u productUsecase) GetProductRatings(id int) (dto.ProductRatingsDTO, error) {
+	ratings, err := u.productRepositoryGateway.GetProductRatings(id)
+	if err != nil {
+		log.Errorf("failed to get product [%d] ratings, error: %v", id, err)
+		return dto.ProductRatingsDTO{}, err
+	}
+
+	return ratings, nil
+}
+
+func (u productUsecase) GetProductPriceHistory(id int) ([]entities.ProductPriceChange, error) {
+	history, err := u.productPriceHistoryRepositoryGateway.FindPriceHistory(id)
+	if err != nil {
+		log.Errorf("failed to get product [%d] price history, error: %v", id, err)
+		return nil, err
+	}
+
+	return history, nil
+}
+
+func (u productUsecase) CreateProductVariant(productId int, variantDTO dto.ProductVariantDTO) error {
+	_, err := u.productRepositoryGateway.FindProductById(productId)
+	if err != nil {
+		log.Errorf("failed to find product [%d] to create variant, error: %v", productId, err)
+		return err
+	}
+
+	variant := variantDTO.ToProductVariant(productId)
+	variant.CreatedAt = entities.NewTimestamp(time.Now())
+	variant.UpdatedAt = entities.NewTimestamp(time.Now())
+
+	err = u.productVariantRepositoryGateway.SaveVariant(variant)
+	if err != nil {
+		log.Errorf("failed to save variant of product [%d], error: %v", productId, err)
+		return err
+	}
+
+	return nil
+}
+
+func (u productUsecase) GetProductVariants(productId int) ([]entities.ProductVariant, error) {
+	variants, err := u.productVariantRepositoryGateway.FindVariantsByProductIds([]int{productId})
+	if err != nil {
+		log.Errorf("failed to get variants of product [%d], error: %v", productId, err)
+		return nil, err
+	}
+
+	for i, variant := range variants {
+		variants[i] = u.withVariantPrices(variant)
+	}
+	return variants, nil
+}
+
+func (u productUsecase) setVariants(products []entities.Product) error {
+	productIds := make([]int, len(products))
+	for i, product := range products {
+		productIds[i] = product.ID
+	}
+
+	variants, err := u.productVariantRepositoryGateway.FindVariantsByProductIds(productIds)
+	if err != nil {
+		log.Errorf("failed to get variants of products, error: %v", err)
+		return err
+	}
+
+	variantsByProduct := map[int][]entities.ProductVariant{}
+	for _, variant := range variants {
+		variantsByProduct[variant.ProductID] = append(variantsByProduct[variant.ProductID], u.withVariantPrices(variant))
+	}
+
+	for i, product := range products {
+		products[i].Variants = variantsByProduct[product.ID]
+	}
+	return nil
+}
+
+func (u productUsecase) setPrices(products []entities.Product) {
+	for i, product := range products {
+		products[i] = u.withPrices(product)
+	}
+}
+
+func (u productUsecase) withPrices(product entities.Product) entities.Product {
+	product.PriceNet = product.Price
+	product.PriceGross = u.taxCalculator.GrossPrice(product.Price, product.TaxCategory)
+	return product
+}
+
+func (u productUsecase) withVariantPrices(variant entities.ProductVariant) entities.ProductVariant {
+	variant.PriceNet = variant.Price
+	variant.PriceGross = u.taxCalculator.GrossPrice(variant.Price, variant.TaxCategory)
+	return variant
+}
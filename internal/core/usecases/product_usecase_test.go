@@ -0,0 +1,509 @@
+package usecases
+
+import (
+	"errors"
+	"g37-lanchonete/internal/core/entities"
+	"g37-lanchonete/internal/core/usecases/dto"
+	"g37-lanchonete/internal/infra/drivers/sql"
+	mock_gateways "g37-lanchonete/internal/infra/gateways/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestProductUsecase_CategoryPolicy(t *testing.T) {
+	productDTO := dto.ProductDTO{
+		Name:  "Suco de laranja",
+		SkuId: "444",
+		Price: 7.5,
+	}
+
+	type repositoryCall struct {
+		times    int
+		category string
+	}
+	tests := []struct {
+		name string
+		mode string
+		repositoryCall
+		wantErr error
+	}{
+		{
+			name:           "should keep the product uncategorized when no mode is configured",
+			mode:           UncategorizedModeNone,
+			repositoryCall: repositoryCall{times: 1, category: ""},
+		},
+		{
+			name:           "should assign the default category to an uncategorized product",
+			mode:           UncategorizedModeDefault,
+			repositoryCall: repositoryCall{times: 1, category: "Outros"},
+		},
+		{
+			name:    "should reject an uncategorized product",
+			mode:    UncategorizedModeReject,
+			wantErr: dto.ErrProductCategoryRequired,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			productRepositoryGateway := mock_gateways.NewMockProductRepositoryGateway(ctrl)
+			productPriceHistoryRepositoryGateway := mock_gateways.NewMockProductPriceHistoryRepositoryGateway(ctrl)
+			productUsecase := NewProductUsecase(productRepositoryGateway, productPriceHistoryRepositoryGateway, nil, NewTaxCalculator(0, nil, 0), NewCategoryPolicy(tt.mode, "Outros"))
+
+			matchCategory := gomock.Cond(func(x any) bool {
+				return x.(entities.Product).Category == tt.repositoryCall.category
+			})
+			productRepositoryGateway.EXPECT().
+				SaveProduct(matchCategory).
+				Times(tt.repositoryCall.times).
+				Return(nil)
+			productRepositoryGateway.EXPECT().
+				FindProductById(gomock.Eq(1)).
+				Times(tt.repositoryCall.times).
+				Return(entities.Product{ID: 1, Price: productDTO.Price}, nil)
+			productRepositoryGateway.EXPECT().
+				UpdateProduct(gomock.Eq(1), matchCategory).
+				Times(tt.repositoryCall.times).
+				Return(nil)
+
+			assert.Equal(t, tt.wantErr, productUsecase.CreateProduct(productDTO, ""))
+			assert.Equal(t, tt.wantErr, productUsecase.UpdateProduct("1", productDTO, ""))
+		})
+	}
+}
+
+func TestProductUsecase_UpdateProduct_PriceHistory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	productRepositoryGateway := mock_gateways.NewMockProductRepositoryGateway(ctrl)
+	productPriceHistoryRepositoryGateway := mock_gateways.NewMockProductPriceHistoryRepositoryGateway(ctrl)
+	productUsecase := NewProductUsecase(productRepositoryGateway, productPriceHistoryRepositoryGateway, nil, NewTaxCalculator(0, nil, 0), NewCategoryPolicy("", ""))
+
+	var history []entities.ProductPriceChange
+	productPriceHistoryRepositoryGateway.EXPECT().
+		SavePriceChange(gomock.Any()).
+		DoAndReturn(func(priceChange entities.ProductPriceChange) error {
+			history = append(history, priceChange)
+			return nil
+		}).
+		Times(2)
+	productRepositoryGateway.EXPECT().UpdateProduct(gomock.Eq(1), gomock.Any()).Times(3).Return(nil)
+	gomock.InOrder(
+		productRepositoryGateway.EXPECT().FindProductById(gomock.Eq(1)).Return(entities.Product{ID: 1, Price: 10}, nil),
+		productRepositoryGateway.EXPECT().FindProductById(gomock.Eq(1)).Return(entities.Product{ID: 1, Price: 12}, nil),
+		productRepositoryGateway.EXPECT().FindProductById(gomock.Eq(1)).Return(entities.Product{ID: 1, Price: 15}, nil),
+	)
+
+	assert.NoError(t, productUsecase.UpdateProduct("1", dto.ProductDTO{Name: "X-Burguer", Price: 12}, ""))
+	assert.NoError(t, productUsecase.UpdateProduct("1", dto.ProductDTO{Name: "X-Burguer", Price: 15}, ""))
+	// same price, only the name changes
+	assert.NoError(t, productUsecase.UpdateProduct("1", dto.ProductDTO{Name: "X-Burguer Duplo", Price: 15}, ""))
+
+	assert.Len(t, history, 2)
+	assert.Equal(t, 12.0, history[0].Price)
+	assert.Equal(t, 15.0, history[1].Price)
+	assert.False(t, history[1].ChangedAt.Before(history[0].ChangedAt.Time))
+}
+
+func TestProductUsecase_Attribution(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	productRepositoryGateway := mock_gateways.NewMockProductRepositoryGateway(ctrl)
+	productPriceHistoryRepositoryGateway := mock_gateways.NewMockProductPriceHistoryRepositoryGateway(ctrl)
+	productUsecase := NewProductUsecase(productRepositoryGateway, productPriceHistoryRepositoryGateway, nil, NewTaxCalculator(0, nil, 0), NewCategoryPolicy("", ""))
+	productDTO := dto.ProductDTO{Name: "X-Burguer", Price: 12}
+
+	t.Run("should credit the created product to the subject of the request", func(t *testing.T) {
+		productRepositoryGateway.EXPECT().
+			SaveProduct(gomock.Any()).
+			DoAndReturn(func(product entities.Product) error {
+				assert.Equal(t, "maria", product.CreatedBy)
+				assert.Equal(t, "maria", product.UpdatedBy)
+				return nil
+			}).
+			Times(1)
+
+		assert.NoError(t, productUsecase.CreateProduct(productDTO, "maria"))
+	})
+
+	t.Run("should credit the update to its own subject", func(t *testing.T) {
+		productRepositoryGateway.EXPECT().FindProductById(1).Return(entities.Product{ID: 1, Price: 12, CreatedBy: "maria", UpdatedBy: "maria"}, nil).Times(1)
+		productRepositoryGateway.EXPECT().
+			UpdateProduct(1, gomock.Any()).
+			DoAndReturn(func(id int, product entities.Product) error {
+				assert.Equal(t, "joao", product.UpdatedBy)
+				return nil
+			}).
+			Times(1)
+
+		assert.NoError(t, productUsecase.UpdateProduct("1", productDTO, "joao"))
+	})
+
+	t.Run("should credit the changes of unauthenticated requests to the system", func(t *testing.T) {
+		productRepositoryGateway.EXPECT().
+			SaveProduct(gomock.Any()).
+			DoAndReturn(func(product entities.Product) error {
+				assert.Equal(t, dto.SystemActor, product.CreatedBy)
+				assert.Equal(t, dto.SystemActor, product.UpdatedBy)
+				return nil
+			}).
+			Times(1)
+
+		assert.NoError(t, productUsecase.CreateProduct(productDTO, ""))
+	})
+}
+
+func TestProductUsecase_GetMenu(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	productRepositoryGateway := mock_gateways.NewMockProductRepositoryGateway(ctrl)
+	productVariantRepositoryGateway := mock_gateways.NewMockProductVariantRepositoryGateway(ctrl)
+	productUsecase := NewProductUsecase(productRepositoryGateway, nil, productVariantRepositoryGateway, NewTaxCalculator(0.1, nil, 0), NewCategoryPolicy("", ""))
+
+	productRepositoryGateway.EXPECT().
+		FindMenuProducts().
+		Return([]entities.Product{
+			{ID: 3, Name: "Batata Frita", Category: "Acompanhamento", Price: 10},
+			{ID: 1, Name: "X-Bacon", Category: "Lanche", Price: 20},
+			{ID: 2, Name: "X-Burguer", Category: "Lanche", Price: 15},
+		}, nil).
+		Times(1)
+	productVariantRepositoryGateway.EXPECT().
+		FindVariantsByProductIds([]int{3, 1, 2}).
+		Return([]entities.ProductVariant{{ID: 7, ProductID: 2, Name: "Duplo", Price: 20}}, nil).
+		Times(1)
+
+	menu, err := productUsecase.GetMenu()
+
+	assert.NoError(t, err)
+	assert.Len(t, menu.Categories, 2)
+	assert.Equal(t, "Acompanhamento", menu.Categories[0].Name)
+	assert.Len(t, menu.Categories[0].Products, 1)
+	assert.Equal(t, "Lanche", menu.Categories[1].Name)
+	assert.Len(t, menu.Categories[1].Products, 2)
+	assert.Equal(t, "X-Bacon", menu.Categories[1].Products[0].Name)
+	assert.Equal(t, 22.0, menu.Categories[1].Products[0].PriceGross)
+	assert.Equal(t, "Duplo", menu.Categories[1].Products[1].Variants[0].Name)
+}
+
+func TestProductUsecase_GetMenu_MultipleCategories(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	productRepositoryGateway := mock_gateways.NewMockProductRepositoryGateway(ctrl)
+	productVariantRepositoryGateway := mock_gateways.NewMockProductVariantRepositoryGateway(ctrl)
+	productUsecase := NewProductUsecase(productRepositoryGateway, nil, productVariantRepositoryGateway, NewTaxCalculator(0, nil, 0), NewCategoryPolicy("", ""))
+
+	productRepositoryGateway.EXPECT().
+		FindMenuProducts().
+		Return([]entities.Product{
+			{ID: 4, Name: "Combo X-Bacon", Category: "Lanche", Categories: []string{"Lanche", "Promoção"}, Price: 30},
+			{ID: 5, Name: "Suco", Category: "Bebida", Categories: []string{"Bebida"}, Price: 8},
+			{ID: 1, Name: "X-Bacon", Category: "Lanche", Price: 20},
+		}, nil).
+		Times(1)
+	productVariantRepositoryGateway.EXPECT().FindVariantsByProductIds([]int{4, 5, 1}).Return(nil, nil).Times(1)
+
+	menu, err := productUsecase.GetMenu()
+
+	assert.NoError(t, err)
+	names := func(category dto.MenuCategoryDTO) []string {
+		products := []string{}
+		for _, product := range category.Products {
+			products = append(products, product.Name)
+		}
+		return products
+	}
+	assert.Len(t, menu.Categories, 3)
+	assert.Equal(t, "Bebida", menu.Categories[0].Name)
+	assert.Equal(t, []string{"Suco"}, names(menu.Categories[0]))
+	assert.Equal(t, "Lanche", menu.Categories[1].Name)
+	assert.Equal(t, []string{"Combo X-Bacon", "X-Bacon"}, names(menu.Categories[1]))
+	assert.Equal(t, "Promoção", menu.Categories[2].Name)
+	assert.Equal(t, []string{"Combo X-Bacon"}, names(menu.Categories[2]))
+}
+
+func TestProductUsecase_CreateProduct_Categories(t *testing.T) {
+	tests := []struct {
+		name           string
+		productDTO     dto.ProductDTO
+		policy         CategoryPolicy
+		wantCategory   string
+		wantCategories []string
+	}{
+		{
+			name:           "should keep the primary category first among the assigned ones",
+			productDTO:     dto.ProductDTO{Name: "Combo", Price: 30, Category: "Lanche", Categories: []string{"Promoção", "Lanche", " Promoção "}},
+			policy:         NewCategoryPolicy("", ""),
+			wantCategory:   "Lanche",
+			wantCategories: []string{"Lanche", "Promoção"},
+		},
+		{
+			name:           "should take the first assigned category as primary when none is given",
+			productDTO:     dto.ProductDTO{Name: "Combo", Price: 30, Categories: []string{"Promoção", "Lanche"}},
+			policy:         NewCategoryPolicy(UncategorizedModeReject, ""),
+			wantCategory:   "Promoção",
+			wantCategories: []string{"Promoção", "Lanche"},
+		},
+		{
+			name:           "should keep the single category of the products saved without categories",
+			productDTO:     dto.ProductDTO{Name: "Suco", Price: 8, Category: "Bebida"},
+			policy:         NewCategoryPolicy("", ""),
+			wantCategory:   "Bebida",
+			wantCategories: []string{"Bebida"},
+		},
+		{
+			name:           "should assign the default category of the policy as primary",
+			productDTO:     dto.ProductDTO{Name: "Suco", Price: 8},
+			policy:         NewCategoryPolicy(UncategorizedModeDefault, "Outros"),
+			wantCategory:   "Outros",
+			wantCategories: []string{"Outros"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			productRepositoryGateway := mock_gateways.NewMockProductRepositoryGateway(ctrl)
+			productUsecase := NewProductUsecase(productRepositoryGateway, nil, nil, NewTaxCalculator(0, nil, 0), tt.policy)
+
+			productRepositoryGateway.EXPECT().
+				SaveProduct(gomock.Any()).
+				DoAndReturn(func(product entities.Product) error {
+					assert.Equal(t, tt.wantCategory, product.Category)
+					assert.Equal(t, tt.wantCategories, product.Categories)
+					return nil
+				}).
+				Times(1)
+
+			assert.NoError(t, productUsecase.CreateProduct(tt.productDTO, ""))
+		})
+	}
+}
+
+func TestCategoryPolicy_Apply_KeepsInformedCategory(t *testing.T) {
+	for _, mode := range []string{UncategorizedModeNone, UncategorizedModeDefault, UncategorizedModeReject} {
+		category, err := NewCategoryPolicy(mode, "Outros").Apply("Bebida")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Bebida", category)
+	}
+}
+
+func TestProductUsecase_BulkDeleteProducts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	productRepositoryGateway := mock_gateways.NewMockProductRepositoryGateway(ctrl)
+	productUsecase := NewProductUsecase(productRepositoryGateway, nil, nil, NewTaxCalculator(0, nil, 0), NewCategoryPolicy("", ""))
+
+	// 1 is deletable, 2 is part of an order, 3 does not exist and 4 fails checking its orders
+	productRepositoryGateway.EXPECT().HasOrders(1).Return(false, nil)
+	productRepositoryGateway.EXPECT().HasOrders(2).Return(true, nil)
+	productRepositoryGateway.EXPECT().HasOrders(3).Return(false, nil)
+	productRepositoryGateway.EXPECT().HasOrders(4).Return(false, errors.New("connection refused"))
+	productRepositoryGateway.EXPECT().DeleteProduct(1).Return(nil)
+	productRepositoryGateway.EXPECT().DeleteProduct(3).Return(sql.ErrNotFound)
+
+	results := productUsecase.BulkDeleteProducts([]int{1, 2, 3, 4})
+
+	assert.Equal(t, []dto.BulkDeleteResultDTO{
+		{ID: 1, Status: dto.BulkDeleteStatusDeleted},
+		{ID: 2, Status: dto.BulkDeleteStatusBlocked, Error: "product is part of an order"},
+		{ID: 3, Status: dto.BulkDeleteStatusNotFound, Error: "entity not found"},
+		{ID: 4, Status: dto.BulkDeleteStatusFailed, Error: "failed to delete product"},
+	}, results)
+}
+
+func TestProductUsecase_GetAllProducts_ExpandVariants(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	productRepositoryGateway := mock_gateways.NewMockProductRepositoryGateway(ctrl)
+	productVariantRepositoryGateway := mock_gateways.NewMockProductVariantRepositoryGateway(ctrl)
+	pageParams := dto.NewPageParams(0, 10)
+
+	productRepositoryGateway.EXPECT().
+		FindAllProducts(pageParams).
+		Return([]entities.Product{{ID: 1, Price: 8}, {ID: 2, Price: 5}}, nil).
+		Times(1)
+	productVariantRepositoryGateway.EXPECT().
+		FindVariantsByProductIds([]int{1, 2}).
+		Return([]entities.ProductVariant{
+			{ID: 11, ProductID: 1, Name: "Pequeno", Price: 8},
+			{ID: 12, ProductID: 1, Name: "Grande", Price: 12},
+		}, nil).
+		Times(1)
+
+	productUsecase := NewProductUsecase(productRepositoryGateway, nil, productVariantRepositoryGateway, NewTaxCalculator(0.1, nil, 0), NewCategoryPolicy("", ""))
+
+	page, err := productUsecase.GetAllProducts(pageParams, true)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []entities.ProductVariant{
+		{ID: 11, ProductID: 1, Name: "Pequeno", Price: 8, PriceNet: 8, PriceGross: 8.8},
+		{ID: 12, ProductID: 1, Name: "Grande", Price: 12, PriceNet: 12, PriceGross: 13.2},
+	}, page.Result[0].Variants)
+	assert.Empty(t, page.Result[1].Variants)
+}
+
+func TestProductUsecase_SetCategoryAvailability(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	productRepositoryGateway := mock_gateways.NewMockProductRepositoryGateway(ctrl)
+	productUsecase := NewProductUsecase(productRepositoryGateway, nil, nil, NewTaxCalculator(0, nil, 0), NewCategoryPolicy("", ""))
+
+	// the fryer broke, so every product of the category becomes unavailable
+	productRepositoryGateway.EXPECT().
+		SetCategoryAvailability(gomock.Eq("Acompanhamento"), gomock.Eq(false), gomock.Any()).
+		Return([]int{3, 5, 8}, nil).
+		Times(1)
+	productRepositoryGateway.EXPECT().
+		SetCategoryAvailability(gomock.Eq("Sobremesa"), gomock.Eq(true), gomock.Any()).
+		Return([]int{}, nil).
+		Times(1)
+
+	unavailable, available := false, true
+	response, err := productUsecase.SetCategoryAvailability(dto.CategoryAvailabilityDTO{Category: "Acompanhamento", Available: &unavailable})
+
+	assert.NoError(t, err)
+	assert.Equal(t, dto.CategoryAvailabilityResponseDTO{Category: "Acompanhamento", Available: false, ProductIDs: []int{3, 5, 8}}, response)
+
+	_, err = productUsecase.SetCategoryAvailability(dto.CategoryAvailabilityDTO{Category: "Sobremesa", Available: &available})
+
+	assert.ErrorIs(t, err, sql.ErrNotFound)
+}
+
+func TestProductUsecase_GetProductBySKU(t *testing.T) {
+	tests := []struct {
+		name      string
+		skuId     string
+		stored    entities.Product
+		storedErr error
+		want      entities.Product
+		wantErr   error
+	}{
+		{
+			name:   "should get the product with the sku and its prices",
+			skuId:  "333",
+			stored: entities.Product{ID: 222, Name: "Batata Frita", SkuId: "333", Price: 10},
+			want:   entities.Product{ID: 222, Name: "Batata Frita", SkuId: "333", Price: 10, PriceNet: 10, PriceGross: 11},
+		},
+		{
+			name:      "should return not found when no product has the sku",
+			skuId:     "999",
+			storedErr: sql.ErrNotFound,
+			wantErr:   sql.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			productRepositoryGateway := mock_gateways.NewMockProductRepositoryGateway(ctrl)
+			productUsecase := NewProductUsecase(productRepositoryGateway, nil, nil, NewTaxCalculator(0.1, nil, 0), NewCategoryPolicy("", ""))
+
+			productRepositoryGateway.EXPECT().FindProductBySKU(tt.skuId).Return(tt.stored, tt.storedErr).Times(1)
+
+			product, err := productUsecase.GetProductBySKU(tt.skuId)
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, product)
+		})
+	}
+}
+
+func TestProductUsecase_DuplicateProduct(t *testing.T) {
+	original := entities.Product{ID: 1, Name: "X-Bacon", SkuId: "XB", Category: "Lanche", Price: 20}
+
+	tests := []struct {
+		name         string
+		duplicateDTO dto.ProductDuplicateDTO
+		wantSkuId    string
+		skuTaken     bool
+		wantErr      error
+	}{
+		{
+			name:      "should duplicate the product suffixing the sku when none is given",
+			wantSkuId: "XB-COPY",
+		},
+		{
+			name:         "should duplicate the product with the sku given",
+			duplicateDTO: dto.ProductDuplicateDTO{SkuId: " XB2 "},
+			wantSkuId:    "XB2",
+		},
+		{
+			name:         "should not duplicate the product when the sku is already in use",
+			duplicateDTO: dto.ProductDuplicateDTO{SkuId: "XS"},
+			wantSkuId:    "XS",
+			skuTaken:     true,
+			wantErr:      dto.ErrProductSKUTaken,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			productRepositoryGateway := mock_gateways.NewMockProductRepositoryGateway(ctrl)
+			productUsecase := NewProductUsecase(productRepositoryGateway, nil, nil, NewTaxCalculator(0, nil, 0), NewCategoryPolicy("", ""))
+
+			productRepositoryGateway.EXPECT().FindProductById(1).Return(original, nil).Times(1)
+			if tt.skuTaken {
+				productRepositoryGateway.EXPECT().FindProductBySKU(tt.wantSkuId).Return(entities.Product{ID: 2, SkuId: tt.wantSkuId}, nil).Times(1)
+				productRepositoryGateway.EXPECT().DuplicateProduct(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			} else {
+				duplicate := original
+				duplicate.ID, duplicate.SkuId, duplicate.Unavailable = 9, tt.wantSkuId, true
+				productRepositoryGateway.EXPECT().FindProductBySKU(tt.wantSkuId).Return(entities.Product{}, sql.ErrNotFound).Times(1)
+				productRepositoryGateway.EXPECT().DuplicateProduct(1, tt.wantSkuId, gomock.Any(), "admin").Return(9, nil).Times(1)
+				productRepositoryGateway.EXPECT().FindProductById(9).Return(duplicate, nil).Times(1)
+			}
+
+			product, err := productUsecase.DuplicateProduct(1, tt.duplicateDTO, "admin")
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			if tt.wantErr == nil {
+				assert.Equal(t, 9, product.ID)
+				assert.Equal(t, tt.wantSkuId, product.SkuId)
+				assert.True(t, product.Unavailable)
+			}
+		})
+	}
+}
+
+func TestProductUsecase_GetProductsChangedBy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	productRepositoryGateway := mock_gateways.NewMockProductRepositoryGateway(ctrl)
+	productUsecase := NewProductUsecase(productRepositoryGateway, nil, nil, NewTaxCalculator(0, nil, 0), NewCategoryPolicy("", ""))
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	productRepositoryGateway.EXPECT().
+		FindProductsChangedBy("maria", from, to).
+		Return([]entities.Product{
+			{ID: 3, Name: "X-Bacon", Price: 20, CreatedBy: "joao", UpdatedBy: "maria"},
+			{ID: 1, Name: "Batata Frita", Price: 9.99, CreatedBy: "maria", UpdatedBy: "maria"},
+		}, nil).
+		Times(1)
+
+	products, err := productUsecase.GetProductsChangedBy("maria", from, to)
+
+	assert.NoError(t, err)
+	assert.Len(t, products, 2)
+	assert.Equal(t, []int{3, 1}, []int{products[0].ID, products[1].ID})
+	assert.Equal(t, float64(20), products[0].PriceGross)
+	assert.Equal(t, "maria", products[1].UpdatedBy)
+}
+
+func TestProductUsecase_GetProductPopularity(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	productRepositoryGateway := mock_gateways.NewMockProductRepositoryGateway(ctrl)
+	productUsecase := NewProductUsecase(productRepositoryGateway, nil, nil, NewTaxCalculator(0, nil, 0), NewCategoryPolicy("", ""))
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	popularity := dto.ProductPopularityDTO{ProductID: 222, OrdersCount: 12, UnitsSold: 30, From: from, To: to}
+
+	productRepositoryGateway.EXPECT().FindProductById(222).Return(entities.Product{ID: 222}, nil).Times(1)
+	productRepositoryGateway.EXPECT().GetProductPopularity(222, from, to).Return(popularity, nil).Times(1)
+	// a missing product is not reported as never ordered
+	productRepositoryGateway.EXPECT().FindProductById(999).Return(entities.Product{}, sql.ErrNotFound).Times(1)
+
+	response, err := productUsecase.GetProductPopularity(222, from, to)
+
+	assert.NoError(t, err)
+	assert.Equal(t, popularity, response)
+
+	_, err = productUsecase.GetProductPopularity(999, from, to)
+
+	assert.ErrorIs(t, err, sql.ErrNotFound)
+}
@@ -0,0 +1,105 @@
+package usecases
+
+import (
+	"fmt"
+	"g37-lanchonete/internal/core/entities"
+	"g37-lanchonete/internal/core/usecases/dto"
+	"math"
+	"strings"
+)
+
+// TaxCalculator computes taxes on top of the stored product prices, which are always net (tax exclusive).
+type TaxCalculator interface {
+	GrossPrice(netPrice float64, taxCategory string) float64
+	CalculateOrderTotals(items []entities.OrderItem) (dto.OrderTotals, error)
+	ValidateTaxCategory(taxCategory string) error
+}
+
+type taxCalculator struct {
+	rate          float64
+	categoryRates map[string]float64
+	maxLineTotal  float64
+}
+
+// NewTaxCalculator builds the calculator with the rate of the products without a tax category and the rates of each
+// tax category, whose names are matched ignoring letter case. Items whose quantity times price goes over maxLineTotal
+// are rejected, a zero maxLineTotal is not enforced.
+func NewTaxCalculator(rate float64, categoryRates map[string]float64, maxLineTotal float64) TaxCalculator {
+	rates := make(map[string]float64, len(categoryRates))
+	for category, categoryRate := range categoryRates {
+		rates[normalizeTaxCategory(category)] = categoryRate
+	}
+
+	return taxCalculator{
+		rate:          rate,
+		categoryRates: rates,
+		maxLineTotal:  maxLineTotal,
+	}
+}
+
+func (c taxCalculator) GrossPrice(netPrice float64, taxCategory string) float64 {
+	return roundMoney(netPrice + netPrice*c.rateOf(taxCategory))
+}
+
+// CalculateOrderTotals sums the tax of each item with the rate of its tax category, rounding only the order totals.
+func (c taxCalculator) CalculateOrderTotals(items []entities.OrderItem) (dto.OrderTotals, error) {
+	var subtotal, tax float64
+	for _, item := range items {
+		amount := item.Product.Price * float64(item.Quantity)
+		// an absurd amount would overflow the totals, or be stored rounded beyond the precision of the cents
+		if math.IsInf(amount, 0) || math.IsNaN(amount) || (c.maxLineTotal > 0 && amount > c.maxLineTotal) {
+			return dto.OrderTotals{}, dto.LineTotalLimitError{ProductID: item.Product.ID, Quantity: item.Quantity, MaxLineTotal: c.maxLineTotal}
+		}
+		subtotal += amount
+		tax += amount * c.rateOf(item.Product.TaxCategory)
+	}
+	subtotal = roundMoney(subtotal)
+	tax = roundMoney(tax)
+
+	return dto.OrderTotals{
+		Subtotal: subtotal,
+		TaxRate:  c.rate,
+		Tax:      tax,
+		Total:    roundMoney(subtotal + tax),
+	}, nil
+}
+
+// ValidateTaxCategory rejects the tax categories without a configured rate, an empty one uses the default rate.
+func (c taxCalculator) ValidateTaxCategory(taxCategory string) error {
+	if strings.TrimSpace(taxCategory) == "" {
+		return nil
+	}
+
+	if _, found := c.categoryRates[normalizeTaxCategory(taxCategory)]; !found {
+		return fmt.Errorf("%w [%s]", dto.ErrUnknownTaxCategory, taxCategory)
+	}
+
+	return nil
+}
+
+func (c taxCalculator) rateOf(taxCategory string) float64 {
+	if rate, found := c.categoryRates[normalizeTaxCategory(taxCategory)]; found {
+		return rate
+	}
+
+	return c.rate
+}
+
+func normalizeTaxCategory(taxCategory string) string {
+	return strings.ToLower(strings.TrimSpace(taxCategory))
+}
+
+func roundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// basisPointsPerUnit is the basis points of a whole amount, 10000 being 100%.
+const basisPointsPerUnit = 10000
+
+func toCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+func fromCents(cents int64) float64 {
+	return float64(cents) / 100
+}
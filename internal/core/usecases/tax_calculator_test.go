@@ -0,0 +1,117 @@
+package usecases
+
+import (
+	"g37-lanchonete/internal/core/entities"
+	"g37-lanchonete/internal/core/usecases/dto"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaxCalculator_CalculateOrderTotals(t *testing.T) {
+	items := []entities.OrderItem{
+		{Product: entities.Product{ID: 1, Price: 10.5}, Quantity: 2},
+		{Product: entities.Product{ID: 2, Price: 7.99}, Quantity: 1},
+	}
+
+	tests := []struct {
+		name string
+		rate float64
+		want dto.OrderTotals
+	}{
+		{
+			name: "should return the subtotal as total when the tax rate is zero",
+			rate: 0,
+			want: dto.OrderTotals{Subtotal: 28.99, TaxRate: 0, Tax: 0, Total: 28.99},
+		},
+		{
+			name: "should add the tax to the order total",
+			rate: 0.1,
+			want: dto.OrderTotals{Subtotal: 28.99, TaxRate: 0.1, Tax: 2.9, Total: 31.89},
+		},
+		{
+			name: "should round the tax to cents",
+			rate: 0.0925,
+			want: dto.OrderTotals{Subtotal: 28.99, TaxRate: 0.0925, Tax: 2.68, Total: 31.67},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calculator := NewTaxCalculator(tt.rate, nil, 0)
+
+			totals, err := calculator.CalculateOrderTotals(items)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, totals)
+		})
+	}
+}
+
+func TestTaxCalculator_GrossPrice(t *testing.T) {
+	calculator := NewTaxCalculator(0.1, map[string]float64{"Bebidas": 0.18}, 0)
+
+	assert.Equal(t, 11.0, calculator.GrossPrice(10, ""))
+	assert.Equal(t, 8.79, calculator.GrossPrice(7.99, ""))
+	assert.Equal(t, 11.8, calculator.GrossPrice(10, "bebidas"))
+	assert.Equal(t, 11.0, calculator.GrossPrice(10, "sobremesas"))
+}
+
+func TestTaxCalculator_CalculateOrderTotals_TaxCategories(t *testing.T) {
+	calculator := NewTaxCalculator(0.1, map[string]float64{"bebidas": 0.18, "alimentos": 0.05}, 0)
+	items := []entities.OrderItem{
+		{Product: entities.Product{ID: 1, Price: 20, TaxCategory: "alimentos"}, Quantity: 2},
+		{Product: entities.Product{ID: 2, Price: 7.5, TaxCategory: "Bebidas"}, Quantity: 2},
+		{Product: entities.Product{ID: 3, Price: 9.99}, Quantity: 1},
+	}
+
+	totals, err := calculator.CalculateOrderTotals(items)
+
+	assert.NoError(t, err)
+
+	// 40 * 0.05 + 15 * 0.18 + 9.99 * 0.1
+	assert.Equal(t, dto.OrderTotals{Subtotal: 64.99, TaxRate: 0.1, Tax: 5.7, Total: 70.69}, totals)
+}
+
+func TestTaxCalculator_CalculateOrderTotals_MaxLineTotal(t *testing.T) {
+	tests := []struct {
+		name         string
+		maxLineTotal float64
+		item         entities.OrderItem
+		wantErr      error
+	}{
+		{
+			name:         "should accept an item up to the maximum line total",
+			maxLineTotal: 1000,
+			item:         entities.OrderItem{Product: entities.Product{ID: 1, Price: 10}, Quantity: 100},
+		},
+		{
+			name:         "should reject an item over the maximum line total",
+			maxLineTotal: 1000,
+			item:         entities.OrderItem{Product: entities.Product{ID: 1, Price: 10}, Quantity: 101},
+			wantErr:      dto.LineTotalLimitError{ProductID: 1, Quantity: 101, MaxLineTotal: 1000},
+		},
+		{
+			name:    "should reject an item whose line total overflows even without a maximum",
+			item:    entities.OrderItem{Product: entities.Product{ID: 2, Price: math.MaxFloat64}, Quantity: math.MaxInt32},
+			wantErr: dto.LineTotalLimitError{ProductID: 2, Quantity: math.MaxInt32},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calculator := NewTaxCalculator(0, nil, tt.maxLineTotal)
+
+			_, err := calculator.CalculateOrderTotals([]entities.OrderItem{tt.item})
+
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestTaxCalculator_ValidateTaxCategory(t *testing.T) {
+	calculator := NewTaxCalculator(0.1, map[string]float64{"bebidas": 0.18}, 0)
+
+	assert.NoError(t, calculator.ValidateTaxCategory(""))
+	assert.NoError(t, calculator.ValidateTaxCategory("BEBIDAS"))
+	assert.ErrorIs(t, calculator.ValidateTaxCategory("sobremesas"), dto.ErrUnknownTaxCategory)
+}
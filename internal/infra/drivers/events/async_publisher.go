@@ -0,0 +1,45 @@
+package events
+
+import (
+	"fmt"
+)
+
+type asyncPublisher struct {
+	name       string
+	events     chan Event
+	deadLetter DeadLetter
+}
+
+// NewAsyncPublisher hands the events to the publisher on a worker goroutine of its own, so a slow or unreachable
+// publisher does not hold the caller. Up to queueSize events wait for the worker, the ones arriving on a full queue are
+// handed to the dead letter. The publisher is expected to handle its own failures, e.g. a retrying publisher.
+func NewAsyncPublisher(name string, publisher Publisher, deadLetter DeadLetter, queueSize int) Publisher {
+	if queueSize < 1 {
+		queueSize = 1
+	}
+
+	p := asyncPublisher{
+		name:       name,
+		events:     make(chan Event, queueSize),
+		deadLetter: deadLetter,
+	}
+	go func() {
+		for event := range p.events {
+			// the failures were already retried and sent to the dead letter by the publisher
+			_ = publisher.Publish(event)
+		}
+	}()
+
+	return p
+}
+
+func (p asyncPublisher) Publish(event Event) error {
+	select {
+	case p.events <- event:
+		return nil
+	default:
+		err := fmt.Errorf("queue of publisher [%s] is full", p.name)
+		p.deadLetter.Store(event, err)
+		return err
+	}
+}
@@ -0,0 +1,73 @@
+package events
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// hangingPartner accepts the deliveries and never answers them until the test is over.
+func hangingPartner(t *testing.T) (*httptest.Server, <-chan struct{}) {
+	received := make(chan struct{}, 10)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- struct{}{}
+		<-release
+	}))
+	t.Cleanup(server.Close)
+	// registered last so it runs first, the server only closes once its handlers return
+	t.Cleanup(func() { close(release) })
+
+	return server, received
+}
+
+func TestAsyncPublisher_Publish(t *testing.T) {
+	event := Event{
+		Type:       OrderStatusChanged,
+		OrderID:    7,
+		Payload:    map[string]interface{}{"from": "IN_PROGRESS", "to": "READY"},
+		OccurredAt: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("should not wait for a partner that does not answer", func(t *testing.T) {
+		server, received := hangingPartner(t)
+		webhook := NewWebhookPublisher(server.Client(), Webhook{Partner: "delivery-app", URL: server.URL, Secret: "s3cr3t"})
+		publisher := NewAsyncPublisher("delivery-app", NewRetryingPublisher(webhook, &fakeDeadLetter{}, &fakeSleeper{}, 3, 200*time.Millisecond), &fakeDeadLetter{}, 10)
+
+		published := make(chan error)
+		go func() {
+			published <- publisher.Publish(event)
+		}()
+
+		select {
+		case err := <-published:
+			assert.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("publish waited for the partner")
+		}
+		select {
+		case <-received:
+		case <-time.After(time.Second):
+			t.Fatal("event not delivered to the partner")
+		}
+	})
+
+	t.Run("should send the events arriving on a full queue to the dead letter", func(t *testing.T) {
+		server, received := hangingPartner(t)
+		deadLetter := &fakeDeadLetter{}
+		webhook := NewWebhookPublisher(server.Client(), Webhook{Partner: "delivery-app", URL: server.URL, Secret: "s3cr3t"})
+		publisher := NewAsyncPublisher("delivery-app", webhook, deadLetter, 1)
+
+		// the first is held by the worker, the second waits in the queue
+		assert.NoError(t, publisher.Publish(event))
+		<-received
+		assert.NoError(t, publisher.Publish(event))
+		err := publisher.Publish(event)
+
+		assert.EqualError(t, err, "queue of publisher [delivery-app] is full")
+		assert.Equal(t, []Event{event}, deadLetter.events)
+	})
+}
@@ -0,0 +1,62 @@
+package events
+
+import (
+	"errors"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	OrderCreated       = "OrderCreated"
+	OrderStatusChanged = "OrderStatusChanged"
+)
+
+type Event struct {
+	Type       string                 `json:"type"`
+	OrderID    int                    `json:"orderId"`
+	Payload    map[string]interface{} `json:"payload,omitempty"`
+	OccurredAt time.Time              `json:"occurredAt"`
+}
+
+type Publisher interface {
+	Publish(event Event) error
+}
+
+type logPublisher struct{}
+
+// NewLogPublisher returns a publisher that only logs the events, until a message broker is wired in.
+func NewLogPublisher() Publisher {
+	return logPublisher{}
+}
+
+func (p logPublisher) Publish(event Event) error {
+	log.WithFields(log.Fields{
+		"eventType": event.Type,
+		"orderId":   event.OrderID,
+		"payload":   event.Payload,
+	}).Info("domain event published")
+	return nil
+}
+
+type multiPublisher struct {
+	publishers []Publisher
+}
+
+// NewMultiPublisher hands every event to all the publishers, a failing publisher does not stop the others.
+func NewMultiPublisher(publishers ...Publisher) Publisher {
+	return multiPublisher{
+		publishers: publishers,
+	}
+}
+
+func (p multiPublisher) Publish(event Event) error {
+	var errs []error
+	for _, publisher := range p.publishers {
+		if err := publisher.Publish(event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
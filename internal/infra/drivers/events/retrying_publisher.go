@@ -0,0 +1,82 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type Sleeper interface {
+	Sleep(d time.Duration)
+}
+
+// DeadLetter keeps the events that could not be published after all the retries, so they can be replayed later.
+type DeadLetter interface {
+	Store(event Event, cause error)
+}
+
+type logDeadLetter struct{}
+
+func NewLogDeadLetter() DeadLetter {
+	return logDeadLetter{}
+}
+
+func (d logDeadLetter) Store(event Event, cause error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Errorf("failed to marshal dead letter event [%s] from order id [%d], error: %v", event.Type, event.OrderID, err)
+	}
+
+	log.WithFields(log.Fields{
+		"eventType": event.Type,
+		"orderId":   event.OrderID,
+		"event":     string(body),
+		"cause":     cause,
+	}).Error("domain event sent to dead letter")
+}
+
+type retryingPublisher struct {
+	publisher   Publisher
+	deadLetter  DeadLetter
+	sleeper     Sleeper
+	maxAttempts int
+	backoff     time.Duration
+}
+
+// NewRetryingPublisher retries the failed publishes up to maxAttempts, doubling the backoff between the attempts.
+// Events still failing after the last attempt are handed to the dead letter.
+func NewRetryingPublisher(publisher Publisher, deadLetter DeadLetter, sleeper Sleeper, maxAttempts int, backoff time.Duration) Publisher {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	return retryingPublisher{
+		publisher:   publisher,
+		deadLetter:  deadLetter,
+		sleeper:     sleeper,
+		maxAttempts: maxAttempts,
+		backoff:     backoff,
+	}
+}
+
+func (p retryingPublisher) Publish(event Event) error {
+	var err error
+	backoff := p.backoff
+	for attempt := 1; attempt <= p.maxAttempts; attempt++ {
+		err = p.publisher.Publish(event)
+		if err == nil {
+			return nil
+		}
+
+		log.Warnf("failed to publish event [%s] from order id [%d] on attempt %d/%d, error: %v", event.Type, event.OrderID, attempt, p.maxAttempts, err)
+		if attempt < p.maxAttempts {
+			p.sleeper.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+
+	p.deadLetter.Store(event, err)
+	return fmt.Errorf("failed to publish event after %d attempts, error %w", p.maxAttempts, err)
+}
@@ -0,0 +1,108 @@
+package events
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errBrokerUnavailable = errors.New("broker unavailable")
+
+// flakyPublisher fails the first publishes, up to failures, and succeeds afterwards.
+type flakyPublisher struct {
+	failures  int
+	attempts  int
+	published []Event
+}
+
+func (p *flakyPublisher) Publish(event Event) error {
+	p.attempts++
+	if p.attempts <= p.failures {
+		return errBrokerUnavailable
+	}
+	p.published = append(p.published, event)
+	return nil
+}
+
+type fakeDeadLetter struct {
+	events []Event
+}
+
+func (d *fakeDeadLetter) Store(event Event, cause error) {
+	d.events = append(d.events, event)
+}
+
+type fakeSleeper struct {
+	sleeps []time.Duration
+}
+
+func (s *fakeSleeper) Sleep(d time.Duration) {
+	s.sleeps = append(s.sleeps, d)
+}
+
+func TestRetryingPublisher_Publish(t *testing.T) {
+	event := Event{
+		Type:       OrderCreated,
+		OrderID:    1,
+		OccurredAt: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	type want struct {
+		err         error
+		attempts    int
+		published   []Event
+		deadLetters []Event
+		sleeps      []time.Duration
+	}
+	tests := []struct {
+		name     string
+		failures int
+		want     want
+	}{
+		{
+			name:     "should publish on the first attempt without waiting",
+			failures: 0,
+			want: want{
+				attempts:  1,
+				published: []Event{event},
+			},
+		},
+		{
+			name:     "should succeed after retrying with backoff",
+			failures: 2,
+			want: want{
+				attempts:  3,
+				published: []Event{event},
+				sleeps:    []time.Duration{100 * time.Millisecond, 200 * time.Millisecond},
+			},
+		},
+		{
+			name:     "should send the event to the dead letter when the attempts are exhausted",
+			failures: 5,
+			want: want{
+				err:         errBrokerUnavailable,
+				attempts:    3,
+				deadLetters: []Event{event},
+				sleeps:      []time.Duration{100 * time.Millisecond, 200 * time.Millisecond},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := &flakyPublisher{failures: tt.failures}
+			deadLetter := &fakeDeadLetter{}
+			sleeper := &fakeSleeper{}
+
+			err := NewRetryingPublisher(publisher, deadLetter, sleeper, 3, 100*time.Millisecond).Publish(event)
+
+			assert.ErrorIs(t, err, tt.want.err)
+			assert.Equal(t, tt.want.attempts, publisher.attempts)
+			assert.Equal(t, tt.want.published, publisher.published)
+			assert.Equal(t, tt.want.deadLetters, deadLetter.events)
+			assert.Equal(t, tt.want.sleeps, sleeper.sleeps)
+		})
+	}
+}
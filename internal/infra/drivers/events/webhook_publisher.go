@@ -0,0 +1,77 @@
+package events
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const (
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// Webhook is an endpoint registered by a partner to be notified of the order status changes.
+type Webhook struct {
+	Partner string
+	URL     string
+	Secret  string
+}
+
+type webhookPublisher struct {
+	client  *http.Client
+	webhook Webhook
+}
+
+// NewWebhookPublisher POSTs the order status changes to the partner endpoint, other events are ignored.
+// The body is signed with the partner secret, so the partner can check the notification was sent by us.
+func NewWebhookPublisher(client *http.Client, webhook Webhook) Publisher {
+	return webhookPublisher{
+		client:  client,
+		webhook: webhook,
+	}
+}
+
+func (p webhookPublisher) Publish(event Event) error {
+	if event.Type != OrderStatusChanged {
+		return nil
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload to partner [%s], error %w", p.webhook.Partner, err)
+	}
+
+	request, err := http.NewRequest(http.MethodPost, p.webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request to partner [%s], error %w", p.webhook.Partner, err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(WebhookEventHeader, event.Type)
+	request.Header.Set(WebhookSignatureHeader, "sha256="+SignWebhookPayload(p.webhook.Secret, body))
+
+	response, err := p.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("failed to deliver webhook to partner [%s], error %w", p.webhook.Partner, err)
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("webhook to partner [%s] answered with status %d", p.webhook.Partner, response.StatusCode)
+	}
+
+	return nil
+}
+
+// SignWebhookPayload returns the hex encoded HMAC-SHA256 of the body, sent as "sha256=<signature>" on WebhookSignatureHeader.
+func SignWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,122 @@
+package events
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type webhookDelivery struct {
+	event     string
+	signature string
+	body      []byte
+}
+
+// stubPartner answers with the given statuses in sequence, repeating the last one, and records every delivery.
+func stubPartner(t *testing.T, statuses ...int) (*httptest.Server, *[]webhookDelivery) {
+	deliveries := &[]webhookDelivery{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		*deliveries = append(*deliveries, webhookDelivery{
+			event:     r.Header.Get(WebhookEventHeader),
+			signature: r.Header.Get(WebhookSignatureHeader),
+			body:      body,
+		})
+
+		status := statuses[len(statuses)-1]
+		if len(*deliveries) <= len(statuses) {
+			status = statuses[len(*deliveries)-1]
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, deliveries
+}
+
+func TestWebhookPublisher_Publish(t *testing.T) {
+	event := Event{
+		Type:       OrderStatusChanged,
+		OrderID:    7,
+		Payload:    map[string]interface{}{"from": "IN_PROGRESS", "to": "READY"},
+		OccurredAt: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("should deliver the status change signed with the partner secret", func(t *testing.T) {
+		server, deliveries := stubPartner(t, http.StatusNoContent)
+		publisher := NewWebhookPublisher(server.Client(), Webhook{Partner: "delivery-app", URL: server.URL, Secret: "s3cr3t"})
+
+		err := publisher.Publish(event)
+
+		assert.NoError(t, err)
+		if assert.Len(t, *deliveries, 1) {
+			delivery := (*deliveries)[0]
+			assert.Equal(t, OrderStatusChanged, delivery.event)
+			assert.JSONEq(t, `{"type":"OrderStatusChanged","orderId":7,"payload":{"from":"IN_PROGRESS","to":"READY"},"occurredAt":"2024-01-01T12:00:00Z"}`, string(delivery.body))
+			assert.Equal(t, "sha256="+SignWebhookPayload("s3cr3t", delivery.body), delivery.signature)
+			assert.NotEqual(t, "sha256="+SignWebhookPayload("other", delivery.body), delivery.signature)
+		}
+	})
+
+	t.Run("should not deliver events other than status changes", func(t *testing.T) {
+		server, deliveries := stubPartner(t, http.StatusOK)
+		publisher := NewWebhookPublisher(server.Client(), Webhook{Partner: "delivery-app", URL: server.URL, Secret: "s3cr3t"})
+
+		err := publisher.Publish(Event{Type: OrderCreated, OrderID: 7})
+
+		assert.NoError(t, err)
+		assert.Empty(t, *deliveries)
+	})
+
+	t.Run("should fail when the partner answers an error status", func(t *testing.T) {
+		server, _ := stubPartner(t, http.StatusInternalServerError)
+		publisher := NewWebhookPublisher(server.Client(), Webhook{Partner: "delivery-app", URL: server.URL, Secret: "s3cr3t"})
+
+		err := publisher.Publish(event)
+
+		assert.EqualError(t, err, "webhook to partner [delivery-app] answered with status 500")
+	})
+
+	t.Run("should redeliver the same signed payload when retried", func(t *testing.T) {
+		server, deliveries := stubPartner(t, http.StatusServiceUnavailable, http.StatusOK)
+		sleeper := &fakeSleeper{}
+		deadLetter := &fakeDeadLetter{}
+		publisher := NewRetryingPublisher(
+			NewWebhookPublisher(server.Client(), Webhook{Partner: "delivery-app", URL: server.URL, Secret: "s3cr3t"}),
+			deadLetter, sleeper, 3, 100*time.Millisecond)
+
+		err := publisher.Publish(event)
+
+		assert.NoError(t, err)
+		assert.Empty(t, deadLetter.events)
+		assert.Equal(t, []time.Duration{100 * time.Millisecond}, sleeper.sleeps)
+		if assert.Len(t, *deliveries, 2) {
+			assert.Equal(t, (*deliveries)[0].body, (*deliveries)[1].body)
+			assert.Equal(t, (*deliveries)[0].signature, (*deliveries)[1].signature)
+
+			var delivered Event
+			assert.NoError(t, json.Unmarshal((*deliveries)[1].body, &delivered))
+			assert.Equal(t, event.OrderID, delivered.OrderID)
+		}
+	})
+}
+
+func TestMultiPublisher_Publish(t *testing.T) {
+	failing := &flakyPublisher{failures: 1}
+	succeeding := &flakyPublisher{}
+	event := Event{Type: OrderStatusChanged, OrderID: 1}
+
+	err := NewMultiPublisher(failing, succeeding).Publish(event)
+
+	assert.ErrorIs(t, err, errBrokerUnavailable)
+	assert.Equal(t, []Event{event}, succeeding.published)
+}
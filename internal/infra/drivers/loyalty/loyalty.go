@@ -0,0 +1,57 @@
+package loyalty
+
+import (
+	"encoding/json"
+	"fmt"
+	"g37-lanchonete/internal/infra/drivers/http"
+)
+
+// StoreCreditProvider gives the store credit balance of a customer, kept by the loyalty program.
+type StoreCreditProvider interface {
+	GetBalance(customerId int) (float64, error)
+}
+
+type storeCreditProvider struct {
+	client     http.HttpClient
+	loyaltyUrl string
+}
+
+func NewStoreCreditProvider(client http.HttpClient, loyaltyUrl string) StoreCreditProvider {
+	return storeCreditProvider{
+		client:     client,
+		loyaltyUrl: loyaltyUrl,
+	}
+}
+
+func (p storeCreditProvider) GetBalance(customerId int) (float64, error) {
+	reqBody := struct {
+		CustomerID int `json:"customerId"`
+	}{
+		CustomerID: customerId,
+	}
+
+	body, err := json.Marshal(reqBody)
+	if err != nil {
+		return 0, err
+	}
+
+	response, err := p.client.DoPost(p.loyaltyUrl, body)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return 0, fmt.Errorf("failed to get store credit balance of customer [%d], status %d", customerId, response.StatusCode)
+	}
+
+	var balanceResponse struct {
+		Balance float64 `json:"balance"`
+	}
+	err = json.NewDecoder(response.Body).Decode(&balanceResponse)
+	if err != nil {
+		return 0, err
+	}
+
+	return balanceResponse.Balance, nil
+}
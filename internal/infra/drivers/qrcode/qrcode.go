@@ -0,0 +1,30 @@
+package qrcode
+
+import (
+	"fmt"
+
+	goqrcode "github.com/skip2/go-qrcode"
+)
+
+type Renderer interface {
+	RenderPNG(content string) ([]byte, error)
+}
+
+type pngRenderer struct {
+	size int
+}
+
+func NewRenderer(size int) Renderer {
+	return pngRenderer{
+		size: size,
+	}
+}
+
+func (r pngRenderer) RenderPNG(content string) ([]byte, error) {
+	png, err := goqrcode.Encode(content, goqrcode.Medium, r.size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render qrcode png, error %w", err)
+	}
+
+	return png, nil
+}
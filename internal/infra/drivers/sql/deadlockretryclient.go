@@ -0,0 +1,110 @@
+package sql
+
+import (
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	deadlockDetectedCode     = "40P01"
+	serializationFailureCode = "40001"
+)
+
+// IsRetryableError reports whether err is a postgres deadlock or serialization failure, which are safe to retry
+// since the database rolled the statement back.
+func IsRetryableError(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+
+	return pqErr.Code == deadlockDetectedCode || pqErr.Code == serializationFailureCode
+}
+
+// deadlockRetrySQLClient retries writes and transactions aborted by a deadlock or a serialization failure. Only Exec,
+// ExecWithReturn and Transaction are retried, Find and FindOne are meant for reads, so the writes returning rows go
+// through ExecWithReturn or run within a Transaction.
+type deadlockRetrySQLClient struct {
+	SQLClient
+	maxAttempts int
+	backoff     time.Duration
+	sleeper     Sleeper
+}
+
+// NewDeadlockRetrySQLClient returns the client untouched when maxAttempts allows a single attempt. The backoff
+// between the attempts doubles on every retry.
+func NewDeadlockRetrySQLClient(client SQLClient, maxAttempts int, backoff time.Duration, sleeper Sleeper) SQLClient {
+	if maxAttempts <= 1 {
+		return client
+	}
+
+	return deadlockRetrySQLClient{
+		SQLClient:   client,
+		maxAttempts: maxAttempts,
+		backoff:     backoff,
+		sleeper:     sleeper,
+	}
+}
+
+func (c deadlockRetrySQLClient) Exec(query string, args ...any) (ResultWrapper, error) {
+	var result ResultWrapper
+	err := c.retry(func() error {
+		var err error
+		result, err = c.SQLClient.Exec(query, args...)
+		return err
+	})
+	return result, err
+}
+
+// ExecWithReturn defers the statement to the Scan of the returned row, as the failure only surfaces there.
+func (c deadlockRetrySQLClient) ExecWithReturn(query string, args ...any) RowWrapper {
+	return &retryingRow{client: c, query: query, args: args}
+}
+
+func (c deadlockRetrySQLClient) Transaction(fn func(tx TransactionWrapper) error) error {
+	return c.retry(func() error {
+		return c.SQLClient.Transaction(fn)
+	})
+}
+
+func (c deadlockRetrySQLClient) retry(fn func() error) error {
+	var err error
+	backoff := c.backoff
+	for attempt := 1; attempt <= c.maxAttempts; attempt++ {
+		err = fn()
+		if err == nil || !IsRetryableError(err) {
+			return err
+		}
+
+		log.Warnf("database write aborted on attempt %d/%d, error: %v", attempt, c.maxAttempts, err)
+		if attempt < c.maxAttempts {
+			c.sleeper.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+
+	return err
+}
+
+// retryingRow runs the statement on Scan, again on every retryable failure.
+type retryingRow struct {
+	client deadlockRetrySQLClient
+	query  string
+	args   []any
+	err    error
+}
+
+func (r *retryingRow) Scan(dest ...any) error {
+	r.err = r.client.retry(func() error {
+		return r.client.SQLClient.ExecWithReturn(r.query, r.args...).Scan(dest...)
+	})
+	return r.err
+}
+
+// Err reports the error of the last Scan, the statement does not run before it.
+func (r *retryingRow) Err() error {
+	return r.err
+}
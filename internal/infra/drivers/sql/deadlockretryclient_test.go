@@ -0,0 +1,132 @@
+package sql_test
+
+import (
+	"errors"
+	"g37-lanchonete/internal/infra/drivers/sql"
+	mock_sql "g37-lanchonete/internal/infra/drivers/sql/mocks"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDeadlockRetrySQLClient_Transaction(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	deadlockErr := &pq.Error{Code: "40P01", Message: "deadlock detected"}
+	serializationErr := &pq.Error{Code: "40001", Message: "could not serialize access due to concurrent update"}
+	uniqueViolationErr := &pq.Error{Code: "23505", Message: "duplicate key value violates unique constraint"}
+
+	tests := []struct {
+		name         string
+		attemptErrs  []error
+		wantErr      error
+		wantAttempts int
+		wantElapsed  time.Duration
+	}{
+		{
+			name:         "should retry the transaction when the first attempt hits a deadlock",
+			attemptErrs:  []error{deadlockErr, nil},
+			wantAttempts: 2,
+			wantElapsed:  50 * time.Millisecond,
+		},
+		{
+			name:         "should not retry errors other than deadlocks and serialization failures",
+			attemptErrs:  []error{uniqueViolationErr},
+			wantErr:      uniqueViolationErr,
+			wantAttempts: 1,
+		},
+		{
+			name:         "should give up after the last attempt",
+			attemptErrs:  []error{serializationErr, deadlockErr, serializationErr},
+			wantErr:      serializationErr,
+			wantAttempts: 3,
+			wantElapsed:  150 * time.Millisecond,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			client := mock_sql.NewMockSQLClient(ctrl)
+			tx := mock_sql.NewMockTransactionWrapper(ctrl)
+			clock := &fakeClock{now: start}
+			client.EXPECT().
+				Transaction(gomock.Any()).
+				DoAndReturn(func(fn func(tx sql.TransactionWrapper) error) error {
+					return fn(tx)
+				}).
+				Times(tt.wantAttempts)
+
+			attempts := 0
+			retryClient := sql.NewDeadlockRetrySQLClient(client, 3, 50*time.Millisecond, clock)
+			err := retryClient.Transaction(func(tx sql.TransactionWrapper) error {
+				attempts++
+				return tt.attemptErrs[attempts-1]
+			})
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.wantAttempts, attempts)
+			assert.Equal(t, tt.wantElapsed, clock.now.Sub(start))
+		})
+	}
+}
+
+func TestDeadlockRetrySQLClient_Exec(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	client := mock_sql.NewMockSQLClient(ctrl)
+	result := mock_sql.NewMockResultWrapper(ctrl)
+	clock := &fakeClock{}
+	gomock.InOrder(
+		client.EXPECT().Exec(gomock.Any(), gomock.Any()).Return(nil, &pq.Error{Code: "40P01"}),
+		client.EXPECT().Exec(gomock.Any(), gomock.Any()).Return(result, nil),
+	)
+
+	retryClient := sql.NewDeadlockRetrySQLClient(client, 3, 50*time.Millisecond, clock)
+	got, err := retryClient.Exec("UPDATE public.orders SET status = $2 WHERE id = $1", 1, "READY")
+
+	assert.NoError(t, err)
+	assert.Equal(t, result, got)
+}
+
+func TestDeadlockRetrySQLClient_ExecWithReturn(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	client := mock_sql.NewMockSQLClient(ctrl)
+	deadlockedRow := mock_sql.NewMockRowWrapper(ctrl)
+	row := mock_sql.NewMockRowWrapper(ctrl)
+	clock := &fakeClock{}
+	// the deadlock only surfaces on the scan of the returned row
+	gomock.InOrder(
+		client.EXPECT().ExecWithReturn(gomock.Any(), gomock.Any()).Return(deadlockedRow),
+		client.EXPECT().ExecWithReturn(gomock.Any(), gomock.Any()).Return(row),
+	)
+	deadlockedRow.EXPECT().Scan(gomock.Any()).Return(&pq.Error{Code: "40P01"}).Times(1)
+	row.EXPECT().
+		Scan(gomock.Any()).
+		DoAndReturn(func(dest ...any) error {
+			*dest[0].(*int) = 42
+			return nil
+		}).
+		Times(1)
+
+	retryClient := sql.NewDeadlockRetrySQLClient(client, 3, 50*time.Millisecond, clock)
+	var sequence int
+	err := retryClient.ExecWithReturn("UPDATE public.daily_order_sequences SET last = last + 1 RETURNING last").Scan(&sequence)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 42, sequence)
+}
+
+func TestIsRetryableError(t *testing.T) {
+	assert.True(t, sql.IsRetryableError(&pq.Error{Code: "40P01"}))
+	assert.True(t, sql.IsRetryableError(&pq.Error{Code: "40001"}))
+	assert.False(t, sql.IsRetryableError(&pq.Error{Code: "23505"}))
+	assert.False(t, sql.IsRetryableError(errors.New("connection refused")))
+}
+
+func TestIsForeignKeyViolation(t *testing.T) {
+	assert.True(t, sql.IsForeignKeyViolation(&pq.Error{Code: "23503"}))
+	assert.False(t, sql.IsForeignKeyViolation(&pq.Error{Code: "23505"}))
+	assert.False(t, sql.IsForeignKeyViolation(errors.New("connection refused")))
+	assert.False(t, sql.IsForeignKeyViolation(nil))
+}
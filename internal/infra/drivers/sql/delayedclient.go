@@ -0,0 +1,67 @@
+package sql
+
+import "time"
+
+type Sleeper interface {
+	Sleep(d time.Duration)
+}
+
+type systemSleeper struct{}
+
+func NewSystemSleeper() Sleeper {
+	return systemSleeper{}
+}
+
+func (systemSleeper) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+// delayedSQLClient adds an artificial latency before every database call, to simulate a slow database on load tests.
+type delayedSQLClient struct {
+	SQLClient
+	delay   time.Duration
+	sleeper Sleeper
+}
+
+// NewDelayedSQLClient returns the client untouched when delay is not positive.
+func NewDelayedSQLClient(client SQLClient, delay time.Duration, sleeper Sleeper) SQLClient {
+	if delay <= 0 {
+		return client
+	}
+
+	return delayedSQLClient{
+		SQLClient: client,
+		delay:     delay,
+		sleeper:   sleeper,
+	}
+}
+
+func (c delayedSQLClient) Find(query string, args ...any) (RowsWrapper, error) {
+	c.sleeper.Sleep(c.delay)
+	return c.SQLClient.Find(query, args...)
+}
+
+func (c delayedSQLClient) FindOne(query string, args ...any) RowWrapper {
+	c.sleeper.Sleep(c.delay)
+	return c.SQLClient.FindOne(query, args...)
+}
+
+func (c delayedSQLClient) Exec(query string, args ...any) (ResultWrapper, error) {
+	c.sleeper.Sleep(c.delay)
+	return c.SQLClient.Exec(query, args...)
+}
+
+func (c delayedSQLClient) ExecWithReturn(query string, args ...any) RowWrapper {
+	c.sleeper.Sleep(c.delay)
+	return c.SQLClient.ExecWithReturn(query, args...)
+}
+
+func (c delayedSQLClient) Begin() (TransactionWrapper, error) {
+	c.sleeper.Sleep(c.delay)
+	return c.SQLClient.Begin()
+}
+
+func (c delayedSQLClient) Transaction(fn func(tx TransactionWrapper) error) error {
+	c.sleeper.Sleep(c.delay)
+	return c.SQLClient.Transaction(fn)
+}
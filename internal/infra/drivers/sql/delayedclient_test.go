@@ -0,0 +1,55 @@
+package sql_test
+
+import (
+	"g37-lanchonete/internal/infra/drivers/sql"
+	mock_sql "g37-lanchonete/internal/infra/drivers/sql/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Sleep(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func TestDelayedSQLClient(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		delay       time.Duration
+		wantElapsed time.Duration
+	}{
+		{
+			name:        "should add the configured delay before each call",
+			delay:       150 * time.Millisecond,
+			wantElapsed: 300 * time.Millisecond,
+		},
+		{
+			name:        "should not delay when the hook is disabled",
+			delay:       0,
+			wantElapsed: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			client := mock_sql.NewMockSQLClient(ctrl)
+			clock := &fakeClock{now: start}
+			client.EXPECT().Find(gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+			client.EXPECT().Exec(gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+
+			delayedClient := sql.NewDelayedSQLClient(client, tt.delay, clock)
+			_, _ = delayedClient.Find("SELECT 1", 1)
+			_, _ = delayedClient.Exec("DELETE FROM products WHERE id = $1", 1)
+
+			assert.Equal(t, tt.wantElapsed, clock.now.Sub(start))
+		})
+	}
+}
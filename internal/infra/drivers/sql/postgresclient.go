@@ -45,6 +45,29 @@ func (client sqlClient) Begin() (TransactionWrapper, error) {
 	return NewTransactionWrapper(tx), err
 }
 
+// Transaction runs fn inside a transaction, committing it when fn succeeds and rolling it back otherwise.
+func (client sqlClient) Transaction(fn func(tx TransactionWrapper) error) error {
+	tx, err := client.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to create a transaction, error %w", err)
+	}
+
+	err = fn(tx)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return fmt.Errorf("failed to rollback the transaction, error %v, cause %w", rollbackErr, err)
+		}
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to commit the transaction, error %w", err)
+	}
+
+	return nil
+}
+
 func (client sqlClient) Ping() error {
 	err := client.db.Ping()
 	return err
@@ -3,16 +3,31 @@ package sql
 import (
 	"database/sql"
 	"errors"
+
+	"github.com/lib/pq"
 )
 
 var ErrNotFound = errors.New("entity not found")
 
+const foreignKeyViolationCode = "23503"
+
+// IsForeignKeyViolation reports whether err is a postgres foreign key violation, e.g. deleting a row still referenced.
+func IsForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+
+	return pqErr.Code == foreignKeyViolationCode
+}
+
 type SQLClient interface {
 	Find(query string, args ...any) (RowsWrapper, error)
 	FindOne(query string, args ...any) RowWrapper
 	Exec(query string, args ...any) (ResultWrapper, error)
 	ExecWithReturn(query string, args ...any) RowWrapper
 	Begin() (TransactionWrapper, error)
+	Transaction(fn func(tx TransactionWrapper) error) error
 	Ping() error
 	GetConnection() *sql.DB
 }
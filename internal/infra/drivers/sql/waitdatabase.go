@@ -0,0 +1,31 @@
+package sql
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// WaitForDatabase pings the database up to maxAttempts, waiting interval between the attempts, so the service
+// survives booting before the database is ready. The last ping error is returned when all the attempts fail.
+func WaitForDatabase(client SQLClient, maxAttempts int, interval time.Duration, sleeper Sleeper) error {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		err = client.Ping()
+		if err == nil {
+			return nil
+		}
+
+		if attempt < maxAttempts {
+			log.Warnf("database is not ready on attempt %d/%d, retrying in [%s], error: %v", attempt, maxAttempts, interval, err)
+			sleeper.Sleep(interval)
+		}
+	}
+
+	return fmt.Errorf("database is not ready after %d attempts, error %w", maxAttempts, err)
+}
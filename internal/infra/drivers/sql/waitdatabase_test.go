@@ -0,0 +1,77 @@
+package sql_test
+
+import (
+	"errors"
+	"g37-lanchonete/internal/infra/drivers/sql"
+	mock_sql "g37-lanchonete/internal/infra/drivers/sql/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestWaitForDatabase(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	errConnectionRefused := errors.New("dial tcp 127.0.0.1:5432: connect: connection refused")
+
+	tests := []struct {
+		name        string
+		failures    int
+		maxAttempts int
+		wantErr     bool
+		wantPings   int
+		wantElapsed time.Duration
+	}{
+		{
+			name:        "should connect after a couple of failed attempts",
+			failures:    2,
+			maxAttempts: 5,
+			wantPings:   3,
+			wantElapsed: 2 * time.Second,
+		},
+		{
+			name:        "should connect on the first attempt without waiting",
+			failures:    0,
+			maxAttempts: 5,
+			wantPings:   1,
+		},
+		{
+			name:        "should give up after the last attempt",
+			failures:    10,
+			maxAttempts: 3,
+			wantErr:     true,
+			wantPings:   3,
+			wantElapsed: 2 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			client := mock_sql.NewMockSQLClient(ctrl)
+			clock := &fakeClock{now: start}
+
+			pings := 0
+			client.EXPECT().
+				Ping().
+				DoAndReturn(func() error {
+					pings++
+					if pings <= tt.failures {
+						return errConnectionRefused
+					}
+					return nil
+				}).
+				Times(tt.wantPings)
+
+			err := sql.WaitForDatabase(client, tt.maxAttempts, time.Second, clock)
+
+			if tt.wantErr {
+				assert.ErrorIs(t, err, errConnectionRefused)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantPings, pings)
+			assert.Equal(t, tt.wantElapsed, clock.now.Sub(start))
+		})
+	}
+}
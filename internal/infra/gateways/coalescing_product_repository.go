@@ -0,0 +1,54 @@
+package gateways
+
+import (
+	"g37-lanchonete/internal/core/entities"
+	"sync"
+)
+
+// productLoad is a product lookup in flight, shared by the callers asking for the same product meanwhile.
+type productLoad struct {
+	done    chan struct{}
+	product entities.Product
+	err     error
+	waiters int
+}
+
+type coalescingProductRepositoryGateway struct {
+	ProductRepositoryGateway
+	mu    sync.Mutex
+	loads map[int]*productLoad
+}
+
+// NewCoalescingProductRepositoryGateway wraps the gateway so concurrent lookups of the same product share a single
+// query, instead of every order being priced at the same time hitting the database with it.
+func NewCoalescingProductRepositoryGateway(gateway ProductRepositoryGateway) ProductRepositoryGateway {
+	return &coalescingProductRepositoryGateway{
+		ProductRepositoryGateway: gateway,
+		loads:                    map[int]*productLoad{},
+	}
+}
+
+func (r *coalescingProductRepositoryGateway) FindProductById(id int) (entities.Product, error) {
+	r.mu.Lock()
+	if load, found := r.loads[id]; found {
+		load.waiters++
+		r.mu.Unlock()
+		<-load.done
+		return load.product, load.err
+	}
+
+	load := &productLoad{done: make(chan struct{})}
+	r.loads[id] = load
+	r.mu.Unlock()
+
+	// the load is forgotten once finished, so only the callers arriving while it runs share its result
+	defer func() {
+		r.mu.Lock()
+		delete(r.loads, id)
+		r.mu.Unlock()
+		close(load.done)
+	}()
+
+	load.product, load.err = r.ProductRepositoryGateway.FindProductById(id)
+	return load.product, load.err
+}
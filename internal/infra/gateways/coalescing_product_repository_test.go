@@ -0,0 +1,61 @@
+package gateways
+
+import (
+	"g37-lanchonete/internal/core/entities"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type blockingProductRepository struct {
+	ProductRepositoryGateway
+	release chan struct{}
+	queries atomic.Int32
+}
+
+func (r *blockingProductRepository) FindProductById(id int) (entities.Product, error) {
+	r.queries.Add(1)
+	<-r.release
+	return entities.Product{ID: id, Name: "X-Burguer", Price: 10}, nil
+}
+
+func TestCoalescingProductRepositoryGateway_FindProductById(t *testing.T) {
+	const callers = 50
+	repository := &blockingProductRepository{release: make(chan struct{})}
+	gateway := NewCoalescingProductRepositoryGateway(repository).(*coalescingProductRepositoryGateway)
+
+	var wg sync.WaitGroup
+	products := make([]entities.Product, callers)
+	errs := make([]error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			products[i], errs[i] = gateway.FindProductById(1)
+		}(i)
+	}
+
+	// the query is only released once every caller is waiting on it
+	assert.Eventually(t, func() bool {
+		gateway.mu.Lock()
+		defer gateway.mu.Unlock()
+		load, found := gateway.loads[1]
+		return found && load.waiters == callers-1
+	}, time.Second, time.Millisecond)
+	close(repository.release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), repository.queries.Load())
+	for i := 0; i < callers; i++ {
+		assert.NoError(t, errs[i])
+		assert.Equal(t, entities.Product{ID: 1, Name: "X-Burguer", Price: 10}, products[i])
+	}
+
+	// a lookup after the shared one finished queries the repository again
+	_, err := gateway.FindProductById(1)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), repository.queries.Load())
+}
@@ -0,0 +1,63 @@
+package gateways
+
+import (
+	dbsql "database/sql"
+	"errors"
+	"fmt"
+	"g37-lanchonete/internal/core/entities"
+	"g37-lanchonete/internal/core/usecases/dto"
+	"g37-lanchonete/internal/infra/drivers/sql"
+	"g37-lanchonete/internal/infra/gateways/sqlscripts"
+	"time"
+)
+
+type CouponRepositoryGateway interface {
+	FindCouponByCode(code string) (entities.Coupon, error)
+	GetCouponUsage(from, to time.Time) ([]dto.CouponUsageDTO, error)
+}
+
+type couponRepositoryGateway struct {
+	sqlClient sql.SQLClient
+}
+
+func NewCouponRepositoryGateway(sqlClient sql.SQLClient) CouponRepositoryGateway {
+	return couponRepositoryGateway{
+		sqlClient: sqlClient,
+	}
+}
+
+func (r couponRepositoryGateway) FindCouponByCode(code string) (entities.Coupon, error) {
+	row := r.sqlClient.FindOne(sqlscripts.GetCouponByCodeQuery, code)
+
+	var coupon entities.Coupon
+	err := row.Scan(&coupon.Code, &coupon.Type, &coupon.Value, &coupon.MinTotal, &coupon.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, dbsql.ErrNoRows) {
+			return entities.Coupon{}, sql.ErrNotFound
+		}
+		return entities.Coupon{}, fmt.Errorf("failed to find coupon [%s], error %w", code, err)
+	}
+
+	return coupon, nil
+}
+
+func (r couponRepositoryGateway) GetCouponUsage(from, to time.Time) ([]dto.CouponUsageDTO, error) {
+	rows, err := r.sqlClient.Find(sqlscripts.GetCouponUsageQuery, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get coupon usage, error %w", err)
+	}
+	defer rows.Close()
+
+	usage := []dto.CouponUsageDTO{}
+	for rows.Next() {
+		var couponUsage dto.CouponUsageDTO
+		err = rows.Scan(&couponUsage.Code, &couponUsage.Redemptions, &couponUsage.DiscountTotal)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan coupon usage, error %w", err)
+		}
+
+		usage = append(usage, couponUsage)
+	}
+
+	return usage, nil
+}
@@ -0,0 +1,10 @@
+package gateways
+
+// valueOrZero reads a nullable column scanned into a pointer, NULL being the zero value of the field.
+func valueOrZero[T any](value *T) T {
+	if value == nil {
+		var zero T
+		return zero
+	}
+	return *value
+}
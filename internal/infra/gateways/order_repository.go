@@ -1,103 +1,553 @@
 package gateways
 
 import (
+	dbsql "database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"g37-lanchonete/internal/core/entities"
 	"g37-lanchonete/internal/core/usecases/dto"
 	"g37-lanchonete/internal/infra/drivers/sql"
 	"g37-lanchonete/internal/infra/gateways/sqlscripts"
+	"strings"
+	"time"
 )
 
 type OrderRepositoryGateway interface {
-	FindAllOrders(pageParams dto.PageParams) ([]entities.Order, error)
+	FindAllOrders(pageParams dto.PageParams, filters dto.OrderFilters) ([]entities.Order, error)
+	FindQueueOrders(pageParams dto.PageParams, filters dto.OrderFilters) ([]dto.QueueOrderDTO, error)
+	FindOrderById(orderId int) (entities.Order, error)
+	FindOrderByNumber(number string) (entities.Order, error)
 	GetOrderStatus(orderId int) (string, error)
-	SaveOrder(order entities.Order) (int, error)
-	UpdateOrderStatus(orderId int, orderStatus string) error
+	GetOrderStatusChange(orderId int) (string, time.Time, error)
+	GetOrderStatuses(orderIds []int) (map[int]string, error)
+	FindCompletedOrders(since time.Time, limit int) ([]dto.CompletedOrderDTO, error)
+	GetStatusTransitionStats(from, to time.Time) ([]dto.StatusTransitionStatsDTO, error)
+	SaveOrder(order entities.Order, number OrderNumberFunc) (int, string, error)
+	AddOrderItem(orderId int, item entities.OrderItem, totals dto.OrderTotals) (int, error)
+	RemoveOrderItem(orderId int, itemId int, totals dto.OrderTotals) error
+	UpdateOrderTotals(orderId int, totals dto.OrderTotals) error
+	UpdateOrderItemStatus(orderId int, itemId int, itemStatus string) (bool, error)
+	UpdateOrderStatus(orderId int, orderStatus string, expectedStatus string) error
+	ConfirmOrderPayment(orderId int) (bool, error)
+	ReopenOrder(orderId int, completedSince time.Time, reopenedAt time.Time) (bool, error)
+	CancelUnpaidOrders(cpf string, cancelledAt time.Time) ([]int, error)
+	GetOrderQRCode(orderId int) (dto.PaymentQRCode, string, error)
+	UpdateOrderQRCode(orderId int, qrCode dto.PaymentQRCode) error
+	SaveOrderFeedback(feedback entities.OrderFeedback) (int, error)
+	AnonymizeDoneOrders(createdBefore time.Time, anonymizedAt time.Time) (int, error)
 }
 
+// OrderNumberFunc builds the number shown to the customer of the order being saved, whose ID is already set.
+// nextDailySequence increments the order counter of the day the order was created within the save transaction.
+type OrderNumberFunc func(order entities.Order, nextDailySequence func() (int, error)) (string, error)
+
 type orderRepositoryGateway struct {
-	sqlClient sql.SQLClient
+	sqlClient   sql.SQLClient
+	defaultSort dto.OrderSort
+	stations    dto.Stations
 }
 
-func NewOrderRepositoryGateway(sqlClient sql.SQLClient) OrderRepositoryGateway {
+// NewOrderRepositoryGateway builds the gateway listing the orders in the given default sort when none is asked,
+// "" or an unknown sort listing the newest first. The stations map each preparation station to the product
+// categories prepared at it, for the queue.
+func NewOrderRepositoryGateway(sqlClient sql.SQLClient, defaultSort string, stations map[string][]string) OrderRepositoryGateway {
 	return orderRepositoryGateway{
-		sqlClient: sqlClient,
+		sqlClient:   sqlClient,
+		defaultSort: dto.OrderSort(strings.ToLower(strings.TrimSpace(defaultSort))),
+		stations:    dto.NewStations(stations),
 	}
 }
 
-func (r orderRepositoryGateway) FindAllOrders(pageParams dto.PageParams) ([]entities.Order, error) {
-	rows, err := r.sqlClient.Find(sqlscripts.FindAllOrdersQuery, pageParams.GetLimit(), pageParams.GetOffset())
+func (r orderRepositoryGateway) FindAllOrders(pageParams dto.PageParams, filters dto.OrderFilters) ([]entities.Order, error) {
+	condition, args := buildOrderFiltersCondition(pageParams, filters)
+
+	sort := filters.Sort
+	if sort == "" {
+		sort = r.defaultSort
+	}
+
+	rows, err := r.sqlClient.Find(fmt.Sprintf(sqlscripts.FindAllOrdersQuery, condition, orderSortClause(sort)), args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find all orders, error %w", err)
 	}
 
 	orders := []entities.Order{}
 	for rows.Next() {
-		var order entities.Order
-		var customer entities.Customer
-
-		err = rows.Scan(&order.ID, &order.Coupon, &order.TotalAmount, &order.Status, &order.CreatedAt,
-			&customer.ID, &customer.Name, &customer.Cpf, &customer.Email, &customer.CreatedAt, &customer.UpdatedAt)
+		order, err := r.scanOrder(rows)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan orders, error %w", err)
+			return nil, err
 		}
+		orders = append(orders, order)
+	}
 
-		orderItems, err := r.getOrderItems(order.ID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan order items, error %w", err)
+	return orders, nil
+}
+
+// FindQueueOrders lists the orders of FindAllOrders in the priority sort, with only the columns shown on the kitchen queue.
+// Filtered by a station, only the orders with items prepared at it are listed, with only those items.
+func (r orderRepositoryGateway) FindQueueOrders(pageParams dto.PageParams, filters dto.OrderFilters) ([]dto.QueueOrderDTO, error) {
+	condition, args := buildOrderFiltersCondition(pageParams, filters)
+
+	var stationCategories []string
+	if filters.Station != "" {
+		stationCategories = r.stations[strings.ToLower(strings.TrimSpace(filters.Station))]
+		if len(stationCategories) == 0 {
+			return nil, dto.ErrUnknownStation
 		}
 
-		order.Customer = customer
-		order.Items = orderItems
+		var placeholders string
+		placeholders, args = buildPlaceholders(stationCategories, args)
+		condition += fmt.Sprintf(sqlscripts.QueueOrderStationCondition, placeholders)
+	}
+
+	rows, err := r.sqlClient.Find(fmt.Sprintf(sqlscripts.FindQueueOrdersQuery, condition), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find queue orders, error %w", err)
+	}
+	defer rows.Close()
+
+	orders := []dto.QueueOrderDTO{}
+	for rows.Next() {
+		var order dto.QueueOrderDTO
+		err := rows.Scan(&order.ID, &order.Number, &order.Status, &order.FulfillmentType, &order.Notes, &order.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan queue orders, error %w", err)
+		}
 		orders = append(orders, order)
 	}
 
+	for i := range orders {
+		items, err := r.getQueueOrderItems(orders[i].ID, filters.Station, stationCategories)
+		if err != nil {
+			return nil, err
+		}
+		orders[i].Items = items
+	}
+
 	return orders, nil
 }
 
+// getQueueOrderItems lists the items of the order, only the ones prepared at the station with the given categories
+// when a station is given.
+func (r orderRepositoryGateway) getQueueOrderItems(orderId int, station string, stationCategories []string) ([]dto.QueueOrderItemDTO, error) {
+	condition, args := "", []any{orderId}
+	if len(stationCategories) > 0 {
+		var placeholders string
+		placeholders, args = buildPlaceholders(stationCategories, args)
+		condition = fmt.Sprintf(sqlscripts.QueueOrderItemStationCondition, placeholders)
+	}
+
+	rows, err := r.sqlClient.Find(fmt.Sprintf(sqlscripts.FindQueueOrderItemsQuery, condition), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find queue order items, error %w", err)
+	}
+	defer rows.Close()
+
+	items := []dto.QueueOrderItemDTO{}
+	for rows.Next() {
+		var item dto.QueueOrderItemDTO
+		var categoriesJSON []byte
+		if err := rows.Scan(&item.Name, &item.Variant, &item.Quantity, &item.Type, &categoriesJSON); err != nil {
+			return nil, fmt.Errorf("failed to scan queue order items, error %w", err)
+		}
+
+		// an item listed for a station is shown as prepared at it, even when another of its categories maps elsewhere
+		item.Station = strings.ToLower(strings.TrimSpace(station))
+		if item.Station == "" && len(categoriesJSON) > 0 {
+			var categories []string
+			if err := json.Unmarshal(categoriesJSON, &categories); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal queue order item categories, error %w", err)
+			}
+			item.Station = r.stations.StationOf(categories)
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+func (r orderRepositoryGateway) FindOrderById(orderId int) (entities.Order, error) {
+	order, err := r.scanOrder(r.sqlClient.FindOne(sqlscripts.FindOrderByIdQuery, orderId))
+	if err != nil {
+		if errors.Is(err, dbsql.ErrNoRows) {
+			return entities.Order{}, sql.ErrNotFound
+		}
+		return entities.Order{}, err
+	}
+
+	return order, nil
+}
+
+func (r orderRepositoryGateway) FindOrderByNumber(number string) (entities.Order, error) {
+	order, err := r.scanOrder(r.sqlClient.FindOne(sqlscripts.FindOrderByNumberQuery, number))
+	if err != nil {
+		if errors.Is(err, dbsql.ErrNoRows) {
+			return entities.Order{}, sql.ErrNotFound
+		}
+		return entities.Order{}, err
+	}
+
+	return order, nil
+}
+
+// scanOrder reads an order row selected with its customer, loading the order items along with it.
+func (r orderRepositoryGateway) scanOrder(row interface{ Scan(dest ...any) error }) (entities.Order, error) {
+	var order entities.Order
+	var customer entities.Customer
+	var deliveryAddress []byte
+	// the orders created before the subtotal was stored have it NULL, the total and status columns are nullable as well
+	var subtotalAmount, totalAmount *float64
+	var status *string
+
+	err := row.Scan(&order.ID, &order.Number, &order.Coupon, &subtotalAmount, &order.TaxAmount, &totalAmount, &status, &order.FulfillmentType, &deliveryAddress, &order.Notes, &order.CreatedAt,
+		&customer.ID, &customer.Name, &customer.Cpf, &customer.Email, &customer.CreatedAt, &customer.UpdatedAt, &order.StoreCreditAmount, &order.DiscountAmount)
+	if err != nil {
+		return entities.Order{}, fmt.Errorf("failed to scan orders, error %w", err)
+	}
+	order.SubtotalAmount = valueOrZero(subtotalAmount)
+	order.TotalAmount = valueOrZero(totalAmount)
+	order.Status = valueOrZero(status)
+
+	orderItems, err := r.getOrderItems(order.ID)
+	if err != nil {
+		return entities.Order{}, fmt.Errorf("failed to scan order items, error %w", err)
+	}
+
+	if deliveryAddress != nil {
+		var address entities.Address
+		err = json.Unmarshal(deliveryAddress, &address)
+		if err != nil {
+			return entities.Order{}, fmt.Errorf("failed to unmarshal order delivery address, error %w", err)
+		}
+		order.DeliveryAddress = &address
+	}
+	order.Customer = customer
+	order.Items = orderItems
+
+	return order, nil
+}
+
 func (r orderRepositoryGateway) GetOrderStatus(orderId int) (string, error) {
 	row := r.sqlClient.FindOne(sqlscripts.FindOrderStatusByIdQuery, orderId)
 
 	var status string
 	err := row.Scan(&status)
 	if err != nil {
+		if errors.Is(err, dbsql.ErrNoRows) {
+			return "", sql.ErrNotFound
+		}
 		return "", fmt.Errorf("failed to find order status, error %w", err)
 	}
 
 	return status, nil
 }
 
-func (r orderRepositoryGateway) SaveOrder(order entities.Order) (int, error) {
-	tx, err := r.sqlClient.Begin()
+// GetOrderStatusChange returns the status of the order and when it got into it, its creation while it never changed.
+func (r orderRepositoryGateway) GetOrderStatusChange(orderId int) (string, time.Time, error) {
+	row := r.sqlClient.FindOne(sqlscripts.FindOrderStatusChangeByIdQuery, orderId)
+
+	var status string
+	var changedAt time.Time
+	err := row.Scan(&status, &changedAt)
 	if err != nil {
-		return -1, fmt.Errorf("failed to create a transaction, error %w", err)
+		if errors.Is(err, dbsql.ErrNoRows) {
+			return "", time.Time{}, sql.ErrNotFound
+		}
+		return "", time.Time{}, fmt.Errorf("failed to find order status change, error %w", err)
 	}
 
-	row := tx.ExecWithReturn(sqlscripts.InsertOrderCmd, order.Coupon, order.TotalAmount, order.Customer.ID, order.Status, order.CreatedAt)
+	return status, changedAt, nil
+}
+
+// GetOrderStatuses returns the status of every given order found, keyed by order id.
+func (r orderRepositoryGateway) GetOrderStatuses(orderIds []int) (map[int]string, error) {
+	statuses := make(map[int]string, len(orderIds))
+	if len(orderIds) == 0 {
+		return statuses, nil
+	}
+
+	args := make([]any, len(orderIds))
+	placeholders := make([]string, len(orderIds))
+	for i, id := range orderIds {
+		args[i] = id
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+	}
+
+	rows, err := r.sqlClient.Find(fmt.Sprintf(sqlscripts.FindOrderStatusesByIdsQuery, strings.Join(placeholders, ", ")), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find order statuses, error %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var status string
+		err = rows.Scan(&id, &status)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan order statuses, error %w", err)
+		}
+		statuses[id] = status
+	}
+
+	return statuses, nil
+}
+
+// FindCompletedOrders returns the orders moved to DONE since the given time, the most recently completed first.
+func (r orderRepositoryGateway) FindCompletedOrders(since time.Time, limit int) ([]dto.CompletedOrderDTO, error) {
+	rows, err := r.sqlClient.Find(sqlscripts.FindCompletedOrdersQuery, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find completed orders, error %w", err)
+	}
+	defer rows.Close()
+
+	orders := []dto.CompletedOrderDTO{}
+	for rows.Next() {
+		var order dto.CompletedOrderDTO
+		err = rows.Scan(&order.OrderID, &order.Number, &order.CompletedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan completed orders, error %w", err)
+		}
+		orders = append(orders, order)
+	}
+
+	return orders, nil
+}
+
+// GetStatusTransitionStats aggregates the durations of the status transitions recorded between from and to.
+func (r orderRepositoryGateway) GetStatusTransitionStats(from, to time.Time) ([]dto.StatusTransitionStatsDTO, error) {
+	rows, err := r.sqlClient.Find(sqlscripts.GetStatusTransitionStatsQuery, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status transition stats, error %w", err)
+	}
+	defer rows.Close()
+
+	stats := []dto.StatusTransitionStatsDTO{}
+	for rows.Next() {
+		var transition dto.StatusTransitionStatsDTO
+		err = rows.Scan(&transition.From, &transition.To, &transition.Count, &transition.AverageSeconds,
+			&transition.P50Seconds, &transition.P90Seconds, &transition.P95Seconds)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan status transition stats, error %w", err)
+		}
+
+		stats = append(stats, transition)
+	}
+
+	return stats, nil
+}
+
+// SaveOrder stores the order with its items and the number built by the given func in a single transaction, so an order
+// is never left without its number. It returns the id and the number of the order.
+func (r orderRepositoryGateway) SaveOrder(order entities.Order, number OrderNumberFunc) (int, string, error) {
+	var deliveryAddress []byte
+	if order.DeliveryAddress != nil {
+		var err error
+		deliveryAddress, err = json.Marshal(order.DeliveryAddress)
+		if err != nil {
+			return -1, "", fmt.Errorf("failed to marshal order delivery address, error %w", err)
+		}
+	}
 
 	var orderId int
-	err = row.Scan(&orderId)
+	var orderNumber string
+	err := r.sqlClient.Transaction(func(tx sql.TransactionWrapper) error {
+		row := tx.ExecWithReturn(sqlscripts.InsertOrderCmd, order.Coupon, order.SubtotalAmount, order.TaxAmount, order.TotalAmount, order.Customer.ID, order.Status,
+			order.FulfillmentType, deliveryAddress, order.Notes, order.CreatedAt, order.StoreCreditAmount, order.DiscountAmount)
+
+		err := row.Scan(&orderId)
+		if err != nil {
+			return fmt.Errorf("failed to save order, error %w", err)
+		}
+
+		for _, item := range order.Items {
+			var variantId *int
+			if item.Variant != nil {
+				variantId = &item.Variant.ID
+			}
+
+			_, err := tx.Exec(sqlscripts.InsertOrderItemCmd, orderId, item.Product.ID, item.Quantity, item.Type, variantId)
+			if err != nil {
+				return fmt.Errorf("failed to save order items associations, error %w", err)
+			}
+		}
+
+		order.ID = orderId
+		orderNumber, err = number(order, func() (int, error) {
+			return nextDailyOrderSequence(tx, order.CreatedAt.Time)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to generate order number, error %w", err)
+		}
+
+		_, err = tx.Exec(sqlscripts.UpdateOrderNumberCmd, orderId, orderNumber)
+		if err != nil {
+			return fmt.Errorf("failed to update order number, error %w", err)
+		}
+
+		return nil
+	})
 	if err != nil {
-		return -1, fmt.Errorf("failed to save order, error %w", err)
+		return -1, "", err
 	}
 
-	for _, item := range order.Items {
-		_, err := tx.Exec(sqlscripts.InsertOrderItemCmd, orderId, item.Product.ID, item.Quantity, item.Type)
+	return orderId, orderNumber, nil
+}
+
+// AddOrderItem inserts the item and stores the recalculated totals of the order, as long as the order is still
+// CREATED, returning the id of the new item.
+func (r orderRepositoryGateway) AddOrderItem(orderId int, item entities.OrderItem, totals dto.OrderTotals) (int, error) {
+	var itemId int
+	err := r.sqlClient.Transaction(func(tx sql.TransactionWrapper) error {
+		err := updateEditableOrderTotals(tx, orderId, totals)
 		if err != nil {
-			return -1, fmt.Errorf("failed to save order items associations, error %v", err)
+			return err
+		}
+
+		var variantId *int
+		if item.Variant != nil {
+			variantId = &item.Variant.ID
 		}
+
+		row := tx.ExecWithReturn(sqlscripts.InsertOrderItemWithReturnCmd, orderId, item.Product.ID, item.Quantity, item.Type, variantId)
+		err = row.Scan(&itemId)
+		if err != nil {
+			return fmt.Errorf("failed to save order item, error %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return -1, err
 	}
 
-	err = tx.Commit()
+	return itemId, nil
+}
+
+// RemoveOrderItem deletes the item and stores the recalculated totals of the order, as long as the order is still
+// CREATED. Both changes are rolled back when the order stopped being editable in the meantime.
+func (r orderRepositoryGateway) RemoveOrderItem(orderId int, itemId int, totals dto.OrderTotals) error {
+	return r.sqlClient.Transaction(func(tx sql.TransactionWrapper) error {
+		err := updateEditableOrderTotals(tx, orderId, totals)
+		if err != nil {
+			return err
+		}
+
+		result, err := tx.Exec(sqlscripts.DeleteOrderItemCmd, orderId, itemId)
+		if err != nil {
+			return fmt.Errorf("failed to delete order item, error %w", err)
+		}
+
+		rowsAffect, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to check order item delete operation, error %w", err)
+		}
+
+		if rowsAffect < 1 {
+			return dto.ErrOrderItemNotFound
+		}
+
+		return nil
+	})
+}
+
+// UpdateOrderTotals stores the recalculated totals of the order, as long as the order is still CREATED.
+func (r orderRepositoryGateway) UpdateOrderTotals(orderId int, totals dto.OrderTotals) error {
+	return r.sqlClient.Transaction(func(tx sql.TransactionWrapper) error {
+		return updateEditableOrderTotals(tx, orderId, totals)
+	})
+}
+
+// UpdateOrderItemStatus sets the status of the item of an order IN_PROGRESS, moving the order to READY in the same
+// transaction once none of its items is pending. It returns whether the order moved to READY.
+func (r orderRepositoryGateway) UpdateOrderItemStatus(orderId int, itemId int, itemStatus string) (bool, error) {
+	advanced := false
+	err := r.sqlClient.Transaction(func(tx sql.TransactionWrapper) error {
+		result, err := tx.Exec(sqlscripts.UpdateOrderItemStatusCmd, orderId, itemId, itemStatus)
+		if err != nil {
+			return fmt.Errorf("failed to update order item status, error %w", err)
+		}
+
+		rowsAffect, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to check order item status update operation, error %w", err)
+		}
+
+		if rowsAffect < 1 {
+			return checkOrderInProgress(tx, orderId)
+		}
+
+		var pendingItems int
+		err = tx.FindOne(sqlscripts.CountPendingOrderItemsQuery, orderId).Scan(&pendingItems)
+		if err != nil {
+			return fmt.Errorf("failed to count pending order items, error %w", err)
+		}
+
+		if pendingItems > 0 {
+			return nil
+		}
+
+		result, err = tx.Exec(sqlscripts.AdvanceReadyOrderCmd, orderId)
+		if err != nil {
+			return fmt.Errorf("failed to advance order to READY, error %w", err)
+		}
+
+		rowsAffect, err = result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to check order advance operation, error %w", err)
+		}
+
+		advanced = rowsAffect > 0
+		return nil
+	})
 	if err != nil {
-		return -1, fmt.Errorf("failed to commit the transaction, error %w", err)
+		return false, err
 	}
 
-	return orderId, nil
+	return advanced, nil
 }
 
-func (r orderRepositoryGateway) UpdateOrderStatus(orderId int, orderStatus string) error {
-	result, err := r.sqlClient.Exec(sqlscripts.UpdateOrderStatusCmd, orderId, orderStatus)
+// checkOrderInProgress tells why no item was updated: a missing order, an order not IN_PROGRESS or a missing item.
+func checkOrderInProgress(tx sql.TransactionWrapper, orderId int) error {
+	var status string
+	err := tx.FindOne(sqlscripts.FindOrderStatusByIdQuery, orderId).Scan(&status)
+	if err != nil {
+		if errors.Is(err, dbsql.ErrNoRows) {
+			return sql.ErrNotFound
+		}
+		return fmt.Errorf("failed to find order status, error %w", err)
+	}
+
+	if dto.OrderStatus(status) != dto.OrderStatusInProgress {
+		return fmt.Errorf("%w, order is [%s]", dto.ErrOrderNotInProgress, status)
+	}
+
+	return dto.ErrOrderItemNotFound
+}
+
+// updateEditableOrderTotals only updates orders still CREATED, failing with dto.ErrOrderNotEditable otherwise.
+func updateEditableOrderTotals(tx sql.TransactionWrapper, orderId int, totals dto.OrderTotals) error {
+	result, err := tx.Exec(sqlscripts.UpdateEditableOrderTotalsCmd, orderId, totals.Subtotal, totals.Tax, totals.Total, totals.Discount, totals.StoreCredit)
+	if err != nil {
+		return fmt.Errorf("failed to update order totals, error %w", err)
+	}
+
+	rowsAffect, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check order totals update operation, error %w", err)
+	}
+
+	if rowsAffect < 1 {
+		return dto.ErrOrderNotEditable
+	}
+
+	return nil
+}
+
+// UpdateOrderStatus returns sql.ErrNotFound when no order was updated, also when an expectedStatus is given and the
+// order is no longer in it.
+func (r orderRepositoryGateway) UpdateOrderStatus(orderId int, orderStatus string, expectedStatus string) error {
+	result, err := r.sqlClient.Exec(sqlscripts.UpdateOrderStatusCmd, orderId, orderStatus, expectedStatus)
 	if err != nil {
 		return fmt.Errorf("failed to update order status, error %w", err)
 	}
@@ -114,26 +564,237 @@ func (r orderRepositoryGateway) UpdateOrderStatus(orderId int, orderStatus strin
 	return nil
 }
 
+func (r orderRepositoryGateway) ConfirmOrderPayment(orderId int) (bool, error) {
+	result, err := r.sqlClient.Exec(sqlscripts.ConfirmOrderPaymentCmd, orderId)
+	if err != nil {
+		return false, fmt.Errorf("failed to confirm order payment, error %w", err)
+	}
+
+	rowsAffect, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to check order payment confirmation, error %w", err)
+	}
+
+	return rowsAffect > 0, nil
+}
+
+// ReopenOrder moves the order back from DONE to READY, only when it was completed since completedSince, recording
+// the transition on the status history. It returns whether the order was reopened.
+func (r orderRepositoryGateway) ReopenOrder(orderId int, completedSince time.Time, reopenedAt time.Time) (bool, error) {
+	result, err := r.sqlClient.Exec(sqlscripts.ReopenOrderCmd, orderId, completedSince, reopenedAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to reopen order, error %w", err)
+	}
+
+	rowsAffect, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to check order reopening, error %w", err)
+	}
+
+	return rowsAffect > 0, nil
+}
+
+// CancelUnpaidOrders cancels the CREATED orders of the customer with the cpf, recording the transition on the status
+// history. It returns the ids of the orders cancelled.
+func (r orderRepositoryGateway) CancelUnpaidOrders(cpf string, cancelledAt time.Time) ([]int, error) {
+	var orderIds []int
+	// run within a transaction, as the writes read through Find are not retried on a deadlock
+	err := r.sqlClient.Transaction(func(tx sql.TransactionWrapper) error {
+		rows, err := tx.Find(sqlscripts.CancelUnpaidOrdersCmd, cpf, cancelledAt)
+		if err != nil {
+			return fmt.Errorf("failed to cancel unpaid orders of customer [%s], error %w", cpf, err)
+		}
+		defer rows.Close()
+
+		orderIds = []int{}
+		for rows.Next() {
+			var orderId int
+			err = rows.Scan(&orderId)
+			if err != nil {
+				return fmt.Errorf("failed to scan cancelled orders of customer [%s], error %w", cpf, err)
+			}
+
+			orderIds = append(orderIds, orderId)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return orderIds, nil
+}
+
+// GetOrderQRCode returns the payment qrcode stored for the order along with its current status.
+func (r orderRepositoryGateway) GetOrderQRCode(orderId int) (dto.PaymentQRCode, string, error) {
+	row := r.sqlClient.FindOne(sqlscripts.FindOrderQRCodeByIdQuery, orderId)
+
+	var qrCode dto.PaymentQRCode
+	var status string
+	err := row.Scan(&qrCode.QRCode, &qrCode.ExpiresAt, &status)
+	if err != nil {
+		if errors.Is(err, dbsql.ErrNoRows) {
+			return dto.PaymentQRCode{}, "", sql.ErrNotFound
+		}
+		return dto.PaymentQRCode{}, "", fmt.Errorf("failed to find order qrcode, error %w", err)
+	}
+
+	return qrCode, status, nil
+}
+
+func (r orderRepositoryGateway) UpdateOrderQRCode(orderId int, qrCode dto.PaymentQRCode) error {
+	result, err := r.sqlClient.Exec(sqlscripts.UpdateOrderQRCodeCmd, orderId, qrCode.QRCode, qrCode.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to update order qrcode, error %w", err)
+	}
+
+	rowsAffect, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check order qrcode update operation, error %w", err)
+	}
+
+	if rowsAffect < 1 {
+		return sql.ErrNotFound
+	}
+
+	return nil
+}
+
+// nextDailyOrderSequence atomically increments the order counter of the given day, starting from 1.
+func nextDailyOrderSequence(tx sql.TransactionWrapper, day time.Time) (int, error) {
+	row := tx.ExecWithReturn(sqlscripts.NextDailyOrderSequenceCmd, day.Format(time.DateOnly))
+
+	var sequence int
+	err := row.Scan(&sequence)
+	if err != nil {
+		return 0, fmt.Errorf("failed to increment daily order sequence, error %w", err)
+	}
+
+	return sequence, nil
+}
+
 func (r orderRepositoryGateway) getOrderItems(orderId int) ([]entities.OrderItem, error) {
 	rows, err := r.sqlClient.Find(sqlscripts.FindOrderItems, orderId)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find order items, error %w", err)
 	}
+	defer rows.Close()
 
 	orderItems := []entities.OrderItem{}
 	for rows.Next() {
 		var orderItem entities.OrderItem
 		var product entities.Product
+		// the product and variant are left joined, and the quantity and type columns are nullable
+		var productId, variantId, quantity *int
+		var productName, variantName, variantSkuId, itemType *string
+		var variantPrice *float64
+		var skuId, description, category *string
+		var price *float64
 
-		err = rows.Scan(&orderItem.ID, &product.ID, &product.Name, &product.SkuId, &product.Description,
-			&product.Category, &product.Price, &product.CreatedAt, &product.UpdatedAt, &orderItem.Quantity, &orderItem.Type)
+		err = rows.Scan(&orderItem.ID, &productId, &productName, &skuId, &description,
+			&category, &price, &product.CreatedAt, &product.UpdatedAt, &quantity, &itemType,
+			&variantId, &variantName, &variantSkuId, &variantPrice, &orderItem.Status)
 		if err != nil {
 			return nil, err
 		}
+		product.ID = valueOrZero(productId)
+		product.Name = valueOrZero(productName)
+		product.SkuId = valueOrZero(skuId)
+		product.Description = valueOrZero(description)
+		product.Category = valueOrZero(category)
+		product.Price = valueOrZero(price)
+		orderItem.Quantity = valueOrZero(quantity)
+		orderItem.Type = valueOrZero(itemType)
 
+		if variantId != nil {
+			orderItem.Variant = &entities.ProductVariant{
+				ID:        *variantId,
+				ProductID: product.ID,
+				Name:      valueOrZero(variantName),
+				SkuId:     valueOrZero(variantSkuId),
+				Price:     valueOrZero(variantPrice),
+			}
+		}
 		orderItem.Product = product
 		orderItems = append(orderItems, orderItem)
 	}
 
 	return orderItems, nil
 }
+
+// SaveOrderFeedback stores the single feedback allowed per order, failing with dto.ErrOrderFeedbackAlreadySent
+// when the order was already rated.
+func (r orderRepositoryGateway) SaveOrderFeedback(feedback entities.OrderFeedback) (int, error) {
+	row := r.sqlClient.ExecWithReturn(sqlscripts.InsertOrderFeedbackCmd, feedback.OrderID, feedback.Rating, feedback.Comment, feedback.CreatedAt)
+
+	var feedbackId int
+	err := row.Scan(&feedbackId)
+	if err != nil {
+		if errors.Is(err, dbsql.ErrNoRows) {
+			return -1, dto.ErrOrderFeedbackAlreadySent
+		}
+		return -1, fmt.Errorf("failed to save order feedback, error %w", err)
+	}
+
+	return feedbackId, nil
+}
+
+// AnonymizeDoneOrders removes the personal data of the DONE orders created before createdBefore, keeping the amounts and
+// items for the reports. Orders already anonymized are skipped, and the number of orders anonymized now is returned.
+func (r orderRepositoryGateway) AnonymizeDoneOrders(createdBefore time.Time, anonymizedAt time.Time) (int, error) {
+	row := r.sqlClient.ExecWithReturn(sqlscripts.AnonymizeDoneOrdersCmd, createdBefore, anonymizedAt)
+
+	var anonymized int
+	err := row.Scan(&anonymized)
+	if err != nil {
+		return 0, fmt.Errorf("failed to anonymize done orders, error %w", err)
+	}
+
+	return anonymized, nil
+}
+
+// buildOrderFiltersCondition builds the WHERE condition of the order lists, after the limit, offset and fulfillment type args.
+func buildOrderFiltersCondition(pageParams dto.PageParams, filters dto.OrderFilters) (string, []any) {
+	args := []any{pageParams.GetLimit(), pageParams.GetOffset(), filters.FulfillmentType}
+	condition, args := buildStatusCondition(filters.Statuses, args)
+	if !filters.CreatedFrom.IsZero() {
+		args = append(args, filters.CreatedFrom, filters.CreatedBefore)
+		condition += fmt.Sprintf(" AND o.created_at >= $%d AND o.created_at < $%d", len(args)-1, len(args))
+	}
+
+	return condition, args
+}
+
+func orderSortClause(sort dto.OrderSort) string {
+	switch sort {
+	case dto.OrderSortOldest:
+		return sqlscripts.OrderSortOldestClause
+	case dto.OrderSortPriority:
+		return sqlscripts.OrderSortPriorityClause
+	}
+	return sqlscripts.OrderSortNewestClause
+}
+
+// buildStatusCondition filters the orders by all the given statuses in a single IN clause,
+// appending one positional parameter per status.
+func buildStatusCondition(statuses []string, args []any) (string, []any) {
+	if len(statuses) == 0 {
+		return sqlscripts.DefaultOrdersStatusCondition, args
+	}
+
+	placeholders, args := buildPlaceholders(statuses, args)
+
+	return fmt.Sprintf("o.status IN (%s)", placeholders), args
+}
+
+// buildPlaceholders appends one positional parameter per value, returning their comma separated placeholders.
+func buildPlaceholders(values []string, args []any) (string, []any) {
+	placeholders := make([]string, len(values))
+	for i, value := range values {
+		args = append(args, value)
+		placeholders[i] = fmt.Sprintf("$%d", len(args))
+	}
+
+	return strings.Join(placeholders, ", "), args
+}
@@ -0,0 +1,700 @@
+package gateways
+
+import (
+	"errors"
+	"fmt"
+	"g37-lanchonete/internal/core/entities"
+	"g37-lanchonete/internal/core/usecases/dto"
+	"g37-lanchonete/internal/infra/drivers/sql"
+	mock_sql "g37-lanchonete/internal/infra/drivers/sql/mocks"
+	"g37-lanchonete/internal/infra/gateways/sqlscripts"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestOrderRepositoryGateway_FindAllOrders_StatusFilter(t *testing.T) {
+	tests := []struct {
+		name          string
+		statuses      []string
+		wantCondition string
+		wantArgs      []any
+	}{
+		{
+			name:          "should keep listing the orders not done nor cancelled when no status is given",
+			wantCondition: "WHERE o.status NOT IN ('DONE', 'CANCELLED')",
+			wantArgs:      []any{10, 0, ""},
+		},
+		{
+			name:          "should filter several statuses in a single IN clause",
+			statuses:      []string{"RECEIVED", "IN_PROGRESS", "READY"},
+			wantCondition: "WHERE o.status IN ($4, $5, $6)",
+			wantArgs:      []any{10, 0, "", "RECEIVED", "IN_PROGRESS", "READY"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			sqlClient := mock_sql.NewMockSQLClient(ctrl)
+			rows := mock_sql.NewMockRowsWrapper(ctrl)
+			orderRepositoryGateway := NewOrderRepositoryGateway(sqlClient, "", nil)
+
+			var query string
+			var args []any
+			sqlClient.EXPECT().
+				Find(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(q string, a ...any) (sql.RowsWrapper, error) {
+					query, args = q, a
+					return rows, nil
+				}).
+				Times(1)
+			rows.EXPECT().Next().Return(false).Times(1)
+
+			orders, err := orderRepositoryGateway.FindAllOrders(dto.NewPageParams(0, 10), dto.OrderFilters{Statuses: tt.statuses})
+
+			assert.NoError(t, err)
+			assert.Empty(t, orders)
+			assert.True(t, strings.Contains(query, tt.wantCondition), query)
+			assert.Equal(t, tt.wantArgs, args)
+		})
+	}
+}
+
+func TestOrderRepositoryGateway_FindAllOrders_Sort(t *testing.T) {
+	tests := []struct {
+		name        string
+		defaultSort string
+		sort        dto.OrderSort
+		wantOrderBy string
+	}{
+		{
+			name:        "should list the newest orders first by default",
+			wantOrderBy: "ORDER BY o.created_at DESC, o.id DESC",
+		},
+		{
+			name:        "should list the orders in the configured default sort",
+			defaultSort: "priority",
+			wantOrderBy: "ORDER BY array_position(array['READY','IN_PROGRESS','RECEIVED'], o.status), o.created_at ASC, o.id ASC",
+		},
+		{
+			name:        "should list the orders in the sort asked over the default",
+			defaultSort: "priority",
+			sort:        dto.OrderSortOldest,
+			wantOrderBy: "ORDER BY o.created_at ASC, o.id ASC",
+		},
+		{
+			name:        "should list the newest orders first when the configured default sort is unknown",
+			defaultSort: "cheapest",
+			wantOrderBy: "ORDER BY o.created_at DESC, o.id DESC",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			sqlClient := mock_sql.NewMockSQLClient(ctrl)
+			rows := mock_sql.NewMockRowsWrapper(ctrl)
+			orderRepositoryGateway := NewOrderRepositoryGateway(sqlClient, tt.defaultSort, nil)
+
+			var query string
+			sqlClient.EXPECT().
+				Find(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(q string, a ...any) (sql.RowsWrapper, error) {
+					query = q
+					return rows, nil
+				}).
+				Times(1)
+			rows.EXPECT().Next().Return(false).Times(1)
+
+			_, err := orderRepositoryGateway.FindAllOrders(dto.NewPageParams(0, 10), dto.OrderFilters{Sort: tt.sort})
+
+			assert.NoError(t, err)
+			assert.True(t, strings.Contains(query, tt.wantOrderBy), query)
+		})
+	}
+}
+
+func TestOrderRepositoryGateway_FindAllOrders_CreatedAtBounds(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	sqlClient := mock_sql.NewMockSQLClient(ctrl)
+	rows := mock_sql.NewMockRowsWrapper(ctrl)
+	orderRepositoryGateway := NewOrderRepositoryGateway(sqlClient, "", nil)
+	from := time.Date(2024, 1, 1, 3, 0, 0, 0, time.UTC)
+	before := from.AddDate(0, 0, 1)
+
+	var query string
+	var args []any
+	sqlClient.EXPECT().
+		Find(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(q string, a ...any) (sql.RowsWrapper, error) {
+			query, args = q, a
+			return rows, nil
+		}).
+		Times(1)
+	rows.EXPECT().Next().Return(false).Times(1)
+
+	_, err := orderRepositoryGateway.FindAllOrders(dto.NewPageParams(0, 10), dto.OrderFilters{Statuses: []string{"READY"}, CreatedFrom: from, CreatedBefore: before})
+
+	assert.NoError(t, err)
+	assert.True(t, strings.Contains(query, "WHERE o.status IN ($4) AND o.created_at >= $5 AND o.created_at < $6"), query)
+	assert.Equal(t, []any{10, 0, "", "READY", from, before}, args)
+}
+
+func TestOrderRepositoryGateway_FindOrderById_CouponWithoutRecord(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	sqlClient := mock_sql.NewMockSQLClient(ctrl)
+	row := mock_sql.NewMockRowWrapper(ctrl)
+	itemRows := mock_sql.NewMockRowsWrapper(ctrl)
+	orderRepositoryGateway := NewOrderRepositoryGateway(sqlClient, "", nil)
+
+	// the coupon is read as stored on the order, there is no coupon record to look up
+	var query string
+	sqlClient.EXPECT().
+		FindOne(gomock.Any(), 7).
+		DoAndReturn(func(q string, a ...any) sql.RowWrapper {
+			query = q
+			return row
+		}).
+		Times(1)
+	row.EXPECT().
+		Scan(gomock.Any()).
+		DoAndReturn(func(dest ...any) error {
+			*dest[0].(*int) = 7
+			*dest[2].(*string) = "APP10"
+			status := "DONE"
+			*dest[6].(**string) = &status
+			return nil
+		}).
+		Times(1)
+	sqlClient.EXPECT().Find(gomock.Any(), 7).Return(itemRows, nil).Times(1)
+	itemRows.EXPECT().Next().Return(false).Times(1)
+	itemRows.EXPECT().Close().Return(nil).Times(1)
+
+	order, err := orderRepositoryGateway.FindOrderById(7)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "APP10", order.Coupon)
+	assert.Equal(t, "DONE", order.Status)
+	assert.True(t, strings.Contains(query, "COALESCE(o.coupon, '')"), query)
+}
+
+func TestOrderRepositoryGateway_FindOrderById_NullColumns(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	sqlClient := mock_sql.NewMockSQLClient(ctrl)
+	row := mock_sql.NewMockRowWrapper(ctrl)
+	itemRows := mock_sql.NewMockRowsWrapper(ctrl)
+	orderRepositoryGateway := NewOrderRepositoryGateway(sqlClient, "", nil)
+
+	sqlClient.EXPECT().FindOne(gomock.Any(), 7).Return(row).Times(1)
+	row.EXPECT().
+		Scan(gomock.Any()).
+		DoAndReturn(func(dest ...any) error {
+			*dest[0].(*int) = 7
+			// the subtotal, total and status columns are NULL
+			*dest[3].(**float64) = nil
+			*dest[5].(**float64) = nil
+			*dest[6].(**string) = nil
+			return nil
+		}).
+		Times(1)
+	sqlClient.EXPECT().Find(gomock.Any(), 7).Return(itemRows, nil).Times(1)
+	gomock.InOrder(
+		itemRows.EXPECT().Next().Return(true),
+		itemRows.EXPECT().Next().Return(false),
+	)
+	itemRows.EXPECT().
+		Scan(gomock.Any()).
+		DoAndReturn(func(dest ...any) error {
+			*dest[0].(*int) = 70
+			productId, productName := 3, "X-Burger"
+			*dest[1].(**int) = &productId
+			*dest[2].(**string) = &productName
+			// the quantity and type columns are NULL, and the variant has no name nor price
+			*dest[9].(**int) = nil
+			*dest[10].(**string) = nil
+			variantId := 11
+			*dest[11].(**int) = &variantId
+			*dest[12].(**string) = nil
+			*dest[14].(**float64) = nil
+			return nil
+		}).
+		Times(1)
+	itemRows.EXPECT().Close().Return(nil).Times(1)
+
+	order, err := orderRepositoryGateway.FindOrderById(7)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 7, order.ID)
+	assert.Zero(t, order.SubtotalAmount)
+	assert.Zero(t, order.TotalAmount)
+	assert.Empty(t, order.Status)
+	assert.Len(t, order.Items, 1)
+	assert.Equal(t, 3, order.Items[0].Product.ID)
+	assert.Equal(t, "X-Burger", order.Items[0].Product.Name)
+	assert.Zero(t, order.Items[0].Quantity)
+	assert.Empty(t, order.Items[0].Type)
+	assert.Equal(t, &entities.ProductVariant{ID: 11, ProductID: 3}, order.Items[0].Variant)
+}
+
+func TestOrderRepositoryGateway_FindQueueOrders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	sqlClient := mock_sql.NewMockSQLClient(ctrl)
+	rows := mock_sql.NewMockRowsWrapper(ctrl)
+	itemRows := mock_sql.NewMockRowsWrapper(ctrl)
+	orderRepositoryGateway := NewOrderRepositoryGateway(sqlClient, "", nil)
+
+	var query, itemsQuery string
+	var args []any
+	sqlClient.EXPECT().
+		Find(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(q string, a ...any) (sql.RowsWrapper, error) {
+			query, args = q, a
+			return rows, nil
+		}).
+		Times(1)
+	gomock.InOrder(
+		rows.EXPECT().Next().Return(true),
+		rows.EXPECT().Next().Return(false),
+	)
+	rows.EXPECT().
+		Scan(gomock.Any()).
+		DoAndReturn(func(dest ...any) error {
+			*dest[0].(*int) = 7
+			*dest[2].(*string) = "IN_PROGRESS"
+			return nil
+		}).
+		Times(1)
+	rows.EXPECT().Close().Return(nil).Times(1)
+	sqlClient.EXPECT().
+		Find(gomock.Any(), 7).
+		DoAndReturn(func(q string, a ...any) (sql.RowsWrapper, error) {
+			itemsQuery = q
+			return itemRows, nil
+		}).
+		Times(1)
+	gomock.InOrder(
+		itemRows.EXPECT().Next().Return(true),
+		itemRows.EXPECT().Next().Return(false),
+	)
+	itemRows.EXPECT().
+		Scan(gomock.Any()).
+		DoAndReturn(func(dest ...any) error {
+			*dest[0].(*string) = "X-Burger"
+			*dest[2].(*int) = 2
+			*dest[3].(*string) = "UNIT"
+			return nil
+		}).
+		Times(1)
+	itemRows.EXPECT().Close().Return(nil).Times(1)
+
+	orders, err := orderRepositoryGateway.FindQueueOrders(dto.NewPageParams(0, 10), dto.OrderFilters{Statuses: []string{"IN_PROGRESS"}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []dto.QueueOrderDTO{{
+		ID:     7,
+		Status: "IN_PROGRESS",
+		Items:  []dto.QueueOrderItemDTO{{Name: "X-Burger", Quantity: 2, Type: "UNIT"}},
+	}}, orders)
+	assert.True(t, strings.Contains(query, "WHERE o.status IN ($4)"), query)
+	assert.Equal(t, []any{10, 0, "", "IN_PROGRESS"}, args)
+	assert.True(t, strings.Contains(query, "o.created_at ASC, o.id ASC"), query)
+	assert.False(t, strings.Contains(query, "customers"), query)
+	assert.False(t, strings.Contains(itemsQuery, "p.description"), itemsQuery)
+}
+
+func TestOrderRepositoryGateway_FindQueueOrders_Station(t *testing.T) {
+	stations := map[string][]string{"Grill": {"Lanche"}, "fryer": {"Acompanhamento", "Porção"}, "drinks": {"Bebida"}}
+
+	t.Run("should list only the orders and items prepared at the station", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		sqlClient := mock_sql.NewMockSQLClient(ctrl)
+		rows := mock_sql.NewMockRowsWrapper(ctrl)
+		itemRows := mock_sql.NewMockRowsWrapper(ctrl)
+		orderRepositoryGateway := NewOrderRepositoryGateway(sqlClient, "", stations)
+
+		var query, itemsQuery string
+		var args, itemsArgs []any
+		sqlClient.EXPECT().
+			Find(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(q string, a ...any) (sql.RowsWrapper, error) {
+				query, args = q, a
+				return rows, nil
+			}).
+			Times(1)
+		gomock.InOrder(
+			rows.EXPECT().Next().Return(true),
+			rows.EXPECT().Next().Return(false),
+		)
+		rows.EXPECT().
+			Scan(gomock.Any()).
+			DoAndReturn(func(dest ...any) error {
+				*dest[0].(*int) = 7
+				*dest[2].(*string) = "RECEIVED"
+				return nil
+			}).
+			Times(1)
+		rows.EXPECT().Close().Return(nil).Times(1)
+		sqlClient.EXPECT().
+			Find(gomock.Any(), 7, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(q string, a ...any) (sql.RowsWrapper, error) {
+				itemsQuery, itemsArgs = q, a
+				return itemRows, nil
+			}).
+			Times(1)
+		gomock.InOrder(
+			itemRows.EXPECT().Next().Return(true),
+			itemRows.EXPECT().Next().Return(false),
+		)
+		itemRows.EXPECT().
+			Scan(gomock.Any()).
+			DoAndReturn(func(dest ...any) error {
+				*dest[0].(*string) = "Batata Frita"
+				*dest[2].(*int) = 1
+				*dest[3].(*string) = "UNIT"
+				*dest[4].(*[]byte) = []byte(`["Promoção","Acompanhamento"]`)
+				return nil
+			}).
+			Times(1)
+		itemRows.EXPECT().Close().Return(nil).Times(1)
+
+		orders, err := orderRepositoryGateway.FindQueueOrders(dto.NewPageParams(0, 10), dto.OrderFilters{Station: "Fryer"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []dto.QueueOrderDTO{{
+			ID:     7,
+			Status: "RECEIVED",
+			Items:  []dto.QueueOrderItemDTO{{Name: "Batata Frita", Quantity: 1, Type: "UNIT", Station: "fryer"}},
+		}}, orders)
+		assert.True(t, strings.Contains(query, "jsonb_array_elements_text(sp.categories)"), query)
+		assert.True(t, strings.Contains(query, "WHERE si.order_id = o.id AND LOWER(sc.category) IN ($4, $5)"), query)
+		assert.Equal(t, []any{10, 0, "", "acompanhamento", "porção"}, args)
+		assert.True(t, strings.Contains(itemsQuery, "jsonb_array_elements_text(p.categories) AS c(category) WHERE LOWER(c.category) IN ($2, $3)"), itemsQuery)
+		assert.Equal(t, []any{7, "acompanhamento", "porção"}, itemsArgs)
+	})
+
+	t.Run("should route each item by the first of its categories prepared at a station", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		sqlClient := mock_sql.NewMockSQLClient(ctrl)
+		rows := mock_sql.NewMockRowsWrapper(ctrl)
+		itemRows := mock_sql.NewMockRowsWrapper(ctrl)
+		orderRepositoryGateway := NewOrderRepositoryGateway(sqlClient, "", stations)
+
+		sqlClient.EXPECT().
+			Find(gomock.Any(), gomock.Any()).
+			Return(rows, nil).
+			Times(1)
+		gomock.InOrder(
+			rows.EXPECT().Next().Return(true),
+			rows.EXPECT().Next().Return(false),
+		)
+		rows.EXPECT().
+			Scan(gomock.Any()).
+			DoAndReturn(func(dest ...any) error {
+				*dest[0].(*int) = 7
+				*dest[2].(*string) = "RECEIVED"
+				return nil
+			}).
+			Times(1)
+		rows.EXPECT().Close().Return(nil).Times(1)
+		sqlClient.EXPECT().
+			Find(gomock.Any(), 7).
+			Return(itemRows, nil).
+			Times(1)
+		gomock.InOrder(
+			itemRows.EXPECT().Next().Return(true),
+			itemRows.EXPECT().Next().Return(true),
+			itemRows.EXPECT().Next().Return(false),
+		)
+		gomock.InOrder(
+			itemRows.EXPECT().
+				Scan(gomock.Any()).
+				DoAndReturn(func(dest ...any) error {
+					*dest[0].(*string) = "Combo X-Burguer"
+					*dest[2].(*int) = 1
+					*dest[3].(*string) = "UNIT"
+					*dest[4].(*[]byte) = []byte(`["Promoção","Lanche","Bebida"]`)
+					return nil
+				}),
+			itemRows.EXPECT().
+				Scan(gomock.Any()).
+				DoAndReturn(func(dest ...any) error {
+					*dest[0].(*string) = "Sorvete"
+					*dest[2].(*int) = 1
+					*dest[3].(*string) = "UNIT"
+					*dest[4].(*[]byte) = []byte(`["Sobremesa"]`)
+					return nil
+				}),
+		)
+		itemRows.EXPECT().Close().Return(nil).Times(1)
+
+		orders, err := orderRepositoryGateway.FindQueueOrders(dto.NewPageParams(0, 10), dto.OrderFilters{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []dto.QueueOrderItemDTO{
+			{Name: "Combo X-Burguer", Quantity: 1, Type: "UNIT", Station: "grill"},
+			{Name: "Sorvete", Quantity: 1, Type: "UNIT"},
+		}, orders[0].Items)
+	})
+
+	t.Run("should reject a station that is not configured", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		sqlClient := mock_sql.NewMockSQLClient(ctrl)
+		orderRepositoryGateway := NewOrderRepositoryGateway(sqlClient, "", stations)
+
+		orders, err := orderRepositoryGateway.FindQueueOrders(dto.NewPageParams(0, 10), dto.OrderFilters{Station: "oven"})
+
+		assert.ErrorIs(t, err, dto.ErrUnknownStation)
+		assert.Nil(t, orders)
+	})
+}
+
+func TestOrderRepositoryGateway_UpdateOrderItemStatus(t *testing.T) {
+	tests := []struct {
+		name         string
+		pendingItems int
+		wantAdvanced bool
+	}{
+		{
+			name:         "should keep the order IN_PROGRESS while other items are pending",
+			pendingItems: 1,
+		},
+		{
+			name:         "should move the order to READY along with its last pending item",
+			pendingItems: 0,
+			wantAdvanced: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			sqlClient := mock_sql.NewMockSQLClient(ctrl)
+			tx := mock_sql.NewMockTransactionWrapper(ctrl)
+			countRow := mock_sql.NewMockRowWrapper(ctrl)
+			itemResult := mock_sql.NewMockResultWrapper(ctrl)
+			orderResult := mock_sql.NewMockResultWrapper(ctrl)
+			orderRepositoryGateway := NewOrderRepositoryGateway(sqlClient, "", nil)
+
+			advanceCalls := 0
+			if tt.wantAdvanced {
+				advanceCalls = 1
+			}
+
+			sqlClient.EXPECT().
+				Transaction(gomock.Any()).
+				DoAndReturn(func(fn func(tx sql.TransactionWrapper) error) error {
+					return fn(tx)
+				}).
+				Times(1)
+			tx.EXPECT().Exec(sqlscripts.UpdateOrderItemStatusCmd, 7, 3, "READY").Return(itemResult, nil).Times(1)
+			itemResult.EXPECT().RowsAffected().Return(int64(1), nil).Times(1)
+			tx.EXPECT().FindOne(sqlscripts.CountPendingOrderItemsQuery, 7).Return(countRow).Times(1)
+			countRow.EXPECT().
+				Scan(gomock.Any()).
+				DoAndReturn(func(dest ...any) error {
+					*dest[0].(*int) = tt.pendingItems
+					return nil
+				}).
+				Times(1)
+			tx.EXPECT().Exec(sqlscripts.AdvanceReadyOrderCmd, 7).Return(orderResult, nil).Times(advanceCalls)
+			orderResult.EXPECT().RowsAffected().Return(int64(1), nil).Times(advanceCalls)
+
+			advanced, err := orderRepositoryGateway.UpdateOrderItemStatus(7, 3, "READY")
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantAdvanced, advanced)
+		})
+	}
+}
+
+func TestOrderRepositoryGateway_UpdateOrderItemStatus_OrderNotInProgress(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	sqlClient := mock_sql.NewMockSQLClient(ctrl)
+	tx := mock_sql.NewMockTransactionWrapper(ctrl)
+	statusRow := mock_sql.NewMockRowWrapper(ctrl)
+	itemResult := mock_sql.NewMockResultWrapper(ctrl)
+	orderRepositoryGateway := NewOrderRepositoryGateway(sqlClient, "", nil)
+
+	sqlClient.EXPECT().
+		Transaction(gomock.Any()).
+		DoAndReturn(func(fn func(tx sql.TransactionWrapper) error) error {
+			return fn(tx)
+		}).
+		Times(1)
+	tx.EXPECT().Exec(sqlscripts.UpdateOrderItemStatusCmd, 7, 3, "READY").Return(itemResult, nil).Times(1)
+	itemResult.EXPECT().RowsAffected().Return(int64(0), nil).Times(1)
+	tx.EXPECT().FindOne(sqlscripts.FindOrderStatusByIdQuery, 7).Return(statusRow).Times(1)
+	statusRow.EXPECT().
+		Scan(gomock.Any()).
+		DoAndReturn(func(dest ...any) error {
+			*dest[0].(*string) = "RECEIVED"
+			return nil
+		}).
+		Times(1)
+
+	_, err := orderRepositoryGateway.UpdateOrderItemStatus(7, 3, "READY")
+
+	assert.ErrorIs(t, err, dto.ErrOrderNotInProgress)
+}
+
+func TestOrderRepositoryGateway_CancelUnpaidOrders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	sqlClient := mock_sql.NewMockSQLClient(ctrl)
+	tx := mock_sql.NewMockTransactionWrapper(ctrl)
+	rows := mock_sql.NewMockRowsWrapper(ctrl)
+	orderRepositoryGateway := NewOrderRepositoryGateway(sqlClient, "", nil)
+	cancelledAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	sqlClient.EXPECT().
+		Transaction(gomock.Any()).
+		DoAndReturn(func(fn func(tx sql.TransactionWrapper) error) error {
+			return fn(tx)
+		}).
+		Times(1)
+	tx.EXPECT().
+		Find(sqlscripts.CancelUnpaidOrdersCmd, gomock.Eq("00551146010"), gomock.Eq(cancelledAt)).
+		Return(rows, nil).
+		Times(1)
+	gomock.InOrder(
+		rows.EXPECT().Next().Return(true),
+		rows.EXPECT().Next().Return(true),
+		rows.EXPECT().Next().Return(false),
+	)
+	gomock.InOrder(
+		rows.EXPECT().Scan(gomock.Any()).DoAndReturn(func(dest ...any) error { *dest[0].(*int) = 7; return nil }),
+		rows.EXPECT().Scan(gomock.Any()).DoAndReturn(func(dest ...any) error { *dest[0].(*int) = 9; return nil }),
+	)
+	rows.EXPECT().Close().Return(nil).Times(1)
+
+	orderIds, err := orderRepositoryGateway.CancelUnpaidOrders("00551146010", cancelledAt)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []int{7, 9}, orderIds)
+}
+
+func TestOrderRepositoryGateway_GetStatusTransitionStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	sqlClient := mock_sql.NewMockSQLClient(ctrl)
+	rows := mock_sql.NewMockRowsWrapper(ctrl)
+	orderRepositoryGateway := NewOrderRepositoryGateway(sqlClient, "", nil)
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	sqlClient.EXPECT().
+		Find(sqlscripts.GetStatusTransitionStatsQuery, gomock.Eq(from), gomock.Eq(to)).
+		Return(rows, nil).
+		Times(1)
+	gomock.InOrder(
+		rows.EXPECT().Next().Return(true),
+		rows.EXPECT().Next().Return(false),
+	)
+	rows.EXPECT().
+		Scan(gomock.Any()).
+		DoAndReturn(func(dest ...any) error {
+			*dest[0].(*dto.OrderStatus) = dto.OrderStatusInProgress
+			*dest[1].(*dto.OrderStatus) = dto.OrderStatusReady
+			*dest[2].(*int) = 40
+			*dest[3].(*float64) = 540
+			*dest[4].(*float64) = 480
+			*dest[5].(*float64) = 960
+			*dest[6].(*float64) = 1140
+			return nil
+		}).
+		Times(1)
+	rows.EXPECT().Close().Return(nil).Times(1)
+
+	stats, err := orderRepositoryGateway.GetStatusTransitionStats(from, to)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []dto.StatusTransitionStatsDTO{
+		{From: dto.OrderStatusInProgress, To: dto.OrderStatusReady, Count: 40, AverageSeconds: 540, P50Seconds: 480, P90Seconds: 960, P95Seconds: 1140},
+	}, stats)
+}
+
+func TestOrderRepositoryGateway_SaveOrder_Number(t *testing.T) {
+	createdAt := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	order := entities.Order{
+		Status:    "CREATED",
+		CreatedAt: entities.NewTimestamp(createdAt),
+		Items:     []entities.OrderItem{{Product: entities.Product{ID: 3}, Quantity: 2, Type: "Lanche"}},
+	}
+
+	t.Run("should save the number along with the order", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		sqlClient := mock_sql.NewMockSQLClient(ctrl)
+		tx := mock_sql.NewMockTransactionWrapper(ctrl)
+		orderRow := mock_sql.NewMockRowWrapper(ctrl)
+		sequenceRow := mock_sql.NewMockRowWrapper(ctrl)
+		orderRepositoryGateway := NewOrderRepositoryGateway(sqlClient, "", nil)
+
+		sqlClient.EXPECT().
+			Transaction(gomock.Any()).
+			DoAndReturn(func(fn func(tx sql.TransactionWrapper) error) error {
+				return fn(tx)
+			}).
+			Times(1)
+		tx.EXPECT().ExecWithReturn(sqlscripts.InsertOrderCmd, gomock.Any()).Return(orderRow).Times(1)
+		orderRow.EXPECT().Scan(gomock.Any()).DoAndReturn(func(dest ...any) error { *dest[0].(*int) = 98765; return nil }).Times(1)
+		tx.EXPECT().Exec(sqlscripts.InsertOrderItemCmd, 98765, 3, 2, "Lanche", gomock.Nil()).Return(nil, nil).Times(1)
+		tx.EXPECT().ExecWithReturn(sqlscripts.NextDailyOrderSequenceCmd, "2024-01-15").Return(sequenceRow).Times(1)
+		sequenceRow.EXPECT().Scan(gomock.Any()).DoAndReturn(func(dest ...any) error { *dest[0].(*int) = 42; return nil }).Times(1)
+		tx.EXPECT().Exec(sqlscripts.UpdateOrderNumberCmd, 98765, "042").Return(nil, nil).Times(1)
+
+		orderId, number, err := orderRepositoryGateway.SaveOrder(order, func(saved entities.Order, nextDailySequence func() (int, error)) (string, error) {
+			assert.Equal(t, 98765, saved.ID)
+			sequence, err := nextDailySequence()
+			return fmt.Sprintf("%03d", sequence), err
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 98765, orderId)
+		assert.Equal(t, "042", number)
+	})
+
+	t.Run("should fail the transaction when the number can not be generated", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		sqlClient := mock_sql.NewMockSQLClient(ctrl)
+		tx := mock_sql.NewMockTransactionWrapper(ctrl)
+		orderRow := mock_sql.NewMockRowWrapper(ctrl)
+		orderRepositoryGateway := NewOrderRepositoryGateway(sqlClient, "", nil)
+
+		sqlClient.EXPECT().
+			Transaction(gomock.Any()).
+			DoAndReturn(func(fn func(tx sql.TransactionWrapper) error) error {
+				return fn(tx)
+			}).
+			Times(1)
+		tx.EXPECT().ExecWithReturn(sqlscripts.InsertOrderCmd, gomock.Any()).Return(orderRow).Times(1)
+		orderRow.EXPECT().Scan(gomock.Any()).DoAndReturn(func(dest ...any) error { *dest[0].(*int) = 98765; return nil }).Times(1)
+		tx.EXPECT().Exec(sqlscripts.InsertOrderItemCmd, gomock.Any()).Return(nil, nil).Times(1)
+
+		_, _, err := orderRepositoryGateway.SaveOrder(order, func(entities.Order, func() (int, error)) (string, error) {
+			return "", errors.New("connection refused")
+		})
+
+		assert.EqualError(t, err, "failed to generate order number, error connection refused")
+	})
+}
+
+func TestOrderRepositoryGateway_UpdateOrderTotals_StoreCredit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	sqlClient := mock_sql.NewMockSQLClient(ctrl)
+	tx := mock_sql.NewMockTransactionWrapper(ctrl)
+	result := mock_sql.NewMockResultWrapper(ctrl)
+	orderRepositoryGateway := NewOrderRepositoryGateway(sqlClient, "", nil)
+
+	sqlClient.EXPECT().
+		Transaction(gomock.Any()).
+		DoAndReturn(func(fn func(tx sql.TransactionWrapper) error) error {
+			return fn(tx)
+		}).
+		Times(1)
+	// the credit clamped to the new total is stored along with it
+	tx.EXPECT().Exec(sqlscripts.UpdateEditableOrderTotalsCmd, 7, 20.0, 0.0, 0.0, 2.5, 17.5).Return(result, nil).Times(1)
+	result.EXPECT().RowsAffected().Return(int64(1), nil).Times(1)
+
+	err := orderRepositoryGateway.UpdateOrderTotals(7, dto.OrderTotals{Subtotal: 20, Discount: 2.5, StoreCredit: 17.5, Total: 0})
+
+	assert.NoError(t, err)
+}
@@ -0,0 +1,52 @@
+package gateways
+
+import (
+	"fmt"
+	"g37-lanchonete/internal/core/entities"
+	"g37-lanchonete/internal/infra/drivers/sql"
+	"g37-lanchonete/internal/infra/gateways/sqlscripts"
+)
+
+type ProductPriceHistoryRepositoryGateway interface {
+	SavePriceChange(priceChange entities.ProductPriceChange) error
+	FindPriceHistory(productId int) ([]entities.ProductPriceChange, error)
+}
+
+type productPriceHistoryRepositoryGateway struct {
+	sqlClient sql.SQLClient
+}
+
+func NewProductPriceHistoryRepositoryGateway(sqlClient sql.SQLClient) ProductPriceHistoryRepositoryGateway {
+	return productPriceHistoryRepositoryGateway{
+		sqlClient: sqlClient,
+	}
+}
+
+func (r productPriceHistoryRepositoryGateway) SavePriceChange(priceChange entities.ProductPriceChange) error {
+	_, err := r.sqlClient.Exec(sqlscripts.InsertProductPriceChangeCmd, priceChange.ProductID, priceChange.Price, priceChange.ChangedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save price change of product [%d], error %w", priceChange.ProductID, err)
+	}
+
+	return nil
+}
+
+func (r productPriceHistoryRepositoryGateway) FindPriceHistory(productId int) ([]entities.ProductPriceChange, error) {
+	rows, err := r.sqlClient.Find(sqlscripts.FindProductPriceHistoryQuery, productId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find price history of product [%d], error %w", productId, err)
+	}
+	defer rows.Close()
+
+	history := []entities.ProductPriceChange{}
+	for rows.Next() {
+		var priceChange entities.ProductPriceChange
+		err = rows.Scan(&priceChange.ProductID, &priceChange.Price, &priceChange.ChangedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan price history, error %w", err)
+		}
+		history = append(history, priceChange)
+	}
+
+	return history, nil
+}
@@ -1,29 +1,61 @@
 package gateways
 
 import (
+	dbsql "database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"g37-lanchonete/internal/core/entities"
 	"g37-lanchonete/internal/core/usecases/dto"
 	"g37-lanchonete/internal/infra/drivers/sql"
 	"g37-lanchonete/internal/infra/gateways/sqlscripts"
+	"strings"
+	"time"
 )
 
 type ProductRepositoryGateway interface {
 	FindAllProducts(pageParams dto.PageParams) ([]entities.Product, error)
 	FindProductsByCategory(pageParams dto.PageParams, category string) ([]entities.Product, error)
 	FindProductById(id int) (entities.Product, error)
+	FindProductBySKU(skuId string) (entities.Product, error)
+	FindMenuProducts() ([]entities.Product, error)
+	FindUnavailableProducts() ([]entities.Product, error)
+	FindProductsChangedBy(actor string, from, to time.Time) ([]entities.Product, error)
 	SaveProduct(product entities.Product) error
+	DuplicateProduct(id int, skuId string, createdAt time.Time, actor string) (int, error)
 	UpdateProduct(id int, product entities.Product) error
+	SetCategoryAvailability(category string, available bool, updatedAt time.Time) ([]int, error)
 	DeleteProduct(id int) error
+	HasOrders(id int) (bool, error)
+	GetProductPopularity(id int, from, to time.Time) (dto.ProductPopularityDTO, error)
+	GetProductRatings(id int) (dto.ProductRatingsDTO, error)
 }
 
+const (
+	CategoryMatchExact  = ""
+	CategoryMatchCase   = "case"
+	CategoryMatchAccent = "accent"
+)
+
 type productRepositoryGateway struct {
-	sqlClient sql.SQLClient
+	sqlClient         sql.SQLClient
+	categoryCondition string
 }
 
-func NewProductRepositoryGateway(sqlClient sql.SQLClient) ProductRepositoryGateway {
+// NewProductRepositoryGateway builds the gateway with the given category match mode: "" compares categories exactly,
+// "case" ignores letter case and "accent" ignores both letter case and accents.
+func NewProductRepositoryGateway(sqlClient sql.SQLClient, categoryMatchMode string) ProductRepositoryGateway {
+	categoryCondition := sqlscripts.CategoryExactCondition
+	switch strings.ToLower(strings.TrimSpace(categoryMatchMode)) {
+	case CategoryMatchCase:
+		categoryCondition = sqlscripts.CategoryCaseInsensitiveCondition
+	case CategoryMatchAccent:
+		categoryCondition = sqlscripts.CategoryAccentInsensitiveCondition
+	}
+
 	return productRepositoryGateway{
-		sqlClient: sqlClient,
+		sqlClient:         sqlClient,
+		categoryCondition: categoryCondition,
 	}
 }
 
@@ -38,8 +70,7 @@ func (r productRepositoryGateway) FindAllProducts(pageParams dto.PageParams) ([]
 
 	products := []entities.Product{}
 	for rows.Next() {
-		var product entities.Product
-		err = rows.Scan(&product.ID, &product.Name, &product.SkuId, &product.Description, &product.Category, &product.Price, &product.CreatedAt, &product.UpdatedAt)
+		product, err := scanProduct(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan all products, error %w", err)
 		}
@@ -51,7 +82,7 @@ func (r productRepositoryGateway) FindAllProducts(pageParams dto.PageParams) ([]
 }
 
 func (r productRepositoryGateway) FindProductsByCategory(pageParams dto.PageParams, category string) ([]entities.Product, error) {
-	getProductsByCategoryQuery := fmt.Sprintf(sqlscripts.GetProductsByCategoryQuery, pageParams.GetLimit(), pageParams.GetOffset())
+	getProductsByCategoryQuery := fmt.Sprintf(sqlscripts.GetProductsByCategoryQuery, r.categoryCondition, pageParams.GetLimit(), pageParams.GetOffset())
 
 	rows, err := r.sqlClient.Find(getProductsByCategoryQuery, category)
 	if err != nil {
@@ -61,8 +92,7 @@ func (r productRepositoryGateway) FindProductsByCategory(pageParams dto.PagePara
 
 	products := []entities.Product{}
 	for rows.Next() {
-		var product entities.Product
-		err = rows.Scan(&product.ID, &product.Name, &product.SkuId, &product.Description, &product.Category, &product.Price, &product.CreatedAt, &product.UpdatedAt)
+		product, err := scanProduct(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan products by category, error %w", err)
 		}
@@ -76,20 +106,146 @@ func (r productRepositoryGateway) FindProductsByCategory(pageParams dto.PagePara
 func (r productRepositoryGateway) FindProductById(id int) (entities.Product, error) {
 	row := r.sqlClient.FindOne(sqlscripts.GetProductByIdQuery, id)
 
-	var product entities.Product
-	err := row.Scan(&product.ID, &product.Name, &product.SkuId, &product.Description, &product.Category, &product.Price, &product.CreatedAt, &product.UpdatedAt)
+	product, err := scanProduct(row)
 	if err != nil {
+		if errors.Is(err, dbsql.ErrNoRows) {
+			return entities.Product{}, sql.ErrNotFound
+		}
 		return entities.Product{}, fmt.Errorf("failed to find product by id, error %w", err)
 	}
 
 	return product, nil
 }
 
+func (r productRepositoryGateway) FindProductBySKU(skuId string) (entities.Product, error) {
+	row := r.sqlClient.FindOne(sqlscripts.GetProductBySKUQuery, skuId)
+
+	product, err := scanProduct(row)
+	if err != nil {
+		if errors.Is(err, dbsql.ErrNoRows) {
+			return entities.Product{}, sql.ErrNotFound
+		}
+		return entities.Product{}, fmt.Errorf("failed to find product by sku [%s], error %w", skuId, err)
+	}
+
+	return product, nil
+}
+
+// FindMenuProducts lists every available product, ordered by name.
+func (r productRepositoryGateway) FindMenuProducts() ([]entities.Product, error) {
+	rows, err := r.sqlClient.Find(sqlscripts.GetMenuProductsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find menu products, error %w", err)
+	}
+	defer rows.Close()
+
+	products := []entities.Product{}
+	for rows.Next() {
+		product, err := scanProduct(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan menu products, error %w", err)
+		}
+
+		products = append(products, product)
+	}
+
+	return products, nil
+}
+
+func (r productRepositoryGateway) FindUnavailableProducts() ([]entities.Product, error) {
+	rows, err := r.sqlClient.Find(sqlscripts.GetUnavailableProductsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find unavailable products, error %w", err)
+	}
+	defer rows.Close()
+
+	products := []entities.Product{}
+	for rows.Next() {
+		product, err := scanProduct(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan unavailable products, error %w", err)
+		}
+
+		products = append(products, product)
+	}
+
+	return products, nil
+}
+
+// FindProductsChangedBy lists the products whose latest change was made by the actor within [from, to), the most
+// recently changed first.
+func (r productRepositoryGateway) FindProductsChangedBy(actor string, from, to time.Time) ([]entities.Product, error) {
+	rows, err := r.sqlClient.Find(sqlscripts.FindProductsChangedByQuery, actor, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find products changed by actor, error %w", err)
+	}
+	defer rows.Close()
+
+	products := []entities.Product{}
+	for rows.Next() {
+		product, err := scanProduct(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan products changed by actor, error %w", err)
+		}
+
+		products = append(products, product)
+	}
+
+	return products, nil
+}
+
+// scanProduct reads a product row, the sku, description, category and price columns are nullable.
+func scanProduct(row interface{ Scan(dest ...any) error }) (entities.Product, error) {
+	var product entities.Product
+	var skuId, description, category *string
+	var price *float64
+	var categories []byte
+
+	err := row.Scan(&product.ID, &product.Name, &skuId, &description, &category, &price, &product.CreatedAt, &product.UpdatedAt,
+		&product.MinQty, &product.MaxQty, &product.TaxCategory, &product.Unavailable, &product.CreatedBy, &product.UpdatedBy, &categories)
+	if err != nil {
+		return entities.Product{}, err
+	}
+
+	product.SkuId = valueOrZero(skuId)
+	product.Description = valueOrZero(description)
+	product.Category = valueOrZero(category)
+	product.Price = valueOrZero(price)
+
+	if len(categories) > 0 {
+		err = json.Unmarshal(categories, &product.Categories)
+		if err != nil {
+			return entities.Product{}, fmt.Errorf("failed to unmarshal product categories, error %w", err)
+		}
+	}
+
+	return product, nil
+}
+
+// marshalCategories encodes the categories of the product for the jsonb column, which never holds a JSON null.
+func marshalCategories(categories []string) (string, error) {
+	if categories == nil {
+		categories = []string{}
+	}
+
+	encoded, err := json.Marshal(categories)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal product categories, error %w", err)
+	}
+
+	return string(encoded), nil
+}
+
 func (r productRepositoryGateway) SaveProduct(product entities.Product) error {
 	inserProductCmd := fmt.Sprintf(sqlscripts.InsertProductCmd)
 
-	_, err := r.sqlClient.Exec(inserProductCmd, product.Name, product.SkuId, product.Description, product.Category,
-		product.Price, product.CreatedAt, product.UpdatedAt)
+	categories, err := marshalCategories(product.Categories)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.sqlClient.Exec(inserProductCmd, product.Name, product.SkuId, product.Description, product.Category,
+		product.Price, product.CreatedAt, product.UpdatedAt, product.MinQty, product.MaxQty, product.TaxCategory, product.CreatedBy, product.UpdatedBy, categories)
 	if err != nil {
 		return fmt.Errorf("failed to save product, error %w", err)
 	}
@@ -97,11 +253,32 @@ func (r productRepositoryGateway) SaveProduct(product entities.Product) error {
 	return nil
 }
 
+// DuplicateProduct copies the product with a new sku, returning the id of the copy.
+func (r productRepositoryGateway) DuplicateProduct(id int, skuId string, createdAt time.Time, actor string) (int, error) {
+	row := r.sqlClient.ExecWithReturn(sqlscripts.DuplicateProductCmd, id, skuId, createdAt, actor)
+
+	var duplicateId int
+	err := row.Scan(&duplicateId)
+	if err != nil {
+		if errors.Is(err, dbsql.ErrNoRows) {
+			return 0, sql.ErrNotFound
+		}
+		return 0, fmt.Errorf("failed to duplicate product [%d], error %w", id, err)
+	}
+
+	return duplicateId, nil
+}
+
 func (r productRepositoryGateway) UpdateProduct(id int, product entities.Product) error {
 	updateProductCmd := fmt.Sprintf(sqlscripts.UpdateProductCmd)
 
+	categories, err := marshalCategories(product.Categories)
+	if err != nil {
+		return err
+	}
+
 	result, err := r.sqlClient.Exec(updateProductCmd, id, product.Name, product.SkuId, product.Description, product.Category,
-		product.Price, product.UpdatedAt)
+		product.Price, product.UpdatedAt, product.MinQty, product.MaxQty, product.TaxCategory, product.UpdatedBy, categories)
 	if err != nil {
 		return fmt.Errorf("failed to update the product [%d], error %w", id, err)
 	}
@@ -118,10 +295,48 @@ func (r productRepositoryGateway) UpdateProduct(id int, product entities.Product
 	return nil
 }
 
+// SetCategoryAvailability flips every product of the category with a single statement, so either all of them are
+// updated or none is, and returns the ids of the updated products.
+func (r productRepositoryGateway) SetCategoryAvailability(category string, available bool, updatedAt time.Time) ([]int, error) {
+	setCategoryAvailabilityCmd := fmt.Sprintf(sqlscripts.SetCategoryAvailabilityCmd, r.categoryCondition)
+
+	var ids []int
+	// run within a transaction, as the writes read through Find are not retried on a deadlock
+	err := r.sqlClient.Transaction(func(tx sql.TransactionWrapper) error {
+		rows, err := tx.Find(setCategoryAvailabilityCmd, category, available, updatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to set availability of category [%s], error %w", category, err)
+		}
+		defer rows.Close()
+
+		ids = []int{}
+		for rows.Next() {
+			var id int
+			err = rows.Scan(&id)
+			if err != nil {
+				return fmt.Errorf("failed to scan products of category [%s], error %w", category, err)
+			}
+
+			ids = append(ids, id)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
 func (r productRepositoryGateway) DeleteProduct(id int) error {
 	deleteProductCmd := fmt.Sprintf(sqlscripts.DeleteProductCmd)
 
 	result, err := r.sqlClient.Exec(deleteProductCmd, id)
+	if sql.IsForeignKeyViolation(err) {
+		// an order referencing the product was placed after the usecase checked for it
+		return dto.ErrProductInOrder
+	}
 	if err != nil {
 		return fmt.Errorf("failed to delete the product [%d], error %v", id, err)
 	}
@@ -136,3 +351,43 @@ func (r productRepositoryGateway) DeleteProduct(id int) error {
 	}
 	return nil
 }
+
+func (r productRepositoryGateway) HasOrders(id int) (bool, error) {
+	row := r.sqlClient.FindOne(sqlscripts.HasOrdersQuery, id)
+
+	var hasOrders bool
+	err := row.Scan(&hasOrders)
+	if err != nil {
+		return false, fmt.Errorf("failed to check orders of product [%d], error %w", id, err)
+	}
+
+	return hasOrders, nil
+}
+
+func (r productRepositoryGateway) GetProductPopularity(id int, from, to time.Time) (dto.ProductPopularityDTO, error) {
+	row := r.sqlClient.FindOne(sqlscripts.GetProductPopularityQuery, id, from, to)
+
+	popularity := dto.ProductPopularityDTO{
+		ProductID: id,
+		From:      from,
+		To:        to,
+	}
+	err := row.Scan(&popularity.OrdersCount, &popularity.UnitsSold)
+	if err != nil {
+		return dto.ProductPopularityDTO{}, fmt.Errorf("failed to get product [%d] popularity, error %w", id, err)
+	}
+
+	return popularity, nil
+}
+
+func (r productRepositoryGateway) GetProductRatings(id int) (dto.ProductRatingsDTO, error) {
+	row := r.sqlClient.FindOne(sqlscripts.GetProductRatingsQuery, id)
+
+	ratings := dto.ProductRatingsDTO{ProductID: id}
+	err := row.Scan(&ratings.RatingsCount, &ratings.AverageRating)
+	if err != nil {
+		return dto.ProductRatingsDTO{}, fmt.Errorf("failed to get product [%d] ratings, error %w", id, err)
+	}
+
+	return ratings, nil
+}
@@ -0,0 +1,216 @@
+package gateways
+
+import (
+	"g37-lanchonete/internal/core/entities"
+	"g37-lanchonete/internal/core/usecases/dto"
+	"g37-lanchonete/internal/infra/drivers/sql"
+	mock_sql "g37-lanchonete/internal/infra/drivers/sql/mocks"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestProductRepositoryGateway_FindProductsByCategory_CategoryMatch(t *testing.T) {
+	tests := []struct {
+		name          string
+		mode          string
+		category      string
+		stored        string
+		wantCondition string
+	}{
+		{
+			name:          "should keep matching the category exactly when no mode is given",
+			category:      "Acompanhamento",
+			stored:        "Acompanhamento",
+			wantCondition: "WHERE c.category = $1",
+		},
+		{
+			name:          "should match a lowercase category ignoring letter case",
+			mode:          CategoryMatchCase,
+			category:      "acompanhamento",
+			stored:        "Acompanhamento",
+			wantCondition: "WHERE LOWER(c.category) = LOWER($1)",
+		},
+		{
+			name:          "should match an unaccented lowercase category ignoring letter case and accents",
+			mode:          CategoryMatchAccent,
+			category:      "porcao",
+			stored:        "Porção",
+			wantCondition: "LOWER(TRANSLATE(c.category, 'áàâãäéèêëíìîïóòôõöúùûüçÁÀÂÃÄÉÈÊËÍÌÎÏÓÒÔÕÖÚÙÛÜÇ', 'aaaaaeeeeiiiiooooouuuucAAAAAEEEEIIIIOOOOOUUUUC')) =",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			sqlClient := mock_sql.NewMockSQLClient(ctrl)
+			rows := mock_sql.NewMockRowsWrapper(ctrl)
+			productRepositoryGateway := NewProductRepositoryGateway(sqlClient, tt.mode)
+
+			var query string
+			var args []any
+			sqlClient.EXPECT().
+				Find(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(q string, a ...any) (sql.RowsWrapper, error) {
+					query, args = q, a
+					return rows, nil
+				}).
+				Times(1)
+			gomock.InOrder(
+				rows.EXPECT().Next().Return(true),
+				rows.EXPECT().Next().Return(false),
+			)
+			rows.EXPECT().
+				Scan(gomock.Any()).
+				DoAndReturn(func(dest ...any) error {
+					stored := tt.stored
+					*dest[4].(**string) = &stored
+					*dest[14].(*[]byte) = []byte(`["` + tt.stored + `", "Promoção"]`)
+					return nil
+				}).
+				Times(1)
+			rows.EXPECT().Close().Return(nil).Times(1)
+
+			products, err := productRepositoryGateway.FindProductsByCategory(dto.NewPageParams(0, 10), tt.category)
+
+			assert.NoError(t, err)
+			assert.True(t, strings.Contains(query, "jsonb_array_elements_text(p.categories)"), query)
+			assert.True(t, strings.Contains(query, tt.wantCondition), query)
+			assert.Equal(t, []any{tt.category}, args)
+			assert.Len(t, products, 1)
+			assert.Equal(t, tt.stored, products[0].Category)
+			assert.Equal(t, []string{tt.stored, "Promoção"}, products[0].Categories)
+		})
+	}
+}
+
+func TestProductRepositoryGateway_SetCategoryAvailability(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	sqlClient := mock_sql.NewMockSQLClient(ctrl)
+	tx := mock_sql.NewMockTransactionWrapper(ctrl)
+	rows := mock_sql.NewMockRowsWrapper(ctrl)
+	productRepositoryGateway := NewProductRepositoryGateway(sqlClient, CategoryMatchCase)
+	updatedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	var query string
+	sqlClient.EXPECT().
+		Transaction(gomock.Any()).
+		DoAndReturn(func(fn func(tx sql.TransactionWrapper) error) error {
+			return fn(tx)
+		}).
+		Times(1)
+	tx.EXPECT().
+		Find(gomock.Any(), gomock.Eq("acompanhamento"), gomock.Eq(false), gomock.Eq(updatedAt)).
+		DoAndReturn(func(q string, a ...any) (sql.RowsWrapper, error) {
+			query = q
+			return rows, nil
+		}).
+		Times(1)
+	gomock.InOrder(
+		rows.EXPECT().Next().Return(true),
+		rows.EXPECT().Next().Return(true),
+		rows.EXPECT().Next().Return(false),
+	)
+	gomock.InOrder(
+		rows.EXPECT().Scan(gomock.Any()).DoAndReturn(func(dest ...any) error { *dest[0].(*int) = 3; return nil }),
+		rows.EXPECT().Scan(gomock.Any()).DoAndReturn(func(dest ...any) error { *dest[0].(*int) = 5; return nil }),
+	)
+	rows.EXPECT().Close().Return(nil).Times(1)
+
+	ids, err := productRepositoryGateway.SetCategoryAvailability("acompanhamento", false, updatedAt)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []int{3, 5}, ids)
+	assert.True(t, strings.Contains(query, "SET unavailable = NOT $2, updated_at = $3"), query)
+	assert.True(t, strings.Contains(query, "WHERE LOWER(c.category) = LOWER($1)"), query)
+}
+
+func TestProductRepositoryGateway_FindProductById_NullOptionalColumns(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	sqlClient := mock_sql.NewMockSQLClient(ctrl)
+	row := mock_sql.NewMockRowWrapper(ctrl)
+	productRepositoryGateway := NewProductRepositoryGateway(sqlClient, "")
+
+	sqlClient.EXPECT().FindOne(gomock.Any(), gomock.Eq(7)).Return(row).Times(1)
+	row.EXPECT().
+		Scan(gomock.Any()).
+		DoAndReturn(func(dest ...any) error {
+			*dest[0].(*int) = 7
+			*dest[1].(*string) = "X-Burger"
+			// the sku, description, category and price columns are NULL
+			*dest[2].(**string) = nil
+			*dest[3].(**string) = nil
+			*dest[4].(**string) = nil
+			*dest[5].(**float64) = nil
+			return nil
+		}).
+		Times(1)
+
+	product, err := productRepositoryGateway.FindProductById(7)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 7, product.ID)
+	assert.Equal(t, "X-Burger", product.Name)
+	assert.Empty(t, product.SkuId)
+	assert.Empty(t, product.Description)
+	assert.Empty(t, product.Category)
+	assert.Zero(t, product.Price)
+	assert.Empty(t, product.Categories)
+}
+
+func TestProductRepositoryGateway_SaveProduct_Categories(t *testing.T) {
+	tests := []struct {
+		name           string
+		categories     []string
+		wantCategories string
+	}{
+		{
+			name:           "should store every category assigned to the product",
+			categories:     []string{"Lanche", "Promoção"},
+			wantCategories: `["Lanche","Promoção"]`,
+		},
+		{
+			name:           "should store an empty list when the product has no category",
+			wantCategories: `[]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			sqlClient := mock_sql.NewMockSQLClient(ctrl)
+			productRepositoryGateway := NewProductRepositoryGateway(sqlClient, "")
+
+			var args []any
+			sqlClient.EXPECT().
+				Exec(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(q string, a ...any) (sql.ResultWrapper, error) {
+					args = a
+					return nil, nil
+				}).
+				Times(1)
+
+			err := productRepositoryGateway.SaveProduct(entities.Product{Name: "Combo", Category: "Lanche", Categories: tt.categories})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCategories, args[len(args)-1])
+		})
+	}
+}
+
+func TestProductRepositoryGateway_DeleteProduct_ReferencedByOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	sqlClient := mock_sql.NewMockSQLClient(ctrl)
+	productRepositoryGateway := NewProductRepositoryGateway(sqlClient, "")
+
+	sqlClient.EXPECT().
+		Exec(gomock.Any(), 7).
+		Return(nil, &pq.Error{Code: "23503", Message: "update or delete on table \"products\" violates foreign key constraint"}).
+		Times(1)
+
+	err := productRepositoryGateway.DeleteProduct(7)
+
+	assert.ErrorIs(t, err, dto.ErrProductInOrder)
+}
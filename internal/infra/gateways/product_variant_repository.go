@@ -0,0 +1,66 @@
+package gateways
+
+import (
+	"fmt"
+	"g37-lanchonete/internal/core/entities"
+	"g37-lanchonete/internal/infra/drivers/sql"
+	"g37-lanchonete/internal/infra/gateways/sqlscripts"
+	"strings"
+)
+
+type ProductVariantRepositoryGateway interface {
+	SaveVariant(variant entities.ProductVariant) error
+	FindVariantsByProductIds(productIds []int) ([]entities.ProductVariant, error)
+}
+
+type productVariantRepositoryGateway struct {
+	sqlClient sql.SQLClient
+}
+
+func NewProductVariantRepositoryGateway(sqlClient sql.SQLClient) ProductVariantRepositoryGateway {
+	return productVariantRepositoryGateway{
+		sqlClient: sqlClient,
+	}
+}
+
+func (r productVariantRepositoryGateway) SaveVariant(variant entities.ProductVariant) error {
+	_, err := r.sqlClient.Exec(sqlscripts.InsertProductVariantCmd, variant.ProductID, variant.Name, variant.SkuId, variant.Price,
+		variant.CreatedAt, variant.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save variant of product [%d], error %w", variant.ProductID, err)
+	}
+
+	return nil
+}
+
+// FindVariantsByProductIds loads the variants of all the given products in a single query.
+func (r productVariantRepositoryGateway) FindVariantsByProductIds(productIds []int) ([]entities.ProductVariant, error) {
+	if len(productIds) == 0 {
+		return []entities.ProductVariant{}, nil
+	}
+
+	args := make([]any, len(productIds))
+	placeholders := make([]string, len(productIds))
+	for i, id := range productIds {
+		args[i] = id
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+	}
+
+	rows, err := r.sqlClient.Find(fmt.Sprintf(sqlscripts.FindProductVariantsQuery, strings.Join(placeholders, ", ")), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find product variants, error %w", err)
+	}
+	defer rows.Close()
+
+	variants := []entities.ProductVariant{}
+	for rows.Next() {
+		var variant entities.ProductVariant
+		err = rows.Scan(&variant.ID, &variant.ProductID, &variant.Name, &variant.SkuId, &variant.Price, &variant.CreatedAt, &variant.UpdatedAt, &variant.TaxCategory)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan product variants, error %w", err)
+		}
+		variants = append(variants, variant)
+	}
+
+	return variants, nil
+}
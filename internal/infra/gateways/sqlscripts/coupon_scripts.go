@@ -0,0 +1,28 @@
+package sqlscripts
+
+const GetCouponByCodeQuery = `
+	SELECT
+		c.code,
+		c.type,
+		c.value,
+		c.min_total,
+		c.expires_at
+	FROM public.coupons as c
+	WHERE c.code = $1
+`
+
+// an order is a redemption only when the coupon took a discount off its total
+const GetCouponUsageQuery = `
+	SELECT
+		o.coupon,
+		COUNT(o.id),
+		COALESCE(SUM(o.discount_amount), 0)
+	FROM public.orders o
+	WHERE o.coupon <> ''
+		AND o.discount_amount > 0
+		AND o.status NOT IN ('CREATED', 'CANCELLED')
+		AND o.created_at >= $1
+		AND o.created_at < $2
+	GROUP BY o.coupon
+	ORDER BY COUNT(o.id) DESC, o.coupon ASC
+`
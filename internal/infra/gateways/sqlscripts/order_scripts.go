@@ -3,23 +3,140 @@ package sqlscripts
 const FindAllOrdersQuery = `
 	SELECT 
 		o.id,
-		o.coupon,
+		COALESCE(o.number, ''),
+		COALESCE(o.coupon, ''),
+		o.subtotal_amount,
+		o.tax_amount,
 		o.total_amount,
 		o.status,
+		o.fulfillment_type,
+		o.delivery_address,
+		o.notes,
 		o.created_at,
-		c.id,
-		c.name, 
-		c.cpf, 
-		c.email,
+		COALESCE(c.id, 0),
+		COALESCE(c.name, ''),
+		COALESCE(c.cpf, ''),
+		COALESCE(c.email, ''),
 		c.created_at,
-		c.updated_at
+		c.updated_at,
+		o.store_credit_amount,
+		o.discount_amount
 	FROM public.orders o
 	LEFT JOIN public.customers c ON o.customer_id = c.id
-	WHERE o.status <> 'DONE'
-	ORDER BY array_position(array['READY','IN_PROGRESS','RECEIVED'], o.status), o.created_at ASC
+	WHERE %s
+		AND ($3::text = '' OR o.fulfillment_type = $3)
+	ORDER BY %s
 	LIMIT $1 OFFSET $2
 `
 
+// the id breaks the ties of the sorts, so the pages of orders created at the same time do not overlap
+const OrderSortNewestClause = `o.created_at DESC, o.id DESC`
+
+const OrderSortOldestClause = `o.created_at ASC, o.id ASC`
+
+const OrderSortPriorityClause = `array_position(array['READY','IN_PROGRESS','RECEIVED'], o.status), o.created_at ASC, o.id ASC`
+
+// the queue selects only what the kitchen shows, always in the priority sort
+const FindQueueOrdersQuery = `
+	SELECT
+		o.id,
+		COALESCE(o.number, ''),
+		o.status,
+		o.fulfillment_type,
+		o.notes,
+		o.created_at
+	FROM public.orders o
+	WHERE %s
+		AND ($3::text = '' OR o.fulfillment_type = $3)
+	ORDER BY ` + OrderSortPriorityClause + `
+	LIMIT $1 OFFSET $2
+`
+
+const FindQueueOrderItemsQuery = `
+	SELECT
+		COALESCE(p.name, ''),
+		COALESCE(v.name, ''),
+		oi.quantity,
+		oi.type,
+		COALESCE(p.categories, '[]'::jsonb)
+	FROM public.order_items oi
+	LEFT JOIN public.products p ON oi.product_id = p.id
+	LEFT JOIN public.product_variants v ON oi.variant_id = v.id
+	WHERE oi.order_id = $1%s
+	ORDER BY oi.id
+`
+
+// the station conditions match any of the categories assigned to the product against the categories prepared at the
+// station, given as the %s placeholders
+const QueueOrderStationCondition = `
+		AND EXISTS (
+			SELECT 1
+			FROM public.order_items si
+			JOIN public.products sp ON si.product_id = sp.id
+			CROSS JOIN jsonb_array_elements_text(sp.categories) AS sc(category)
+			WHERE si.order_id = o.id AND LOWER(sc.category) IN (%s)
+		)`
+
+const QueueOrderItemStationCondition = `
+		AND EXISTS (SELECT 1 FROM jsonb_array_elements_text(p.categories) AS c(category) WHERE LOWER(c.category) IN (%s))`
+
+const FindOrderByIdQuery = `
+	SELECT 
+		o.id,
+		COALESCE(o.number, ''),
+		COALESCE(o.coupon, ''),
+		o.subtotal_amount,
+		o.tax_amount,
+		o.total_amount,
+		o.status,
+		o.fulfillment_type,
+		o.delivery_address,
+		o.notes,
+		o.created_at,
+		COALESCE(c.id, 0),
+		COALESCE(c.name, ''),
+		COALESCE(c.cpf, ''),
+		COALESCE(c.email, ''),
+		c.created_at,
+		c.updated_at,
+		o.store_credit_amount,
+		o.discount_amount
+	FROM public.orders o
+	LEFT JOIN public.customers c ON o.customer_id = c.id
+	WHERE o.id = $1
+`
+
+// daily numbers repeat every day, so the most recent order with the number is the one on the receipt
+const FindOrderByNumberQuery = `
+	SELECT 
+		o.id,
+		COALESCE(o.number, ''),
+		COALESCE(o.coupon, ''),
+		o.subtotal_amount,
+		o.tax_amount,
+		o.total_amount,
+		o.status,
+		o.fulfillment_type,
+		o.delivery_address,
+		o.notes,
+		o.created_at,
+		COALESCE(c.id, 0),
+		COALESCE(c.name, ''),
+		COALESCE(c.cpf, ''),
+		COALESCE(c.email, ''),
+		c.created_at,
+		c.updated_at,
+		o.store_credit_amount,
+		o.discount_amount
+	FROM public.orders o
+	LEFT JOIN public.customers c ON o.customer_id = c.id
+	WHERE o.number = $1
+	ORDER BY o.created_at DESC
+	LIMIT 1
+`
+
+const DefaultOrdersStatusCondition = `o.status NOT IN ('DONE', 'CANCELLED')`
+
 const FindOrderItems = `
 	SELECT
 		oi.id,
@@ -32,9 +149,15 @@ const FindOrderItems = `
 		p.created_at,
 		p.updated_at,
 		oi.quantity,
-		oi.type
+		oi.type,
+		v.id,
+		v.name,
+		v.sku_id,
+		v.price,
+		oi.status
 	FROM public.order_items oi
 	LEFT JOIN public.products p ON oi.product_id = p.id
+	LEFT JOIN public.product_variants v ON oi.variant_id = v.id
 	WHERE oi.order_id = $1
 `
 
@@ -45,18 +168,220 @@ const FindOrderStatusByIdQuery = `
 	WHERE o.id = $1	
 `
 
+const FindOrderStatusChangeByIdQuery = `
+	SELECT
+		o.status,
+		COALESCE((SELECT MAX(h.changed_at) FROM public.order_status_history h WHERE h.order_id = o.id), o.created_at)
+	FROM public.orders o
+	WHERE o.id = $1
+`
+
+const FindOrderStatusesByIdsQuery = `
+	SELECT
+		o.id,
+		o.status
+	FROM public.orders o
+	WHERE o.id IN (%s)
+`
+
 const InsertOrderCmd = `
-	INSERT INTO public.orders(coupon, total_amount, customer_id, status, created_at)
-	VALUES ($1, $2, $3, $4, $5) RETURNING id
+	INSERT INTO public.orders(coupon, subtotal_amount, tax_amount, total_amount, customer_id, status, fulfillment_type, delivery_address, notes, created_at, store_credit_amount, discount_amount)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id
 `
 
 const InsertOrderItemCmd = `
-	INSERT INTO public.order_items(order_id, product_id, quantity, type)
-	VALUES ($1, $2, $3, $4)
+	INSERT INTO public.order_items(order_id, product_id, quantity, type, variant_id)
+	VALUES ($1, $2, $3, $4, $5)
+`
+
+const InsertOrderItemWithReturnCmd = `
+	INSERT INTO public.order_items(order_id, product_id, quantity, type, variant_id)
+	VALUES ($1, $2, $3, $4, $5) RETURNING id
+`
+
+const DeleteOrderItemCmd = `
+	DELETE FROM public.order_items
+	WHERE order_id = $1 AND id = $2
 `
 
+const UpdateOrderItemStatusCmd = `
+	UPDATE public.order_items oi
+	SET status = $3
+	FROM public.orders o
+	WHERE oi.order_id = $1 AND oi.id = $2 AND o.id = oi.order_id AND o.status = 'IN_PROGRESS'
+`
+
+const CountPendingOrderItemsQuery = `
+	SELECT COUNT(*)
+	FROM public.order_items
+	WHERE order_id = $1 AND status <> 'READY'
+`
+
+const AdvanceReadyOrderCmd = `
+	WITH advanced AS (
+		UPDATE public.orders
+		SET status = 'READY'
+		WHERE id = $1 AND status = 'IN_PROGRESS'
+		RETURNING id
+	)
+	INSERT INTO public.order_status_history(order_id, from_status, to_status, changed_at)
+	SELECT id, 'IN_PROGRESS', 'READY', NOW()
+	FROM advanced
+`
+
+const UpdateEditableOrderTotalsCmd = `
+	UPDATE public.orders
+	SET subtotal_amount = $2, tax_amount = $3, total_amount = $4, discount_amount = $5, store_credit_amount = $6
+	WHERE id = $1 AND status = 'CREATED'
+`
+
+const ConfirmOrderPaymentCmd = `
+	WITH paid AS (
+		UPDATE public.orders
+		SET status = 'PAID'
+		WHERE id = $1 AND status = 'CREATED'
+		RETURNING id
+	)
+	INSERT INTO public.order_status_history(order_id, from_status, to_status, changed_at)
+	SELECT id, 'CREATED', 'PAID', NOW()
+	FROM paid
+`
+
+// the previous status is locked and read ahead of the update, so the transition is recorded on the status history
 const UpdateOrderStatusCmd = `
+	WITH previous AS (
+		SELECT id, status
+		FROM public.orders
+		WHERE id = $1 AND ($3::text = '' OR status = $3)
+		FOR UPDATE
+	), history AS (
+		INSERT INTO public.order_status_history(order_id, from_status, to_status, changed_at)
+		SELECT id, status, $2::text, NOW()
+		FROM previous
+		WHERE status <> $2::text
+	)
+	UPDATE public.orders o
+	SET status = $2,
+		completed_at = CASE WHEN $2::text = 'DONE' THEN COALESCE(o.completed_at, NOW()) END
+	FROM previous p
+	WHERE o.id = p.id
+`
+
+const ReopenOrderCmd = `
+	WITH reopened AS (
+		UPDATE public.orders
+		SET status = 'READY', completed_at = NULL
+		WHERE id = $1 AND status = 'DONE' AND completed_at >= $2
+		RETURNING id
+	)
+	INSERT INTO public.order_status_history(order_id, from_status, to_status, changed_at)
+	SELECT id, 'DONE', 'READY', $3
+	FROM reopened
+`
+
+const CancelUnpaidOrdersCmd = `
+	WITH cancelled AS (
+		UPDATE public.orders o
+		SET status = 'CANCELLED'
+		FROM public.customers c
+		WHERE o.customer_id = c.id AND c.cpf = $1 AND o.status = 'CREATED'
+		RETURNING o.id
+	), history AS (
+		INSERT INTO public.order_status_history(order_id, from_status, to_status, changed_at)
+		SELECT id, 'CREATED', 'CANCELLED', $2
+		FROM cancelled
+	)
+	SELECT id FROM cancelled
+`
+
+// each transition took from the previous one of the order, or from its creation when leaving CREATED, so the orders
+// whose earlier transitions were not recorded are left out
+const GetStatusTransitionStatsQuery = `
+	WITH transitions AS (
+		SELECT
+			h.from_status,
+			h.to_status,
+			h.changed_at,
+			h.changed_at - COALESCE(
+				LAG(h.changed_at) OVER (PARTITION BY h.order_id ORDER BY h.changed_at, h.id),
+				CASE WHEN h.from_status = 'CREATED' THEN o.created_at END
+			) AS duration
+		FROM public.order_status_history h
+		INNER JOIN public.orders o ON o.id = h.order_id
+	)
+	SELECT
+		t.from_status,
+		t.to_status,
+		COUNT(*),
+		EXTRACT(EPOCH FROM AVG(t.duration)),
+		EXTRACT(EPOCH FROM PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY t.duration)),
+		EXTRACT(EPOCH FROM PERCENTILE_CONT(0.9) WITHIN GROUP (ORDER BY t.duration)),
+		EXTRACT(EPOCH FROM PERCENTILE_CONT(0.95) WITHIN GROUP (ORDER BY t.duration))
+	FROM transitions t
+	WHERE t.duration IS NOT NULL
+		AND t.changed_at >= $1
+		AND t.changed_at < $2
+	GROUP BY t.from_status, t.to_status
+	ORDER BY t.from_status, t.to_status
+`
+
+const FindCompletedOrdersQuery = `
+	SELECT
+		o.id,
+		COALESCE(o.number, ''),
+		o.completed_at
+	FROM public.orders o
+	WHERE o.status = 'DONE' AND o.completed_at >= $1
+	ORDER BY o.completed_at DESC
+	LIMIT $2
+`
+
+const FindOrderQRCodeByIdQuery = `
+	SELECT
+		COALESCE(o.qr_code, ''),
+		o.qr_code_expires_at,
+		o.status
+	FROM public.orders o
+	WHERE o.id = $1
+`
+
+const UpdateOrderQRCodeCmd = `
+	UPDATE public.orders
+	SET qr_code = $2, qr_code_expires_at = $3
+	WHERE id = $1
+`
+
+const UpdateOrderNumberCmd = `
 	UPDATE public.orders
-	SET status = $2
+	SET number = $2
 	WHERE id = $1
 `
+
+const NextDailyOrderSequenceCmd = `
+	INSERT INTO public.order_number_sequences(day, value)
+	VALUES ($1, 1)
+	ON CONFLICT (day) DO UPDATE SET value = public.order_number_sequences.value + 1
+	RETURNING value
+`
+
+const InsertOrderFeedbackCmd = `
+	INSERT INTO public.order_feedback(order_id, rating, comment, created_at)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (order_id) DO NOTHING
+	RETURNING id
+`
+
+// the orders are detached from the customer, so the cpf and contacts are no longer reachable from them
+const AnonymizeDoneOrdersCmd = `
+	WITH anonymized AS (
+		UPDATE public.orders
+		SET customer_id = NULL, delivery_address = NULL, notes = '', anonymized_at = $2
+		WHERE status = 'DONE' AND created_at < $1 AND anonymized_at IS NULL
+		RETURNING id
+	), feedback AS (
+		UPDATE public.order_feedback
+		SET comment = NULL
+		WHERE order_id IN (SELECT id FROM anonymized)
+	)
+	SELECT COUNT(*) FROM anonymized
+`
@@ -0,0 +1,16 @@
+package sqlscripts
+
+const InsertProductPriceChangeCmd = `
+	INSERT INTO public.product_price_history(product_id, price, changed_at)
+	VALUES ($1, $2, $3)
+`
+
+const FindProductPriceHistoryQuery = `
+	SELECT
+		h.product_id,
+		h.price,
+		h.changed_at
+	FROM public.product_price_history h
+	WHERE h.product_id = $1
+	ORDER BY h.changed_at ASC, h.id ASC
+`
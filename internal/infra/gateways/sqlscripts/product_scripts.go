@@ -9,7 +9,14 @@ const GetAllProductsQuery = `
 		p.category,
 		p.price,
 		p.created_at,
-		p.updated_at
+		p.updated_at,
+		p.min_qty,
+		p.max_qty,
+		p.tax_category,
+		p.unavailable,
+		p.created_by,
+		p.updated_by,
+		p.categories
 	FROM public.products as p
 	ORDER BY p.name ASC
 	LIMIT %d OFFSET %d
@@ -24,13 +31,97 @@ const GetProductsByCategoryQuery = `
 		p.category,
 		p.price,
 		p.created_at,
-		p.updated_at
+		p.updated_at,
+		p.min_qty,
+		p.max_qty,
+		p.tax_category,
+		p.unavailable,
+		p.created_by,
+		p.updated_by,
+		p.categories
 	FROM public.products as p
-	WHERE p.category = $1
+	WHERE %s
 	ORDER BY p.name ASC
 	LIMIT %d OFFSET %d
 `
 
+const GetMenuProductsQuery = `
+	SELECT 
+		p.id,
+		p.name, 
+		p.sku_id, 
+		p.description,
+		p.category,
+		p.price,
+		p.created_at,
+		p.updated_at,
+		p.min_qty,
+		p.max_qty,
+		p.tax_category,
+		p.unavailable,
+		p.created_by,
+		p.updated_by,
+		p.categories
+	FROM public.products as p
+	WHERE NOT p.unavailable
+	ORDER BY p.name ASC
+`
+
+const GetUnavailableProductsQuery = `
+	SELECT 
+		p.id,
+		p.name, 
+		p.sku_id, 
+		p.description,
+		p.category,
+		p.price,
+		p.created_at,
+		p.updated_at,
+		p.min_qty,
+		p.max_qty,
+		p.tax_category,
+		p.unavailable,
+		p.created_by,
+		p.updated_by,
+		p.categories
+	FROM public.products as p
+	WHERE p.unavailable
+	ORDER BY p.name ASC
+`
+
+// only the latest change of the product is kept, by updated_by at updated_at
+const FindProductsChangedByQuery = `
+	SELECT 
+		p.id,
+		p.name, 
+		p.sku_id, 
+		p.description,
+		p.category,
+		p.price,
+		p.created_at,
+		p.updated_at,
+		p.min_qty,
+		p.max_qty,
+		p.tax_category,
+		p.unavailable,
+		p.created_by,
+		p.updated_by,
+		p.categories
+	FROM public.products as p
+	WHERE p.updated_by = $1 AND p.updated_at >= $2 AND p.updated_at < $3
+	ORDER BY p.updated_at DESC, p.id DESC
+`
+
+// the category conditions match any of the categories assigned to the product, the primary one included
+const CategoryExactCondition = `EXISTS (SELECT 1 FROM jsonb_array_elements_text(p.categories) AS c(category) WHERE c.category = $1)`
+
+const CategoryCaseInsensitiveCondition = `EXISTS (SELECT 1 FROM jsonb_array_elements_text(p.categories) AS c(category) WHERE LOWER(c.category) = LOWER($1))`
+
+const CategoryAccentInsensitiveCondition = `EXISTS (
+	SELECT 1 FROM jsonb_array_elements_text(p.categories) AS c(category)
+	WHERE LOWER(TRANSLATE(c.category, 'áàâãäéèêëíìîïóòôõöúùûüçÁÀÂÃÄÉÈÊËÍÌÎÏÓÒÔÕÖÚÙÛÜÇ', 'aaaaaeeeeiiiiooooouuuucAAAAAEEEEIIIIOOOOOUUUUC')) =
+		LOWER(TRANSLATE($1, 'áàâãäéèêëíìîïóòôõöúùûüçÁÀÂÃÄÉÈÊËÍÌÎÏÓÒÔÕÖÚÙÛÜÇ', 'aaaaaeeeeiiiiooooouuuucAAAAAEEEEIIIIOOOOOUUUUC')))`
+
 const GetProductByIdQuery = `
 	SELECT 
 		p.id,
@@ -40,23 +131,105 @@ const GetProductByIdQuery = `
 		p.category,
 		p.price,
 		p.created_at,
-		p.updated_at
+		p.updated_at,
+		p.min_qty,
+		p.max_qty,
+		p.tax_category,
+		p.unavailable,
+		p.created_by,
+		p.updated_by,
+		p.categories
 	FROM public.products as p
 	WHERE p.id = $1
 `
 
+// sku ids are not unique, so the oldest product with the sku is the one found
+const GetProductBySKUQuery = `
+	SELECT 
+		p.id,
+		p.name, 
+		p.sku_id, 
+		p.description,
+		p.category,
+		p.price,
+		p.created_at,
+		p.updated_at,
+		p.min_qty,
+		p.max_qty,
+		p.tax_category,
+		p.unavailable,
+		p.created_by,
+		p.updated_by,
+		p.categories
+	FROM public.products as p
+	WHERE p.sku_id = $1
+	ORDER BY p.id ASC
+	LIMIT 1
+`
+
 const InsertProductCmd = `
-	INSERT INTO public.products(name, sku_id, description, category, price, created_at, updated_at)
-	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	INSERT INTO public.products(name, sku_id, description, category, price, created_at, updated_at, min_qty, max_qty, tax_category, created_by, updated_by, categories)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+`
+
+// the copy is unavailable until reviewed, so it does not show up on the menu as a duplicate of the original
+const DuplicateProductCmd = `
+	INSERT INTO public.products(name, sku_id, description, category, price, created_at, updated_at, min_qty, max_qty, tax_category, unavailable, created_by, updated_by, categories)
+	SELECT p.name, $2, p.description, p.category, p.price, $3, $3, p.min_qty, p.max_qty, p.tax_category, true, $4, $4, p.categories
+	FROM public.products as p
+	WHERE p.id = $1
+	RETURNING id
 `
 
 const UpdateProductCmd = `
 	UPDATE public.products
-	SET name = $2, sku_id = $3, description = $4, category = $5, price = $6, updated_at = $7
+	SET name = $2, sku_id = $3, description = $4, category = $5, price = $6, updated_at = $7, min_qty = $8, max_qty = $9, tax_category = $10, updated_by = $11, categories = $12
 	WHERE id = $1
 `
 
+const SetCategoryAvailabilityCmd = `
+	UPDATE public.products as p
+	SET unavailable = NOT $2, updated_at = $3
+	WHERE %s
+	RETURNING p.id
+`
+
 const DeleteProductCmd = `
 	DELETE FROM public.products
 	WHERE id = $1
 `
+
+// the order items keep referencing the product after the order is done or cancelled, so any of them blocks the deletion
+const HasOrdersQuery = `
+	SELECT EXISTS (
+		SELECT 1
+		FROM public.order_items oi
+		WHERE oi.product_id = $1
+	)
+`
+
+// the units of cancelled orders were never sold, so they do not count towards the popularity
+const GetProductPopularityQuery = `
+	SELECT
+		COUNT(DISTINCT oi.order_id),
+		COALESCE(SUM(oi.quantity), 0)
+	FROM public.order_items oi
+	INNER JOIN public.orders o ON oi.order_id = o.id
+	WHERE oi.product_id = $1
+		AND o.status <> 'CANCELLED'
+		AND o.created_at >= $2
+		AND o.created_at < $3
+`
+
+const GetProductRatingsQuery = `
+	SELECT
+		COUNT(f.id),
+		COALESCE(AVG(f.rating), 0)
+	FROM public.order_feedback f
+	WHERE EXISTS (
+		SELECT 1
+		FROM public.order_items oi
+		WHERE oi.order_id = f.order_id
+			AND oi.product_id = $1
+	)
+`
@@ -0,0 +1,22 @@
+package sqlscripts
+
+const InsertProductVariantCmd = `
+	INSERT INTO public.product_variants(product_id, name, sku_id, price, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $5, $6)
+`
+
+const FindProductVariantsQuery = `
+	SELECT
+		v.id,
+		v.product_id,
+		v.name,
+		v.sku_id,
+		v.price,
+		v.created_at,
+		v.updated_at,
+		p.tax_category
+	FROM public.product_variants v
+	INNER JOIN public.products p ON v.product_id = p.id
+	WHERE v.product_id IN (%s)
+	ORDER BY v.product_id ASC, v.price ASC, v.id ASC
+`
@@ -0,0 +1,2 @@
+ALTER TABLE public.orders DROP COLUMN IF EXISTS "delivery_address";
+ALTER TABLE public.orders DROP COLUMN IF EXISTS "fulfillment_type";
@@ -0,0 +1,2 @@
+ALTER TABLE public.orders ADD COLUMN IF NOT EXISTS "fulfillment_type" text not null default 'DINE_IN';
+ALTER TABLE public.orders ADD COLUMN IF NOT EXISTS "delivery_address" text;
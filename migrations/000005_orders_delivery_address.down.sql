@@ -0,0 +1,2 @@
+ALTER TABLE public.orders ALTER COLUMN "delivery_address" TYPE text
+    USING NULLIF(concat_ws(', ', NULLIF("delivery_address"->>'street', ''), NULLIF("delivery_address"->>'number', ''), NULLIF("delivery_address"->>'complement', ''), NULLIF("delivery_address"->>'zip', ''), NULLIF("delivery_address"->>'city', '')), '');
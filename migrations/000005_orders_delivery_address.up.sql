@@ -0,0 +1,2 @@
+ALTER TABLE public.orders ALTER COLUMN "delivery_address" TYPE jsonb
+    USING CASE WHEN "delivery_address" IS NULL THEN NULL ELSE jsonb_build_object('street', "delivery_address") END;
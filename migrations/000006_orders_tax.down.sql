@@ -0,0 +1,2 @@
+ALTER TABLE public.orders DROP COLUMN IF EXISTS "tax_amount";
+ALTER TABLE public.orders DROP COLUMN IF EXISTS "subtotal_amount";
@@ -0,0 +1,3 @@
+ALTER TABLE public.orders ADD COLUMN IF NOT EXISTS "subtotal_amount" numeric;
+ALTER TABLE public.orders ADD COLUMN IF NOT EXISTS "tax_amount" numeric not null default 0;
+UPDATE public.orders SET subtotal_amount = total_amount WHERE subtotal_amount IS NULL;
@@ -0,0 +1 @@
+ALTER TABLE public.orders DROP COLUMN IF EXISTS "notes";
@@ -0,0 +1 @@
+ALTER TABLE public.orders ADD COLUMN IF NOT EXISTS "notes" text not null default '';
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS public.product_price_history (
+	"id" serial primary key,
+	"product_id" integer not null,
+	"price" numeric not null,
+	"changed_at" timestamptz not null,
+	CONSTRAINT "FK_product_price_history_product" FOREIGN KEY (product_id) REFERENCES public.products(id) ON DELETE CASCADE
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS public.order_number_sequences;
+ALTER TABLE public.orders DROP COLUMN IF EXISTS "number";
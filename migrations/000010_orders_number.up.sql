@@ -0,0 +1,5 @@
+ALTER TABLE public.orders ADD COLUMN IF NOT EXISTS "number" text;
+CREATE TABLE IF NOT EXISTS public.order_number_sequences (
+	"day" date primary key,
+	"value" integer not null
+);
@@ -0,0 +1,2 @@
+ALTER TABLE public.order_items DROP COLUMN IF EXISTS "variant_id";
+DROP TABLE IF EXISTS public.product_variants;
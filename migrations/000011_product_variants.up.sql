@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS public.product_variants (
+	"id" serial primary key,
+	"product_id" integer not null,
+	"name" text not null,
+	"sku_id" text,
+	"price" numeric not null,
+	"created_at" timestamptz not null,
+	"updated_at" timestamptz not null,
+	CONSTRAINT "FK_product_variants_product" FOREIGN KEY (product_id) REFERENCES public.products(id) ON DELETE CASCADE
+);
+ALTER TABLE public.order_items ADD COLUMN IF NOT EXISTS "variant_id" integer;
+ALTER TABLE public.order_items ADD CONSTRAINT "FK_order_items_variant" FOREIGN KEY (variant_id) REFERENCES public.product_variants(id);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS public.order_feedback (
+	"id" serial primary key,
+	"order_id" integer not null unique,
+	"rating" integer not null CHECK (rating BETWEEN 1 AND 5),
+	"comment" text,
+	"created_at" timestamptz not null,
+	CONSTRAINT "FK_order_feedback_order" FOREIGN KEY (order_id) REFERENCES public.orders(id)
+);
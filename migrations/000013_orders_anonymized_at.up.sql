@@ -0,0 +1 @@
+ALTER TABLE public.orders ADD COLUMN IF NOT EXISTS "anonymized_at" timestamptz;
@@ -0,0 +1,2 @@
+ALTER TABLE public.products DROP COLUMN IF EXISTS "max_qty";
+ALTER TABLE public.products DROP COLUMN IF EXISTS "min_qty";
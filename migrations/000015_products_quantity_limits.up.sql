@@ -0,0 +1,2 @@
+ALTER TABLE public.products ADD COLUMN IF NOT EXISTS "min_qty" integer not null default 0;
+ALTER TABLE public.products ADD COLUMN IF NOT EXISTS "max_qty" integer not null default 0;
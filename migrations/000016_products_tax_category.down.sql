@@ -0,0 +1 @@
+ALTER TABLE public.products DROP COLUMN IF EXISTS "tax_category";
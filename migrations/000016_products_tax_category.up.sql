@@ -0,0 +1 @@
+ALTER TABLE public.products ADD COLUMN IF NOT EXISTS "tax_category" text not null default '';
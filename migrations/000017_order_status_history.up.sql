@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS public.order_status_history (
+	"id" serial primary key,
+	"order_id" integer not null,
+	"from_status" text not null,
+	"to_status" text not null,
+	"changed_at" timestamptz not null,
+	CONSTRAINT "FK_order_status_history_order" FOREIGN KEY (order_id) REFERENCES public.orders(id)
+);
@@ -0,0 +1,2 @@
+-- the original letter case of the coupons is not kept, there is nothing to revert
+SELECT 1;
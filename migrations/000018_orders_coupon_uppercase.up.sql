@@ -0,0 +1 @@
+UPDATE public.orders SET coupon = UPPER(TRIM(coupon)) WHERE coupon <> UPPER(TRIM(coupon));
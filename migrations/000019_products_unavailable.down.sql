@@ -0,0 +1 @@
+ALTER TABLE public.products DROP COLUMN IF EXISTS "unavailable";
\ No newline at end of file
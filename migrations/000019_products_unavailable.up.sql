@@ -0,0 +1 @@
+ALTER TABLE public.products ADD COLUMN IF NOT EXISTS "unavailable" boolean not null default false;
\ No newline at end of file
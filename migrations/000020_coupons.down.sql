@@ -0,0 +1 @@
+DROP TABLE IF EXISTS public.coupons;
\ No newline at end of file
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS public.coupons (
+	"code" text primary key,
+	"type" text not null,
+	"value" numeric(10,2) not null,
+	"min_total" numeric(10,2) not null default 0,
+	"expires_at" timestamptz,
+	"created_at" timestamptz not null default now()
+);
@@ -0,0 +1 @@
+ALTER TABLE public.orders DROP COLUMN IF EXISTS "store_credit_amount";
@@ -0,0 +1 @@
+ALTER TABLE public.orders ADD COLUMN IF NOT EXISTS "store_credit_amount" numeric(10,2) not null default 0;
@@ -0,0 +1,2 @@
+ALTER TABLE public.products DROP COLUMN IF EXISTS "created_by";
+ALTER TABLE public.products DROP COLUMN IF EXISTS "updated_by";
\ No newline at end of file
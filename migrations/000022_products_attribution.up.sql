@@ -0,0 +1,2 @@
+ALTER TABLE public.products ADD COLUMN IF NOT EXISTS "created_by" varchar(100) not null default 'system';
+ALTER TABLE public.products ADD COLUMN IF NOT EXISTS "updated_by" varchar(100) not null default 'system';
\ No newline at end of file
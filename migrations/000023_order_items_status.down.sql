@@ -0,0 +1 @@
+ALTER TABLE public.order_items DROP COLUMN IF EXISTS "status";
\ No newline at end of file
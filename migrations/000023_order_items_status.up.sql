@@ -0,0 +1 @@
+ALTER TABLE public.order_items ADD COLUMN IF NOT EXISTS "status" varchar(20) not null default 'PENDING';
\ No newline at end of file
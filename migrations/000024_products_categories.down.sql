@@ -0,0 +1 @@
+ALTER TABLE public.products DROP COLUMN IF EXISTS "categories";
\ No newline at end of file
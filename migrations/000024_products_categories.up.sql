@@ -0,0 +1,2 @@
+ALTER TABLE public.products ADD COLUMN IF NOT EXISTS "categories" jsonb not null default '[]';
+UPDATE public.products SET "categories" = jsonb_build_array(category) WHERE category IS NOT NULL AND category <> '';
\ No newline at end of file
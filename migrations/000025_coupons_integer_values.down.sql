@@ -0,0 +1,2 @@
+ALTER TABLE public.coupons ALTER COLUMN "value" TYPE numeric(10,2) USING "value" / 100.0;
+ALTER TABLE public.coupons ALTER COLUMN "min_total" TYPE numeric(10,2) USING "min_total" / 100.0;
\ No newline at end of file
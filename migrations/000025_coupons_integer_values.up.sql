@@ -0,0 +1,3 @@
+-- FIXED coupons keep their value in cents and PERCENT coupons in basis points, 12.5% being 1250
+ALTER TABLE public.coupons ALTER COLUMN "value" TYPE bigint USING ROUND("value" * 100);
+ALTER TABLE public.coupons ALTER COLUMN "min_total" TYPE bigint USING ROUND("min_total" * 100);
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE public.orders ADD COLUMN IF NOT EXISTS "qr_code_expires_at" timestamptz;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE public.orders DROP COLUMN IF EXISTS "discount_amount";
\ No newline at end of file
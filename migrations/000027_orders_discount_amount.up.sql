@@ -0,0 +1 @@
+ALTER TABLE public.orders ADD COLUMN IF NOT EXISTS "discount_amount" numeric(10,2) not null default 0;
\ No newline at end of file